@@ -61,6 +61,27 @@ func GetArmoriesConfig() []*ArmoryConfig {
 	return append(armoryConfigs, DefaultArmoryConfig)
 }
 
+// SaveArmoriesConfig - Save armories to the config file, the default armory is always added on load
+func SaveArmoriesConfig(armoryConfigs []*ArmoryConfig) error {
+	var saved []*ArmoryConfig
+	for _, armoryConfig := range armoryConfigs {
+		if armoryConfig.Name == DefaultArmoryName {
+			continue
+		}
+		config := *armoryConfig
+		if config.AuthorizationCmd != "" {
+			// authorization is refreshed by authorization_cmd on load
+			config.Authorization = ""
+		}
+		saved = append(saved, &config)
+	}
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(GetRootAppDir(), armoryConfigFileName), data, 0600)
+}
+
 func executeAuthorizationCmd(armoryConfig *ArmoryConfig) string {
 	if armoryConfig.AuthorizationCmd == "" {
 		return ""
@@ -0,0 +1,179 @@
+package assets
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/chainreactors/malice-network/helper/helper"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	armoryLockFileName = "armory.lock"
+
+	LockedAlias     = "alias"
+	LockedExtension = "extension"
+)
+
+// LockedPackage - installed armory package pinned by the lockfile
+type LockedPackage struct {
+	Name        string            `json:"name"` // alias command name or extension name, also the install directory
+	Type        string            `json:"type"`
+	ID          string            `json:"id"`
+	CommandName string            `json:"command_name"` // command name in the armory index
+	Version     string            `json:"version"`
+	ArmoryPK    string            `json:"armory_public_key"`
+	Files       map[string]string `json:"files"` // sha256 of installed files, relative to the install directory
+}
+
+// InstallPath - directory the package is installed to
+func (p *LockedPackage) InstallPath() string {
+	if p.Type == LockedExtension {
+		return filepath.Join(GetExtensionsDir(), filepath.Base(p.Name))
+	}
+	return filepath.Join(GetAliasesDir(), filepath.Base(p.Name))
+}
+
+// Verify - differences between the installed package and the lockfile, empty if nothing drifts
+func (p *LockedPackage) Verify() []string {
+	return p.VerifyDir(p.InstallPath())
+}
+
+func (p *LockedPackage) VerifyDir(dir string) []string {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return []string{"not installed"}
+	}
+	var drifts []string
+	if version, err := installedVersion(dir, p.Type); err != nil {
+		drifts = append(drifts, fmt.Sprintf("cannot read manifest, %s", err.Error()))
+	} else if version != p.Version {
+		drifts = append(drifts, fmt.Sprintf("version %s, locked %s", version, p.Version))
+	}
+	files, err := HashInstalledFiles(dir)
+	if err != nil {
+		return append(drifts, err.Error())
+	}
+	var names []string
+	for name := range p.Files {
+		names = append(names, name)
+	}
+	for name := range files {
+		if _, ok := p.Files[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		locked, installed := p.Files[name], files[name]
+		switch {
+		case installed == "":
+			drifts = append(drifts, fmt.Sprintf("%s missing", name))
+		case locked == "":
+			drifts = append(drifts, fmt.Sprintf("%s not in lockfile", name))
+		case locked != installed:
+			drifts = append(drifts, fmt.Sprintf("%s modified", name))
+		}
+	}
+	return drifts
+}
+
+type ArmoryLock struct {
+	Packages []*LockedPackage `json:"packages"`
+}
+
+// Get - locked package by type and name, nil if not locked
+func (l *ArmoryLock) Get(typ, name string) *LockedPackage {
+	for _, p := range l.Packages {
+		if p.Type == typ && p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// Set - add package, or replace the package with the same type and name
+func (l *ArmoryLock) Set(pkg *LockedPackage) {
+	for i, p := range l.Packages {
+		if p.Type == pkg.Type && p.Name == pkg.Name {
+			l.Packages[i] = pkg
+			return
+		}
+	}
+	l.Packages = append(l.Packages, pkg)
+}
+
+func GetArmoryLockPath() string {
+	rootDir, _ := filepath.Abs(GetRootAppDir())
+	return filepath.Join(rootDir, armoryLockFileName)
+}
+
+// LoadArmoryLock - read lockfile, empty lock if the file does not exist
+func LoadArmoryLock(path string) (*ArmoryLock, error) {
+	lock := &ArmoryLock{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return lock, nil
+	} else if err != nil {
+		return lock, err
+	}
+	if err = json.Unmarshal(data, lock); err != nil {
+		return &ArmoryLock{}, err
+	}
+	return lock, nil
+}
+
+func SaveArmoryLock(path string, lock *ArmoryLock) error {
+	slices.SortFunc(lock.Packages, func(a, b *LockedPackage) int {
+		if c := strings.Compare(a.Type, b.Type); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// HashInstalledFiles - sha256 of every file in dir, keyed by slash separated relative path
+func HashInstalledFiles(dir string) (map[string]string, error) {
+	files := map[string]string{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(name)] = helper.SHA256Checksum(content)
+		return nil
+	})
+	return files, err
+}
+
+// installedVersion - version in the manifest of installed alias or extension
+func installedVersion(dir, typ string) (string, error) {
+	manifest := "alias.json"
+	if typ == LockedExtension {
+		manifest = "extension.json"
+	}
+	data, err := os.ReadFile(filepath.Join(dir, manifest))
+	if err != nil {
+		return "", err
+	}
+	var m struct {
+		Version string `json:"version"`
+	}
+	if err = json.Unmarshal(data, &m); err != nil {
+		return "", err
+	}
+	return m.Version, nil
+}
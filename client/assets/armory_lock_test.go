@@ -0,0 +1,66 @@
+package assets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLockedPackageVerify(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("alias.json", `{"name": "test1", "version": "1.0.0"}`)
+	write("bin/test1.dll", "dll")
+	write("bin/test1.so", "so")
+
+	files, err := HashInstalledFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := &LockedPackage{Name: "test1", Type: LockedAlias, Version: "1.0.0", Files: files}
+	if drifts := pkg.VerifyDir(dir); len(drifts) != 0 {
+		t.Fatalf("unexpected drifts %v", drifts)
+	}
+
+	write("alias.json", `{"name": "test1", "version": "1.1.0"}`)
+	write("bin/test1.dll", "patched")
+	write("bin/extra.dll", "extra")
+	os.Remove(filepath.Join(dir, "bin", "test1.so"))
+	drifts := pkg.VerifyDir(dir)
+	expected := []string{
+		"version 1.1.0, locked 1.0.0",
+		"alias.json modified",
+		"bin/extra.dll not in lockfile",
+		"bin/test1.dll modified",
+		"bin/test1.so missing",
+	}
+	if len(drifts) != len(expected) {
+		t.Fatalf("unexpected drifts %v", drifts)
+	}
+	for i := range expected {
+		if drifts[i] != expected[i] {
+			t.Errorf("drift %d: %q, expected %q", i, drifts[i], expected[i])
+		}
+	}
+	if drifts := pkg.VerifyDir(filepath.Join(dir, "missing")); len(drifts) != 1 {
+		t.Errorf("unexpected drifts of missing package %v", drifts)
+	}
+
+	lock := &ArmoryLock{}
+	lock.Set(pkg)
+	lock.Set(&LockedPackage{Name: "test1", Type: LockedAlias, Version: "1.1.0"})
+	if len(lock.Packages) != 1 || lock.Get(LockedAlias, "test1").Version != "1.1.0" {
+		t.Error("locked package not replaced")
+	}
+	if lock.Get(LockedExtension, "test1") != nil {
+		t.Error("locked package found with wrong type")
+	}
+}
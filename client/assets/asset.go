@@ -63,6 +63,9 @@ func GetConfigs() ([]string, error) {
 func MvConfig(oldPath string) error {
 	fileName := filepath.Base(oldPath)
 	newPath := filepath.Join(GetConfigDir(), fileName)
+	if absPath, _ := filepath.Abs(oldPath); absPath == newPath {
+		return nil
+	}
 	err := helper.CopyFile(oldPath, newPath)
 	if err != nil {
 		return err
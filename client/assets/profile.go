@@ -1,11 +1,118 @@
 package assets
 
+import (
+	"errors"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
 var (
 	maliceProfile = "malice.yaml"
+
+	ErrProfileNotFound = errors.New("profile not found")
 )
 
+// Profile - aliases, extensions and modules loaded when using a session matched by Os and Arch,
+// empty Os or Arch matches any session
 type Profile struct {
-	Aliases    []string `yaml:"aliases"`
-	Extensions []string `yaml:"extensions"`
-	Modules    []string `yaml:"modules"`
+	Name       string           `yaml:"name"`
+	Os         string           `yaml:"os,omitempty"`
+	Arch       string           `yaml:"arch,omitempty"`
+	Aliases    []string         `yaml:"aliases"`
+	Extensions []string         `yaml:"extensions"`
+	Modules    []*ProfileModule `yaml:"modules"`
+}
+
+// ProfileModule - module file loaded as Bundle, skipped if the session already has the bundle
+type ProfileModule struct {
+	Bundle string `yaml:"bundle"`
+	Path   string `yaml:"path"`
+}
+
+// Match - session os and arch meet the profile criteria
+func (p *Profile) Match(os, arch string) bool {
+	if p.Os != "" && !strings.EqualFold(p.Os, os) {
+		return false
+	}
+	if p.Arch != "" && !strings.EqualFold(p.Arch, arch) {
+		return false
+	}
+	return true
+}
+
+type Profiles struct {
+	Profiles []*Profile `yaml:"profiles"`
+}
+
+func GetProfilePath() string {
+	rootDir, _ := filepath.Abs(GetRootAppDir())
+	return filepath.Join(rootDir, maliceProfile)
+}
+
+// LoadProfiles - read profiles from malice.yaml, no profiles if the file does not exist
+func LoadProfiles() (*Profiles, error) {
+	profiles := &Profiles{}
+	data, err := os.ReadFile(GetProfilePath())
+	if os.IsNotExist(err) {
+		return profiles, nil
+	} else if err != nil {
+		return profiles, err
+	}
+	err = yaml.Unmarshal(data, profiles)
+	if err != nil {
+		return &Profiles{}, err
+	}
+	return profiles, nil
+}
+
+// SaveProfiles - Save the profiles to malice.yaml
+func SaveProfiles(profiles *Profiles) error {
+	data, err := yaml.Marshal(profiles)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(GetProfilePath(), data, 0600)
+}
+
+func (ps *Profiles) Get(name string) (*Profile, error) {
+	for _, p := range ps.Profiles {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return nil, ErrProfileNotFound
+}
+
+// Set - add profile, or replace the profile with the same name
+func (ps *Profiles) Set(profile *Profile) {
+	for i, p := range ps.Profiles {
+		if p.Name == profile.Name {
+			ps.Profiles[i] = profile
+			return
+		}
+	}
+	ps.Profiles = append(ps.Profiles, profile)
+}
+
+func (ps *Profiles) Remove(name string) error {
+	for i, p := range ps.Profiles {
+		if p.Name == name {
+			ps.Profiles = append(ps.Profiles[:i], ps.Profiles[i+1:]...)
+			return nil
+		}
+	}
+	return ErrProfileNotFound
+}
+
+// Match - profiles matched by session os and arch, in the order of malice.yaml
+func (ps *Profiles) Match(os, arch string) []*Profile {
+	var matched []*Profile
+	for _, p := range ps.Profiles {
+		if p.Match(os, arch) {
+			matched = append(matched, p)
+		}
+	}
+	return matched
 }
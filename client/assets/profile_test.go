@@ -0,0 +1,46 @@
+package assets
+
+import (
+	"gopkg.in/yaml.v3"
+	"testing"
+)
+
+func TestProfilesMatch(t *testing.T) {
+	profiles := &Profiles{}
+	profiles.Set(&Profile{Name: "any"})
+	profiles.Set(&Profile{Name: "win", Os: "windows"})
+	profiles.Set(&Profile{Name: "win64", Os: "Windows", Arch: "x64"})
+	profiles.Set(&Profile{Name: "win", Os: "windows", Arch: "x86"})
+
+	var names []string
+	for _, p := range profiles.Match("windows", "x64") {
+		names = append(names, p.Name)
+	}
+	if len(names) != 2 || names[0] != "any" || names[1] != "win64" {
+		t.Errorf("unexpected matched profiles %v", names)
+	}
+	if matched := profiles.Match("linux", "x64"); len(matched) != 1 {
+		t.Errorf("unexpected matched profiles for linux %d", len(matched))
+	}
+	if err := profiles.Remove("win"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := profiles.Get("win"); err != ErrProfileNotFound {
+		t.Errorf("removed profile still exists")
+	}
+}
+
+func TestProfileModulesYaml(t *testing.T) {
+	data := []byte("profiles:\n  - name: win\n    modules:\n      - bundle: full\n        path: /opt/modules/full.dll\n")
+	profiles := &Profiles{}
+	if err := yaml.Unmarshal(data, profiles); err != nil {
+		t.Fatal(err)
+	}
+	profile, err := profiles.Get("win")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profile.Modules) != 1 || profile.Modules[0].Bundle != "full" || profile.Modules[0].Path != "/opt/modules/full.dll" {
+		t.Errorf("unexpected modules %v", profile.Modules)
+	}
+}
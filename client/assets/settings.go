@@ -18,6 +18,7 @@ type Settings struct {
 	SmallTermWidth    int    `json:"small_term_width"`
 	AlwaysOverflow    bool   `json:"always_overflow"`
 	VimMode           bool   `json:"vim_mode"`
+	OutputFormat      string `json:"output_format"` // text, json or csv of table commands
 	DefaultTimeout    int
 }
 
@@ -0,0 +1,85 @@
+package assets
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const waitingTasksFileName = "waiting_tasks.json"
+
+// WaitingTask - task sent by the console whose output is not shown yet
+type WaitingTask struct {
+	Server    string    `json:"server"` // host:port of the server the task was sent to
+	SessionID string    `json:"session_id"`
+	TaskID    uint32    `json:"task_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WaitingTasks - tasks to recover after the console restarts or reconnects
+type WaitingTasks struct {
+	Tasks []*WaitingTask `json:"tasks"`
+}
+
+func (w *WaitingTasks) Add(task *WaitingTask) {
+	w.Remove(task.Server, task.SessionID, task.TaskID)
+	w.Tasks = append(w.Tasks, task)
+}
+
+// Remove - drop the task, true if it was waiting. Task ids are counted per session,
+// a task is identified by server, session and task id
+func (w *WaitingTasks) Remove(server string, sessionID string, taskID uint32) bool {
+	count := len(w.Tasks)
+	w.Tasks = slices.DeleteFunc(w.Tasks, func(task *WaitingTask) bool {
+		return task.Server == server && task.SessionID == sessionID && task.TaskID == taskID
+	})
+	return len(w.Tasks) != count
+}
+
+// Server - waiting tasks sent to server, ordered by task id and session
+func (w *WaitingTasks) Server(server string) []*WaitingTask {
+	var tasks []*WaitingTask
+	for _, task := range w.Tasks {
+		if task.Server == server {
+			tasks = append(tasks, task)
+		}
+	}
+	slices.SortFunc(tasks, func(a, b *WaitingTask) int {
+		if a.TaskID != b.TaskID {
+			return int(a.TaskID) - int(b.TaskID)
+		}
+		return strings.Compare(a.SessionID, b.SessionID)
+	})
+	return tasks
+}
+
+func GetWaitingTasksPath() string {
+	rootDir, _ := filepath.Abs(GetRootAppDir())
+	return filepath.Join(rootDir, waitingTasksFileName)
+}
+
+// LoadWaitingTasks - read waiting tasks, empty if the file does not exist
+func LoadWaitingTasks(path string) (*WaitingTasks, error) {
+	waiting := &WaitingTasks{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return waiting, nil
+	} else if err != nil {
+		return waiting, err
+	}
+	if err = json.Unmarshal(data, waiting); err != nil {
+		return &WaitingTasks{}, err
+	}
+	return waiting, nil
+}
+
+func SaveWaitingTasks(path string, waiting *WaitingTasks) error {
+	data, err := json.MarshalIndent(waiting, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
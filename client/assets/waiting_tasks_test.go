@@ -0,0 +1,61 @@
+package assets
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWaitingTasks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), waitingTasksFileName)
+	waiting, err := LoadWaitingTasks(path)
+	if err != nil || len(waiting.Tasks) != 0 {
+		t.Fatalf("missing file loaded as %v, %v", waiting, err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	waiting.Add(&WaitingTask{Server: "127.0.0.1:5004", SessionID: "a", TaskID: 3, CreatedAt: now})
+	waiting.Add(&WaitingTask{Server: "127.0.0.1:5004", SessionID: "b", TaskID: 1, CreatedAt: now})
+	waiting.Add(&WaitingTask{Server: "10.0.0.1:5004", SessionID: "c", TaskID: 1, CreatedAt: now})
+	waiting.Add(&WaitingTask{Server: "127.0.0.1:5004", SessionID: "a", TaskID: 3, CreatedAt: now})
+	if err = SaveWaitingTasks(path, waiting); err != nil {
+		t.Fatal(err)
+	}
+
+	waiting, err = LoadWaitingTasks(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tasks := waiting.Server("127.0.0.1:5004")
+	if len(tasks) != 2 || tasks[0].TaskID != 1 || tasks[1].TaskID != 3 {
+		t.Fatalf("unexpected tasks %v", tasks)
+	}
+	if !tasks[1].CreatedAt.Equal(now) || tasks[1].SessionID != "a" {
+		t.Errorf("unexpected task %v", tasks[1])
+	}
+	if !waiting.Remove("10.0.0.1:5004", "c", 1) || waiting.Remove("10.0.0.1:5004", "c", 1) {
+		t.Error("task removed twice")
+	}
+	if len(waiting.Tasks) != 2 {
+		t.Errorf("task of another server removed, %v", waiting.Tasks)
+	}
+}
+
+func TestWaitingTasksOfSessions(t *testing.T) {
+	// task ids are counted per session, sessions of one server share them
+	waiting := &WaitingTasks{}
+	waiting.Add(&WaitingTask{Server: "127.0.0.1:5004", SessionID: "a", TaskID: 1})
+	waiting.Add(&WaitingTask{Server: "127.0.0.1:5004", SessionID: "b", TaskID: 1})
+	tasks := waiting.Server("127.0.0.1:5004")
+	if len(tasks) != 2 || tasks[0].SessionID != "a" || tasks[1].SessionID != "b" {
+		t.Fatalf("task of another session replaced, %v", tasks)
+	}
+
+	if !waiting.Remove("127.0.0.1:5004", "a", 1) {
+		t.Fatal("task of session a not removed")
+	}
+	tasks = waiting.Server("127.0.0.1:5004")
+	if len(tasks) != 1 || tasks[0].SessionID != "b" {
+		t.Errorf("task of session b removed with session a, %v", tasks)
+	}
+}
@@ -3,10 +3,34 @@ package cli
 import (
 	"github.com/chainreactors/malice-network/client/command"
 	"github.com/chainreactors/malice-network/client/console"
+	"github.com/jessevdk/go-flags"
+	"os"
 )
 
+type Options struct {
+	Config  string      `long:"config" description:"Path to client config file"`
+	ExecCmd ExecCommand `command:"exec" description:"Run command or script without the shell"`
+}
+
+// StartConsole - client entrypoint, a bare config path as the first argument is still accepted
 func StartConsole() error {
-	err := console.Start(command.BindClientsCommands, command.BindImplantCommands)
+	var opt Options
+	parser := flags.NewParser(&opt, flags.Default)
+	parser.SubcommandsOptional = true
+	args, err := parser.Parse()
+	if err != nil {
+		if flagsErr, ok := err.(*flags.Error); !ok || flagsErr.Type != flags.ErrHelp {
+			os.Exit(ExitCommandError)
+		}
+		return nil
+	}
+	if parser.Active != nil {
+		os.Exit(opt.ExecCmd.Run(opt.Config))
+	}
+	if opt.Config == "" && len(args) > 0 {
+		opt.Config = args[0]
+	}
+	err = console.Start(opt.Config, command.BindClientsCommands, command.BindImplantCommands)
 	if err != nil {
 		return err
 	}
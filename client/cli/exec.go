@@ -0,0 +1,233 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/chainreactors/logs"
+	"github.com/chainreactors/malice-network/client/command"
+	"github.com/chainreactors/malice-network/client/command/profile"
+	"github.com/chainreactors/malice-network/client/console"
+	"github.com/chainreactors/malice-network/proto/client/clientpb"
+	"github.com/desertbit/go-shlex"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"os"
+	"strings"
+	"time"
+)
+
+// exit codes of exec, the first failed command decides the code
+const (
+	ExitSuccess = iota
+	ExitCommandError
+	ExitTaskError
+	ExitConnectError
+	ExitSessionNotFound
+	ExitTimeout
+	ExitSessionAmbiguous
+)
+
+// execLogStyle - plain log lines for automation
+var execLogStyle = map[logs.Level]string{
+	logs.Debug:     "[debug] %s",
+	logs.Warn:      "[warn] %s",
+	logs.Important: "[*] %s",
+	logs.Info:      "[i] %s",
+	logs.Error:     "[-] %s",
+}
+
+// execResult - json line printed for every task, or for a command without task
+type execResult struct {
+	Command string          `json:"command"`
+	Session string          `json:"session,omitempty"`
+	TaskID  uint32          `json:"task_id,omitempty"`
+	Error   string          `json:"error,omitempty"`
+	Spite   json.RawMessage `json:"spite,omitempty"`
+}
+
+// ExecCommand - `client --config x.yaml exec [-s session] [--json] (-f script | -- command...)`
+type ExecCommand struct {
+	Session  string        `short:"s" long:"session" description:"Session id or unique prefix of session id"`
+	File     string        `short:"f" long:"file" description:"Script file, one command per line"`
+	Json     bool          `long:"json" description:"Print results as json lines"`
+	Timeout  time.Duration `long:"timeout" default:"5m" description:"Timeout of waiting tasks of each command"`
+	Continue bool          `long:"continue" description:"Run the rest of the script after a command failed"`
+	Args     struct {
+		Command []string `positional-arg-name:"command"`
+	} `positional-args:"yes"`
+}
+
+// Run - run the command or script, wait all tasks and return the exit code
+func (cmd *ExecCommand) Run(config string) int {
+	for _, log := range []*logs.Logger{logs.Log, console.Log} {
+		log.SetOutput(os.Stderr)
+		log.SetFormatter(execLogStyle)
+	}
+
+	var cmdlines [][]string
+	if cmd.File != "" {
+		var err error
+		cmdlines, err = readScript(cmd.File)
+		if err != nil {
+			console.Log.Errorf("Failed to read script: %s", err)
+			return ExitCommandError
+		}
+	} else if len(cmd.Args.Command) > 0 {
+		cmdlines = [][]string{cmd.Args.Command}
+	} else {
+		console.Log.Errorf("Nothing to run, give a command after -- or a script by -f")
+		return ExitCommandError
+	}
+
+	if config == "" {
+		console.Log.Errorf("Client config is required, use --config")
+		return ExitConnectError
+	}
+	con, err := console.NewConsole(config, command.BindClientsCommands, command.BindImplantCommands)
+	if err != nil {
+		return ExitConnectError
+	}
+	con.EnableWait()
+	if cmd.Session != "" {
+		if err = con.UpdateSessions(false); err != nil {
+			console.Log.Errorf("Failed to get sessions: %s", err)
+			return ExitConnectError
+		}
+		session, err := findSession(con, cmd.Session)
+		if err != nil {
+			console.Log.Errorf("%s: %s", err, cmd.Session)
+			if errors.Is(err, console.ErrAmbiguousSession) {
+				return ExitSessionAmbiguous
+			}
+			return ExitSessionNotFound
+		}
+		con.ActiveTarget.Set(session)
+		con.EnableImplantCommands()
+		profile.LoadSessionProfiles(session, con)
+	}
+
+	exitCode := ExitSuccess
+	for _, cmdline := range cmdlines {
+		code := execCommand(con, cmdline, cmd.Json, cmd.Timeout)
+		if code == ExitSuccess {
+			continue
+		}
+		if exitCode == ExitSuccess {
+			exitCode = code
+		}
+		if !cmd.Continue {
+			break
+		}
+	}
+	return exitCode
+}
+
+// execCommand - run command, then wait every task it sent and output the results
+func execCommand(con *console.Console, cmdline []string, jsonOutput bool, timeout time.Duration) int {
+	result := &execResult{Command: strings.Join(cmdline, " ")}
+	if session := con.ActiveTarget.Get(); session != nil {
+		result.Session = session.SessionId
+	}
+	err := con.App.RunCommand(cmdline)
+	tasks := con.TakePending()
+	if err != nil {
+		console.Log.Errorf("%s: %s", result.Command, err)
+		result.Error = err.Error()
+		if jsonOutput {
+			printResult(result)
+		}
+		return ExitCommandError
+	}
+	if len(tasks) == 0 {
+		if jsonOutput {
+			printResult(result)
+		}
+		return ExitSuccess
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	exitCode := ExitSuccess
+	for _, task := range tasks {
+		result.TaskID = task.TaskId
+		result.Error, result.Spite = "", nil
+		content, err := con.WaitTask(ctx, result.Session, task.TaskId)
+		if content != nil {
+			result.Spite, _ = protojson.Marshal(content)
+		}
+		code := ExitSuccess
+		switch {
+		case err == nil:
+			if !jsonOutput {
+				task.Callback(content)
+			}
+		case errors.Is(err, console.ErrTaskFailed):
+			code = ExitTaskError
+		case status.Code(err) == codes.DeadlineExceeded:
+			console.Log.Errorf("task %d timeout after %s", task.TaskId, timeout)
+			code = ExitTimeout
+		default:
+			console.Log.Errorf("Failed to wait task %d: %s", task.TaskId, err)
+			code = ExitTaskError
+		}
+		if code != ExitSuccess {
+			result.Error = err.Error()
+			if exitCode == ExitSuccess {
+				exitCode = code
+			}
+		}
+		if jsonOutput {
+			printResult(result)
+		}
+	}
+	return exitCode
+}
+
+// findSession - session by id, or by the prefix matching only one session
+func findSession(con *console.Console, id string) (*clientpb.Session, error) {
+	if _, session := con.FindSession(id); session != nil {
+		return session, nil
+	}
+	names := con.SessionNames(id)
+	switch len(names) {
+	case 0:
+		return nil, console.ErrNotFoundSession
+	case 1:
+		_, session := con.FindSession(names[0])
+		return session, nil
+	default:
+		return nil, console.ErrAmbiguousSession
+	}
+}
+
+// readScript - commands of script, blank lines and lines starting with # are skipped
+func readScript(path string) ([][]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var cmdlines [][]string
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		args, err := shlex.Split(text, true)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		cmdlines = append(cmdlines, args)
+	}
+	return cmdlines, scanner.Err()
+}
+
+func printResult(result *execResult) {
+	data, _ := json.Marshal(result)
+	fmt.Println(string(data))
+}
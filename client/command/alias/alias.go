@@ -6,6 +6,7 @@ import (
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/assets"
 	"github.com/chainreactors/malice-network/client/console"
+	"github.com/chainreactors/malice-network/client/utils"
 	"github.com/chainreactors/tui"
 	"github.com/charmbracelet/bubbles/table"
 	"os"
@@ -16,7 +17,7 @@ import (
 // AliasesCmd - The alias command
 func AliasesCmd(ctx *grumble.Context, con *console.Console) error {
 	if 0 < len(loadedAliases) {
-		PrintAliases(con)
+		PrintAliases(con, con.OutputFormat(ctx))
 	} else {
 		console.Log.Infof("No aliases installed, use the 'armory' command to automatically install some")
 	}
@@ -25,7 +26,7 @@ func AliasesCmd(ctx *grumble.Context, con *console.Console) error {
 }
 
 // PrintAliases - Print a list of loaded aliases
-func PrintAliases(con *console.Console) {
+func PrintAliases(con *console.Console, format string) {
 	var rowEntries []table.Row
 	var row table.Row
 	tableModel := tui.NewTable([]table.Column{
@@ -60,6 +61,10 @@ func PrintAliases(con *console.Console) {
 		rowEntries = append(rowEntries, row)
 	}
 	tableModel.SetRows(rowEntries)
+	if format != utils.OutputText {
+		con.PrintTable(format, tableModel)
+		return
+	}
 	newTable := tui.NewModel(tableModel, nil, false, false)
 	err := newTable.Run()
 	if err != nil {
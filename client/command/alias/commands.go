@@ -13,9 +13,9 @@ func Commands(con *console.Console) []*grumble.Command {
 		Name:     consts.CommandAlias,
 		Help:     "List current aliases",
 		LongHelp: help.GetHelpFor(consts.CommandAlias),
+		Flags:    console.OutputFlags,
 		Run: func(ctx *grumble.Context) error {
-			AliasesCmd(ctx, con)
-			return nil
+			return AliasesCmd(ctx, con)
 		},
 		HelpGroup: consts.GenericGroup,
 	}
@@ -24,8 +24,7 @@ func Commands(con *console.Console) []*grumble.Command {
 		Help:     "Load a command alias",
 		LongHelp: help.GetHelpFor(consts.CommandAlias + " " + consts.CommandAliasLoad),
 		Run: func(ctx *grumble.Context) error {
-			AliasesLoadCmd(ctx, con)
-			return nil
+			return AliasesLoadCmd(ctx, con)
 		},
 		Args: func(a *grumble.Args) {
 			a.String("dir-path", "path to the alias directory")
@@ -41,8 +40,7 @@ func Commands(con *console.Console) []*grumble.Command {
 		Help:     "Install a command alias",
 		LongHelp: help.GetHelpFor(consts.CommandAlias + " " + consts.CommandAliasInstall),
 		Run: func(ctx *grumble.Context) error {
-			AliasesInstallCmd(ctx, con)
-			return nil
+			return AliasesInstallCmd(ctx, con)
 		},
 		Args: func(a *grumble.Args) {
 			a.String("path", "path to the alias directory or tar.gz file")
@@ -58,8 +56,7 @@ func Commands(con *console.Console) []*grumble.Command {
 		Help:     "Remove an alias",
 		LongHelp: help.GetHelpFor(consts.CommandAlias + " " + consts.CommandAliasRemove),
 		Run: func(ctx *grumble.Context) error {
-			AliasesRemoveCmd(ctx, con)
-			return nil
+			return AliasesRemoveCmd(ctx, con)
 		},
 		Args: func(a *grumble.Args) {
 			a.String("name", "name of the alias to remove")
@@ -13,18 +13,18 @@ import (
 )
 
 // AliasesInstallCmd - Install an alias
-func AliasesInstallCmd(ctx *grumble.Context, con *console.Console) {
+func AliasesInstallCmd(ctx *grumble.Context, con *console.Console) error {
 	aliasLocalPath := ctx.Args.String("path")
 	fi, err := os.Stat(aliasLocalPath)
 	if os.IsNotExist(err) {
-		console.Log.Errorf("alias path '%s' does not exist", aliasLocalPath)
-		return
+		return fmt.Errorf("alias path '%s' does not exist", aliasLocalPath)
 	}
 	if !fi.IsDir() {
 		InstallFromFile(aliasLocalPath, "", false, con)
 	} else {
 		installFromDir(aliasLocalPath, con)
 	}
+	return nil
 }
 
 // Install an extension from a directory
@@ -83,6 +83,11 @@ func installFromDir(aliasLocalPath string, con *console.Console) {
 
 // Install an extension from a .tar.gz file
 func InstallFromFile(aliasGzFilePath string, aliasName string, promptToOverwrite bool, con *console.Console) *string {
+	return InstallFromFileTo(aliasGzFilePath, aliasName, assets.GetAliasesDir(), promptToOverwrite, con)
+}
+
+// InstallFromFileTo - install alias into rootDir instead of the aliases directory, e.g. a staging directory
+func InstallFromFileTo(aliasGzFilePath string, aliasName string, rootDir string, promptToOverwrite bool, con *console.Console) *string {
 	manifestData, err := utils.ReadFileFromTarGz(aliasGzFilePath, fmt.Sprintf("./%s", ManifestFileName))
 	if err != nil {
 		console.Log.Errorf("Failed to read %s from '%s': %s\n", ManifestFileName, aliasGzFilePath, err)
@@ -99,7 +104,7 @@ func InstallFromFile(aliasGzFilePath string, aliasName string, promptToOverwrite
 		console.Log.Errorf(errorMsg)
 		return nil
 	}
-	installPath := filepath.Join(assets.GetAliasesDir(), filepath.Base(manifest.CommandName))
+	installPath := filepath.Join(rootDir, filepath.Base(manifest.CommandName))
 	if _, err := os.Stat(installPath); !os.IsNotExist(err) {
 		if promptToOverwrite {
 			console.Log.Infof("Alias '%s' already exists\n", manifest.CommandName)
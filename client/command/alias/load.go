@@ -2,10 +2,13 @@ package alias
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/chainreactors/files"
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/assets"
+	"github.com/chainreactors/malice-network/client/command/artifact"
+	"github.com/chainreactors/malice-network/client/command/parser"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/client/utils"
 	"github.com/chainreactors/malice-network/helper/consts"
@@ -69,6 +72,7 @@ type AliasManifest struct {
 	Files        []*AliasFile `json:"files"`
 	IsReflective bool         `json:"is_reflective"`
 	IsAssembly   bool         `json:"is_assembly"`
+	Parser       string       `json:"parser"` // wasm module parsing the output, relative to the alias directory
 
 	RootPath   string `json:"-"`
 	ArmoryName string `json:"-"`
@@ -103,7 +107,7 @@ func (a *AliasManifest) getFileForTarget(cmdName string, targetOS string, target
 }
 
 // AliasesLoadCmd - Locally load a alias into the Sliver shell.
-func AliasesLoadCmd(ctx *grumble.Context, con *console.Console) {
+func AliasesLoadCmd(ctx *grumble.Context, con *console.Console) error {
 	dirPath := ctx.Args.String("dir-path")
 	alias, err := LoadAlias(dirPath, con)
 	if err != nil {
@@ -111,6 +115,7 @@ func AliasesLoadCmd(ctx *grumble.Context, con *console.Console) {
 	} else {
 		console.Log.Infof("%s alias has been loaded\n", alias.Name)
 	}
+	return nil
 }
 
 // LoadAlias - Load an alias into the Sliver shell from a given directory
@@ -152,8 +157,7 @@ func LoadAlias(manifestPath string, con *console.Console) (*AliasManifest, error
 		Help:     helpMsg,
 		LongHelp: aliasManifest.LongHelp,
 		Run: func(extCtx *grumble.Context) error {
-			runAliasCommand(extCtx, con)
-			return nil
+			return runAliasCommand(extCtx, con)
 		},
 		Flags: func(f *grumble.Flags) {
 			if aliasManifest.IsAssembly {
@@ -226,10 +230,10 @@ func ParseAliasManifest(data []byte) (*AliasManifest, error) {
 	return alias, nil
 }
 
-func runAliasCommand(ctx *grumble.Context, con *console.Console) {
+func runAliasCommand(ctx *grumble.Context, con *console.Console) error {
 	session := con.GetInteractive()
 	if session == nil {
-		return
+		return nil
 	}
 	var goos string
 	var goarch string
@@ -238,14 +242,12 @@ func runAliasCommand(ctx *grumble.Context, con *console.Console) {
 
 	loadedAlias, ok := loadedAliases[ctx.Command.Name]
 	if !ok {
-		console.Log.Errorf("No alias found for `%s` command\n", ctx.Command.Name)
-		return
+		return fmt.Errorf("No alias found for `%s` command", ctx.Command.Name)
 	}
 	aliasManifest := loadedAlias.Manifest
 	binPath, err := aliasManifest.getFileForTarget(ctx.Command.Name, goos, goarch)
 	if err != nil {
-		console.Log.Errorf("Fail to find alias file: %s\n", err)
-		return
+		return fmt.Errorf("Fail to find alias file: %s", err)
 	}
 	args := ctx.Args.StringList("arguments")
 	var extArgs string
@@ -275,8 +277,7 @@ func runAliasCommand(ctx *grumble.Context, con *console.Console) {
 				msgStr = " Arguments are limited to 256 characters when using the default fork/exec model for .NET assemblies.\nConsider using the --in-process flag to execute .NET assemblies in-process and work around this limitation.\n"
 			}
 			if !inProcess && (runtime != "" || etwBypass || amsiBypass) {
-				console.Log.Errorf("The --runtime, --etw-bypass, and --amsi-bypass flags can only be used with the --in-process flag\n")
-				return
+				return errors.New("The --runtime, --etw-bypass, and --amsi-bypass flags can only be used with the --in-process flag")
 			}
 		} else if !aliasManifest.IsReflective {
 			msgStr = " Arguments are limited to 256 characters when using the default fork/exec model for non-reflective PE payloads.\n"
@@ -294,8 +295,7 @@ func runAliasCommand(ctx *grumble.Context, con *console.Console) {
 	if processName == "" {
 		processName, err = aliasManifest.getDefaultProcess(goos)
 		if err != nil {
-			console.Log.Errorf("%s\n", err)
-			return
+			return err
 		}
 	}
 	//isDLL := false
@@ -304,8 +304,7 @@ func runAliasCommand(ctx *grumble.Context, con *console.Console) {
 	//}
 	binData, err := os.ReadFile(binPath)
 	if err != nil {
-		console.Log.Errorf("%s\n", err)
-		return
+		return err
 	}
 	//var outFilePath *os.File
 	//if ctx.Flags.Bool("save") {
@@ -322,21 +321,33 @@ func runAliasCommand(ctx *grumble.Context, con *console.Console) {
 		// Execute Assembly
 		//msg := fmt.Sprintf("Executing %s %s ...", ctx.Command.Name, extArgs)
 		//con.SpinUntil(msg, ctrl)
+		if aliasManifest.Parser != "" {
+			parserPath := path.Join(assets.GetAliasesDir(), aliasManifest.CommandName, aliasManifest.Parser)
+			if err := parser.Register(con, loadedAlias.Command.Name, parserPath); err != nil {
+				console.Log.Warnf("Output parser of %s not registered: %s\n", loadedAlias.Command.Name, err)
+			}
+		}
+		hash, err := artifact.Ensure(con, loadedAlias.Command.Name, consts.CommandAlias, binData)
+		if err != nil {
+			return err
+		}
 		executeAssemblyResp, err := con.Rpc.ExecuteAssembly(con.ActiveTarget.Context(), &implantpb.ExecuteBinary{
-			Name:   loadedAlias.Command.Name,
-			Bin:    binData,
-			Type:   consts.ModuleExecuteAssembly,
-			Params: args,
+			Name:     loadedAlias.Command.Name,
+			Artifact: hash,
+			Type:     consts.ModuleExecuteAssembly,
+			Params:   args,
 		})
 		if err != nil {
-			console.Log.Errorf("%s\n", err)
-			return
+			return err
 		}
 
-		con.AddCallback(executeAssemblyResp.TaskId, func(msg proto.Message) {
+		con.AddCallback(executeAssemblyResp, func(msg proto.Message) {
 			resp := msg.(*implantpb.Spite).GetAssemblyResponse()
 			sid := con.GetInteractive().SessionId
 			if resp.Status == 0 {
+				if aliasManifest.Parser != "" && parser.PrintResult(con, executeAssemblyResp.SessionId, executeAssemblyResp.TaskId) {
+					return
+				}
 				con.SessionLog(sid).Infof("%s output:\n%s", loadedAlias.Command.Name, string(resp.Data))
 			} else {
 				con.SessionLog(sid).Errorf("%s %s ", ctx.Command.Name, resp.Err)
@@ -418,6 +429,7 @@ func runAliasCommand(ctx *grumble.Context, con *console.Console) {
 		//		PrintSideloadOutput(ctx.Command.Name, sideloadResp, outFilePath, con)
 		//	}
 	}
+	return nil
 }
 
 // PrintSpawnDLLOutput - Prints the output of a spawn dll command
@@ -455,3 +467,9 @@ func cmdExists(name string, app *grumble.App) bool {
 	}
 	return false
 }
+
+// AliasLoaded - alias command is registered in the console
+func AliasLoaded(name string) bool {
+	_, ok := loadedAliases[name]
+	return ok
+}
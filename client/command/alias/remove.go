@@ -20,6 +20,7 @@ package alias
 
 import (
 	"errors"
+	"fmt"
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/assets"
 	"github.com/chainreactors/malice-network/client/console"
@@ -28,12 +29,11 @@ import (
 )
 
 // AliasesRemoveCmd - Locally load a alias into the Sliver shell.
-func AliasesRemoveCmd(ctx *grumble.Context, con *console.Console) {
+func AliasesRemoveCmd(ctx *grumble.Context, con *console.Console) error {
 	//name := ctx.Args
 	name := ctx.Args.String("name")
 	if name == "" {
-		console.Log.Errorf("Extension name is required\n")
-		return
+		return errors.New("Extension name is required")
 	}
 	//confirm := false
 	//prompt := &survey.Confirm{Message: fmt.Sprintf("Remove '%s' alias?", name)}
@@ -43,11 +43,11 @@ func AliasesRemoveCmd(ctx *grumble.Context, con *console.Console) {
 	//}
 	err := RemoveAliasByCommandName(name, con)
 	if err != nil {
-		console.Log.Errorf("Error removing alias: %s\n", err)
-		return
+		return fmt.Errorf("Error removing alias: %s", err)
 	} else {
 		console.Log.Infof("Alias '%s' removed\n", name)
 	}
+	return nil
 }
 
 // RemoveAliasByCommandName - Remove an alias by command name
@@ -9,6 +9,7 @@ import (
 	"github.com/chainreactors/malice-network/client/command/alias"
 	"github.com/chainreactors/malice-network/client/command/extension"
 	"github.com/chainreactors/malice-network/client/console"
+	"github.com/chainreactors/malice-network/client/utils"
 	"github.com/chainreactors/malice-network/helper/cryptography/minisign"
 	"github.com/chainreactors/tui"
 	"github.com/pterm/pterm"
@@ -108,7 +109,7 @@ var (
 	defaultArmoryRemoved = false
 )
 
-func ArmoryCmd(ctx *grumble.Context, con *console.Console) {
+func ArmoryCmd(ctx *grumble.Context, con *console.Console) error {
 	armoriesConfig := getCurrentArmoryConfiguration()
 	if len(armoriesConfig) == 1 {
 		console.Log.Infof("Reading armory index ... ")
@@ -132,7 +133,7 @@ func ArmoryCmd(ctx *grumble.Context, con *console.Console) {
 	armoriesInitialized = true
 	if len(indexes) == 0 {
 		console.Log.Infof("No indexes found\n")
-		return
+		return nil
 	}
 	var aliases []*alias.AliasManifest
 	var exts []*extension.ExtensionManifest
@@ -170,18 +171,19 @@ func ArmoryCmd(ctx *grumble.Context, con *console.Console) {
 			console.Log.Infof("done!\n")
 		}
 		if 0 < len(aliases) || 0 < len(exts) {
-			PrintArmoryPackages(aliases, exts, con, clientConfig)
+			PrintArmoryPackages(aliases, exts, con, clientConfig, con.OutputFormat(ctx))
 		} else {
 			console.Log.Infof("No packages found")
 		}
 
 		bundles := bundlesInCache()
 		if 0 < len(bundles) {
-			PrintArmoryBundles(bundles, con)
+			PrintArmoryBundles(bundles, con, con.OutputFormat(ctx))
 		} else {
 			console.Log.Infof("No bundles found\n")
 		}
 	}
+	return nil
 }
 
 func refresh(clientConfig ArmoryHTTPConfig) {
@@ -365,7 +367,7 @@ func AliasExtensionOrBundleCompleter(prefix string, args []string, con *console.
 
 // PrintArmoryPackages - Prints the armory packages
 func PrintArmoryPackages(aliases []*alias.AliasManifest, exts []*extension.ExtensionManifest, con *console.Console,
-	clientConfig ArmoryHTTPConfig) {
+	clientConfig ArmoryHTTPConfig, format string) {
 	var rowEntries []table.Row
 	var row table.Row
 
@@ -429,6 +431,10 @@ func PrintArmoryPackages(aliases []*alias.AliasManifest, exts []*extension.Exten
 		rowEntries = append(rowEntries, row)
 	}
 	tableModel.SetRows(rowEntries)
+	if format != utils.OutputText {
+		con.PrintTable(format, tableModel)
+		return
+	}
 	tableModel.SetHandle(func() {
 		selected := tableModel.GetSelectedRow()
 		armoryPK := getArmoryPublicKey(selected[0])
@@ -457,7 +463,7 @@ func PrintArmoryPackages(aliases []*alias.AliasManifest, exts []*extension.Exten
 }
 
 // PrintArmoryBundles - Prints the armory bundles
-func PrintArmoryBundles(bundles []*ArmoryBundle, con *console.Console) {
+func PrintArmoryBundles(bundles []*ArmoryBundle, con *console.Console, format string) {
 	var rowEntries []table.Row
 	var row table.Row
 
@@ -493,6 +499,10 @@ func PrintArmoryBundles(bundles []*ArmoryBundle, con *console.Console) {
 		rowEntries = append(rowEntries, row)
 	}
 	tableModel.SetRows(rowEntries)
+	if format != utils.OutputText {
+		con.PrintTable(format, tableModel)
+		return
+	}
 	newTable := tui.NewModel(tableModel, nil, false, false)
 	err := newTable.Run()
 	if err != nil {
@@ -604,10 +614,12 @@ func fetchIndex(armoryConfig *assets.ArmoryConfig, requestChannel chan struct{},
 	}
 
 	for _, pkg := range index.Aliases {
+		resolvePackageURL(armoryConfig, pkg)
 		pkg.ID = calculatePackageHash(pkg)
 	}
 
 	for _, pkg := range index.Extensions {
+		resolvePackageURL(armoryConfig, pkg)
 		pkg.ID = calculatePackageHash(pkg)
 	}
 	if err != nil {
@@ -615,6 +627,13 @@ func fetchIndex(armoryConfig *assets.ArmoryConfig, requestChannel chan struct{},
 	}
 }
 
+// resolvePackageURL - package urls of a mirrored armory are relative to the index url
+func resolvePackageURL(armoryConfig *assets.ArmoryConfig, pkg *ArmoryPackage) {
+	if pkgURL, err := resolveURL(armoryConfig.RepoURL, pkg.RepoURL); err == nil {
+		pkg.RepoURL = pkgURL.String()
+	}
+}
+
 func fetchPackageSignatures(index ArmoryIndex, clientConfig ArmoryHTTPConfig) {
 	wg := &sync.WaitGroup{}
 	// Be kind to armories and limit concurrent requests to 10
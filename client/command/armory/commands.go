@@ -18,10 +18,10 @@ func Commands(con *console.Console) []*grumble.Command {
 			f.String("t", "timeout", "", "timeout")
 			f.Bool("i", "insecure", false, "disable TLS validation")
 			f.Bool("", "ignore-cache", false, "ignore cache")
+			console.OutputFlags(f)
 		},
 		Run: func(ctx *grumble.Context) error {
-			ArmoryCmd(ctx, con)
-			return nil
+			return ArmoryCmd(ctx, con)
 		},
 		HelpGroup: consts.GenericGroup,
 	}
@@ -39,8 +39,7 @@ func Commands(con *console.Console) []*grumble.Command {
 			f.String("p", "proxy", "", "proxy URL")
 		},
 		Run: func(ctx *grumble.Context) error {
-			ArmoryInstallCmd(ctx, con)
-			return nil
+			return ArmoryInstallCmd(ctx, con)
 		},
 		HelpGroup: consts.GenericGroup,
 	})
@@ -50,10 +49,10 @@ func Commands(con *console.Console) []*grumble.Command {
 		LongHelp: help.GetHelpFor(consts.CommandArmory + " " + consts.CommandArmoryUpdate),
 		Flags: func(f *grumble.Flags) {
 			f.String("a", "armory", "", "name of the armory to update")
+			f.Bool("f", "force", false, "update packages pinned by the lockfile, and lock the updated versions")
 		},
 		Run: func(ctx *grumble.Context) error {
-			ArmoryUpdateCmd(ctx, con)
-			return nil
+			return ArmoryUpdateCmd(ctx, con)
 		},
 		HelpGroup: consts.GenericGroup,
 	})
@@ -65,8 +64,84 @@ func Commands(con *console.Console) []*grumble.Command {
 			a.String("name", "name of the package to search for")
 		},
 		Run: func(ctx *grumble.Context) error {
-			ArmorySearchCmd(ctx, con)
-			return nil
+			return ArmorySearchCmd(ctx, con)
+		},
+		HelpGroup: consts.GenericGroup,
+	})
+	armoryCmd.AddCommand(&grumble.Command{
+		Name:     consts.CommandArmoryAdd,
+		Help:     "Add an armory",
+		LongHelp: help.GetHelpFor(consts.CommandArmory + " " + consts.CommandArmoryAdd),
+		Args: func(a *grumble.Args) {
+			a.String("name", "name of the armory")
+		},
+		Flags: func(f *grumble.Flags) {
+			f.String("u", "url", "", "url of the armory index")
+			f.String("k", "public-key", "", "minisign public key of the armory")
+			f.String("", "authorization", "", "authorization header")
+			f.String("", "authorization-cmd", "", "command to get the authorization header")
+		},
+		Run: func(ctx *grumble.Context) error {
+			return ArmoryAddCmd(ctx, con)
+		},
+		HelpGroup: consts.GenericGroup,
+	})
+	armoryCmd.AddCommand(&grumble.Command{
+		Name:     consts.CommandArmoryMirror,
+		Help:     "Export armory packages as a signed offline armory",
+		LongHelp: help.GetHelpFor(consts.CommandArmory + " " + consts.CommandArmoryMirror),
+		Args: func(a *grumble.Args) {
+			a.String("output", "output directory, or tarball if ends with .tar.gz")
+		},
+		Flags: func(f *grumble.Flags) {
+			f.StringSliceL("package", []string{}, "package to mirror, all packages if no package or bundle given")
+			f.StringSliceL("bundle", []string{}, "bundle to mirror")
+			f.String("a", "armory", "", "name of the armory to mirror from")
+			f.String("k", "key", "", "minisign private key to sign the index, generated if not exist")
+			f.StringL("password", "", "password of the private key")
+		},
+		Run: func(ctx *grumble.Context) error {
+			return ArmoryMirrorCmd(ctx, con)
+		},
+		HelpGroup: consts.GenericGroup,
+	})
+	armoryCmd.AddCommand(&grumble.Command{
+		Name:     consts.CommandArmoryHost,
+		Help:     "Host an armory mirror with the website of team server",
+		LongHelp: help.GetHelpFor(consts.CommandArmory + " " + consts.CommandArmoryHost),
+		Args: func(a *grumble.Args) {
+			a.String("path", "armory mirror directory or tarball")
+		},
+		Flags: func(f *grumble.Flags) {
+			f.String("n", "website", "armory", "name of the website")
+			f.StringL("web-path", "/armory", "web path of the armory")
+		},
+		Run: func(ctx *grumble.Context) error {
+			return ArmoryHostCmd(ctx, con)
+		},
+		HelpGroup: consts.GenericGroup,
+	})
+	armoryCmd.AddCommand(&grumble.Command{
+		Name:     consts.CommandArmoryLock,
+		Help:     "Pin installed armory packages to the lockfile",
+		LongHelp: help.GetHelpFor(consts.CommandArmory + " " + consts.CommandArmoryLock),
+		Flags: func(f *grumble.Flags) {
+			f.String("l", "lockfile", "", "path of the lockfile, default armory.lock in client directory")
+		},
+		Run: func(ctx *grumble.Context) error {
+			return ArmoryLockCmd(ctx, con)
+		},
+		HelpGroup: consts.GenericGroup,
+	})
+	armoryCmd.AddCommand(&grumble.Command{
+		Name:     consts.CommandArmorySync,
+		Help:     "Install the versions pinned by the lockfile",
+		LongHelp: help.GetHelpFor(consts.CommandArmory + " " + consts.CommandArmorySync),
+		Flags: func(f *grumble.Flags) {
+			f.String("l", "lockfile", "", "path of the lockfile, default armory.lock in client directory")
+		},
+		Run: func(ctx *grumble.Context) error {
+			return ArmorySyncCmd(ctx, con)
 		},
 		HelpGroup: consts.GenericGroup,
 	})
@@ -23,6 +23,7 @@ var (
 	// ErrPackageNotFound - The package was not found
 	ErrPackageNotFound         = errors.New("package not found")
 	ErrPackageAlreadyInstalled = errors.New("package is already installed")
+	ErrPackagePinned           = errors.New("package is pinned by lockfile")
 )
 
 const (
@@ -31,12 +32,11 @@ const (
 )
 
 // ArmoryInstallCmd - The armory install command
-func ArmoryInstallCmd(ctx *grumble.Context, con *console.Console) {
+func ArmoryInstallCmd(ctx *grumble.Context, con *console.Console) error {
 	var promptToOverwrite bool
 	name := ctx.Args.String("name")
 	if name == "" {
-		console.Log.Errorf("A package or bundle name is required")
-		return
+		return errors.New("A package or bundle name is required")
 	}
 	forceInstallation := ctx.Flags.Bool("force")
 	if forceInstallation {
@@ -53,6 +53,16 @@ func ArmoryInstallCmd(ctx *grumble.Context, con *console.Console) {
 		//return
 	}
 
+	// packages pinned to another version are skipped unless forced
+	var lock *assets.ArmoryLock
+	if !forceInstallation {
+		var err error
+		lock, err = assets.LoadArmoryLock(assets.GetArmoryLockPath())
+		if err != nil {
+			return fmt.Errorf("Failed to read lockfile: %s", err)
+		}
+	}
+
 	clientConfig := parseArmoryHTTPConfig(ctx)
 	refresh(clientConfig)
 	if name == "all" {
@@ -72,24 +82,23 @@ func ArmoryInstallCmd(ctx *grumble.Context, con *console.Console) {
 		newconfirm := tui.NewModel(confirmModel, nil, false, true)
 		err := newconfirm.Run()
 		if err != nil {
-			console.Log.Errorf("Error running confirm model: %s", err)
-			return
+			return fmt.Errorf("Error running confirm model: %s", err)
 		}
 		if !confirmModel.Confirmed {
-			return
+			return nil
 		}
 		promptToOverwrite = false
 	}
-	err := installPackageByName(name, armoryPK, forceInstallation, promptToOverwrite, clientConfig, con)
+	err := installPackageByName(name, armoryPK, forceInstallation, promptToOverwrite, lock, clientConfig, con)
 	if err == nil {
-		return
+		return nil
 	}
 	if errors.Is(err, ErrPackageNotFound) {
 		bundles := bundlesInCache()
 		for _, bundle := range bundles {
 			if bundle.Name == name {
-				installBundle(bundle, armoryPK, forceInstallation, clientConfig, con)
-				return
+				installBundle(bundle, armoryPK, forceInstallation, lock, clientConfig, con)
+				return nil
 			}
 		}
 		if armoryPK == "" {
@@ -99,13 +108,16 @@ func ArmoryInstallCmd(ctx *grumble.Context, con *console.Console) {
 		}
 	} else if errors.Is(err, ErrPackageAlreadyInstalled) {
 		console.Log.Errorf("Package %q is already installed - use the force option to overwrite it\n", name)
+	} else if errors.Is(err, ErrPackagePinned) {
+		console.Log.Warnf("%s\n", err)
 	} else {
 		console.Log.Errorf("Could not install package: %s\n", err)
 	}
+	return nil
 }
 
-func installBundle(bundle *ArmoryBundle, armoryPK string, forceInstallation bool, clientConfig ArmoryHTTPConfig,
-	con *console.Console) {
+func installBundle(bundle *ArmoryBundle, armoryPK string, forceInstallation bool, lock *assets.ArmoryLock,
+	clientConfig ArmoryHTTPConfig, con *console.Console) {
 	installList := []string{}
 	pendingPackages := make(map[string]string)
 
@@ -132,23 +144,37 @@ func installBundle(bundle *ArmoryBundle, armoryPK string, forceInstallation bool
 			console.Log.Errorf("The package cache is out of date. Please run armory refresh and try again.\n")
 			return
 		}
-		if packageEntry.Pkg.IsAlias {
-			err := installAliasPackage(packageEntry, false, clientConfig, con)
-			if err != nil {
-				console.Log.Errorf("Failed to install alias '%s': %s\n", packageEntry.Alias.CommandName, err)
-				return
-			}
-		} else {
-			err := installExtensionPackage(packageEntry, false, clientConfig, con)
-			if err != nil {
-				console.Log.Errorf("Failed to install extension '%s': %s\n", packageEntry.Extension.Name, err)
-				return
-			}
+		err := installPackage(packageEntry, lock, false, clientConfig, con)
+		if errors.Is(err, ErrPackagePinned) {
+			console.Log.Warnf("%s, skipped\n", err)
+		} else if err != nil {
+			console.Log.Errorf("Failed to install %s\n", err)
+			return
+		}
+	}
+}
+
+// installPackage - install the alias or extension of entry, refused with ErrPackagePinned if lock pins
+// it to another version. Single, all and bundle installs share it, lock is nil when forced.
+func installPackage(entry *pkgCacheEntry, lock *assets.ArmoryLock, promptToOverwrite bool,
+	clientConfig ArmoryHTTPConfig, con *console.Console) error {
+	if locked := pinnedEntry(lock, entry); locked != nil {
+		return fmt.Errorf("%w: %s %s is pinned to %s, use `armory sync` to install the pinned version or --force to override",
+			ErrPackagePinned, locked.Type, locked.Name, locked.Version)
+	}
+	if entry.Pkg.IsAlias {
+		if err := installAliasPackage(entry, promptToOverwrite, clientConfig, con); err != nil {
+			return fmt.Errorf("alias '%s': %s", entry.Alias.CommandName, err)
 		}
+		return nil
 	}
+	if err := installExtensionPackage(entry, promptToOverwrite, clientConfig, con); err != nil {
+		return fmt.Errorf("extension '%s': %s", entry.Extension.Name, err)
+	}
+	return nil
 }
 
-func installPackageByName(name, armoryPK string, forceInstallation, promptToOverwrite bool,
+func installPackageByName(name, armoryPK string, forceInstallation, promptToOverwrite bool, lock *assets.ArmoryLock,
 	clientConfig ArmoryHTTPConfig, con *console.Console) error {
 	pendingPackages := make(map[string]string)
 	packageInstallList, err := buildInstallList(name, armoryPK, forceInstallation, pendingPackages)
@@ -161,16 +187,13 @@ func installPackageByName(name, armoryPK string, forceInstallation, promptToOver
 			if entry == nil {
 				return errors.New("cache consistency error - please refresh the cache and try again")
 			}
-			if entry.Pkg.IsAlias {
-				err := installAliasPackage(entry, promptToOverwrite, clientConfig, con)
-				if err != nil {
-					return fmt.Errorf("failed to install alias '%s': %s", entry.Alias.CommandName, err)
-				}
-			} else {
-				err := installExtensionPackage(entry, promptToOverwrite, clientConfig, con)
-				if err != nil {
-					return fmt.Errorf("failed to install extension '%s': %s", entry.Extension.Name, err)
-				}
+			err := installPackage(entry, lock, promptToOverwrite, clientConfig, con)
+			if errors.Is(err, ErrPackagePinned) && name == "all" {
+				console.Log.Warnf("%s, skipped\n", err)
+			} else if errors.Is(err, ErrPackagePinned) {
+				return err
+			} else if err != nil {
+				return fmt.Errorf("failed to install %s", err)
 			}
 		}
 	} else {
@@ -427,15 +450,29 @@ func buildInstallList(name, armoryPK string, forceInstallation bool, pendingPack
 
 func installAliasPackage(entry *pkgCacheEntry, promptToOverwrite bool, clientConfig ArmoryHTTPConfig,
 	con *console.Console) error {
+	installPath, err := installAliasPackageTo(entry, assets.GetAliasesDir(), promptToOverwrite, clientConfig, con)
+	if err != nil {
+		return err
+	}
+	_, err = alias.LoadAlias(filepath.Join(installPath, alias.ManifestFileName), con)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// installAliasPackageTo - download and install alias into rootDir without loading it, returns the install path
+func installAliasPackageTo(entry *pkgCacheEntry, rootDir string, promptToOverwrite bool, clientConfig ArmoryHTTPConfig,
+	con *console.Console) (string, error) {
 	if entry == nil {
-		return errors.New("package not found")
+		return "", errors.New("package not found")
 	}
 	if !entry.Pkg.IsAlias {
-		return errors.New("package is not an alias")
+		return "", errors.New("package is not an alias")
 	}
 	repoURL, err := url.Parse(entry.RepoURL)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	console.Log.Infof("Downloading alias ...")
@@ -448,7 +485,7 @@ func installAliasPackage(entry *pkgCacheEntry, promptToOverwrite bool, clientCon
 		sig, tarGz, err = DefaultArmoryPkgParser(entry.ArmoryConfig, &entry.Pkg, false, clientConfig)
 	}
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	var publicKey minisign.PublicKey
@@ -456,30 +493,26 @@ func installAliasPackage(entry *pkgCacheEntry, promptToOverwrite bool, clientCon
 	rawSig, _ := sig.MarshalText()
 	valid := minisign.Verify(publicKey, tarGz, []byte(rawSig))
 	if !valid {
-		return errors.New("signature verification failed")
+		return "", errors.New("signature verification failed")
 	}
 
 	tmpFile, err := ioutil.TempFile("", "sliver-armory-")
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer os.Remove(tmpFile.Name())
 	_, err = tmpFile.Write(tarGz)
 	if err != nil {
-		return err
+		return "", err
 	}
 	tmpFile.Close()
 	tui.Clear()
 
-	installPath := alias.InstallFromFile(tmpFile.Name(), entry.Alias.CommandName, promptToOverwrite, con)
+	installPath := alias.InstallFromFileTo(tmpFile.Name(), entry.Alias.CommandName, rootDir, promptToOverwrite, con)
 	if installPath == nil {
-		return errors.New("failed to install alias")
+		return "", errors.New("failed to install alias")
 	}
-	_, err = alias.LoadAlias(filepath.Join(*installPath, alias.ManifestFileName), con)
-	if err != nil {
-		return err
-	}
-	return nil
+	return *installPath, nil
 }
 
 const maxDepDepth = 10 // Arbitrary recursive limit for dependencies
@@ -519,6 +552,11 @@ func resolveExtensionPackageDependencies(pkg *pkgCacheEntry, deps map[string]*pk
 }
 
 func installExtensionPackage(entry *pkgCacheEntry, promptToOverwrite bool, clientConfig ArmoryHTTPConfig, con *console.Console) error {
+	return installExtensionPackageTo(entry, assets.GetExtensionsDir(), promptToOverwrite, clientConfig, con)
+}
+
+// installExtensionPackageTo - download and install extension into rootDir
+func installExtensionPackageTo(entry *pkgCacheEntry, rootDir string, promptToOverwrite bool, clientConfig ArmoryHTTPConfig, con *console.Console) error {
 	if entry == nil {
 		return errors.New("package not found")
 	}
@@ -564,7 +602,7 @@ func installExtensionPackage(entry *pkgCacheEntry, promptToOverwrite bool, clien
 
 	tui.Clear()
 
-	extension.InstallFromDir(tmpFile.Name(), promptToOverwrite, con, true)
+	extension.InstallFromDirTo(tmpFile.Name(), rootDir, promptToOverwrite, con, true)
 
 	return nil
 }
@@ -0,0 +1,283 @@
+package armory
+
+import (
+	"fmt"
+	"github.com/chainreactors/grumble"
+	"github.com/chainreactors/malice-network/client/assets"
+	"github.com/chainreactors/malice-network/client/command/alias"
+	"github.com/chainreactors/malice-network/client/command/extension"
+	"github.com/chainreactors/malice-network/client/console"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ArmoryLockCmd - Pin installed aliases and extensions to the lockfile
+func ArmoryLockCmd(ctx *grumble.Context, con *console.Console) error {
+	lockPath := ctx.Flags.String("lockfile")
+	if lockPath == "" {
+		lockPath = assets.GetArmoryLockPath()
+	}
+	clientConfig := parseArmoryHTTPConfig(ctx)
+	refresh(clientConfig)
+
+	lock := &assets.ArmoryLock{}
+	for _, manifestPath := range assets.GetInstalledAliasManifests() {
+		data, err := os.ReadFile(manifestPath)
+		if err != nil {
+			console.Log.Errorf("Failed to read %s: %s\n", manifestPath, err)
+			continue
+		}
+		manifest, err := alias.ParseAliasManifest(data)
+		if err != nil {
+			console.Log.Errorf("Failed to parse %s: %s\n", manifestPath, err)
+			continue
+		}
+		pkg, err := lockInstalled(assets.LockedAlias, manifest.CommandName, manifest.Version, filepath.Dir(manifestPath))
+		if err != nil {
+			console.Log.Errorf("Failed to lock alias %s: %s\n", manifest.CommandName, err)
+			continue
+		}
+		lock.Set(pkg)
+	}
+	for _, manifestPath := range assets.GetInstalledExtensionManifests() {
+		manifest, err := extension.LoadExtensionManifest(manifestPath)
+		if err != nil {
+			console.Log.Errorf("Failed to parse %s: %s\n", manifestPath, err)
+			continue
+		}
+		pkg, err := lockInstalled(assets.LockedExtension, manifest.Name, manifest.Version, filepath.Dir(manifestPath))
+		if err != nil {
+			console.Log.Errorf("Failed to lock extension %s: %s\n", manifest.Name, err)
+			continue
+		}
+		lock.Set(pkg)
+	}
+	if err := assets.SaveArmoryLock(lockPath, lock); err != nil {
+		return fmt.Errorf("Failed to save lockfile: %s", err)
+	}
+	console.Log.Importantf("%d packages locked to %s", len(lock.Packages), lockPath)
+	return nil
+}
+
+// ArmorySyncCmd - Install the locked version of every package drifted from the lockfile
+func ArmorySyncCmd(ctx *grumble.Context, con *console.Console) error {
+	lockPath := ctx.Flags.String("lockfile")
+	if lockPath == "" {
+		lockPath = assets.GetArmoryLockPath()
+	}
+	lock, err := assets.LoadArmoryLock(lockPath)
+	if err != nil {
+		return fmt.Errorf("Failed to read lockfile: %s", err)
+	}
+	if len(lock.Packages) == 0 {
+		console.Log.Infof("No packages in lockfile %s", lockPath)
+		return nil
+	}
+	clientConfig := parseArmoryHTTPConfig(ctx)
+	refresh(clientConfig)
+
+	var synced, failed int
+	for _, locked := range lock.Packages {
+		if drifts := locked.Verify(); len(drifts) == 0 {
+			continue
+		}
+		entry := lockedPackageEntry(locked)
+		if entry == nil {
+			console.Log.Errorf("%s %s %s not found in armories\n", locked.Type, locked.Name, locked.Version)
+			failed++
+			continue
+		}
+		if err = syncLockedPackage(locked, entry, clientConfig, con); err != nil {
+			console.Log.Errorf("Failed to sync %s %s, the installed package is kept: %s\n", locked.Type, locked.Name, err)
+			failed++
+			continue
+		}
+		synced++
+	}
+	// the lockfile of another operator becomes the lockfile verified at console start
+	if defaultPath := assets.GetArmoryLockPath(); lockPath != defaultPath {
+		if err = assets.SaveArmoryLock(defaultPath, lock); err != nil {
+			console.Log.Errorf("Failed to save lockfile: %s\n", err)
+		}
+	}
+	console.Log.Importantf("%d packages synced, %d failed", synced, failed)
+	return nil
+}
+
+// syncLockedPackage - install the package into a staging directory and only replace the install directory
+// if the staged files match the hashes of the lockfile
+func syncLockedPackage(locked *assets.LockedPackage, entry *pkgCacheEntry, clientConfig ArmoryHTTPConfig, con *console.Console) error {
+	// staged next to the install directory, so that it is moved into place by rename
+	stageDir, err := os.MkdirTemp(assets.GetRootAppDir(), ".armory-sync-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(stageDir)
+	if entry.Pkg.IsAlias {
+		_, err = installAliasPackageTo(entry, stageDir, false, clientConfig, con)
+	} else {
+		err = installExtensionPackageTo(entry, stageDir, false, clientConfig, con)
+	}
+	if err != nil {
+		return err
+	}
+	staged := filepath.Join(stageDir, filepath.Base(locked.Name))
+	if drifts := locked.VerifyDir(staged); len(drifts) > 0 {
+		return fmt.Errorf("does not match lockfile: %s", strings.Join(drifts, "; "))
+	}
+
+	installPath := locked.InstallPath()
+	backup := filepath.Join(stageDir, ".old")
+	if err = os.Rename(installPath, backup); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err = os.Rename(staged, installPath); err != nil {
+		os.Rename(backup, installPath)
+		return err
+	}
+	if entry.Pkg.IsAlias {
+		_, err = alias.LoadAlias(filepath.Join(installPath, alias.ManifestFileName), con)
+	}
+	return err
+}
+
+// VerifyLock - Warn about installed packages drifted from the lockfile, called at console start
+func VerifyLock() {
+	lock, err := assets.LoadArmoryLock(assets.GetArmoryLockPath())
+	if err != nil {
+		console.Log.Warnf("Failed to read armory lockfile: %s\n", err)
+		return
+	}
+	for _, locked := range lock.Packages {
+		if drifts := locked.Verify(); len(drifts) > 0 {
+			console.Log.Warnf("%s %s drifted from armory lockfile: %s, run `armory sync` to restore\n",
+				locked.Type, locked.Name, strings.Join(drifts, "; "))
+		}
+	}
+}
+
+// lockInstalled - lock package installed in dir, armory of the package is looked up in the package cache
+func lockInstalled(typ, name, version, dir string) (*assets.LockedPackage, error) {
+	files, err := assets.HashInstalledFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	locked := &assets.LockedPackage{
+		Name:    name,
+		Type:    typ,
+		Version: version,
+		Files:   files,
+	}
+	if entry := packageCacheLookupByManifest(typ, name, version, ""); entry != nil {
+		locked.ID = entry.ID
+		locked.CommandName = entry.Pkg.CommandName
+		locked.ArmoryPK = entry.ArmoryConfig.PublicKey
+	} else {
+		console.Log.Warnf("%s %s %s not found in armories, only files are locked\n", typ, name, version)
+	}
+	return locked, nil
+}
+
+// lockedPackageEntry - armory package of the locked version
+func lockedPackageEntry(locked *assets.LockedPackage) *pkgCacheEntry {
+	if entry := packageCacheLookupByID(locked.ID); entry != nil && entry.LastErr == nil {
+		if manifestVersion(entry) == locked.Version {
+			return entry
+		}
+	}
+	return packageCacheLookupByManifest(locked.Type, locked.Name, locked.Version, locked.ArmoryPK)
+}
+
+// packageCacheLookupByManifest - package by alias command name or extension name, empty version or armoryPK matches any
+func packageCacheLookupByManifest(typ, name, version, armoryPK string) *pkgCacheEntry {
+	var result *pkgCacheEntry
+	pkgCache.Range(func(key, value interface{}) bool {
+		cacheEntry := value.(pkgCacheEntry)
+		if cacheEntry.LastErr != nil || cacheEntry.Pkg.IsAlias != (typ == assets.LockedAlias) {
+			return true
+		}
+		if armoryPK != "" && cacheEntry.ArmoryConfig.PublicKey != armoryPK {
+			return true
+		}
+		if version != "" && manifestVersion(&cacheEntry) != version {
+			return true
+		}
+		if (cacheEntry.Pkg.IsAlias && cacheEntry.Alias.CommandName == name) ||
+			(!cacheEntry.Pkg.IsAlias && cacheEntry.Extension.Name == name) {
+			result = &cacheEntry
+			return false
+		}
+		return true
+	})
+	return result
+}
+
+func manifestVersion(entry *pkgCacheEntry) string {
+	if entry.Pkg.IsAlias {
+		return entry.Alias.Version
+	}
+	return entry.Extension.Version
+}
+
+// pinnedEntry - lockfile entry pinning the package of entry to another version, nil lock pins nothing
+func pinnedEntry(lock *assets.ArmoryLock, entry *pkgCacheEntry) *assets.LockedPackage {
+	if lock == nil {
+		return nil
+	}
+	typ, name := assets.LockedExtension, entry.Extension.Name
+	if entry.Pkg.IsAlias {
+		typ, name = assets.LockedAlias, entry.Alias.CommandName
+	}
+	if locked := lock.Get(typ, name); locked != nil && locked.Version != manifestVersion(entry) {
+		return locked
+	}
+	return nil
+}
+
+// skipPinned - drop updates of packages pinned by the lockfile
+func skipPinned(lock *assets.ArmoryLock, typ string, updates map[string]VersionInformation) {
+	for name, info := range updates {
+		if locked := lock.Get(typ, name); locked != nil {
+			console.Log.Infof("%s %s is pinned to %s by lockfile, %s skipped\n", typ, name, locked.Version, info.NewVersion)
+			delete(updates, name)
+		}
+	}
+}
+
+// relock - update lockfile entry of a pinned package after it is updated
+func relock(lock *assets.ArmoryLock, typ, name string) error {
+	locked := lock.Get(typ, name)
+	if locked == nil {
+		return nil
+	}
+	version, err := installedManifestVersion(locked)
+	if err != nil {
+		return err
+	}
+	pkg, err := lockInstalled(typ, name, version, locked.InstallPath())
+	if err != nil {
+		return err
+	}
+	lock.Set(pkg)
+	return nil
+}
+
+func installedManifestVersion(locked *assets.LockedPackage) (string, error) {
+	if locked.Type == assets.LockedExtension {
+		manifest, err := extension.LoadExtensionManifest(filepath.Join(locked.InstallPath(), extension.ManifestFileName))
+		if err != nil {
+			return "", err
+		}
+		return manifest.Version, nil
+	}
+	data, err := os.ReadFile(filepath.Join(locked.InstallPath(), alias.ManifestFileName))
+	if err != nil {
+		return "", err
+	}
+	manifest, err := alias.ParseAliasManifest(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", alias.ManifestFileName, err)
+	}
+	return manifest.Version, nil
+}
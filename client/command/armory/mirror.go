@@ -0,0 +1,462 @@
+package armory
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/chainreactors/grumble"
+	"github.com/chainreactors/malice-network/client/assets"
+	"github.com/chainreactors/malice-network/client/console"
+	"github.com/chainreactors/malice-network/helper/cryptography/minisign"
+	"github.com/chainreactors/malice-network/proto/listener/lispb"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	mirrorIndexFileName   = "armory.json"
+	mirrorPackageDir      = "packages"
+	mirrorKeyFileName     = "armory-mirror.key"
+	mirrorPublicKeySuffix = ".pub"
+)
+
+// ArmoryMirrorCmd - Export selected packages as a signed armory, to a directory or a tar.gz
+func ArmoryMirrorCmd(ctx *grumble.Context, con *console.Console) error {
+	output := ctx.Args.String("output")
+	if output == "" {
+		return errors.New("An output directory or tarball is required")
+	}
+	clientConfig := parseArmoryHTTPConfig(ctx)
+	refresh(clientConfig)
+
+	var armoryPK string
+	if armoryName := ctx.Flags.String("armory"); armoryName != "" {
+		armoryPK = getArmoryPublicKey(armoryName)
+		if armoryPK == "" {
+			return fmt.Errorf("Armory '%s' not found", armoryName)
+		}
+	}
+	entries, bundles, err := selectMirrorPackages(ctx.Flags.StringSlice("package"), ctx.Flags.StringSlice("bundle"), armoryPK)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return errors.New("No packages to mirror")
+	}
+
+	keyPath := ctx.Flags.String("key")
+	if keyPath == "" {
+		keyPath = filepath.Join(assets.GetRootAppDir(), mirrorKeyFileName)
+	}
+	privateKey, publicKey, err := loadMirrorKey(keyPath, ctx.Flags.String("password"))
+	if err != nil {
+		return fmt.Errorf("Failed to load mirror key: %s", err)
+	}
+
+	console.Log.Infof("Mirroring %d packages ...", len(entries))
+	files, err := mirrorPackages(entries, bundles, privateKey, clientConfig)
+	if err != nil {
+		return fmt.Errorf("Failed to mirror packages: %s", err)
+	}
+	if isTarGz(output) {
+		err = writeMirrorTarGz(output, files)
+	} else {
+		err = writeMirrorDir(output, files)
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to write mirror: %s", err)
+	}
+	console.Log.Importantf("Armory mirror written to %s, public key: %s", output, publicKey)
+	return nil
+}
+
+// ArmoryHostCmd - Serve a mirrored armory with the website of team server
+func ArmoryHostCmd(ctx *grumble.Context, con *console.Console) error {
+	mirrorPath := ctx.Args.String("path")
+	name := ctx.Flags.String("website")
+	webPath := ctx.Flags.String("web-path")
+	files, err := readMirror(mirrorPath)
+	if err != nil {
+		return fmt.Errorf("Failed to read mirror: %s", err)
+	}
+	if _, ok := files[mirrorIndexFileName]; !ok {
+		return fmt.Errorf("%s is not an armory mirror, missing %s", mirrorPath, mirrorIndexFileName)
+	}
+	addWeb := &lispb.WebsiteAddContent{
+		Name:     name,
+		Contents: map[string]*lispb.WebContent{},
+	}
+	for filePath, content := range files {
+		contentPath := path.Join("/", webPath, filePath)
+		contentType := "application/octet-stream"
+		if strings.HasSuffix(filePath, ".json") {
+			contentType = "application/json"
+		}
+		addWeb.Contents[contentPath] = &lispb.WebContent{
+			Path:        contentPath,
+			ContentType: contentType,
+			Content:     content,
+		}
+	}
+	_, err = con.Rpc.WebsiteAddContent(context.Background(), addWeb)
+	if err != nil {
+		return err
+	}
+	console.Log.Importantf("Armory mirror added to website %s, index at %s",
+		name, path.Join("/", webPath, mirrorIndexFileName))
+	return nil
+}
+
+// ArmoryAddCmd - Add an armory, e.g. a mirror hosted by team server
+func ArmoryAddCmd(ctx *grumble.Context, con *console.Console) error {
+	armoryConfig := &assets.ArmoryConfig{
+		Name:             ctx.Args.String("name"),
+		RepoURL:          ctx.Flags.String("url"),
+		PublicKey:        ctx.Flags.String("public-key"),
+		Authorization:    ctx.Flags.String("authorization"),
+		AuthorizationCmd: ctx.Flags.String("authorization-cmd"),
+		Enabled:          true,
+	}
+	if armoryConfig.Name == assets.DefaultArmoryName {
+		return fmt.Errorf("Armory name '%s' is reserved", assets.DefaultArmoryName)
+	}
+	repoURL, err := url.Parse(armoryConfig.RepoURL)
+	if err != nil || (repoURL.Scheme != "https" && repoURL.Scheme != "http") {
+		return fmt.Errorf("Invalid armory url '%s'", armoryConfig.RepoURL)
+	}
+	var publicKey minisign.PublicKey
+	if err = publicKey.UnmarshalText([]byte(armoryConfig.PublicKey)); err != nil {
+		return fmt.Errorf("Invalid public key: %s", err)
+	}
+	armoryConfig.PublicKey = publicKey.String()
+
+	configs := getCurrentArmoryConfiguration()
+	configs = slices.DeleteFunc(configs, func(config *assets.ArmoryConfig) bool {
+		if config.Name == armoryConfig.Name {
+			currentArmories.Delete(config.PublicKey)
+			return true
+		}
+		return false
+	})
+	configs = append(configs, armoryConfig)
+	if armoryConfig.AuthorizationCmd != "" {
+		assets.RefreshArmoryAuthorization([]*assets.ArmoryConfig{armoryConfig})
+	}
+	currentArmories.Store(armoryConfig.PublicKey, *armoryConfig)
+	if err = assets.SaveArmoriesConfig(configs); err != nil {
+		return fmt.Errorf("Failed to save armories: %s", err)
+	}
+	console.Log.Importantf("Armory %s added", armoryConfig.Name)
+	return nil
+}
+
+// selectMirrorPackages - packages by command name, and packages of bundles, with their dependencies.
+// All packages in cache are selected if no name is given
+func selectMirrorPackages(names, bundleNames []string, armoryPK string) ([]*pkgCacheEntry, []*ArmoryBundle, error) {
+	var entries []*pkgCacheEntry
+	var bundles []*ArmoryBundle
+	if len(names) == 0 && len(bundleNames) == 0 {
+		pkgCache.Range(func(key, value interface{}) bool {
+			cacheEntry := value.(pkgCacheEntry)
+			if cacheEntry.LastErr == nil && (armoryPK == "" || cacheEntry.ArmoryConfig.PublicKey == armoryPK) {
+				entries = append(entries, &cacheEntry)
+			}
+			return true
+		})
+		return entries, bundlesInArmory(armoryPK), nil
+	}
+
+	for _, bundleName := range bundleNames {
+		var found *ArmoryBundle
+		for _, bundle := range bundlesInArmory(armoryPK) {
+			if bundle.Name == bundleName {
+				found = bundle
+				break
+			}
+		}
+		if found == nil {
+			return nil, nil, fmt.Errorf("bundle '%s': %w", bundleName, ErrPackageNotFound)
+		}
+		bundles = append(bundles, found)
+		names = append(names, found.Packages...)
+	}
+
+	selected := map[string]*pkgCacheEntry{}
+	for _, name := range names {
+		entry, err := getPackageForCommand(name, armoryPK, "")
+		if err != nil {
+			return nil, nil, fmt.Errorf("package '%s': %w", name, err)
+		}
+		selected[entry.ID] = entry
+		if entry.Pkg.IsAlias {
+			continue
+		}
+		deps := map[string]*pkgCacheEntry{}
+		if err = resolveExtensionPackageDependencies(entry, deps, map[string]string{}); err != nil {
+			return nil, nil, err
+		}
+		for _, dep := range deps {
+			selected[dep.ID] = dep
+		}
+	}
+	for _, entry := range selected {
+		entries = append(entries, entry)
+	}
+	slices.SortFunc(entries, func(a, b *pkgCacheEntry) int {
+		return strings.Compare(a.Pkg.CommandName, b.Pkg.CommandName)
+	})
+	return entries, bundles, nil
+}
+
+// bundlesInArmory - bundles in index cache of armory, all armories if armoryPK is empty
+func bundlesInArmory(armoryPK string) []*ArmoryBundle {
+	bundles := []*ArmoryBundle{}
+	indexCache.Range(func(key, value interface{}) bool {
+		indexEntry := value.(indexCacheEntry)
+		if indexEntry.LastErr == nil && (armoryPK == "" || indexEntry.ArmoryConfig.PublicKey == armoryPK) {
+			bundles = append(bundles, indexEntry.Index.Bundles...)
+		}
+		return true
+	})
+	return bundles
+}
+
+// mirrorPackages - download and verify packages, then build the files of a self-hosted armory:
+//
+//	armory.json                  index signed by the mirror key
+//	packages/<command>.json      package manifest, keeps the signature of package author
+//	packages/<command>.tar.gz
+//
+// Package urls are relative, the mirror can be served under any url.
+func mirrorPackages(entries []*pkgCacheEntry, bundles []*ArmoryBundle, privateKey minisign.PrivateKey,
+	clientConfig ArmoryHTTPConfig) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	index := &ArmoryIndex{
+		Aliases:    []*ArmoryPackage{},
+		Extensions: []*ArmoryPackage{},
+		Bundles:    []*ArmoryBundle{},
+	}
+	for _, entry := range entries {
+		pkgPath := path.Join(mirrorPackageDir, entry.Pkg.CommandName+".json")
+		if _, ok := files[pkgPath]; ok {
+			console.Log.Warnf("Package %s already mirrored from another armory, skipped", entry.Pkg.CommandName)
+			continue
+		}
+		sig, tarGz, err := downloadPackage(entry, clientConfig)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Pkg.CommandName, err)
+		}
+		rawSig, err := sig.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		tarGzName := entry.Pkg.CommandName + ".tar.gz"
+		pkgData, err := json.Marshal(armoryPkgResponse{
+			Minisig:  string(rawSig),
+			TarGzURL: tarGzName,
+		})
+		if err != nil {
+			return nil, err
+		}
+		files[pkgPath] = pkgData
+		files[path.Join(mirrorPackageDir, tarGzName)] = tarGz
+
+		pkg := &ArmoryPackage{
+			Name:        entry.Pkg.Name,
+			CommandName: entry.Pkg.CommandName,
+			RepoURL:     pkgPath,
+			PublicKey:   entry.Pkg.PublicKey,
+		}
+		if entry.Pkg.IsAlias {
+			index.Aliases = append(index.Aliases, pkg)
+		} else {
+			index.Extensions = append(index.Extensions, pkg)
+		}
+	}
+	for _, bundle := range bundles {
+		index.Bundles = append(index.Bundles, &ArmoryBundle{Name: bundle.Name, Packages: bundle.Packages})
+	}
+
+	indexData, err := json.Marshal(index)
+	if err != nil {
+		return nil, err
+	}
+	indexResp, err := json.Marshal(armoryIndexResponse{
+		Minisig:     base64.StdEncoding.EncodeToString(minisign.Sign(privateKey, indexData)),
+		ArmoryIndex: base64.StdEncoding.EncodeToString(indexData),
+	})
+	if err != nil {
+		return nil, err
+	}
+	files[mirrorIndexFileName] = indexResp
+	return files, nil
+}
+
+// downloadPackage - download package tar.gz and verify it with the public key of package
+func downloadPackage(entry *pkgCacheEntry, clientConfig ArmoryHTTPConfig) (*minisign.Signature, []byte, error) {
+	repoURL, err := url.Parse(entry.RepoURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	var sig *minisign.Signature
+	var tarGz []byte
+	if pkgParser, ok := pkgParsers[repoURL.Hostname()]; ok {
+		sig, tarGz, err = pkgParser(entry.ArmoryConfig, &entry.Pkg, false, clientConfig)
+	} else {
+		sig, tarGz, err = DefaultArmoryPkgParser(entry.ArmoryConfig, &entry.Pkg, false, clientConfig)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if sig == nil {
+		return nil, nil, errors.New("nil signature")
+	}
+
+	var publicKey minisign.PublicKey
+	if err = publicKey.UnmarshalText([]byte(entry.Pkg.PublicKey)); err != nil {
+		return nil, nil, err
+	}
+	rawSig, _ := sig.MarshalText()
+	if !minisign.Verify(publicKey, tarGz, rawSig) {
+		return nil, nil, errors.New("signature verification failed")
+	}
+	return sig, tarGz, nil
+}
+
+// loadMirrorKey - read minisign private key, a new key pair is generated if path does not exist
+func loadMirrorKey(keyPath, password string) (minisign.PrivateKey, string, error) {
+	if _, err := os.Stat(keyPath); os.IsNotExist(err) {
+		publicKey, privateKey, err := minisign.GenerateKey(rand.Reader)
+		if err != nil {
+			return minisign.PrivateKey{}, "", err
+		}
+		encrypted, err := minisign.EncryptKey(password, privateKey)
+		if err != nil {
+			return minisign.PrivateKey{}, "", err
+		}
+		if err = os.WriteFile(keyPath, encrypted, 0600); err != nil {
+			return minisign.PrivateKey{}, "", err
+		}
+		rawPublicKey, _ := publicKey.MarshalText()
+		if err = os.WriteFile(keyPath+mirrorPublicKeySuffix, rawPublicKey, 0644); err != nil {
+			return minisign.PrivateKey{}, "", err
+		}
+		console.Log.Infof("Generated mirror key %s", keyPath)
+		return privateKey, publicKey.String(), nil
+	}
+	privateKey, err := minisign.PrivateKeyFromFile(password, keyPath)
+	if err != nil {
+		return minisign.PrivateKey{}, "", err
+	}
+	return privateKey, privateKey.Public().(minisign.PublicKey).String(), nil
+}
+
+func isTarGz(name string) bool {
+	return strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".tgz")
+}
+
+func writeMirrorDir(dir string, files map[string][]byte) error {
+	for name, content := range files {
+		filePath := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filePath, content, 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeMirrorTarGz(tarGzPath string, files map[string][]byte) error {
+	buf := &bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		err := tarWriter.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Mode:     0600,
+			Size:     int64(len(files[name])),
+		})
+		if err != nil {
+			return err
+		}
+		if _, err = tarWriter.Write(files[name]); err != nil {
+			return err
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(tarGzPath, buf.Bytes(), 0600)
+}
+
+// readMirror - files of a mirror directory or tar.gz, keyed by slash separated relative path
+func readMirror(mirrorPath string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	if isTarGz(mirrorPath) {
+		f, err := os.Open(mirrorPath)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		gzipReader, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		tarReader := tar.NewReader(gzipReader)
+		for {
+			header, err := tarReader.Next()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+			if header.Typeflag != tar.TypeReg {
+				continue
+			}
+			content, err := io.ReadAll(tarReader)
+			if err != nil {
+				return nil, err
+			}
+			files[path.Clean(header.Name)] = content
+		}
+		return files, nil
+	}
+	err := filepath.Walk(mirrorPath, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		name, err := filepath.Rel(mirrorPath, filePath)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(name)] = content
+		return nil
+	})
+	return files, err
+}
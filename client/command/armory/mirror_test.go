@@ -0,0 +1,154 @@
+package armory
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chainreactors/malice-network/client/assets"
+	"github.com/chainreactors/malice-network/helper/cryptography/minisign"
+)
+
+const testAliasManifest = `{
+	"name": "test1",
+	"command_name": "test1",
+	"version": "1.0.0",
+	"help": "some help",
+	"files": [
+		{
+			"os": "windows",
+			"arch": "amd64",
+			"path": "test1.dll"
+		}
+	]
+}`
+
+// newTestArmory - armory serving one alias signed by an author key, package urls are absolute like upstream armories
+func newTestArmory(t *testing.T, tarGz []byte) (*httptest.Server, *assets.ArmoryConfig) {
+	armoryPublicKey, armoryPrivateKey, err := minisign.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authorPublicKey, authorPrivateKey, err := minisign.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := minisign.SignWithComments(authorPrivateKey, tarGz,
+		base64.StdEncoding.EncodeToString([]byte(testAliasManifest)), "")
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	index, _ := json.Marshal(&ArmoryIndex{
+		Aliases: []*ArmoryPackage{{
+			Name:        "test1",
+			CommandName: "test1",
+			RepoURL:     server.URL + "/test1/package.json",
+			PublicKey:   authorPublicKey.String(),
+		}},
+		Bundles: []*ArmoryBundle{{Name: "bundle1", Packages: []string{"test1"}}},
+	})
+	mux.HandleFunc("/armory.json", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(armoryIndexResponse{
+			Minisig:     base64.StdEncoding.EncodeToString(minisign.Sign(armoryPrivateKey, index)),
+			ArmoryIndex: base64.StdEncoding.EncodeToString(index),
+		})
+	})
+	mux.HandleFunc("/test1/package.json", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(armoryPkgResponse{
+			Minisig:  string(sig),
+			TarGzURL: server.URL + "/test1/test1.tar.gz",
+		})
+	})
+	mux.HandleFunc("/test1/test1.tar.gz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(tarGz)
+	})
+	return server, &assets.ArmoryConfig{
+		Name:      "test",
+		RepoURL:   server.URL + "/armory.json",
+		PublicKey: armoryPublicKey.String(),
+		Enabled:   true,
+	}
+}
+
+func TestArmoryMirror(t *testing.T) {
+	tarGz := []byte("alias package")
+	_, armoryConfig := newTestArmory(t, tarGz)
+	clientConfig := ArmoryHTTPConfig{Timeout: 10 * time.Second, IgnoreCache: true}
+	currentArmories.Store(armoryConfig.PublicKey, *armoryConfig)
+	defer currentArmories.Delete(armoryConfig.PublicKey)
+
+	for _, index := range fetchIndexes(clientConfig) {
+		fetchPackageSignatures(index, clientConfig)
+	}
+	entries, bundles, err := selectMirrorPackages(nil, []string{"bundle1"}, armoryConfig.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || len(bundles) != 1 {
+		t.Fatalf("selected %d packages and %d bundles", len(entries), len(bundles))
+	}
+
+	mirrorPublicKey, mirrorPrivateKey, err := minisign.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := mirrorPackages(entries, bundles, mirrorPrivateKey, clientConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	tarGzPath := filepath.Join(dir, "mirror.tar.gz")
+	if err := writeMirrorTarGz(tarGzPath, files); err != nil {
+		t.Fatal(err)
+	}
+	archived, err := readMirror(tarGzPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mirrorDir := filepath.Join(dir, "mirror")
+	if err := writeMirrorDir(mirrorDir, archived); err != nil {
+		t.Fatal(err)
+	}
+
+	// the upstream armory is gone, the mirror is served under an arbitrary path
+	mirror := httptest.NewServer(http.StripPrefix("/armory/", http.FileServer(http.Dir(mirrorDir))))
+	defer mirror.Close()
+	mirrorConfig := &assets.ArmoryConfig{
+		Name:      "mirror",
+		RepoURL:   mirror.URL + "/armory/" + mirrorIndexFileName,
+		PublicKey: mirrorPublicKey.String(),
+	}
+	index, err := DefaultArmoryIndexParser(mirrorConfig, clientConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(index.Aliases) != 1 || len(index.Bundles) != 1 {
+		t.Fatalf("mirror index has %d aliases and %d bundles", len(index.Aliases), len(index.Bundles))
+	}
+	pkg := index.Aliases[0]
+	resolvePackageURL(mirrorConfig, pkg)
+	sig, data, err := downloadPackage(&pkgCacheEntry{ArmoryConfig: mirrorConfig, RepoURL: pkg.RepoURL, Pkg: *pkg}, clientConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, tarGz) {
+		t.Error("mirrored package content mismatch")
+	}
+	if sig.TrustedComment != entries[0].Sig.TrustedComment {
+		t.Error("mirrored package signature mismatch")
+	}
+
+	// index of mirror is only trusted with the mirror key
+	mirrorConfig.PublicKey = armoryConfig.PublicKey
+	if _, err := DefaultArmoryIndexParser(mirrorConfig, clientConfig); err == nil {
+		t.Error("mirror index verified with wrong key")
+	}
+}
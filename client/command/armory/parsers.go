@@ -92,6 +92,9 @@ func DefaultArmoryIndexParser(armoryConfig *assets.ArmoryConfig, clientConfig Ar
 	}
 
 	resp, body, err := httpRequest(clientConfig, armoryConfig.RepoURL, armoryConfig, http.Header{})
+	if err != nil {
+		return nil, err
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, errors.New("api returned non-200 status code")
 	}
@@ -134,7 +137,7 @@ func DefaultArmoryPkgParser(armoryConfig *assets.ArmoryConfig, armoryPkg *Armory
 		return nil, nil, err
 	}
 
-	resp, body, err := httpRequest(clientConfig, armoryConfig.RepoURL, armoryConfig, http.Header{})
+	resp, body, err := httpRequest(clientConfig, armoryPkg.RepoURL, armoryConfig, http.Header{})
 	if err != nil {
 		return nil, nil, err
 	}
@@ -152,7 +155,8 @@ func DefaultArmoryPkgParser(armoryConfig *assets.ArmoryConfig, armoryPkg *Armory
 	}
 	var tarGz []byte
 	if !sigOnly {
-		tarGzURL, err := url.Parse(armoryPkg.RepoURL)
+		// tar_gz_url may be relative to the package manifest, e.g. in a mirrored armory
+		tarGzURL, err := resolveURL(armoryPkg.RepoURL, pkgResp.TarGzURL)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -389,6 +393,19 @@ func githubLatestTagParser(armoryPkg *ArmoryPackage, clientConfig ArmoryHTTPConf
 	return "", errors.New("tag not found in location header")
 }
 
+// resolveURL - resolve ref against base, absolute ref is returned as is
+func resolveURL(base, ref string) (*url.URL, error) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return nil, err
+	}
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return nil, err
+	}
+	return baseURL.ResolveReference(refURL), nil
+}
+
 func parsePkgMinsig(data []byte) (*minisign.Signature, error) {
 	var sig minisign.Signature
 	err := sig.UnmarshalText(data)
@@ -1,6 +1,8 @@
 package armory
 
 import (
+	"errors"
+	"fmt"
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/command/alias"
 	"github.com/chainreactors/malice-network/client/command/extension"
@@ -10,20 +12,18 @@ import (
 )
 
 // ArmorySearchCmd - Search for packages by name
-func ArmorySearchCmd(ctx *grumble.Context, con *console.Console) {
+func ArmorySearchCmd(ctx *grumble.Context, con *console.Console) error {
 	console.Log.Infof("Refreshing package cache ... ")
 	clientConfig := parseArmoryHTTPConfig(ctx)
 	refresh(clientConfig)
 	tui.Clear()
 	rawNameExpr := ctx.Args.String("name")
 	if rawNameExpr == "" {
-		console.Log.Errorf("Please specify a search term!\n")
-		return
+		return errors.New("Please specify a search term!")
 	}
 	nameExpr, err := regexp.Compile(rawNameExpr)
 	if err != nil {
-		console.Log.Errorf("Invalid regular expression: %s\n", err)
-		return
+		return fmt.Errorf("Invalid regular expression: %s", err)
 	}
 
 	aliases, exts := packageManifestsInCache()
@@ -43,7 +43,8 @@ func ArmorySearchCmd(ctx *grumble.Context, con *console.Console) {
 	}
 	if len(matchedAliases) == 0 && len(matchedExts) == 0 {
 		console.Log.Infof("No packages found matching '%s'\n", rawNameExpr)
-		return
+		return nil
 	}
-	PrintArmoryPackages(matchedAliases, matchedExts, con, clientConfig)
+	PrintArmoryPackages(matchedAliases, matchedExts, con, clientConfig, con.OutputFormat(ctx))
+	return nil
 }
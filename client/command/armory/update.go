@@ -35,7 +35,7 @@ type UpdateIdentifier struct {
 }
 
 // ArmoryUpdateCmd - Update all installed extensions/aliases
-func ArmoryUpdateCmd(ctx *grumble.Context, con *console.Console) {
+func ArmoryUpdateCmd(ctx *grumble.Context, con *console.Console) error {
 	var selectedUpdates []UpdateIdentifier
 	var err error
 
@@ -58,6 +58,15 @@ func ArmoryUpdateCmd(ctx *grumble.Context, con *console.Console) {
 	// Check packages for updates
 	aliasUpdates := checkForAliasUpdates(armoryPK)
 	extUpdates := checkForExtensionUpdates(armoryPK)
+	lock, err := assets.LoadArmoryLock(assets.GetArmoryLockPath())
+	if err != nil {
+		return fmt.Errorf("Failed to read lockfile: %s", err)
+	}
+	force := ctx.Flags.Bool("force")
+	if !force {
+		skipPinned(lock, assets.LockedAlias, aliasUpdates)
+		skipPinned(lock, assets.LockedExtension, extUpdates)
+	}
 
 	// Display a table of results
 	if len(aliasUpdates) > 0 || len(extUpdates) > 0 {
@@ -65,15 +74,14 @@ func ArmoryUpdateCmd(ctx *grumble.Context, con *console.Console) {
 		displayAvailableUpdates(updateKeys, aliasUpdates, extUpdates)
 		selectedUpdates, err = getUpdatesFromUser(updateKeys)
 		if err != nil {
-			console.Log.Errorf(err.Error() + "\n")
-			return
+			return err
 		}
 		if len(selectedUpdates) == 0 {
-			return
+			return nil
 		}
 	} else {
 		console.Log.Infof("All packages are up to date")
-		return
+		return nil
 	}
 
 	for _, update := range selectedUpdates {
@@ -91,6 +99,8 @@ func ArmoryUpdateCmd(ctx *grumble.Context, con *console.Console) {
 			err = installAliasPackage(updatePackage, false, clientConfig, con)
 			if err != nil {
 				console.Log.Errorf("Failed to update %s: %s\n", update.Name, err)
+			} else if err = relock(lock, assets.LockedAlias, update.Name); err != nil {
+				console.Log.Errorf("Failed to lock %s: %s\n", update.Name, err)
 			}
 		case ExtensionPackage:
 			extVersionInfo, ok := extUpdates[update.Name]
@@ -105,11 +115,19 @@ func ArmoryUpdateCmd(ctx *grumble.Context, con *console.Console) {
 			err = installExtensionPackage(updatedPackage, false, clientConfig, con)
 			if err != nil {
 				console.Log.Errorf("Failed to update %s: %s\n", update.Name, err)
+			} else if err = relock(lock, assets.LockedExtension, update.Name); err != nil {
+				console.Log.Errorf("Failed to lock %s: %s\n", update.Name, err)
 			}
 		default:
 			continue
 		}
 	}
+	if force && len(lock.Packages) > 0 {
+		if err = assets.SaveArmoryLock(assets.GetArmoryLockPath(), lock); err != nil {
+			console.Log.Errorf("Failed to save lockfile: %s\n", err)
+		}
+	}
+	return nil
 }
 
 func checkForAliasUpdates(armoryPK string) map[string]VersionInformation {
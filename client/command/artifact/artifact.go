@@ -0,0 +1,130 @@
+package artifact
+
+import (
+	"context"
+	"fmt"
+	"github.com/chainreactors/grumble"
+	"github.com/chainreactors/malice-network/client/console"
+	"github.com/chainreactors/malice-network/helper/helper"
+	"github.com/chainreactors/malice-network/proto/client/clientpb"
+	"github.com/chainreactors/tui"
+	"github.com/charmbracelet/bubbles/table"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func ListArtifactsCmd(ctx *grumble.Context, con *console.Console) error {
+	artifacts, err := con.Rpc.ListArtifacts(context.Background(), &clientpb.Empty{})
+	if err != nil {
+		return fmt.Errorf("Error listing artifacts: %v", err)
+	}
+	if len(artifacts.Artifacts) == 0 {
+		console.Log.Info("No artifacts")
+		return nil
+	}
+	PrintArtifacts(artifacts.Artifacts, con, con.OutputFormat(ctx))
+	return nil
+}
+
+func UploadArtifactCmd(ctx *grumble.Context, con *console.Console) error {
+	path := ctx.Args.String("path")
+	name := ctx.Flags.String("name")
+	if name == "" {
+		name = filepath.Base(path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("Error reading file: %v", err)
+	}
+	hash, err := Ensure(con, name, ctx.Flags.String("type"), data)
+	if err != nil {
+		return fmt.Errorf("Error uploading artifact: %v", err)
+	}
+	console.Log.Infof("Artifact %s stored as %s\n", name, hash)
+	return nil
+}
+
+func RemoveArtifactCmd(ctx *grumble.Context, con *console.Console) error {
+	hash, err := resolveHash(con, ctx.Args.String("hash"))
+	if err != nil {
+		return err
+	}
+	_, err = con.Rpc.DeleteArtifact(context.Background(), &clientpb.Artifact{Hash: hash})
+	if err != nil {
+		return fmt.Errorf("Error removing artifact: %v", err)
+	}
+	console.Log.Infof("Artifact %s removed\n", hash)
+	return nil
+}
+
+// resolveHash - full hash of the artifact, the list only shows a prefix
+func resolveHash(con *console.Console, prefix string) (string, error) {
+	artifacts, err := con.Rpc.ListArtifacts(context.Background(), &clientpb.Empty{})
+	if err != nil {
+		return "", err
+	}
+	var matched []string
+	for _, artifact := range artifacts.Artifacts {
+		if strings.HasPrefix(artifact.Hash, prefix) {
+			matched = append(matched, artifact.Hash)
+		}
+	}
+	switch len(matched) {
+	case 0:
+		return "", fmt.Errorf("artifact %s not found", prefix)
+	case 1:
+		return matched[0], nil
+	default:
+		return "", fmt.Errorf("artifact %s is ambiguous", prefix)
+	}
+}
+
+// Ensure - make sure the server stores data, the content is only uploaded if the server lacks its hash.
+// Requests then reference the artifact by the returned hash instead of carrying the binary.
+func Ensure(con *console.Console, name string, typ string, data []byte) (string, error) {
+	hash := helper.SHA256Checksum(data)
+	_, err := con.Rpc.GetArtifact(context.Background(), &clientpb.Artifact{Hash: hash})
+	if err == nil {
+		return hash, nil
+	} else if status.Code(err) != codes.NotFound {
+		return "", err
+	}
+	_, err = con.Rpc.UploadArtifact(context.Background(), &clientpb.Artifact{
+		Hash: hash,
+		Name: name,
+		Type: typ,
+		Bin:  data,
+	})
+	if err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+func PrintArtifacts(artifacts []*clientpb.Artifact, con *console.Console, format string) {
+	var rowEntries []table.Row
+	tableModel := tui.NewTable([]table.Column{
+		{Title: "Hash", Width: 16},
+		{Title: "Name", Width: 20},
+		{Title: "Type", Width: 10},
+		{Title: "Size", Width: 10},
+		{Title: "Uploader", Width: 10},
+		{Title: "Created", Width: 16},
+	}, true)
+	for _, artifact := range artifacts {
+		rowEntries = append(rowEntries, table.Row{
+			artifact.Hash[:16],
+			artifact.Name,
+			artifact.Type,
+			helper.ByteCountBinary(artifact.Size),
+			artifact.Callby,
+			time.Unix(artifact.CreatedAt, 0).Format("2006-01-02 15:04"),
+		})
+	}
+	tableModel.SetRows(rowEntries)
+	con.PrintTable(format, tableModel)
+}
@@ -0,0 +1,51 @@
+package artifact
+
+import (
+	"github.com/chainreactors/grumble"
+	"github.com/chainreactors/malice-network/client/command/completer"
+	"github.com/chainreactors/malice-network/client/command/help"
+	"github.com/chainreactors/malice-network/client/console"
+)
+
+func Commands(con *console.Console) []*grumble.Command {
+	artifactCmd := &grumble.Command{
+		Name:     "artifact",
+		Help:     "List extension and alias binaries stored on server",
+		LongHelp: help.GetHelpFor("artifact"),
+		Flags:    console.OutputFlags,
+		Run: func(ctx *grumble.Context) error {
+			return ListArtifactsCmd(ctx, con)
+		},
+	}
+
+	artifactCmd.AddCommand(&grumble.Command{
+		Name: "upload",
+		Help: "Upload a binary to the server artifact store",
+		Args: func(a *grumble.Args) {
+			a.String("path", "local file path")
+		},
+		Flags: func(f *grumble.Flags) {
+			f.StringL("name", "", "artifact name, default the file name")
+			f.StringL("type", "", "artifact type, e.g. alias, extension")
+		},
+		Run: func(ctx *grumble.Context) error {
+			return UploadArtifactCmd(ctx, con)
+		},
+		Completer: func(prefix string, args []string) []string {
+			return completer.LocalPathCompleter(prefix, args, con)
+		},
+	})
+
+	artifactCmd.AddCommand(&grumble.Command{
+		Name: "rm",
+		Help: "Remove an artifact from the server",
+		Args: func(a *grumble.Args) {
+			a.String("hash", "artifact sha256")
+		},
+		Run: func(ctx *grumble.Context) error {
+			return RemoveArtifactCmd(ctx, con)
+		},
+	})
+
+	return []*grumble.Command{artifactCmd}
+}
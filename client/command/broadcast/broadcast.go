@@ -0,0 +1,267 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/chainreactors/grumble"
+	"github.com/chainreactors/malice-network/client/command/filesystem"
+	"github.com/chainreactors/malice-network/client/command/sys"
+	"github.com/chainreactors/malice-network/client/console"
+	"github.com/chainreactors/malice-network/client/utils"
+	"github.com/chainreactors/malice-network/helper/consts"
+	"github.com/chainreactors/malice-network/proto/client/clientpb"
+	"github.com/chainreactors/malice-network/proto/implant/implantpb"
+	"github.com/chainreactors/tui"
+	"github.com/charmbracelet/bubbles/table"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// argsModules - modules take a list of arguments, the others take one input
+var argsModules = map[string]bool{
+	consts.ModuleCp:     true,
+	consts.ModuleMv:     true,
+	consts.ModuleChmod:  true,
+	consts.ModuleSetEnv: true,
+}
+
+// NewRequest - request of module, args are passed as list or joined as input depending on the module
+func NewRequest(module string, args []string) *implantpb.Request {
+	req := &implantpb.Request{Name: module}
+	if argsModules[module] {
+		req.Args = args
+	} else {
+		req.Input = strings.Join(args, " ")
+	}
+	return req
+}
+
+func BroadcastCmd(ctx *grumble.Context, con *console.Console) error {
+	module := ctx.Args.String("module")
+	args := ctx.Args.StringList("args")
+	req := &clientpb.BroadcastRequest{
+		Request: NewRequest(module, args),
+	}
+	if name := ctx.Flags.String("filter"); name != "" {
+		req.Filter = &clientpb.SessionFilter{Name: name}
+	} else if sessions := ctx.Flags.StringSlice("sessions"); len(sessions) > 0 {
+		req.SessionIds = sessions
+	} else {
+		return errors.New("Require --sessions or --filter")
+	}
+
+	batch, err := con.Rpc.BroadcastTask(context.Background(), req)
+	if err != nil {
+		return fmt.Errorf("Broadcast error: %v", err)
+	}
+	for sid, err := range batch.Errors {
+		console.Log.Errorf("Broadcast to %s failed: %s", sid, err)
+	}
+	if batch.Total == 0 {
+		return nil
+	}
+	console.Log.Infof("Batch %d: %s sent to %d sessions\n", batch.Id, module, batch.Total)
+	format := con.OutputFormat(ctx)
+	if batch.Done {
+		PrintBatch(batch, con, format)
+		return nil
+	}
+	con.AddBatchCallback(batch.Id, func(batch *clientpb.Batch) {
+		PrintBatch(batch, con, format)
+	})
+	// the batch may be done before the callback is registered
+	batch, err = con.Rpc.GetBatch(context.Background(), &clientpb.Batch{Id: batch.Id})
+	if err != nil {
+		return fmt.Errorf("Error getting batch: %v", err)
+	}
+	if batch.Done {
+		con.TriggerBatchCallback(batch)
+	}
+	return nil
+}
+
+func BatchCmd(ctx *grumble.Context, con *console.Console) error {
+	id := ctx.Args.Uint("id")
+	if id == 0 {
+		batches, err := con.Rpc.GetBatches(context.Background(), &clientpb.Empty{})
+		if err != nil {
+			return fmt.Errorf("Error getting batches: %v", err)
+		}
+		if len(batches.Batches) == 0 {
+			console.Log.Info("No batches")
+			return nil
+		}
+		PrintBatches(batches.Batches, con, con.OutputFormat(ctx))
+		return nil
+	}
+	batch, err := con.Rpc.GetBatch(context.Background(), &clientpb.Batch{Id: uint32(id)})
+	if err != nil {
+		return fmt.Errorf("Error getting batch: %v", err)
+	}
+	PrintBatch(batch, con, con.OutputFormat(ctx))
+	return nil
+}
+
+func PrintBatches(batches []*clientpb.Batch, con *console.Console, format string) {
+	sort.Slice(batches, func(i, j int) bool {
+		return batches[i].Id < batches[j].Id
+	})
+	var rowEntries []table.Row
+	tableModel := tui.NewTable([]table.Column{
+		{Title: "ID", Width: 5},
+		{Title: "Module", Width: 15},
+		{Title: "Operator", Width: 10},
+		{Title: "Created", Width: 20},
+		{Title: "Progress", Width: 10},
+		{Title: "Failed", Width: 6},
+	}, true)
+	for _, batch := range batches {
+		rowEntries = append(rowEntries, table.Row{
+			strconv.Itoa(int(batch.Id)),
+			batch.Type,
+			batch.Callby,
+			time.Unix(batch.CreatedAt, 0).Format("2006-01-02 15:04:05"),
+			fmt.Sprintf("%d/%d", batch.Finished, batch.Total),
+			strconv.Itoa(int(batch.Failed) + len(batch.Errors)),
+		})
+	}
+	tableModel.SetRows(rowEntries)
+	con.PrintTable(format, tableModel)
+}
+
+// mergedTable - table of a module whose results of all sessions are merged into one, with a session column
+type mergedTable struct {
+	columns func() []table.Column
+	rows    func(*implantpb.Spite) []table.Row
+}
+
+var mergedTables = map[string]mergedTable{
+	consts.ModulePs: {sys.PsColumns, func(content *implantpb.Spite) []table.Row {
+		return sys.PsRows(content.GetPsResponse())
+	}},
+	consts.ModuleLs: {filesystem.LsColumns, func(content *implantpb.Spite) []table.Row {
+		return filesystem.LsRows(content.GetLsResponse())
+	}},
+	consts.ModuleNetstat: {sys.NetstatColumns, func(content *implantpb.Spite) []table.Row {
+		return sys.NetstatRows(content.GetNetstatResponse())
+	}},
+}
+
+// PrintBatch - combined results of all sessions in the batch, multi-line outputs are printed after the table,
+// or kept in the result column of json and csv. Results of ps, ls and netstat are merged into one table
+func PrintBatch(batch *clientpb.Batch, con *console.Console, format string) {
+	var rowEntries []table.Row
+	var details []string
+	merged, mergeable := mergedTables[batch.Type]
+	var mergedRows []table.Row
+	tableModel := tui.NewTable([]table.Column{
+		{Title: "Session", Width: 10},
+		{Title: "Hostname", Width: 15},
+		{Title: "Task", Width: 5},
+		{Title: "Status", Width: 8},
+		{Title: "Result", Width: 40},
+	}, true)
+	for _, task := range batch.Tasks {
+		var status, result, full string
+		if task.Cur < task.Total && !batch.Done {
+			status = "running"
+		} else {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			content, err := con.Rpc.GetTaskContent(ctx, task)
+			cancel()
+			status, result, full = taskResult(content, err)
+			if mergeable && status == "done" {
+				for _, row := range merged.rows(content) {
+					mergedRows = append(mergedRows, append(table.Row{shortID(task.SessionId)}, row...))
+				}
+			}
+		}
+		if format != utils.OutputText && full != "" {
+			result = full
+		}
+		rowEntries = append(rowEntries, table.Row{
+			shortID(task.SessionId),
+			hostname(con, task.SessionId),
+			strconv.Itoa(int(task.TaskId)),
+			status,
+			result,
+		})
+		if strings.Contains(full, "\n") {
+			details = append(details, fmt.Sprintf("[%s] %s\n%s", shortID(task.SessionId), hostname(con, task.SessionId), full))
+		}
+	}
+	for sid, err := range batch.Errors {
+		rowEntries = append(rowEntries, table.Row{shortID(sid), hostname(con, sid), "", "failed", err})
+	}
+	tableModel.SetRows(rowEntries)
+	con.PrintTable(format, tableModel)
+	if len(mergedRows) > 0 {
+		mergedModel := tui.NewTable(append([]table.Column{{Title: "Session", Width: 10}}, merged.columns()...), true)
+		mergedModel.SetRows(mergedRows)
+		con.PrintTable(format, mergedModel)
+	}
+	if format != utils.OutputText {
+		return
+	}
+	for _, detail := range details {
+		fmt.Println(detail)
+	}
+}
+
+// taskResult - status, one line summary and full output of task content
+func taskResult(content *implantpb.Spite, err error) (string, string, string) {
+	if err != nil {
+		return "error", err.Error(), ""
+	}
+	if content.GetError() != 0 {
+		return "error", fmt.Sprintf("malefic error %d", content.GetError()), ""
+	}
+	if content.GetStatus().GetStatus() != 0 {
+		return "error", content.GetStatus().GetError(), ""
+	}
+	var full string
+	switch {
+	case content.GetPsResponse() != nil:
+		return "done", fmt.Sprintf("%d processes", len(content.GetPsResponse().GetProcesses())), ""
+	case content.GetLsResponse() != nil:
+		return "done", fmt.Sprintf("%d files", len(content.GetLsResponse().GetFiles())), ""
+	case content.GetNetstatResponse() != nil:
+		return "done", fmt.Sprintf("%d sockets", len(content.GetNetstatResponse().GetSocks())), ""
+	case content.GetResponse() != nil:
+		resp := content.GetResponse()
+		full = strings.TrimSpace(resp.GetOutput())
+		if full == "" && len(resp.GetKv()) > 0 {
+			var kvs []string
+			for k, v := range resp.GetKv() {
+				kvs = append(kvs, fmt.Sprintf("%s=%s", k, v))
+			}
+			sort.Strings(kvs)
+			full = strings.Join(kvs, "\n")
+		}
+	}
+	result := full
+	if i := strings.Index(result, "\n"); i >= 0 {
+		result = result[:i] + " ..."
+	}
+	if result == "" {
+		result = "ok"
+	}
+	return "done", result, full
+}
+
+func shortID(sid string) string {
+	if len(sid) > 8 {
+		return sid[:8]
+	}
+	return sid
+}
+
+func hostname(con *console.Console, sid string) string {
+	if _, session := con.FindSession(sid); session != nil && session.Os != nil {
+		return session.Os.Hostname
+	}
+	return ""
+}
@@ -0,0 +1,41 @@
+package broadcast
+
+import (
+	"github.com/chainreactors/grumble"
+	"github.com/chainreactors/malice-network/client/command/help"
+	"github.com/chainreactors/malice-network/client/console"
+)
+
+func Commands(con *console.Console) []*grumble.Command {
+	return []*grumble.Command{
+		{
+			Name:     "broadcast",
+			Help:     "Run a module on many sessions at once",
+			LongHelp: help.GetHelpFor("broadcast"),
+			Args: func(a *grumble.Args) {
+				a.String("module", "module name, e.g. whoami, ps")
+				a.StringList("args", "module arguments", grumble.Default([]string{}))
+			},
+			Flags: func(f *grumble.Flags) {
+				f.StringSlice("s", "sessions", []string{}, "target session ids")
+				f.String("f", "filter", "", "target sessions matched by saved filter")
+				console.OutputFlags(f)
+			},
+			Run: func(ctx *grumble.Context) error {
+				return BroadcastCmd(ctx, con)
+			},
+		},
+		{
+			Name:     "batch",
+			Help:     "List broadcast batches or show results of a batch",
+			LongHelp: help.GetHelpFor("batch"),
+			Args: func(a *grumble.Args) {
+				a.Uint("id", "batch id", grumble.Default(uint(0)))
+			},
+			Flags: console.OutputFlags,
+			Run: func(ctx *grumble.Context) error {
+				return BatchCmd(ctx, con)
+			},
+		},
+	}
+}
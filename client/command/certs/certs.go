@@ -0,0 +1,183 @@
+package certs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/chainreactors/grumble"
+	"github.com/chainreactors/malice-network/client/console"
+	"github.com/chainreactors/malice-network/client/utils"
+	"github.com/chainreactors/malice-network/helper/consts"
+	"github.com/chainreactors/malice-network/proto/client/clientpb"
+	"github.com/chainreactors/tui"
+	"github.com/charmbracelet/bubbles/table"
+	"os"
+	"strings"
+	"time"
+)
+
+func listCertsCmd(c *grumble.Context, con *console.Console) error {
+	certs, err := con.Rpc.ListCerts(context.Background(), &clientpb.Empty{})
+	if err != nil {
+		return fmt.Errorf("Failed to list certificates %s", err)
+	}
+	if len(certs.Certs) == 0 {
+		console.Log.Importantf("No certificates found")
+		return nil
+	}
+	PrintCerts(certs.Certs, con, con.OutputFormat(c))
+	return nil
+}
+
+func PrintCerts(certs []*clientpb.Cert, con *console.Console, format string) {
+	var rowEntries []table.Row
+	tableModel := tui.NewTable([]table.Column{
+		{Title: "ID", Width: 36},
+		{Title: "Name", Width: 20},
+		{Title: "Type", Width: 9},
+		{Title: "Key", Width: 4},
+		{Title: "Not After", Width: 20},
+		{Title: "Status", Width: 16},
+	}, true)
+	for _, cert := range certs {
+		rowEntries = append(rowEntries, table.Row{
+			cert.Id,
+			cert.Name,
+			cert.Type,
+			cert.KeyType,
+			time.Unix(cert.NotAfter, 0).Format("2006-01-02 15:04:05"),
+			expiryStatus(cert),
+		})
+	}
+	tableModel.SetRows(rowEntries)
+	if format != utils.OutputText {
+		con.PrintTable(format, tableModel)
+		return
+	}
+	newTable := tui.NewModel(tableModel, nil, false, false)
+	err := newTable.Run()
+	if err != nil {
+		return
+	}
+}
+
+func expiryStatus(cert *clientpb.Cert) string {
+	if cert.NotAfter == 0 {
+		return "invalid"
+	}
+	left := time.Until(time.Unix(cert.NotAfter, 0))
+	if left <= 0 {
+		return "expired"
+	} else if left < consts.CertExpiryWarning {
+		return fmt.Sprintf("expires in %dd", int(left.Hours()/24))
+	}
+	return "valid"
+}
+
+func inspectCertCmd(c *grumble.Context, con *console.Console) error {
+	id := c.Args.String("id")
+	if id == "" {
+		return errors.New("Must specify a certificate id or name, see --help")
+	}
+	cert, err := con.Rpc.GetCert(context.Background(), &clientpb.CertRequest{Id: id})
+	if err != nil {
+		return fmt.Errorf("Failed to get certificate %s", err)
+	}
+	fmt.Printf("ID:          %s\n", cert.Id)
+	fmt.Printf("Name:        %s\n", cert.Name)
+	fmt.Printf("Type:        %s\n", cert.Type)
+	fmt.Printf("Key Type:    %s\n", cert.KeyType)
+	fmt.Printf("Subject:     %s\n", cert.Subject)
+	fmt.Printf("Issuer:      %s\n", cert.Issuer)
+	fmt.Printf("DNS Names:   %s\n", strings.Join(cert.DnsNames, ", "))
+	fmt.Printf("Not Before:  %s\n", time.Unix(cert.NotBefore, 0).Format("2006-01-02 15:04:05"))
+	fmt.Printf("Not After:   %s (%s)\n", time.Unix(cert.NotAfter, 0).Format("2006-01-02 15:04:05"), expiryStatus(cert))
+	fmt.Printf("Fingerprint: %s\n\n", cert.Fingerprint)
+	fmt.Print(cert.Cert)
+	return nil
+}
+
+func uploadCertCmd(c *grumble.Context, con *console.Console) error {
+	name := c.Flags.String("name")
+	if name == "" {
+		return errors.New("Must specify a certificate name, see --help")
+	}
+	cert, key, err := readPair(c.Flags.String("cert"), c.Flags.String("key"))
+	if err != nil {
+		return err
+	}
+	pb, err := con.Rpc.UploadCert(context.Background(), &clientpb.CertRequest{
+		Name: name,
+		Cert: cert,
+		Key:  key,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to upload certificate %s", err)
+	}
+	console.Log.Importantf("Uploaded certificate %s (%s), expires at %s\n", pb.Name, pb.Id,
+		time.Unix(pb.NotAfter, 0).Format("2006-01-02 15:04:05"))
+	return nil
+}
+
+func generateCertCmd(c *grumble.Context, con *console.Console) error {
+	name := c.Flags.String("name")
+	if name == "" {
+		return errors.New("Must specify a certificate name, see --help")
+	}
+	pb, err := con.Rpc.GenerateCert(context.Background(), &clientpb.CertRequest{
+		Name: name,
+		Cn:   c.Flags.String("cn"),
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to generate certificate %s", err)
+	}
+	console.Log.Importantf("Generated certificate %s (%s), expires at %s\n", pb.Name, pb.Id,
+		time.Unix(pb.NotAfter, 0).Format("2006-01-02 15:04:05"))
+	return nil
+}
+
+func rotateCertCmd(c *grumble.Context, con *console.Console) error {
+	pipeline := c.Args.String("pipeline")
+	listenerID := c.Flags.String("listener_id")
+	if pipeline == "" || listenerID == "" {
+		return errors.New("Must specify pipeline and listener_id, see --help")
+	}
+	typ := c.Flags.String("type")
+	if typ != "tcp" && typ != "website" {
+		return fmt.Errorf("Unknown pipeline type %s, must be tcp or website", typ)
+	}
+	req := &clientpb.CertRequest{
+		Id:         c.Flags.String("id"),
+		Pipeline:   pipeline,
+		Type:       typ,
+		ListenerId: listenerID,
+	}
+	if req.Id == "" {
+		var err error
+		req.Cert, req.Key, err = readPair(c.Flags.String("cert"), c.Flags.String("key"))
+		if err != nil {
+			return err
+		}
+	}
+	_, err := con.Rpc.RotateCert(context.Background(), req)
+	if err != nil {
+		return fmt.Errorf("Failed to rotate certificate %s", err)
+	}
+	console.Log.Infof("Rotating certificate of %s, waiting for listener %s\n", pipeline, listenerID)
+	return nil
+}
+
+func readPair(certPath, keyPath string) (string, string, error) {
+	if certPath == "" || keyPath == "" {
+		return "", "", fmt.Errorf("must specify --cert and --key")
+	}
+	cert, err := os.ReadFile(certPath)
+	if err != nil {
+		return "", "", err
+	}
+	key, err := os.ReadFile(keyPath)
+	if err != nil {
+		return "", "", err
+	}
+	return string(cert), string(key), nil
+}
@@ -0,0 +1,78 @@
+package certs
+
+import (
+	"github.com/chainreactors/grumble"
+	"github.com/chainreactors/malice-network/client/command/help"
+	"github.com/chainreactors/malice-network/client/console"
+	"github.com/chainreactors/malice-network/helper/consts"
+)
+
+func Commands(con *console.Console) []*grumble.Command {
+	certsCmd := &grumble.Command{
+		Name:     "certs",
+		Help:     "certificate manager",
+		LongHelp: help.GetHelpFor("certs"),
+		Flags:    console.OutputFlags,
+		Run: func(c *grumble.Context) error {
+			return listCertsCmd(c, con)
+		},
+		HelpGroup: consts.ListenerGroup,
+	}
+
+	certsCmd.AddCommand(&grumble.Command{
+		Name: "inspect",
+		Help: "Show details and pem of a certificate",
+		Args: func(a *grumble.Args) {
+			a.String("id", "id or name of the certificate")
+		},
+		Run: func(c *grumble.Context) error {
+			return inspectCertCmd(c, con)
+		},
+	})
+
+	certsCmd.AddCommand(&grumble.Command{
+		Name: "upload",
+		Help: "Upload certificate and private key",
+		Flags: func(f *grumble.Flags) {
+			f.String("n", "name", "", "name of the certificate")
+			f.String("", "cert", "", "certificate pem file")
+			f.String("", "key", "", "private key pem file")
+		},
+		Run: func(c *grumble.Context) error {
+			return uploadCertCmd(c, con)
+		},
+	})
+
+	certsCmd.AddCommand(&grumble.Command{
+		Name: "generate",
+		Help: "Generate a certificate signed by the server root ca",
+		Flags: func(f *grumble.Flags) {
+			f.String("n", "name", "", "name of the certificate")
+			f.String("", "cn", "", "common name, default is name")
+		},
+		Run: func(c *grumble.Context) error {
+			return generateCertCmd(c, con)
+		},
+	})
+
+	certsCmd.AddCommand(&grumble.Command{
+		Name:     "rotate",
+		Help:     "Replace the certificate of a running pipeline",
+		LongHelp: help.GetHelpFor("certs rotate"),
+		Args: func(a *grumble.Args) {
+			a.String("pipeline", "name of the pipeline or website")
+		},
+		Flags: func(f *grumble.Flags) {
+			f.String("l", "listener_id", "", "listener id")
+			f.String("t", "type", "tcp", "pipeline type, tcp/website")
+			f.String("i", "id", "", "id or name of a stored certificate")
+			f.String("", "cert", "", "certificate pem file")
+			f.String("", "key", "", "private key pem file")
+		},
+		Run: func(c *grumble.Context) error {
+			return rotateCertCmd(c, con)
+		},
+	})
+
+	return []*grumble.Command{certsCmd}
+}
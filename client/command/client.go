@@ -5,13 +5,22 @@ import (
 	"github.com/chainreactors/malice-network/client/assets"
 	"github.com/chainreactors/malice-network/client/command/alias"
 	"github.com/chainreactors/malice-network/client/command/armory"
+	"github.com/chainreactors/malice-network/client/command/artifact"
+	"github.com/chainreactors/malice-network/client/command/broadcast"
+	"github.com/chainreactors/malice-network/client/command/certs"
 	"github.com/chainreactors/malice-network/client/command/explorer"
 	"github.com/chainreactors/malice-network/client/command/extension"
 	"github.com/chainreactors/malice-network/client/command/jobs"
 	"github.com/chainreactors/malice-network/client/command/listener"
 	"github.com/chainreactors/malice-network/client/command/login"
 	"github.com/chainreactors/malice-network/client/command/observe"
+	"github.com/chainreactors/malice-network/client/command/parser"
+	"github.com/chainreactors/malice-network/client/command/profile"
+	"github.com/chainreactors/malice-network/client/command/report"
+	"github.com/chainreactors/malice-network/client/command/schedule"
+	"github.com/chainreactors/malice-network/client/command/server"
 	"github.com/chainreactors/malice-network/client/command/sessions"
+	"github.com/chainreactors/malice-network/client/command/settings"
 	"github.com/chainreactors/malice-network/client/command/tasks"
 	"github.com/chainreactors/malice-network/client/command/use"
 	"github.com/chainreactors/malice-network/client/command/version"
@@ -37,10 +46,19 @@ func BindClientsCommands(con *console.Console) {
 		armory.Commands,
 		observe.Command,
 		explorer.Commands,
+		report.Command,
+		broadcast.Commands,
+		schedule.Commands,
+		profile.Commands,
+		artifact.Commands,
+		parser.Commands,
+		settings.Commands,
+		server.Commands,
 	)
 
 	bind(consts.ListenerGroup,
 		listener.Commands,
+		certs.Commands,
 	)
 
 	bind(consts.AliasesGroup)
@@ -74,6 +92,7 @@ func BindClientsCommands(con *console.Console) {
 			extension.ExtensionRegisterCommand(ext, con)
 		}
 	}
+	armory.VerifyLock()
 
 	if con.ServerStatus == nil {
 		login.LoginCmd(&grumble.Context{}, con)
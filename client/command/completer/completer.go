@@ -48,8 +48,14 @@ func LocalPathCompleter(prefix string, args []string, con *console.Console) []st
 	return results
 }
 
+// SessionIDCompleter - session ids of all connected servers, prefixed by the server name if more than one is connected
 func SessionIDCompleter(con *console.Console, prefix string) (results []string) {
-	for _, s := range con.Sessions {
+	return con.SessionNames(prefix)
+}
+
+// ActiveSessionIDCompleter - session ids of the active server, for commands bound to the active server
+func ActiveSessionIDCompleter(con *console.Console, prefix string) (results []string) {
+	for _, s := range con.Sessions() {
 		if strings.HasPrefix(s.SessionId, prefix) {
 			results = append(results, s.SessionId)
 		}
@@ -62,7 +68,7 @@ func BasicSessionIDCompleter(con *console.Console, prefix string) (results []str
 		results = append(results, con.GetInteractive().SessionId)
 		return results
 	}
-	for _, s := range con.Sessions {
+	for _, s := range con.Sessions() {
 		if strings.HasPrefix(s.SessionId, prefix) {
 			results = append(results, s.SessionId)
 		}
@@ -7,8 +7,19 @@ import (
 	"github.com/chainreactors/malice-network/client/command/help"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/helper/consts"
+	"github.com/chainreactors/malice-network/proto/implant/implantpb"
+	"google.golang.org/protobuf/proto"
 )
 
+// output handler of execute tasks recovered after the console restarts or reconnects, named by the request message
+func init() {
+	console.RegisterTaskHandler("ExecRequest", func(con *console.Console, sid string, msg proto.Message) {
+		resp := msg.(*implantpb.Spite).GetExecResponse()
+		con.SessionLog(sid).Infof("pid: %d, status: %d", resp.Pid, resp.StatusCode)
+		con.SessionLog(sid).Consolef("output:\n%s", string(resp.Stdout))
+	})
+}
+
 func Commands(con *console.Console) []*grumble.Command {
 	return []*grumble.Command{
 		&grumble.Command{
@@ -24,8 +35,7 @@ func Commands(con *console.Console) []*grumble.Command {
 				a.StringList("arguments", "arguments to the command")
 			},
 			Run: func(ctx *grumble.Context) error {
-				ExecuteCmd(ctx, con)
-				return nil
+				return ExecuteCmd(ctx, con)
 			},
 		},
 
@@ -55,8 +65,7 @@ func Commands(con *console.Console) []*grumble.Command {
 				//f.Int("t", "timeout", consts.DefaultTimeout, "command timeout in seconds")
 			},
 			Run: func(ctx *grumble.Context) error {
-				ExecuteAssemblyCmd(ctx, con)
-				return nil
+				return ExecuteAssemblyCmd(ctx, con)
 			},
 			HelpGroup: consts.ImplantGroup,
 			Completer: func(prefix string, args []string) []string {
@@ -72,8 +81,7 @@ func Commands(con *console.Console) []*grumble.Command {
 			Help:     "Executes the given shellcode in the sliver process",
 			LongHelp: help.GetHelpFor(consts.ModuleExecuteShellcode),
 			Run: func(ctx *grumble.Context) error {
-				ExecuteShellcodeCmd(ctx, con)
-				return nil
+				return ExecuteShellcodeCmd(ctx, con)
 			},
 			Args: func(a *grumble.Args) {
 				a.String("path", "path the shellcode file")
@@ -104,8 +112,7 @@ func Commands(con *console.Console) []*grumble.Command {
 				a.StringList("args", "arguments to pass to the assembly entrypoint")
 			},
 			Run: func(ctx *grumble.Context) error {
-				InlineShellcodeCmd(ctx, con)
-				return nil
+				return InlineShellcodeCmd(ctx, con)
 			},
 			HelpGroup: consts.ImplantGroup,
 			Completer: func(prefix string, args []string) []string {
@@ -132,8 +139,7 @@ func Commands(con *console.Console) []*grumble.Command {
 				f.String("a", "argue", "", "argue")
 			},
 			Run: func(c *grumble.Context) error {
-				ExecuteDLLCmd(c, con)
-				return nil
+				return ExecuteDLLCmd(c, con)
 			},
 			HelpGroup: consts.ImplantGroup,
 			Completer: func(prefix string, args []string) []string {
@@ -178,8 +184,7 @@ func Commands(con *console.Console) []*grumble.Command {
 				f.String("a", "argue", "", "argue")
 			},
 			Run: func(c *grumble.Context) error {
-				ExecutePECmd(c, con)
-				return nil
+				return ExecutePECmd(c, con)
 			},
 			HelpGroup: consts.ImplantGroup,
 			Completer: func(prefix string, args []string) []string {
@@ -220,8 +225,7 @@ func Commands(con *console.Console) []*grumble.Command {
 				f.Int("t", "timeout", consts.DefaultTimeout, "command timeout in seconds")
 			},
 			Run: func(ctx *grumble.Context) error {
-				ExecuteBofCmd(ctx, con)
-				return nil
+				return ExecuteBofCmd(ctx, con)
 			},
 			HelpGroup: consts.ImplantGroup,
 			Completer: func(prefix string, args []string) []string {
@@ -245,8 +249,7 @@ func Commands(con *console.Console) []*grumble.Command {
 				f.Int("t", "timeout", consts.DefaultTimeout, "command timeout in seconds")
 			},
 			Run: func(ctx *grumble.Context) error {
-				ExecutePowershellCmd(ctx, con)
-				return nil
+				return ExecutePowershellCmd(ctx, con)
 			},
 			HelpGroup: consts.ImplantGroup,
 		},
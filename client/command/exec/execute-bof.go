@@ -12,18 +12,17 @@ import (
 	"path/filepath"
 )
 
-func ExecuteBofCmd(ctx *grumble.Context, con *console.Console) {
+func ExecuteBofCmd(ctx *grumble.Context, con *console.Console) error {
 	session := con.GetInteractive()
 	if session == nil {
-		return
+		return nil
 	}
 	path := ctx.Args.String("path")
 	args := ctx.Args.StringList("args")
 	name := filepath.Base(path)
 	binData, err := os.ReadFile(path)
 	if err != nil {
-		console.Log.Errorf("%s\n", err)
-		return
+		return err
 	}
 
 	var task *clientpb.Task
@@ -36,13 +35,13 @@ func ExecuteBofCmd(ctx *grumble.Context, con *console.Console) {
 	})
 
 	if err != nil {
-		console.Log.Errorf("%s", err.Error())
-		return
+		return err
 	}
 
-	con.AddCallback(task.TaskId, func(msg proto.Message) {
+	con.AddCallback(task, func(msg proto.Message) {
 		resp := msg.(*implantpb.Spite).GetAssemblyResponse()
 
 		con.SessionLog(con.GetInteractive().SessionId).Infof("%s output:\n%s", name, string(resp.Data))
 	})
+	return nil
 }
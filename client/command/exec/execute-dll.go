@@ -1,6 +1,7 @@
 package exec
 
 import (
+	"errors"
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/helper/consts"
@@ -11,10 +12,10 @@ import (
 	"path/filepath"
 )
 
-func ExecuteDLLCmd(ctx *grumble.Context, con *console.Console) {
+func ExecuteDLLCmd(ctx *grumble.Context, con *console.Console) error {
 	session := con.GetInteractive()
 	if session == nil {
-		return
+		return nil
 	}
 	sid := con.GetInteractive().SessionId
 	ppid := ctx.Flags.Uint("ppid")
@@ -25,12 +26,10 @@ func ExecuteDLLCmd(ctx *grumble.Context, con *console.Console) {
 	isBlockDll := ctx.Flags.Bool("block_dll")
 	dllBin, err := os.ReadFile(pePath)
 	if err != nil {
-		console.Log.Errorf("%s\n", err.Error())
-		return
+		return err
 	}
 	if helper.CheckPEType(dllBin) != consts.DLLFile {
-		console.Log.Errorf("The file is not a DLL file\n")
-		return
+		return errors.New("The file is not a DLL file")
 	}
 	task, err := con.Rpc.ExecutePE(con.ActiveTarget.Context(), &implantpb.ExecuteBinary{
 		Name:       filepath.Base(pePath),
@@ -48,14 +47,14 @@ func ExecuteDLLCmd(ctx *grumble.Context, con *console.Console) {
 	})
 
 	if err != nil {
-		console.Log.Errorf("%s\n", err)
-		return
+		return err
 	}
 
-	con.AddCallback(task.TaskId, func(msg proto.Message) {
+	con.AddCallback(task, func(msg proto.Message) {
 		resp := msg.(*implantpb.Spite)
 		con.SessionLog(sid).Consolef("Executed PE on target: %s\n", resp.GetAssemblyResponse().GetData())
 	})
+	return nil
 }
 
 func InlineDLLCmd(ctx *grumble.Context, con *console.Console) {
@@ -86,7 +85,7 @@ func InlineDLLCmd(ctx *grumble.Context, con *console.Console) {
 		return
 	}
 
-	con.AddCallback(shellcodeTask.TaskId, func(msg proto.Message) {
+	con.AddCallback(shellcodeTask, func(msg proto.Message) {
 		resp := msg.(*implantpb.Spite)
 		con.SessionLog(sid).Consolef("Executed PE on target: %s\n", resp.GetAssemblyResponse().GetData())
 	})
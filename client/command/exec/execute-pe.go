@@ -1,6 +1,7 @@
 package exec
 
 import (
+	"errors"
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/helper/consts"
@@ -12,10 +13,10 @@ import (
 )
 
 // ExecutePECmd - Execute PE on sacrifice process
-func ExecutePECmd(ctx *grumble.Context, con *console.Console) {
+func ExecutePECmd(ctx *grumble.Context, con *console.Console) error {
 	session := con.GetInteractive()
 	if session == nil {
-		return
+		return nil
 	}
 	sid := con.GetInteractive().SessionId
 	ppid := ctx.Flags.Uint("ppid")
@@ -26,12 +27,10 @@ func ExecutePECmd(ctx *grumble.Context, con *console.Console) {
 	isBlockDll := ctx.Flags.Bool("block_dll")
 	peBin, err := os.ReadFile(pePath)
 	if err != nil {
-		console.Log.Errorf("%s\n", err.Error())
-		return
+		return err
 	}
 	if helper.CheckPEType(peBin) != consts.EXEFile {
-		console.Log.Errorf("The file is not a PE file\n")
-		return
+		return errors.New("The file is not a PE file")
 	}
 
 	task, err := con.Rpc.ExecutePE(con.ActiveTarget.Context(), &implantpb.ExecuteBinary{
@@ -48,14 +47,14 @@ func ExecutePECmd(ctx *grumble.Context, con *console.Console) {
 		},
 	})
 	if err != nil {
-		console.Log.Errorf("%s\n", err)
-		return
+		return err
 	}
 
-	con.AddCallback(task.TaskId, func(msg proto.Message) {
+	con.AddCallback(task, func(msg proto.Message) {
 		resp := msg.(*implantpb.Spite)
 		con.SessionLog(sid).Consolef("Executed PE on target: \n %s\n", resp.GetAssemblyResponse().GetData())
 	})
+	return nil
 }
 
 // InlinePECmd - Execute PE in current process
@@ -87,7 +86,7 @@ func InlinePECmd(ctx *grumble.Context, con *console.Console) {
 		return
 	}
 
-	con.AddCallback(shellcodeTask.TaskId, func(msg proto.Message) {
+	con.AddCallback(shellcodeTask, func(msg proto.Message) {
 		resp := msg.(*implantpb.Spite)
 		if !(resp.Status.Error != "") {
 			con.SessionLog(sid).Consolef("Executed PE on target: %s\n", resp.GetAssemblyResponse().GetData())
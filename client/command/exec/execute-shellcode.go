@@ -1,6 +1,7 @@
 package exec
 
 import (
+	"fmt"
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/helper/consts"
@@ -11,10 +12,10 @@ import (
 )
 
 // ExecuteShellcodeCmd - Execute shellcode in-memory
-func ExecuteShellcodeCmd(ctx *grumble.Context, con *console.Console) {
+func ExecuteShellcodeCmd(ctx *grumble.Context, con *console.Console) error {
 	session := con.GetInteractive()
 	if session == nil {
-		return
+		return nil
 	}
 	sid := con.GetInteractive().SessionId
 	ppid := ctx.Flags.Uint("ppid")
@@ -25,8 +26,7 @@ func ExecuteShellcodeCmd(ctx *grumble.Context, con *console.Console) {
 	isBlockDll := ctx.Flags.Bool("block_dll")
 	shellcodeBin, err := os.ReadFile(shellcodePath)
 	if err != nil {
-		console.Log.Errorf("%s\n", err.Error())
-		return
+		return err
 	}
 
 	shellcodeTask, err := con.Rpc.ExecuteShellcode(con.ActiveTarget.Context(), &implantpb.ExecuteBinary{
@@ -44,27 +44,26 @@ func ExecuteShellcodeCmd(ctx *grumble.Context, con *console.Console) {
 	})
 
 	if err != nil {
-		console.Log.Errorf("%s\n", err)
-		return
+		return err
 	}
 
-	con.AddCallback(shellcodeTask.TaskId, func(msg proto.Message) {
+	con.AddCallback(shellcodeTask, func(msg proto.Message) {
 		resp := msg.(*implantpb.Spite)
 		con.SessionLog(sid).Consolef("Executed shellcode on target: %s\n", resp.GetAssemblyResponse().GetData())
 	})
+	return nil
 }
 
-func InlineShellcodeCmd(ctx *grumble.Context, con *console.Console) {
+func InlineShellcodeCmd(ctx *grumble.Context, con *console.Console) error {
 	session := con.GetInteractive()
 	if session == nil {
-		return
+		return nil
 	}
 	sid := con.GetInteractive().SessionId
 	path := ctx.Args.String("path")
 	data, err := os.ReadFile(path)
 	if err != nil {
-		console.Log.Errorf("Error reading file: %v", err)
-		return
+		return fmt.Errorf("Error reading file: %v", err)
 	}
 	shellcodeTask, err := con.Rpc.ExecuteShellcode(con.ActiveTarget.Context(), &implantpb.ExecuteBinary{
 		Name:   filepath.Base(path),
@@ -72,8 +71,9 @@ func InlineShellcodeCmd(ctx *grumble.Context, con *console.Console) {
 		Type:   consts.ModuleExecuteShellcode,
 		Output: true,
 	})
-	con.AddCallback(shellcodeTask.TaskId, func(msg proto.Message) {
+	con.AddCallback(shellcodeTask, func(msg proto.Message) {
 		resp := msg.(*implantpb.Spite)
 		con.SessionLog(sid).Consolef("Executed shellcode on target: %s\n", resp.GetAssemblyResponse().GetData())
 	})
+	return nil
 }
@@ -10,10 +10,10 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
-func ExecuteCmd(ctx *grumble.Context, con *console.Console) {
+func ExecuteCmd(ctx *grumble.Context, con *console.Console) error {
 	session := con.GetInteractive()
 	if session == nil {
-		return
+		return nil
 	}
 
 	cmdPath := ctx.Args.String("command")
@@ -38,15 +38,15 @@ func ExecuteCmd(ctx *grumble.Context, con *console.Console) {
 		Stdout: stdout,
 	})
 	if err != nil {
-		console.Log.Errorf("%s", err.Error())
-		return
+		return err
 	}
 
-	con.AddCallback(resp.TaskId, func(msg proto.Message) {
+	con.AddCallback(resp, func(msg proto.Message) {
 		resp := msg.(*implantpb.Spite).GetExecResponse()
 		sid := con.GetInteractive().SessionId
 		con.SessionLog(sid).Infof("pid: %d, status: %d", resp.Pid, resp.StatusCode)
 		con.SessionLog(sid).Consolef("%s %s , output:\n%s", cmdPath, strings.Join(args, " "), string(resp.Stdout))
 	})
 
+	return nil
 }
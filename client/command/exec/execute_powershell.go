@@ -12,10 +12,10 @@ import (
 	"strings"
 )
 
-func ExecutePowershellCmd(ctx *grumble.Context, con *console.Console) {
+func ExecutePowershellCmd(ctx *grumble.Context, con *console.Console) error {
 	session := con.GetInteractive()
 	if session == nil {
-		return
+		return nil
 	}
 	sid := con.GetInteractive().SessionId
 	psPath := ctx.Flags.String("path")
@@ -24,8 +24,7 @@ func ExecutePowershellCmd(ctx *grumble.Context, con *console.Console) {
 	if psPath != "" {
 		content, err := os.ReadFile(psPath)
 		if err != nil {
-			console.Log.Errorf("%s\n", err.Error())
-			return
+			return err
 		}
 		psBin.Write(content)
 		psBin.WriteString("\n")
@@ -39,12 +38,12 @@ func ExecutePowershellCmd(ctx *grumble.Context, con *console.Console) {
 		Type: consts.ModulePowershell,
 	})
 	if err != nil {
-		console.Log.Errorf("%s\n", err)
-		return
+		return err
 	}
 
-	con.AddCallback(task.TaskId, func(msg proto.Message) {
+	con.AddCallback(task, func(msg proto.Message) {
 		resp := msg.(*implantpb.Spite)
 		con.SessionLog(sid).Consolef("Executed Powershell on target: %s\n", resp.GetAssemblyResponse().GetData())
 	})
+	return nil
 }
@@ -11,8 +11,7 @@ func Commands(con *console.Console) []*grumble.Command {
 			Name: "explorer",
 			Help: "file explorer",
 			Run: func(ctx *grumble.Context) error {
-				explorerCmd(ctx, con)
-				return nil
+				return explorerCmd(ctx, con)
 			},
 		},
 	}
@@ -280,7 +280,7 @@ func sendLsRequest(e *ExplorerModel) error {
 		console.Log.Errorf("load directory error: %v", err)
 		return err
 	}
-	e.con.AddCallback(lsTask.TaskId, func(msg proto.Message) {
+	e.con.AddCallback(lsTask, func(msg proto.Message) {
 		resp := msg.(*implantpb.Spite).GetLsResponse()
 		var dirEntries []os.DirEntry
 		for _, protoFile := range resp.GetFiles() {
@@ -315,7 +315,7 @@ func downloadRequest(e *ExplorerModel) error {
 		return err
 	}
 	total := downloadTask.Total
-	e.con.AddCallback(downloadTask.TaskId, func(msg proto.Message) {
+	e.con.AddCallback(downloadTask, func(msg proto.Message) {
 		block := msg.(*implantpb.Spite).GetBlock()
 		e.progress.SetProgressPercent(float64(block.BlockId+1) / float64(total))
 		e.progress.Update(tui.ViewMsg{})
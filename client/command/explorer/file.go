@@ -1,6 +1,7 @@
 package explorer
 
 import (
+	"fmt"
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/helper/consts"
@@ -10,10 +11,10 @@ import (
 	"os"
 )
 
-func explorerCmd(ctx *grumble.Context, con *console.Console) {
+func explorerCmd(ctx *grumble.Context, con *console.Console) error {
 	session := con.GetInteractive()
 	if session == nil {
-		return
+		return nil
 	}
 	dirEntriesChan := make(chan []os.DirEntry, 1)
 	var path = ""
@@ -23,11 +24,10 @@ func explorerCmd(ctx *grumble.Context, con *console.Console) {
 		Input: "./",
 	})
 	if err != nil {
-		console.Log.Errorf("load directory error: %v", err)
-		return
+		return fmt.Errorf("load directory error: %v", err)
 	}
 
-	con.AddCallback(lsTask.TaskId, func(msg proto.Message) {
+	con.AddCallback(lsTask, func(msg proto.Message) {
 		resp := msg.(*implantpb.Spite).GetLsResponse()
 		var dirEntries []os.DirEntry
 		for _, protoFile := range resp.GetFiles() {
@@ -49,8 +49,7 @@ func explorerCmd(ctx *grumble.Context, con *console.Console) {
 				dirEntries = newEntries
 				err := SetFiles(&explorer.FilePicker, dirEntries)
 				if err != nil {
-					console.Log.Errorf("Error setting files: %v", err)
-					return
+					return fmt.Errorf("Error setting files: %v", err)
 				}
 				explorer.Files = dirEntries
 				explorer.FilePicker.CurrentDirectory = path
@@ -63,9 +62,10 @@ func explorerCmd(ctx *grumble.Context, con *console.Console) {
 				//if err != nil {
 				//	con.SessionLog(sid).Errorf("Error running explorer: %v", err)
 				//}
-				return
+				return nil
 			}
 
 		}
 	}
+	return nil
 }
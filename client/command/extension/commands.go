@@ -12,9 +12,9 @@ func Commands(con *console.Console) []*grumble.Command {
 		Name:     "extension",
 		Help:     "Extension commands",
 		LongHelp: help.GetHelpFor(consts.CommandExtension),
+		Flags:    console.OutputFlags,
 		Run: func(ctx *grumble.Context) error {
-			ExtensionsCmd(ctx, con)
-			return nil
+			return ExtensionsCmd(ctx, con)
 		},
 		HelpGroup: consts.GenericGroup,
 	}
@@ -23,8 +23,7 @@ func Commands(con *console.Console) []*grumble.Command {
 		Help:     "List all extensions",
 		LongHelp: help.GetHelpFor(consts.CommandExtension + " " + consts.CommandExtensionList),
 		Run: func(ctx *grumble.Context) error {
-			ExtensionsListCmd(ctx, con)
-			return nil
+			return ExtensionsListCmd(ctx, con)
 		},
 		HelpGroup: "Extension",
 	})
@@ -36,8 +35,7 @@ func Commands(con *console.Console) []*grumble.Command {
 		},
 		LongHelp: help.GetHelpFor(consts.CommandExtension + " " + consts.CommandExtensionLoad),
 		Run: func(ctx *grumble.Context) error {
-			ExtensionLoadCmd(ctx, con)
-			return nil
+			return ExtensionLoadCmd(ctx, con)
 		},
 	})
 	extensionCmd.AddCommand(&grumble.Command{
@@ -45,8 +43,7 @@ func Commands(con *console.Console) []*grumble.Command {
 		Help:     "Install an extension",
 		LongHelp: help.GetHelpFor(consts.CommandExtension + " " + consts.CommandExtensionInstall),
 		Run: func(ctx *grumble.Context) error {
-			ExtensionsInstallCmd(ctx, con)
-			return nil
+			return ExtensionsInstallCmd(ctx, con)
 		},
 		Args: func(a *grumble.Args) {
 			a.String("path", "path to the extension directory or tar.gz file")
@@ -57,8 +54,7 @@ func Commands(con *console.Console) []*grumble.Command {
 		Help:     "Remove an extension",
 		LongHelp: help.GetHelpFor(consts.CommandExtension + " " + consts.CommandExtensionRemove),
 		Run: func(ctx *grumble.Context) error {
-			ExtensionsRemoveCmd(ctx, con)
-			return nil
+			return ExtensionsRemoveCmd(ctx, con)
 		},
 		Args: func(a *grumble.Args) {
 			a.String("name", "name of the extension to remove")
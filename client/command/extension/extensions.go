@@ -6,6 +6,7 @@ import (
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/assets"
 	"github.com/chainreactors/malice-network/client/console"
+	"github.com/chainreactors/malice-network/client/utils"
 	"github.com/chainreactors/tui"
 	"github.com/charmbracelet/bubbles/table"
 	"io/ioutil"
@@ -13,16 +14,17 @@ import (
 )
 
 // ExtensionsCmd - List information about installed extensions
-func ExtensionsCmd(ctx *grumble.Context, con *console.Console) {
+func ExtensionsCmd(ctx *grumble.Context, con *console.Console) error {
 	if 0 < len(getInstalledManifests()) {
-		PrintExtensions(con)
+		PrintExtensions(con, con.OutputFormat(ctx))
 	} else {
 		console.Log.Infof("No extensions installed, use the 'armory' command to automatically install some\n")
 	}
+	return nil
 }
 
 // PrintExtensions - Print a list of loaded extensions
-func PrintExtensions(con *console.Console) {
+func PrintExtensions(con *console.Console, format string) {
 	var rowEntries []table.Row
 	var row table.Row
 	tableModel := tui.NewTable([]table.Column{
@@ -55,6 +57,10 @@ func PrintExtensions(con *console.Console) {
 		rowEntries = append(rowEntries, row)
 	}
 	tableModel.SetRows(rowEntries)
+	if format != utils.OutputText {
+		con.PrintTable(format, tableModel)
+		return
+	}
 	newTable := tui.NewModel(tableModel, nil, false, false)
 	err := newTable.Run()
 	if err != nil {
@@ -14,18 +14,23 @@ import (
 )
 
 // ExtensionsInstallCmd - Install an extension
-func ExtensionsInstallCmd(ctx *grumble.Context, con *console.Console) {
+func ExtensionsInstallCmd(ctx *grumble.Context, con *console.Console) error {
 	extLocalPath := ctx.Args.String("path")
 	_, err := os.Stat(extLocalPath)
 	if os.IsNotExist(err) {
-		console.Log.Errorf("Extension path '%s' does not exist", extLocalPath)
-		return
+		return fmt.Errorf("Extension path '%s' does not exist", extLocalPath)
 	}
 	InstallFromDir(extLocalPath, true, con, strings.HasSuffix(extLocalPath, ".tar.gz"))
+	return nil
 }
 
 // Install an extension from a directory
 func InstallFromDir(extLocalPath string, promptToOverwrite bool, con *console.Console, isGz bool) {
+	InstallFromDirTo(extLocalPath, assets.GetExtensionsDir(), promptToOverwrite, con, isGz)
+}
+
+// InstallFromDirTo - install extension into rootDir instead of the extensions directory, e.g. a staging directory
+func InstallFromDirTo(extLocalPath string, rootDir string, promptToOverwrite bool, con *console.Console, isGz bool) {
 	var manifestData []byte
 	var err error
 
@@ -45,7 +50,7 @@ func InstallFromDir(extLocalPath string, promptToOverwrite bool, con *console.Co
 		return
 	}
 
-	installPath := filepath.Join(assets.GetExtensionsDir(), filepath.Base(manifest.Name))
+	installPath := filepath.Join(rootDir, filepath.Base(manifest.Name))
 	if _, err := os.Stat(installPath); !os.IsNotExist(err) {
 		if promptToOverwrite {
 			console.Log.Infof("Extension '%s' already exists", manifest.Name)
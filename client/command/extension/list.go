@@ -9,24 +9,24 @@ import (
 )
 
 // ExtensionsListCmd - List all extension loaded on the active session/beacon
-func ExtensionsListCmd(ctx *grumble.Context, con *console.Console) {
+func ExtensionsListCmd(ctx *grumble.Context, con *console.Console) error {
 	session := con.GetInteractive()
 	if session == nil {
-		return
+		return nil
 	}
 
 	task, err := con.Rpc.ListExtensions(con.ActiveTarget.Context(), &implantpb.Request{
 		Name: consts.ModuleListExtension,
 	})
 	if err != nil {
-		console.Log.Errorf("%s\n", err)
-		return
+		return err
 	}
 
-	con.AddCallback(task.TaskId, func(msg proto.Message) {
+	con.AddCallback(task, func(msg proto.Message) {
 		exts := msg.(*implantpb.Spite).GetExtensions()
 		for _, ext := range exts.Extensions {
 			con.SessionLog(session.SessionId).Consolef("%s\t%s\t%s", ext.Name, ext.Type, ext.Depend)
 		}
 	})
+	return nil
 }
@@ -8,6 +8,8 @@ import (
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/logs"
 	"github.com/chainreactors/malice-network/client/assets"
+	"github.com/chainreactors/malice-network/client/command/artifact"
+	"github.com/chainreactors/malice-network/client/command/parser"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/client/utils"
 	"github.com/chainreactors/malice-network/helper/consts"
@@ -80,6 +82,7 @@ type ExtCommand struct {
 	Entrypoint  string               `json:"entrypoint"`
 	DependsOn   string               `json:"depends_on"`
 	Init        string               `json:"init"`
+	Parser      string               `json:"parser"` // wasm module parsing the output, relative to the extension directory
 
 	Manifest *ExtensionManifest
 }
@@ -117,11 +120,11 @@ func (e *ExtCommand) getFileForTarget(targetOS string, targetArch string) (strin
 }
 
 // ExtensionLoadCmd - Load extension command
-func ExtensionLoadCmd(ctx *grumble.Context, con *console.Console) {
+func ExtensionLoadCmd(ctx *grumble.Context, con *console.Console) error {
 	dirPath := ctx.Args.String("dir-path")
 	manifest, err := LoadExtensionManifest(filepath.Join(dirPath, ManifestFileName))
 	if err != nil {
-		return
+		return nil
 	}
 	// do not add if the command already exists
 	for _, extCmd := range manifest.ExtCommand {
@@ -131,17 +134,17 @@ func ExtensionLoadCmd(ctx *grumble.Context, con *console.Console) {
 			newConfirm := tui.NewModel(confirmModel, nil, false, true)
 			err = newConfirm.Run()
 			if err != nil {
-				console.Log.Errorf("Error running confirm model: %s\n", err)
-				return
+				return fmt.Errorf("Error running confirm model: %s", err)
 			}
 			if !confirmModel.Confirmed {
-				return
+				return nil
 			}
 		}
 		ExtensionRegisterCommand(extCmd, con)
 		console.Log.Infof("Added %s command: %s\n", extCmd.CommandName, extCmd.Help)
 
 	}
+	return nil
 }
 
 // LoadExtensionManifest - Parse extension files
@@ -193,8 +196,7 @@ func ExtensionRegisterCommand(extCmd *ExtCommand, con *console.Console) {
 		Help: helpMsg,
 		//LongHelp: help.FormatHelpTmpl(extCmd.LongHelp),
 		Run: func(extCtx *grumble.Context) error {
-			runExtensionCmd(extCtx, con)
-			return nil
+			return runExtensionCmd(extCtx, con)
 		},
 		Flags: func(f *grumble.Flags) {
 			// f.Bool("s", "save", false, "Save output to disk")
@@ -246,7 +248,7 @@ func loadExtension(goos string, goarch string, extcmd *ExtCommand, con *console.
 		return ErrExtensionDependModuleNotFound
 	}
 
-	for _, ext := range con.GetInteractive().Extensions.Extensions {
+	for _, ext := range con.GetInteractive().GetExtensions().GetExtensions() {
 		if ext.Name == extcmd.CommandName {
 			return nil
 		}
@@ -262,18 +264,48 @@ func loadExtension(goos string, goarch string, extcmd *ExtCommand, con *console.
 	return nil
 }
 
+// LoadSessionExtension - register commands of the installed extension, and load them into the
+// interactive session if the implant lacks them
+func LoadSessionExtension(name string, con *console.Console) error {
+	manifest, ok := loadedManifests[name]
+	if !ok {
+		var err error
+		manifest, err = LoadExtensionManifest(filepath.Join(assets.GetExtensionsDir(), name, ManifestFileName))
+		if err != nil {
+			return err
+		}
+		for _, extCmd := range manifest.ExtCommand {
+			ExtensionRegisterCommand(extCmd, con)
+		}
+	}
+	session := con.GetInteractive()
+	if session == nil {
+		return nil
+	}
+	for _, extCmd := range manifest.ExtCommand {
+		if err := loadExtension(session.GetOs().GetName(), session.GetOs().GetArch(), extCmd, con); err != nil {
+			return fmt.Errorf("%s: %w", extCmd.CommandName, err)
+		}
+	}
+	return nil
+}
+
 func registerExtension(ext *ExtCommand, binData []byte, con *console.Console) error {
+	hash, err := artifact.Ensure(con, ext.CommandName, consts.CommandExtension, binData)
+	if err != nil {
+		return err
+	}
 	task, err := con.Rpc.LoadExtension(con.ActiveTarget.Context(), &implantpb.LoadExtension{
-		Name:   ext.CommandName,
-		Bin:    binData,
-		Depend: ext.DependsOn,
-		Type:   "",
+		Name:     ext.CommandName,
+		Artifact: hash,
+		Depend:   ext.DependsOn,
+		Type:     "",
 	})
 	if err != nil {
 		return err
 	}
 
-	con.AddCallback(task.TaskId, func(msg proto.Message) {
+	con.AddCallback(task, func(msg proto.Message) {
 		con.SessionLog(con.GetInteractive().SessionId).Infof("Loaded extension %s", ext.CommandName)
 	})
 	return nil
@@ -295,7 +327,7 @@ func registerExtension(ext *ExtCommand, binData []byte, con *console.Console) er
 //	return fmt.Errorf("missing dependency %s", depName)
 //}
 
-func runExtensionCmd(ctx *grumble.Context, con *console.Console) {
+func runExtensionCmd(ctx *grumble.Context, con *console.Console) error {
 	var (
 		err error
 		//extensionArgs []byte
@@ -305,7 +337,7 @@ func runExtensionCmd(ctx *grumble.Context, con *console.Console) {
 	session := con.GetInteractive()
 	args := ctx.Args.StringList("arguments")
 	if session == nil {
-		return
+		return nil
 	}
 	var goos string
 	var goarch string
@@ -316,19 +348,16 @@ func runExtensionCmd(ctx *grumble.Context, con *console.Console) {
 
 	ext, ok := loadedExtensions[ctx.Command.Name]
 	if !ok {
-		console.Log.Errorf("No extension command found for `%s` command\n", ctx.Command.Name)
-		return
+		return fmt.Errorf("No extension command found for `%s` command", ctx.Command.Name)
 	}
 
 	if err = loadExtension(goos, goarch, ext, con); err != nil {
-		console.Log.Errorf("Could not load extension: %s\n", err)
-		return
+		return fmt.Errorf("Could not load extension: %s", err)
 	}
 
 	binPath, err := ext.getFileForTarget(goos, goarch)
 	if err != nil {
-		console.Log.Errorf("Failed to read extension file: %s\n", err)
-		return
+		return fmt.Errorf("Failed to read extension file: %s", err)
 	}
 
 	isBOF := filepath.Ext(binPath) == ".o"
@@ -342,8 +371,7 @@ func runExtensionCmd(ctx *grumble.Context, con *console.Console) {
 		// Beacon Object File -- requires a COFF loader
 		//extensionArgs, err = getBOFArgs(ctx, args, binPath, ext)
 		if err != nil {
-			console.Log.Errorf("BOF args error: %s\n", err)
-			return
+			return fmt.Errorf("BOF args error: %s", err)
 		}
 		//extName = ext.DependsOn
 		entryPoint = loadedExtensions[extName].Entrypoint // should exist at this point
@@ -355,6 +383,12 @@ func runExtensionCmd(ctx *grumble.Context, con *console.Console) {
 		entryPoint = ext.Entrypoint
 	}
 
+	if ext.Parser != "" {
+		parserPath := filepath.Join(assets.GetExtensionsDir(), ext.CommandName, ext.Parser)
+		if err = parser.Register(con, ext.CommandName, parserPath); err != nil {
+			console.Log.Warnf("Output parser of %s not registered: %s\n", ext.CommandName, err)
+		}
+	}
 	go func() {
 
 	}()
@@ -369,13 +403,16 @@ func runExtensionCmd(ctx *grumble.Context, con *console.Console) {
 		},
 	})
 	if err != nil {
-		console.Log.Errorf("Call extension error: %s\n", err.Error())
-		return
+		return fmt.Errorf("Call extension error: %s", err.Error())
 	}
-	con.AddCallback(task.TaskId, func(msg proto.Message) {
+	con.AddCallback(task, func(msg proto.Message) {
 		resp := msg.(*implantpb.Spite).GetAssemblyResponse()
+		if ext.Parser != "" && parser.PrintResult(con, task.SessionId, task.TaskId) {
+			return
+		}
 		con.SessionLog(session.SessionId).Console(string(resp.Data))
 	})
+	return nil
 }
 
 // PrintExtOutput - Print the ext execution output
@@ -13,29 +13,27 @@ import (
 )
 
 // ExtensionsRemoveCmd - Remove an extension
-func ExtensionsRemoveCmd(ctx *grumble.Context, con *console.Console) {
+func ExtensionsRemoveCmd(ctx *grumble.Context, con *console.Console) error {
 	name := ctx.Args.String("name")
 	if name == "" {
-		console.Log.Errorf("Extension name is required\n")
-		return
+		return errors.New("Extension name is required")
 	}
 	confirmModel := tui.NewConfirm(fmt.Sprintf("Remove '%s' extension?", name))
 	newConfirm := tui.NewModel(confirmModel, nil, false, true)
 	err := newConfirm.Run()
 	if err != nil {
-		console.Log.Errorf("Error running confirm model: %s", err)
-		return
+		return fmt.Errorf("Error running confirm model: %s", err)
 	}
 	if !confirmModel.Confirmed {
-		return
+		return nil
 	}
 	err = RemoveExtensionByCommandName(name, con)
 	if err != nil {
-		console.Log.Errorf("Error removing extension: %s\n", err)
-		return
+		return fmt.Errorf("Error removing extension: %s", err)
 	} else {
 		console.Log.Infof("Extension '%s' removed\n", name)
 	}
+	return nil
 }
 
 // RemoveExtensionByCommandName - Remove an extension by command name
@@ -19,8 +19,7 @@ func Commands(con *console.Console) []*grumble.Command {
 				f.String("p", "path", "", "filepath")
 			},
 			Run: func(ctx *grumble.Context) error {
-				download(ctx, con)
-				return nil
+				return download(ctx, con)
 			},
 			HelpGroup: consts.ImplantGroup,
 		},
@@ -32,8 +31,7 @@ func Commands(con *console.Console) []*grumble.Command {
 				f.String("i", "taskID", "", "task ID")
 			},
 			Run: func(ctx *grumble.Context) error {
-				sync(ctx, con)
-				return nil
+				return sync(ctx, con)
 			}, HelpGroup: consts.ImplantGroup,
 		},
 		{
@@ -49,8 +47,7 @@ func Commands(con *console.Console) []*grumble.Command {
 				f.Bool("", "hidden", false, "filename")
 			},
 			Run: func(ctx *grumble.Context) error {
-				upload(ctx, con)
-				return nil
+				return upload(ctx, con)
 			},
 			HelpGroup: consts.ImplantGroup,
 			Completer: func(prefix string, args []string) []string {
@@ -1,6 +1,7 @@
 package file
 
 import (
+	"fmt"
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/proto/implant/implantpb"
@@ -8,10 +9,10 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
-func download(ctx *grumble.Context, con *console.Console) {
+func download(ctx *grumble.Context, con *console.Console) error {
 	session := con.GetInteractive()
 	if session == nil {
-		return
+		return nil
 	}
 	sid := con.GetInteractive().SessionId
 	name := ctx.Flags.String("name")
@@ -21,10 +22,10 @@ func download(ctx *grumble.Context, con *console.Console) {
 		Path: path,
 	})
 	if err != nil {
-		console.Log.Errorf("Download error: %v", err)
-		return
+		return fmt.Errorf("Download error: %v", err)
 	}
-	con.AddCallback(downloadTask.TaskId, func(msg proto.Message) {
+	con.AddCallback(downloadTask, func(msg proto.Message) {
 		con.SessionLog(sid).Importantf("Downloaded file %s from %s", name, path)
 	})
+	return nil
 }
@@ -1,6 +1,7 @@
 package file
 
 import (
+	"fmt"
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/proto/implant/implantpb"
@@ -11,10 +12,10 @@ import (
 	"os"
 )
 
-func upload(ctx *grumble.Context, con *console.Console) {
+func upload(ctx *grumble.Context, con *console.Console) error {
 	session := con.GetInteractive()
 	if session == nil {
-		return
+		return nil
 	}
 	sid := con.GetInteractive().SessionId
 	path := ctx.Args.String("source")
@@ -33,12 +34,11 @@ func upload(ctx *grumble.Context, con *console.Console) {
 		Hidden: hidden,
 	})
 	if err != nil {
-		console.Log.Errorf("Download error: %v", err)
-		return
+		return fmt.Errorf("Download error: %v", err)
 	}
 	total := uploadTask.Total
 	cur := uploadTask.Cur
-	con.AddCallback(uploadTask.TaskId, func(msg proto.Message) {
+	con.AddCallback(uploadTask, func(msg proto.Message) {
 		cur++
 		barModel := tui.NewBar()
 		barModel.SetProgressPercent(float64(cur) / float64(total))
@@ -47,4 +47,5 @@ func upload(ctx *grumble.Context, con *console.Console) {
 		//	con.SessionLog(sid).Errorf("Error running bar: %v", err)
 		//}
 	})
+	return nil
 }
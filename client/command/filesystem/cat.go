@@ -1,6 +1,7 @@
 package filesystem
 
 import (
+	"fmt"
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/helper/consts"
@@ -8,10 +9,10 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
-func CatCmd(ctx *grumble.Context, con *console.Console) {
+func CatCmd(ctx *grumble.Context, con *console.Console) error {
 	session := con.GetInteractive()
 	if session == nil {
-		return
+		return nil
 	}
 	sid := con.GetInteractive().SessionId
 	fileName := ctx.Flags.String("name")
@@ -20,11 +21,15 @@ func CatCmd(ctx *grumble.Context, con *console.Console) {
 		Input: fileName,
 	})
 	if err != nil {
-		console.Log.Errorf("Cat error: %v", err)
-		return
+		return fmt.Errorf("Cat error: %v", err)
 	}
-	con.AddCallback(catTask.TaskId, func(msg proto.Message) {
-		resp := msg.(*implantpb.Spite).GetResponse()
-		con.SessionLog(sid).Consolef("File content: %s\n", resp.GetOutput())
+	con.AddCallback(catTask, func(msg proto.Message) {
+		printCat(con, sid, msg)
 	})
+	return nil
+}
+
+func printCat(con *console.Console, sid string, msg proto.Message) {
+	resp := msg.(*implantpb.Spite).GetResponse()
+	con.SessionLog(sid).Consolef("File content: %s\n", resp.GetOutput())
 }
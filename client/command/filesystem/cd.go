@@ -1,6 +1,7 @@
 package filesystem
 
 import (
+	"fmt"
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/helper/consts"
@@ -8,10 +9,10 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
-func CdCmd(ctx *grumble.Context, con *console.Console) {
+func CdCmd(ctx *grumble.Context, con *console.Console) error {
 	session := con.GetInteractive()
 	if session == nil {
-		return
+		return nil
 	}
 	sid := con.GetInteractive().SessionId
 	path := ctx.Flags.String("path")
@@ -20,11 +21,11 @@ func CdCmd(ctx *grumble.Context, con *console.Console) {
 		Input: path,
 	})
 	if err != nil {
-		console.Log.Errorf("Cd error: %v", err)
-		return
+		return fmt.Errorf("Cd error: %v", err)
 	}
-	con.AddCallback(cdTask.TaskId, func(msg proto.Message) {
+	con.AddCallback(cdTask, func(msg proto.Message) {
 		_ = msg.(*implantpb.Spite).GetResponse()
 		con.SessionLog(sid).Consolef("Changed directory to: %s\n", path)
 	})
+	return nil
 }
@@ -8,10 +8,10 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
-func ChmodCmd(ctx *grumble.Context, con *console.Console) {
+func ChmodCmd(ctx *grumble.Context, con *console.Console) error {
 	session := con.GetInteractive()
 	if session == nil {
-		return
+		return nil
 	}
 	sid := con.GetInteractive().SessionId
 	path := ctx.Flags.String("path")
@@ -22,10 +22,11 @@ func ChmodCmd(ctx *grumble.Context, con *console.Console) {
 	})
 	if err != nil {
 		con.SessionLog(sid).Errorf("Chmod error: %v", err)
-		return
+		return nil
 	}
-	con.AddCallback(chmodTask.TaskId, func(msg proto.Message) {
+	con.AddCallback(chmodTask, func(msg proto.Message) {
 		_ = msg.(*implantpb.Spite)
 		console.Log.Consolef("Chmod success\n")
 	})
+	return nil
 }
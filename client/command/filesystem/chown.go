@@ -1,16 +1,17 @@
 package filesystem
 
 import (
+	"fmt"
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/proto/implant/implantpb"
 	"google.golang.org/protobuf/proto"
 )
 
-func ChownCmd(ctx *grumble.Context, con *console.Console) {
+func ChownCmd(ctx *grumble.Context, con *console.Console) error {
 	session := con.GetInteractive()
 	if session == nil {
-		return
+		return nil
 	}
 	sid := con.GetInteractive().SessionId
 	path := ctx.Flags.String("path")
@@ -24,11 +25,11 @@ func ChownCmd(ctx *grumble.Context, con *console.Console) {
 		Recursive: recursive,
 	})
 	if err != nil {
-		console.Log.Errorf("Chown error: %v", err)
-		return
+		return fmt.Errorf("Chown error: %v", err)
 	}
-	con.AddCallback(chownTask.TaskId, func(msg proto.Message) {
+	con.AddCallback(chownTask, func(msg proto.Message) {
 		_ = msg.(*implantpb.Response)
 		con.SessionLog(sid).Consolef("Chown success\n")
 	})
+	return nil
 }
@@ -5,8 +5,18 @@ import (
 	"github.com/chainreactors/malice-network/client/command/help"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/helper/consts"
+	"google.golang.org/protobuf/proto"
 )
 
+// output handlers of tasks recovered after the console restarts or reconnects
+func init() {
+	console.RegisterTaskHandler(consts.ModulePwd, printPwd)
+	console.RegisterTaskHandler(consts.ModuleCat, printCat)
+	console.RegisterTaskHandler(consts.ModuleLs, func(con *console.Console, sid string, msg proto.Message) {
+		printLs(con, con.OutputFormat(nil), msg)
+	})
+}
+
 func Commands(con *console.Console) []*grumble.Command {
 	return []*grumble.Command{
 		&grumble.Command{
@@ -14,8 +24,7 @@ func Commands(con *console.Console) []*grumble.Command {
 			Help:     "Print working directory",
 			LongHelp: help.GetHelpFor(consts.ModulePwd),
 			Run: func(ctx *grumble.Context) error {
-				PwdCmd(ctx, con)
-				return nil
+				return PwdCmd(ctx, con)
 			},
 			HelpGroup: consts.ImplantGroup,
 		},
@@ -27,8 +36,7 @@ func Commands(con *console.Console) []*grumble.Command {
 			},
 			LongHelp: help.GetHelpFor(consts.ModuleCat),
 			Run: func(ctx *grumble.Context) error {
-				CatCmd(ctx, con)
-				return nil
+				return CatCmd(ctx, con)
 			},
 			HelpGroup: consts.ImplantGroup,
 		},
@@ -37,11 +45,11 @@ func Commands(con *console.Console) []*grumble.Command {
 			Help: "Change directory",
 			Flags: func(f *grumble.Flags) {
 				f.String("p", "path", "", "Directory path")
+				console.OutputFlags(f)
 			},
 			LongHelp: help.GetHelpFor(consts.ModuleCd),
 			Run: func(ctx *grumble.Context) error {
-				CdCmd(ctx, con)
-				return nil
+				return CdCmd(ctx, con)
 			},
 			HelpGroup: consts.ImplantGroup,
 		},
@@ -54,8 +62,7 @@ func Commands(con *console.Console) []*grumble.Command {
 			},
 			LongHelp: help.GetHelpFor(consts.ModuleChmod),
 			Run: func(ctx *grumble.Context) error {
-				ChmodCmd(ctx, con)
-				return nil
+				return ChmodCmd(ctx, con)
 			},
 			HelpGroup: consts.ImplantGroup,
 		},
@@ -70,8 +77,7 @@ func Commands(con *console.Console) []*grumble.Command {
 			},
 			LongHelp: help.GetHelpFor(consts.ModuleChown),
 			Run: func(ctx *grumble.Context) error {
-				ChownCmd(ctx, con)
-				return nil
+				return ChownCmd(ctx, con)
 			},
 			HelpGroup: consts.ImplantGroup,
 		},
@@ -84,8 +90,7 @@ func Commands(con *console.Console) []*grumble.Command {
 			},
 			LongHelp: help.GetHelpFor(consts.ModuleCp),
 			Run: func(ctx *grumble.Context) error {
-				CpCmd(ctx, con)
-				return nil
+				return CpCmd(ctx, con)
 			},
 			HelpGroup: consts.ImplantGroup,
 		},
@@ -94,11 +99,11 @@ func Commands(con *console.Console) []*grumble.Command {
 			Help: "List directory",
 			Flags: func(f *grumble.Flags) {
 				f.String("p", "path", "", "Directory path")
+				console.OutputFlags(f)
 			},
 			LongHelp: help.GetHelpFor(consts.ModuleLs),
 			Run: func(ctx *grumble.Context) error {
-				LsCmd(ctx, con)
-				return nil
+				return LsCmd(ctx, con)
 			},
 			HelpGroup: consts.ImplantGroup,
 		},
@@ -107,11 +112,11 @@ func Commands(con *console.Console) []*grumble.Command {
 			Help: "Make directory",
 			Flags: func(f *grumble.Flags) {
 				f.String("p", "path", "", "Directory path")
+				console.OutputFlags(f)
 			},
 			LongHelp: help.GetHelpFor(consts.ModuleMkdir),
 			Run: func(ctx *grumble.Context) error {
-				MkdirCmd(ctx, con)
-				return nil
+				return MkdirCmd(ctx, con)
 			},
 			HelpGroup: consts.ImplantGroup,
 		},
@@ -124,8 +129,7 @@ func Commands(con *console.Console) []*grumble.Command {
 			},
 			LongHelp: help.GetHelpFor(consts.ModuleMv),
 			Run: func(ctx *grumble.Context) error {
-				MvCmd(ctx, con)
-				return nil
+				return MvCmd(ctx, con)
 			},
 			HelpGroup: consts.ImplantGroup,
 		},
@@ -137,8 +141,7 @@ func Commands(con *console.Console) []*grumble.Command {
 			},
 			LongHelp: help.GetHelpFor(consts.ModuleRm),
 			Run: func(ctx *grumble.Context) error {
-				RmCmd(ctx, con)
-				return nil
+				return RmCmd(ctx, con)
 			},
 			HelpGroup: consts.ImplantGroup,
 		},
@@ -1,6 +1,7 @@
 package filesystem
 
 import (
+	"fmt"
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/helper/consts"
@@ -8,10 +9,10 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
-func CpCmd(ctx *grumble.Context, con *console.Console) {
+func CpCmd(ctx *grumble.Context, con *console.Console) error {
 	session := con.GetInteractive()
 	if session == nil {
-		return
+		return nil
 	}
 	sid := con.GetInteractive().SessionId
 	originPath := ctx.Flags.String("source")
@@ -22,11 +23,11 @@ func CpCmd(ctx *grumble.Context, con *console.Console) {
 		Args: args,
 	})
 	if err != nil {
-		console.Log.Errorf("Cp error: %v", err)
-		return
+		return fmt.Errorf("Cp error: %v", err)
 	}
-	con.AddCallback(mvTask.TaskId, func(msg proto.Message) {
+	con.AddCallback(mvTask, func(msg proto.Message) {
 		_ = msg.(*implantpb.Spite)
 		con.SessionLog(sid).Consolef("Cp success\n")
 	})
+	return nil
 }
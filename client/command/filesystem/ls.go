@@ -9,14 +9,13 @@ import (
 	"github.com/chainreactors/tui"
 	"github.com/charmbracelet/bubbles/table"
 	"google.golang.org/protobuf/proto"
-	"os"
 	"strconv"
 )
 
-func LsCmd(ctx *grumble.Context, con *console.Console) {
+func LsCmd(ctx *grumble.Context, con *console.Console) error {
 	session := con.GetInteractive()
 	if session == nil {
-		return
+		return nil
 	}
 	path := ctx.Flags.String("path")
 	if path == "" {
@@ -27,32 +26,11 @@ func LsCmd(ctx *grumble.Context, con *console.Console) {
 		Input: path,
 	})
 	if err != nil {
-		console.Log.Errorf("Ls error: %v", err)
-		return
+		return fmt.Errorf("Ls error: %v", err)
 	}
-	con.AddCallback(lsTask.TaskId, func(msg proto.Message) {
-		resp := msg.(*implantpb.Spite).GetLsResponse()
-		var rowEntries []table.Row
-		var row table.Row
-		tableModel := tui.NewTable([]table.Column{
-			{Title: "Name", Width: 20},
-			{Title: "IsDir", Width: 5},
-			{Title: "Size", Width: 7},
-			{Title: "ModTime", Width: 10},
-			{Title: "Link", Width: 15},
-		}, true)
-		for _, file := range resp.GetFiles() {
-			row = table.Row{
-				file.Name,
-				strconv.FormatBool(file.IsDir),
-				strconv.FormatUint(file.Size, 10),
-				strconv.FormatInt(file.ModTime, 10),
-				file.Link,
-			}
-			rowEntries = append(rowEntries, row)
-		}
-		tableModel.SetRows(rowEntries)
-		fmt.Printf(tableModel.View(), os.Stdout)
+	format := con.OutputFormat(ctx)
+	con.AddCallback(lsTask, func(msg proto.Message) {
+		printLs(con, format, msg)
 	})
 
 	//newTable := tui.NewModel(tableModel, nil, false, false)
@@ -60,4 +38,38 @@ func LsCmd(ctx *grumble.Context, con *console.Console) {
 	//if err != nil {
 	//	con.SessionLog(sid).Errorf("Error running table: %v", err)
 	//}
+	return nil
+}
+
+func printLs(con *console.Console, format string, msg proto.Message) {
+	tableModel := tui.NewTable(LsColumns(), true)
+	tableModel.SetRows(LsRows(msg.(*implantpb.Spite).GetLsResponse()))
+	con.PrintTable(format, tableModel)
+}
+
+// LsColumns - columns of LsRows, also used by the merged table of broadcast
+func LsColumns() []table.Column {
+	return []table.Column{
+		{Title: "Name", Width: 20},
+		{Title: "IsDir", Width: 5},
+		{Title: "Size", Width: 7},
+		{Title: "ModTime", Width: 10},
+		{Title: "Link", Width: 15},
+	}
+}
+
+func LsRows(resp *implantpb.LsResponse) []table.Row {
+	var rowEntries []table.Row
+	var row table.Row
+	for _, file := range resp.GetFiles() {
+		row = table.Row{
+			file.Name,
+			strconv.FormatBool(file.IsDir),
+			strconv.FormatUint(file.Size, 10),
+			strconv.FormatInt(file.ModTime, 10),
+			file.Link,
+		}
+		rowEntries = append(rowEntries, row)
+	}
+	return rowEntries
 }
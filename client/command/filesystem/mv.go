@@ -1,6 +1,7 @@
 package filesystem
 
 import (
+	"fmt"
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/helper/consts"
@@ -8,10 +9,10 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
-func MvCmd(ctx *grumble.Context, con *console.Console) {
+func MvCmd(ctx *grumble.Context, con *console.Console) error {
 	session := con.GetInteractive()
 	if session == nil {
-		return
+		return nil
 	}
 	sid := con.GetInteractive().SessionId
 	sourcePath := ctx.Flags.String("source")
@@ -22,11 +23,11 @@ func MvCmd(ctx *grumble.Context, con *console.Console) {
 		Args: args,
 	})
 	if err != nil {
-		console.Log.Errorf("Mv error: %v", err)
-		return
+		return fmt.Errorf("Mv error: %v", err)
 	}
-	con.AddCallback(mvTask.TaskId, func(msg proto.Message) {
+	con.AddCallback(mvTask, func(msg proto.Message) {
 		_ = msg.(*implantpb.Spite)
 		con.SessionLog(sid).Consolef("Mv success\n")
 	})
+	return nil
 }
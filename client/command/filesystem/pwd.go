@@ -1,6 +1,7 @@
 package filesystem
 
 import (
+	"fmt"
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/helper/consts"
@@ -8,21 +9,25 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
-func PwdCmd(ctx *grumble.Context, con *console.Console) {
+func PwdCmd(ctx *grumble.Context, con *console.Console) error {
 	session := con.GetInteractive()
 	if session == nil {
-		return
+		return nil
 	}
 	sid := con.GetInteractive().SessionId
 	pwdTask, err := con.Rpc.Pwd(con.ActiveTarget.Context(), &implantpb.Request{
 		Name: consts.ModulePwd,
 	})
 	if err != nil {
-		console.Log.Errorf("Pwd error: %v", err)
-		return
+		return fmt.Errorf("Pwd error: %v", err)
 	}
-	con.AddCallback(pwdTask.TaskId, func(msg proto.Message) {
-		resp := msg.(*implantpb.Spite).GetResponse()
-		con.SessionLog(sid).Consolef("%s\n", resp.GetOutput())
+	con.AddCallback(pwdTask, func(msg proto.Message) {
+		printPwd(con, sid, msg)
 	})
+	return nil
+}
+
+func printPwd(con *console.Console, sid string, msg proto.Message) {
+	resp := msg.(*implantpb.Spite).GetResponse()
+	con.SessionLog(sid).Consolef("%s\n", resp.GetOutput())
 }
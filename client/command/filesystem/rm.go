@@ -1,6 +1,7 @@
 package filesystem
 
 import (
+	"fmt"
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/helper/consts"
@@ -8,10 +9,10 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
-func RmCmd(ctx *grumble.Context, con *console.Console) {
+func RmCmd(ctx *grumble.Context, con *console.Console) error {
 	session := con.GetInteractive()
 	if session == nil {
-		return
+		return nil
 	}
 	sid := con.GetInteractive().SessionId
 	fileName := ctx.Flags.String("name")
@@ -20,11 +21,11 @@ func RmCmd(ctx *grumble.Context, con *console.Console) {
 		Input: fileName,
 	})
 	if err != nil {
-		console.Log.Errorf("Rm error: %v", err)
-		return
+		return fmt.Errorf("Rm error: %v", err)
 	}
-	con.AddCallback(rmTask.TaskId, func(msg proto.Message) {
+	con.AddCallback(rmTask, func(msg proto.Message) {
 		_ = msg.(*implantpb.Spite)
 		con.SessionLog(sid).Consolef("Removed file success\n")
 	})
+	return nil
 }
@@ -5,6 +5,7 @@ import (
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/assets"
 	"github.com/chainreactors/malice-network/client/console"
+	"github.com/chainreactors/malice-network/client/utils"
 	"github.com/chainreactors/malice-network/proto/client/clientpb"
 	"github.com/chainreactors/tui"
 	"github.com/charmbracelet/bubbles/table"
@@ -23,28 +24,29 @@ func Command(con *console.Console) []*grumble.Command {
 				//f.String("e", "filter-re", "", "filter sessions by regular expression")
 
 				f.Int("t", "timeout", assets.DefaultSettings.DefaultTimeout, "command timeout in seconds")
+				console.OutputFlags(f)
 			},
 			Run: func(ctx *grumble.Context) error {
-				JobCmd(ctx, con)
-				return nil
+				return JobCmd(ctx, con)
 			},
 		},
 	}
 }
-func JobCmd(ctx *grumble.Context, con *console.Console) {
+func JobCmd(ctx *grumble.Context, con *console.Console) error {
 	jobs, err := con.Rpc.GetJobs(context.Background(), &clientpb.Empty{})
 	if err != nil {
-		return
+		return nil
 	}
 	if len(jobs.Job) > 0 {
-		printJobs(jobs, con)
+		printJobs(jobs, con, con.OutputFormat(ctx))
 	} else {
 		console.Log.Info("No jobs")
 	}
 
+	return nil
 }
 
-func printJobs(jobs *clientpb.Jobs, con *console.Console) {
+func printJobs(jobs *clientpb.Jobs, con *console.Console, format string) {
 	var rowEntries []table.Row
 	var row table.Row
 	// TODO tui : 添加更多字段, 包括protocol, remote addr
@@ -63,6 +65,10 @@ func printJobs(jobs *clientpb.Jobs, con *console.Console) {
 		rowEntries = append(rowEntries, row)
 	}
 	tableModel.Rows = rowEntries
+	if format != utils.OutputText {
+		con.PrintTable(format, tableModel)
+		return
+	}
 	newTable := tui.NewModel(tableModel, nil, false, false)
 	err := newTable.Run()
 	if err != nil {
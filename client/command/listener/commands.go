@@ -28,11 +28,16 @@ func Commands(con *console.Console) []*grumble.Command {
 	//		f.StringL("web-path", "", "path to the website")
 	//		f.String("", "content-type", "", "content type")
 	//		f.IntL("port", 0, "website pipeline port")
+	//		f.StringL("host", "", "website virtual host, empty for default")
 	//		f.StringL("name", "", "website name")
 	//		f.StringL("content-path", "", "path to the content file")
 	//		f.StringL("listener_id", "", "listener id")
 	//		f.StringL("cert_path", "", "website tls cert path")
 	//		f.StringL("key_path", "", "website tls key path")
+	//		f.StringSliceL("acme", []string{}, "issue tls certificate of domains by acme")
+	//		f.StringL("acme_email", "", "acme account email")
+	//		f.StringL("acme_directory", "", "acme directory url, empty for Let's Encrypt")
+	//		f.IntL("acme_http_port", 0, "port serving acme HTTP-01 challenge, 0 for 80")
 	//		f.Bool("", "recursive", false, "add content recursively")
 	//	},
 	//	Run: func(ctx *grumble.Context) error {
@@ -57,9 +62,9 @@ func Commands(con *console.Console) []*grumble.Command {
 		Name:     "listener",
 		Help:     "List listeners in server",
 		LongHelp: help.GetHelpFor("listener"),
+		Flags:    console.OutputFlags,
 		Run: func(ctx *grumble.Context) error {
-			ListenerCmd(ctx, con)
-			return nil
+			return ListenerCmd(ctx, con)
 		},
 		HelpGroup: consts.ListenerGroup,
 	}
@@ -71,9 +76,9 @@ func Commands(con *console.Console) []*grumble.Command {
 		Args: func(a *grumble.Args) {
 			a.String("listener_id", "listener id")
 		},
+		Flags: console.OutputFlags,
 		Run: func(ctx *grumble.Context) error {
-			listTcpPipelines(ctx, con)
-			return nil
+			return listTcpPipelines(ctx, con)
 		},
 		HelpGroup: consts.ListenerGroup,
 	}
@@ -89,10 +94,13 @@ func Commands(con *console.Console) []*grumble.Command {
 			f.StringL("listener_id", "", "listener id")
 			f.StringL("cert_path", "", "tcp pipeline tls cert path")
 			f.StringL("key_path", "", "tcp pipeline tls key path")
+			f.StringSliceL("acme", []string{}, "issue tls certificate of domains by acme")
+			f.StringL("acme_email", "", "acme account email")
+			f.StringL("acme_directory", "", "acme directory url, empty for Let's Encrypt")
+			f.IntL("acme_http_port", 0, "port serving acme HTTP-01 challenge, 0 for 80")
 		},
 		Run: func(ctx *grumble.Context) error {
-			startTcpPipelineCmd(ctx, con)
-			return nil
+			return startTcpPipelineCmd(ctx, con)
 		},
 	})
 
@@ -105,8 +113,7 @@ func Commands(con *console.Console) []*grumble.Command {
 			a.String("listener_id", "listener id")
 		},
 		Run: func(ctx *grumble.Context) error {
-			stopTcpPipelineCmd(ctx, con)
-			return nil
+			return stopTcpPipelineCmd(ctx, con)
 		},
 	})
 	return []*grumble.Command{listenerCmd, tcpCmd}
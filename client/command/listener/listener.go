@@ -2,24 +2,26 @@ package listener
 
 import (
 	"context"
+	"fmt"
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/console"
+	"github.com/chainreactors/malice-network/client/utils"
 	"github.com/chainreactors/malice-network/proto/client/clientpb"
 	"github.com/chainreactors/tui"
 	"github.com/charmbracelet/bubbles/table"
 	"strconv"
 )
 
-func ListenerCmd(ctx *grumble.Context, con *console.Console) {
+func ListenerCmd(ctx *grumble.Context, con *console.Console) error {
 	listeners, err := con.Rpc.GetListeners(context.Background(), &clientpb.Empty{})
 	if err != nil {
-		console.Log.Errorf("Failed to list listeners: %s", err)
-		return
+		return fmt.Errorf("Failed to list listeners: %s", err)
 	}
-	printListeners(listeners)
+	printListeners(listeners, con, con.OutputFormat(ctx))
+	return nil
 }
 
-func printListeners(listeners *clientpb.Listeners) {
+func printListeners(listeners *clientpb.Listeners, con *console.Console, format string) {
 	var rowEntries []table.Row
 	var row table.Row
 	tableModel := tui.NewTable([]table.Column{
@@ -36,6 +38,10 @@ func printListeners(listeners *clientpb.Listeners) {
 	}
 	tableModel.SetRows(rowEntries)
 	tableModel.Title = "listeners"
+	if format != utils.OutputText {
+		con.PrintTable(format, tableModel)
+		return
+	}
 	newTable := tui.NewModel(tableModel, nil, false, false)
 	err := newTable.Run()
 	if err != nil {
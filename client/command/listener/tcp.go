@@ -2,20 +2,23 @@ package listener
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/helper/cryptography"
 	"github.com/chainreactors/malice-network/proto/listener/lispb"
 	"github.com/chainreactors/tui"
 	"github.com/charmbracelet/bubbles/table"
-	"os"
 	"strconv"
 )
 
-func startTcpPipelineCmd(ctx *grumble.Context, con *console.Console) {
+func startTcpPipelineCmd(ctx *grumble.Context, con *console.Console) error {
 	certPath := ctx.Flags.String("cert_path")
 	keyPath := ctx.Flags.String("key_path")
+	acmeDomains := ctx.Flags.StringSlice("acme")
+	acmeEmail := ctx.Flags.String("acme_email")
+	acmeDirectory := ctx.Flags.String("acme_directory")
+	acmeHTTPPort := uint32(ctx.Flags.Int("acme_http_port"))
 	host := ctx.Flags.String("host")
 	port := uint32(ctx.Flags.Int("port"))
 	name := ctx.Flags.String("name")
@@ -25,19 +28,23 @@ func startTcpPipelineCmd(ctx *grumble.Context, con *console.Console) {
 	if certPath != "" && keyPath != "" {
 		cert, err = cryptography.ProcessPEM(certPath)
 		if err != nil {
-			console.Log.Error(err.Error())
-			return
+			return err
 		}
 		key, err = cryptography.ProcessPEM(keyPath)
 		if err != nil {
-			console.Log.Error(err.Error())
-			return
+			return err
 		}
 	}
 	_, err = con.Rpc.StartTcpPipeline(context.Background(), &lispb.Pipeline{
 		Tls: &lispb.TLS{
-			Cert: cert,
-			Key:  key,
+			Cert:    cert,
+			Key:     key,
+			Acme:    len(acmeDomains) > 0,
+			Domains: acmeDomains,
+			Email:   acmeEmail,
+
+			AcmeDirectory: acmeDirectory,
+			AcmeHttpPort:  acmeHTTPPort,
 		},
 		Body: &lispb.Pipeline_Tcp{
 			Tcp: &lispb.TCPPipeline{
@@ -52,9 +59,10 @@ func startTcpPipelineCmd(ctx *grumble.Context, con *console.Console) {
 	if err != nil {
 		console.Log.Error(err.Error())
 	}
+	return nil
 }
 
-func stopTcpPipelineCmd(ctx *grumble.Context, con *console.Console) {
+func stopTcpPipelineCmd(ctx *grumble.Context, con *console.Console) error {
 	name := ctx.Args.String("name")
 	listenerID := ctx.Args.String("listener_id")
 	_, err := con.Rpc.StopTcpPipeline(context.Background(), &lispb.TCPPipeline{
@@ -64,20 +72,19 @@ func stopTcpPipelineCmd(ctx *grumble.Context, con *console.Console) {
 	if err != nil {
 		console.Log.Error(err.Error())
 	}
+	return nil
 }
 
-func listTcpPipelines(ctx *grumble.Context, con *console.Console) {
+func listTcpPipelines(ctx *grumble.Context, con *console.Console) error {
 	listenerID := ctx.Args.String("listener_id")
 	if listenerID == "" {
-		console.Log.Error("listener_id is required")
-		return
+		return errors.New("listener_id is required")
 	}
 	Pipelines, err := con.Rpc.ListPipelines(context.Background(), &lispb.ListenerName{
 		Name: listenerID,
 	})
 	if err != nil {
-		console.Log.Error(err.Error())
-		return
+		return err
 	}
 	var rowEntries []table.Row
 	var row table.Row
@@ -96,5 +103,6 @@ func listTcpPipelines(ctx *grumble.Context, con *console.Console) {
 		rowEntries = append(rowEntries, row)
 	}
 	tableModel.SetRows(rowEntries)
-	fmt.Printf(tableModel.View(), os.Stdout)
+	con.PrintTable(con.OutputFormat(ctx), tableModel)
+	return nil
 }
@@ -2,7 +2,6 @@ package listener
 
 import (
 	"context"
-	"fmt"
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/command/website"
 	"github.com/chainreactors/malice-network/client/console"
@@ -18,8 +17,13 @@ import (
 func startWebsiteCmd(ctx *grumble.Context, con *console.Console) {
 	certPath := ctx.Flags.String("cert_path")
 	keyPath := ctx.Flags.String("key_path")
+	acmeDomains := ctx.Flags.StringSlice("acme")
+	acmeEmail := ctx.Flags.String("acme_email")
+	acmeDirectory := ctx.Flags.String("acme_directory")
+	acmeHTTPPort := uint32(ctx.Flags.Int("acme_http_port"))
 	webPath := ctx.Flags.String("web-path")
 	port := uint32(ctx.Flags.Int("port"))
+	host := ctx.Flags.String("host")
 	name := ctx.Flags.String("name")
 	listenerID := ctx.Flags.String("listener_id")
 	cPath := ctx.Flags.String("content-path")
@@ -56,13 +60,20 @@ func startWebsiteCmd(ctx *grumble.Context, con *console.Console) {
 	}
 	_, err = con.Rpc.StartWebsite(context.Background(), &lispb.Pipeline{
 		Tls: &lispb.TLS{
-			Cert: cert,
-			Key:  key,
+			Cert:    cert,
+			Key:     key,
+			Acme:    len(acmeDomains) > 0,
+			Domains: acmeDomains,
+			Email:   acmeEmail,
+
+			AcmeDirectory: acmeDirectory,
+			AcmeHttpPort:  acmeHTTPPort,
 		},
 		Body: &lispb.Pipeline_Web{
 			Web: &lispb.Website{
 				RootPath:   webPath,
 				Port:       port,
+				Host:       host,
 				Name:       name,
 				ListenerId: listenerID,
 				Contents:   addWeb.Contents,
@@ -120,5 +131,5 @@ func listWebsitesCmd(ctx *grumble.Context, con *console.Console) {
 		rowEntries = append(rowEntries, row)
 	}
 	tableModel.SetRows(rowEntries)
-	fmt.Printf(tableModel.View(), os.Stdout)
+	con.PrintTable(con.OutputFormat(ctx), tableModel)
 }
@@ -2,11 +2,13 @@ package login
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/assets"
 	"github.com/chainreactors/malice-network/client/command/help"
 	"github.com/chainreactors/malice-network/client/console"
+	"github.com/chainreactors/malice-network/helper/consts"
 	"github.com/chainreactors/malice-network/helper/mtls"
 	"github.com/chainreactors/malice-network/proto/client/clientpb"
 	"github.com/chainreactors/tui"
@@ -66,15 +68,16 @@ func loginServer(ctx *grumble.Context, con *console.Console, selectedFile string
 		return err
 	}
 
-	err = con.Login(config)
+	err = con.Login(console.ServerName(selectedFile), config)
 	if err != nil {
 		con.App.Println("Error login:", err)
 		return err
 	}
 	req := &clientpb.LoginReq{
-		Name: config.Operator,
-		Host: config.LHost,
-		Port: uint32(config.LPort),
+		Name:          config.Operator,
+		Host:          config.LHost,
+		Port:          uint32(config.LPort),
+		ClientVersion: consts.Version,
 	}
 	res, err := con.Rpc.LoginClient(context.Background(), req)
 	if err != nil {
@@ -83,7 +86,8 @@ func loginServer(ctx *grumble.Context, con *console.Console, selectedFile string
 	}
 	if res.Success != true {
 		con.App.Println("Error login server")
-		return err
+		return errors.New("login rejected by server")
 	}
+	con.RecoverCallbacks()
 	return nil
 }
@@ -14,9 +14,9 @@ func Commands(con *console.Console) []*grumble.Command {
 			Name:     consts.ModuleListModule,
 			Help:     "list modules",
 			LongHelp: help.GetHelpFor(consts.ModuleListModule),
+			Flags:    console.OutputFlags,
 			Run: func(ctx *grumble.Context) error {
-				listModules(ctx, con)
-				return nil
+				return listModules(ctx, con)
 			},
 			HelpGroup: consts.ImplantGroup,
 		},
@@ -31,8 +31,7 @@ func Commands(con *console.Console) []*grumble.Command {
 				f.String("n", "name", "", "module name")
 			},
 			Run: func(ctx *grumble.Context) error {
-				loadModule(ctx, con)
-				return nil
+				return loadModule(ctx, con)
 			},
 			HelpGroup: consts.ImplantGroup,
 			Completer: func(prefix string, args []string) []string {
@@ -9,20 +9,19 @@ import (
 	"github.com/chainreactors/tui"
 	"github.com/charmbracelet/bubbles/table"
 	"google.golang.org/protobuf/proto"
-	"os"
 )
 
-func listModules(ctx *grumble.Context, con *console.Console) {
+func listModules(ctx *grumble.Context, con *console.Console) error {
 	session := con.GetInteractive()
 	if session == nil {
-		return
+		return nil
 	}
 	listTask, err := con.Rpc.ListModules(con.ActiveTarget.Context(), &implantpb.Request{Name: consts.ModuleListModule})
 	if err != nil {
-		console.Log.Errorf("ListModules error: %v", err)
-		return
+		return fmt.Errorf("ListModules error: %v", err)
 	}
-	con.AddCallback(listTask.TaskId, func(msg proto.Message) {
+	format := con.OutputFormat(ctx)
+	con.AddCallback(listTask, func(msg proto.Message) {
 		resp := msg.(*implantpb.Spite).GetModules()
 		var rowEntries []table.Row
 		var row table.Row
@@ -38,7 +37,8 @@ func listModules(ctx *grumble.Context, con *console.Console) {
 			rowEntries = append(rowEntries, row)
 		}
 		tableModel.SetRows(rowEntries)
-		fmt.Printf(tableModel.View(), os.Stdout)
+		con.PrintTable(format, tableModel)
 	})
 
+	return nil
 }
@@ -8,29 +8,36 @@ import (
 	"os"
 )
 
-func loadModule(ctx *grumble.Context, con *console.Console) {
+func loadModule(ctx *grumble.Context, con *console.Console) error {
 	session := con.GetInteractive()
 	if session == nil {
-		return
+		return nil
 	}
-	sid := con.GetInteractive().SessionId
 	bundle := ctx.Flags.String("name")
 	path := ctx.Args.String("path")
+	if err := LoadModule(bundle, path, con); err != nil {
+		console.Log.Errorf("LoadModule error: %v", err)
+	}
+	return nil
+}
+
+// LoadModule - load module bundle from local file into the interactive session
+func LoadModule(bundle string, path string, con *console.Console) error {
+	sid := con.GetInteractive().SessionId
 	data, err := os.ReadFile(path)
 	if err != nil {
-		console.Log.Errorf("Error reading file: %v", err)
-		return
+		return err
 	}
 	loadTask, err := con.Rpc.LoadModule(con.ActiveTarget.Context(), &implantpb.LoadModule{
 		Bundle: bundle,
 		Bin:    data,
 	})
 	if err != nil {
-		console.Log.Errorf("LoadModule error: %v", err)
-		return
+		return err
 	}
-	con.AddCallback(loadTask.TaskId, func(msg proto.Message) {
+	con.AddCallback(loadTask, func(msg proto.Message) {
 		//modules := msg.(*implantpb.Spite).GetModules()
 		con.SessionLog(sid).Infof("LoadModule: success")
 	})
+	return nil
 }
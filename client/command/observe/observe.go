@@ -5,7 +5,6 @@ import (
 	"github.com/chainreactors/malice-network/client/command/completer"
 	"github.com/chainreactors/malice-network/client/command/help"
 	"github.com/chainreactors/malice-network/client/console"
-	"github.com/chainreactors/malice-network/proto/client/clientpb"
 )
 
 func Command(con *console.Console) []*grumble.Command {
@@ -22,23 +21,21 @@ func Command(con *console.Console) []*grumble.Command {
 				f.Bool("l", "list", false, "list all observers")
 			},
 			Run: func(ctx *grumble.Context) error {
-				ObserveCmd(ctx, con)
-				return nil
+				return ObserveCmd(ctx, con)
 			},
 			Completer: func(prefix string, args []string) []string {
-				return completer.SessionIDCompleter(con, prefix)
+				return completer.ActiveSessionIDCompleter(con, prefix)
 			},
 		},
 	}
 }
 
-func ObserveCmd(ctx *grumble.Context, con *console.Console) {
-	var session *clientpb.Session
+func ObserveCmd(ctx *grumble.Context, con *console.Console) error {
 	if ctx.Flags.Bool("list") {
 		for i, ob := range con.Observers {
 			console.Log.Infof("%d: %s", i, ob.SessionId())
 		}
-		return
+		return nil
 	}
 
 	idArg := ctx.Args.StringList("sid")
@@ -49,14 +46,18 @@ func ObserveCmd(ctx *grumble.Context, con *console.Console) {
 			for i, ob := range con.Observers {
 				console.Log.Infof("%d: %s", i, ob.SessionId())
 			}
-			return
+			return nil
 		}
 	}
 	for _, sid := range idArg {
-		session = con.Sessions[sid]
-
+		// observers belong to the active server
+		server, session := con.FindSession(sid)
 		if session == nil {
 			console.Log.Warn(console.ErrNotFoundSession.Error())
+			continue
+		} else if server != con.ServerStatus {
+			console.Log.Warnf("%s belongs to server %s, `server use %s` first", sid, server.Name, server.Name)
+			continue
 		}
 
 		if ctx.Flags.Bool("remove") {
@@ -65,4 +66,5 @@ func ObserveCmd(ctx *grumble.Context, con *console.Console) {
 			con.AddObserver(session)
 		}
 	}
+	return nil
 }
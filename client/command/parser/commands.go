@@ -0,0 +1,48 @@
+package parser
+
+import (
+	"github.com/chainreactors/grumble"
+	"github.com/chainreactors/malice-network/client/command/completer"
+	"github.com/chainreactors/malice-network/client/command/help"
+	"github.com/chainreactors/malice-network/client/console"
+)
+
+func Commands(con *console.Console) []*grumble.Command {
+	parserCmd := &grumble.Command{
+		Name:     "parser",
+		Help:     "List output parsers of extensions and aliases",
+		LongHelp: help.GetHelpFor("parser"),
+		Flags:    console.OutputFlags,
+		Run: func(ctx *grumble.Context) error {
+			return ListParsersCmd(ctx, con)
+		},
+	}
+
+	parserCmd.AddCommand(&grumble.Command{
+		Name: "add",
+		Help: "Parse output of an extension or alias with a wasm module",
+		Args: func(a *grumble.Args) {
+			a.String("name", "extension or alias command name")
+			a.String("path", "wasm module path")
+		},
+		Run: func(ctx *grumble.Context) error {
+			return AddParserCmd(ctx, con)
+		},
+		Completer: func(prefix string, args []string) []string {
+			return completer.LocalPathCompleter(prefix, args, con)
+		},
+	})
+
+	parserCmd.AddCommand(&grumble.Command{
+		Name: "rm",
+		Help: "Remove the parser of an extension or alias",
+		Args: func(a *grumble.Args) {
+			a.String("name", "extension or alias command name")
+		},
+		Run: func(ctx *grumble.Context) error {
+			return RemoveParserCmd(ctx, con)
+		},
+	})
+
+	return []*grumble.Command{parserCmd}
+}
@@ -0,0 +1,132 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"github.com/chainreactors/grumble"
+	"github.com/chainreactors/malice-network/client/command/artifact"
+	"github.com/chainreactors/malice-network/client/console"
+	"github.com/chainreactors/malice-network/helper/helper"
+	"github.com/chainreactors/malice-network/proto/client/clientpb"
+	"github.com/chainreactors/tui"
+	"github.com/charmbracelet/bubbles/table"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// registered - command name -> wasm hash registered to the server by this client
+var registered = &sync.Map{}
+
+func ListParsersCmd(ctx *grumble.Context, con *console.Console) error {
+	parsers, err := con.Rpc.ListParsers(context.Background(), &clientpb.Empty{})
+	if err != nil {
+		return fmt.Errorf("Error listing parsers: %v", err)
+	}
+	if len(parsers.Parsers) == 0 {
+		console.Log.Info("No parsers")
+		return nil
+	}
+	var rowEntries []table.Row
+	tableModel := tui.NewTable([]table.Column{
+		{Title: "Name", Width: 20},
+		{Title: "Artifact", Width: 16},
+		{Title: "Operator", Width: 10},
+		{Title: "Created", Width: 16},
+	}, true)
+	for _, p := range parsers.Parsers {
+		rowEntries = append(rowEntries, table.Row{
+			p.Name,
+			p.Artifact[:16],
+			p.Callby,
+			time.Unix(p.CreatedAt, 0).Format("2006-01-02 15:04"),
+		})
+	}
+	tableModel.SetRows(rowEntries)
+	con.PrintTable(con.OutputFormat(ctx), tableModel)
+	return nil
+}
+
+func AddParserCmd(ctx *grumble.Context, con *console.Console) error {
+	name := ctx.Args.String("name")
+	if err := Register(con, name, ctx.Args.String("path")); err != nil {
+		return fmt.Errorf("Error adding parser: %v", err)
+	}
+	console.Log.Infof("Output of %s will be parsed\n", name)
+	return nil
+}
+
+func RemoveParserCmd(ctx *grumble.Context, con *console.Console) error {
+	name := ctx.Args.String("name")
+	_, err := con.Rpc.RemoveParser(context.Background(), &clientpb.Parser{Name: name})
+	if err != nil {
+		return fmt.Errorf("Error removing parser: %v", err)
+	}
+	registered.Delete(name)
+	console.Log.Infof("Parser of %s removed\n", name)
+	return nil
+}
+
+// Register - upload the wasm parser declared in manifest of name, skipped if this client already registered the same module
+func Register(con *console.Console, name string, path string) error {
+	wasm, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	hash := helper.SHA256Checksum(wasm)
+	if registeredHash, ok := registered.Load(name); ok && registeredHash == hash {
+		return nil
+	}
+	hash, err = artifact.Ensure(con, name+".wasm", "parser", wasm)
+	if err != nil {
+		return err
+	}
+	_, err = con.Rpc.RegisterParser(context.Background(), &clientpb.Parser{
+		Name:     name,
+		Artifact: hash,
+	})
+	if err != nil {
+		return err
+	}
+	registered.Store(name, hash)
+	return nil
+}
+
+// PrintResult - print records parsed from output of task as tables, false if the task has no parsed result
+func PrintResult(con *console.Console, sessionID string, taskID uint32) bool {
+	result, err := con.Rpc.GetTaskResult(context.Background(), &clientpb.Task{
+		SessionId: sessionID,
+		TaskId:    taskID,
+	})
+	if err != nil || len(result.Tables) == 0 {
+		return false
+	}
+	for _, t := range result.Tables {
+		var columns []table.Column
+		for i, c := range t.Columns {
+			columns = append(columns, table.Column{Title: c, Width: columnWidth(i, c, t.Rows)})
+		}
+		var rowEntries []table.Row
+		for _, row := range t.Rows {
+			cells := make(table.Row, len(columns))
+			copy(cells, row.Cells)
+			rowEntries = append(rowEntries, cells)
+		}
+		tableModel := tui.NewTable(columns, true)
+		tableModel.SetRows(rowEntries)
+		con.SessionLog(sessionID).Infof("%s %s:\n%s", result.Parser, t.Name, tableModel.View())
+	}
+	return true
+}
+
+// columnWidth - width of the longest cell in column i, at most 40
+func columnWidth(i int, title string, rows []*clientpb.ResultRow) int {
+	width := len(title)
+	for _, row := range rows {
+		if i < len(row.Cells) {
+			width = max(width, len(strings.TrimSpace(row.Cells[i])))
+		}
+	}
+	return min(width, 40)
+}
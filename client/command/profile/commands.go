@@ -0,0 +1,62 @@
+package profile
+
+import (
+	"github.com/chainreactors/grumble"
+	"github.com/chainreactors/malice-network/client/command/help"
+	"github.com/chainreactors/malice-network/client/console"
+)
+
+func Commands(con *console.Console) []*grumble.Command {
+	profileCmd := &grumble.Command{
+		Name:     "profile",
+		Help:     "List profiles loaded when using a session",
+		LongHelp: help.GetHelpFor("profile"),
+		Flags:    console.OutputFlags,
+		Run: func(ctx *grumble.Context) error {
+			return ListProfilesCmd(ctx, con)
+		},
+	}
+
+	profileCmd.AddCommand(&grumble.Command{
+		Name:     "add",
+		Help:     "Add or replace a profile",
+		LongHelp: help.GetHelpFor("profile add"),
+		Args: func(a *grumble.Args) {
+			a.String("name", "profile name")
+		},
+		Flags: func(f *grumble.Flags) {
+			f.StringL("os", "", "match session os, e.g. windows, empty matches any")
+			f.StringL("arch", "", "match session arch, e.g. x64, empty matches any")
+			f.StringSliceL("alias", []string{}, "installed alias name, repeatable")
+			f.StringSliceL("extension", []string{}, "installed extension name, repeatable")
+			f.StringSliceL("module", []string{}, "module as <bundle>=<path>, repeatable")
+		},
+		Run: func(ctx *grumble.Context) error {
+			return AddProfileCmd(ctx, con)
+		},
+	})
+
+	profileCmd.AddCommand(&grumble.Command{
+		Name: "rm",
+		Help: "Remove a profile",
+		Args: func(a *grumble.Args) {
+			a.String("name", "profile name")
+		},
+		Run: func(ctx *grumble.Context) error {
+			return RemoveProfileCmd(ctx, con)
+		},
+	})
+
+	profileCmd.AddCommand(&grumble.Command{
+		Name: "load",
+		Help: "Load a profile into the interactive session",
+		Args: func(a *grumble.Args) {
+			a.String("name", "profile name")
+		},
+		Run: func(ctx *grumble.Context) error {
+			return LoadProfileCmd(ctx, con)
+		},
+	})
+
+	return []*grumble.Command{profileCmd}
+}
@@ -0,0 +1,174 @@
+package profile
+
+import (
+	"fmt"
+	"github.com/chainreactors/grumble"
+	"github.com/chainreactors/malice-network/client/assets"
+	"github.com/chainreactors/malice-network/client/command/alias"
+	"github.com/chainreactors/malice-network/client/command/extension"
+	"github.com/chainreactors/malice-network/client/command/modules"
+	"github.com/chainreactors/malice-network/client/console"
+	"github.com/chainreactors/malice-network/proto/client/clientpb"
+	"github.com/chainreactors/tui"
+	"github.com/charmbracelet/bubbles/table"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+func ListProfilesCmd(ctx *grumble.Context, con *console.Console) error {
+	profiles, err := assets.LoadProfiles()
+	if err != nil {
+		return fmt.Errorf("Error loading profiles: %v", err)
+	}
+	if len(profiles.Profiles) == 0 {
+		console.Log.Info("No profiles")
+		return nil
+	}
+	PrintProfiles(profiles.Profiles, con, con.OutputFormat(ctx))
+	return nil
+}
+
+func AddProfileCmd(ctx *grumble.Context, con *console.Console) error {
+	profiles, err := assets.LoadProfiles()
+	if err != nil {
+		return fmt.Errorf("Error loading profiles: %v", err)
+	}
+	profile := &assets.Profile{
+		Name:       ctx.Args.String("name"),
+		Os:         ctx.Flags.String("os"),
+		Arch:       ctx.Flags.String("arch"),
+		Aliases:    ctx.Flags.StringSlice("alias"),
+		Extensions: ctx.Flags.StringSlice("extension"),
+	}
+	for _, module := range ctx.Flags.StringSlice("module") {
+		bundle, path, ok := strings.Cut(module, "=")
+		if !ok || bundle == "" || path == "" {
+			return fmt.Errorf("Invalid module %s, require <bundle>=<path>", module)
+		}
+		path, err = filepath.Abs(path)
+		if err != nil {
+			return fmt.Errorf("Invalid module path: %v", err)
+		}
+		profile.Modules = append(profile.Modules, &assets.ProfileModule{Bundle: bundle, Path: path})
+	}
+	profiles.Set(profile)
+	if err = assets.SaveProfiles(profiles); err != nil {
+		return fmt.Errorf("Error saving profiles: %v", err)
+	}
+	console.Log.Infof("Profile %s saved\n", profile.Name)
+	return nil
+}
+
+func RemoveProfileCmd(ctx *grumble.Context, con *console.Console) error {
+	name := ctx.Args.String("name")
+	profiles, err := assets.LoadProfiles()
+	if err != nil {
+		return fmt.Errorf("Error loading profiles: %v", err)
+	}
+	if err = profiles.Remove(name); err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	if err = assets.SaveProfiles(profiles); err != nil {
+		return fmt.Errorf("Error saving profiles: %v", err)
+	}
+	console.Log.Infof("Profile %s removed\n", name)
+	return nil
+}
+
+func LoadProfileCmd(ctx *grumble.Context, con *console.Console) error {
+	if con.GetInteractive() == nil {
+		return console.ErrNotFoundSession
+	}
+	name := ctx.Args.String("name")
+	profiles, err := assets.LoadProfiles()
+	if err != nil {
+		return fmt.Errorf("Error loading profiles: %v", err)
+	}
+	profile, err := profiles.Get(name)
+	if err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	LoadProfile(profile, con)
+	return nil
+}
+
+// LoadSessionProfiles - load every profile matched by the os and arch of session, called after `use`
+func LoadSessionProfiles(session *clientpb.Session, con *console.Console) {
+	profiles, err := assets.LoadProfiles()
+	if err != nil {
+		console.Log.Errorf("Error loading profiles: %v", err)
+		return
+	}
+	for _, profile := range profiles.Match(session.GetOs().GetName(), session.GetOs().GetArch()) {
+		LoadProfile(profile, con)
+	}
+}
+
+// LoadProfile - register aliases and extensions of profile, and load the extensions and modules
+// the interactive session lacks. A failed item is logged and skipped.
+func LoadProfile(profile *assets.Profile, con *console.Console) {
+	for _, name := range profile.Aliases {
+		if alias.AliasLoaded(name) {
+			continue
+		}
+		if _, err := alias.LoadAlias(name, con); err != nil {
+			console.Log.Errorf("Profile %s: failed to load alias %s: %v", profile.Name, name, err)
+		}
+	}
+	for _, name := range profile.Extensions {
+		if err := extension.LoadSessionExtension(name, con); err != nil {
+			console.Log.Errorf("Profile %s: failed to load extension %s: %v", profile.Name, name, err)
+		}
+	}
+	if con.GetInteractive() == nil {
+		return
+	}
+	// modules loaded since use or by the extensions above are only known after refresh
+	con.RefreshActiveSession()
+	session := con.GetInteractive()
+	for _, module := range profile.Modules {
+		if slices.Contains(session.Modules, module.Bundle) {
+			continue
+		}
+		if err := modules.LoadModule(module.Bundle, module.Path, con); err != nil {
+			console.Log.Errorf("Profile %s: failed to load module %s: %v", profile.Name, module.Bundle, err)
+		}
+	}
+	console.Log.Infof("Profile %s loaded\n", profile.Name)
+}
+
+func PrintProfiles(profiles []*assets.Profile, con *console.Console, format string) {
+	var rowEntries []table.Row
+	tableModel := tui.NewTable([]table.Column{
+		{Title: "Name", Width: 12},
+		{Title: "Os", Width: 8},
+		{Title: "Arch", Width: 6},
+		{Title: "Aliases", Width: 20},
+		{Title: "Extensions", Width: 20},
+		{Title: "Modules", Width: 20},
+	}, true)
+	for _, profile := range profiles {
+		bundles := make([]string, 0, len(profile.Modules))
+		for _, module := range profile.Modules {
+			bundles = append(bundles, module.Bundle)
+		}
+		rowEntries = append(rowEntries, table.Row{
+			profile.Name,
+			orAny(profile.Os),
+			orAny(profile.Arch),
+			strings.Join(profile.Aliases, ","),
+			strings.Join(profile.Extensions, ","),
+			strings.Join(bundles, ","),
+		})
+	}
+	tableModel.SetRows(rowEntries)
+	con.PrintTable(format, tableModel)
+}
+
+func orAny(s string) string {
+	if s == "" {
+		return "*"
+	}
+	return s
+}
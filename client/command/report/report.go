@@ -0,0 +1,84 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"github.com/chainreactors/grumble"
+	"github.com/chainreactors/malice-network/client/command/help"
+	"github.com/chainreactors/malice-network/client/console"
+	"github.com/chainreactors/malice-network/proto/client/clientpb"
+	"os"
+	"strings"
+)
+
+var formatExt = map[string]string{
+	"markdown": "md",
+	"html":     "html",
+	"json":     "json",
+}
+
+func Command(con *console.Console) []*grumble.Command {
+	return []*grumble.Command{
+		&grumble.Command{
+			Name:     "report",
+			Help:     "Generate engagement report",
+			LongHelp: help.GetHelpFor("report"),
+			Flags: func(f *grumble.Flags) {
+				f.String("n", "name", "", "report name")
+				f.String("f", "format", "markdown", "report format, markdown/html/json")
+				f.String("t", "template", "", "custom go template file")
+				f.String("o", "output", "", "output file, default is <name>.<format>")
+				f.String("s", "sessions", "", "only report these sessions, comma separated")
+				f.Bool("p", "print", false, "print report instead of saving to file")
+			},
+			Run: func(ctx *grumble.Context) error {
+				return ReportCmd(ctx, con)
+			},
+		},
+	}
+}
+
+func ReportCmd(ctx *grumble.Context, con *console.Console) error {
+	format := strings.ToLower(ctx.Flags.String("format"))
+	ext, ok := formatExt[format]
+	if !ok {
+		return fmt.Errorf("Unknown report format %s, must be markdown, html or json", format)
+	}
+	var tmpl string
+	if path := ctx.Flags.String("template"); path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("Error reading template: %v", err)
+		}
+		tmpl = string(content)
+	}
+	var sessions []string
+	if s := ctx.Flags.String("sessions"); s != "" {
+		sessions = strings.Split(s, ",")
+	}
+
+	report, err := con.Rpc.GenerateReport(context.Background(), &clientpb.ReportRequest{
+		Name:       ctx.Flags.String("name"),
+		Format:     format,
+		Template:   tmpl,
+		SessionIds: sessions,
+	})
+	if err != nil {
+		return fmt.Errorf("Error generating report: %v", err)
+	}
+	if ctx.Flags.Bool("print") {
+		fmt.Println(string(report.Content))
+		return nil
+	}
+
+	output := ctx.Flags.String("output")
+	if output == "" {
+		output = fmt.Sprintf("%s.%s", report.Name, ext)
+	}
+	err = os.WriteFile(output, report.Content, 0600)
+	if err != nil {
+		return fmt.Errorf("Error writing report: %v", err)
+	}
+	console.Log.Importantf("Report saved to %s\n", output)
+	return nil
+}
@@ -0,0 +1,77 @@
+package schedule
+
+import (
+	"github.com/chainreactors/grumble"
+	"github.com/chainreactors/malice-network/client/command/help"
+	"github.com/chainreactors/malice-network/client/console"
+)
+
+func Commands(con *console.Console) []*grumble.Command {
+	scheduleCmd := &grumble.Command{
+		Name:     "schedule",
+		Help:     "List scheduled tasks",
+		LongHelp: help.GetHelpFor("schedule"),
+		Flags:    console.OutputFlags,
+		Run: func(ctx *grumble.Context) error {
+			return ListSchedulesCmd(ctx, con)
+		},
+	}
+
+	scheduleCmd.AddCommand(&grumble.Command{
+		Name:     "add",
+		Help:     "Schedule a module to run by cron or once at a time",
+		LongHelp: help.GetHelpFor("schedule add"),
+		Args: func(a *grumble.Args) {
+			a.String("module", "module name, e.g. whoami, ps")
+			a.StringList("args", "module arguments", grumble.Default([]string{}))
+		},
+		Flags: func(f *grumble.Flags) {
+			f.StringL("cron", "", "cron expression, e.g. \"0 * * * *\"")
+			f.StringL("every", "", "run every duration, e.g. 30m")
+			f.StringL("at", "", "run once at time, \"2006-01-02 15:04\" or \"15:04\"")
+			f.StringL("session", "", "target session id, default the interactive session")
+			f.StringL("filter", "", "target sessions matched by saved filter")
+			f.StringL("name", "", "schedule name")
+		},
+		Run: func(ctx *grumble.Context) error {
+			return AddScheduleCmd(ctx, con)
+		},
+	})
+
+	scheduleCmd.AddCommand(&grumble.Command{
+		Name: "pause",
+		Help: "Pause a schedule",
+		Args: func(a *grumble.Args) {
+			a.Uint("id", "schedule id")
+		},
+		Run: func(ctx *grumble.Context) error {
+			PauseScheduleCmd(ctx, con, true)
+			return nil
+		},
+	})
+
+	scheduleCmd.AddCommand(&grumble.Command{
+		Name: "resume",
+		Help: "Resume a paused schedule",
+		Args: func(a *grumble.Args) {
+			a.Uint("id", "schedule id")
+		},
+		Run: func(ctx *grumble.Context) error {
+			PauseScheduleCmd(ctx, con, false)
+			return nil
+		},
+	})
+
+	scheduleCmd.AddCommand(&grumble.Command{
+		Name: "rm",
+		Help: "Remove a schedule",
+		Args: func(a *grumble.Args) {
+			a.Uint("id", "schedule id")
+		},
+		Run: func(ctx *grumble.Context) error {
+			return RemoveScheduleCmd(ctx, con)
+		},
+	})
+
+	return []*grumble.Command{scheduleCmd}
+}
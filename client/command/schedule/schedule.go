@@ -0,0 +1,168 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/chainreactors/grumble"
+	"github.com/chainreactors/malice-network/client/command/broadcast"
+	"github.com/chainreactors/malice-network/client/console"
+	"github.com/chainreactors/malice-network/proto/client/clientpb"
+	"github.com/chainreactors/tui"
+	"github.com/charmbracelet/bubbles/table"
+	"strconv"
+	"time"
+)
+
+func ListSchedulesCmd(ctx *grumble.Context, con *console.Console) error {
+	schedules, err := con.Rpc.ListSchedules(context.Background(), &clientpb.Empty{})
+	if err != nil {
+		return fmt.Errorf("Error listing schedules: %v", err)
+	}
+	if len(schedules.Schedules) == 0 {
+		console.Log.Info("No schedules")
+		return nil
+	}
+	PrintSchedules(schedules.Schedules, con, con.OutputFormat(ctx))
+	return nil
+}
+
+func AddScheduleCmd(ctx *grumble.Context, con *console.Console) error {
+	module := ctx.Args.String("module")
+	req := &clientpb.Schedule{
+		Name:    ctx.Flags.String("name"),
+		Filter:  ctx.Flags.String("filter"),
+		Request: broadcast.NewRequest(module, ctx.Args.StringList("args")),
+	}
+
+	spec, every, at := ctx.Flags.String("cron"), ctx.Flags.String("every"), ctx.Flags.String("at")
+	switch {
+	case spec != "" && every == "" && at == "":
+		req.Spec = spec
+	case every != "" && spec == "" && at == "":
+		if _, err := time.ParseDuration(every); err != nil {
+			return fmt.Errorf("Invalid duration %s: %v", every, err)
+		}
+		req.Spec = "@every " + every
+	case at != "" && spec == "" && every == "":
+		t, err := parseAt(at, time.Now())
+		if err != nil {
+			return err
+		}
+		req.At = t.Unix()
+	default:
+		return errors.New("Require one of --cron, --every or --at")
+	}
+
+	if req.Filter == "" {
+		req.SessionId = ctx.Flags.String("session")
+		if req.SessionId == "" {
+			if session := con.GetInteractive(); session != nil {
+				req.SessionId = session.SessionId
+			}
+		}
+		if req.SessionId == "" {
+			return errors.New("Require --session or --filter, or use a session")
+		}
+	}
+
+	schedule, err := con.Rpc.AddSchedule(context.Background(), req)
+	if err != nil {
+		return fmt.Errorf("Error adding schedule: %v", err)
+	}
+	console.Log.Infof("Schedule %d added, next run at %s\n", schedule.Id, formatTime(schedule.NextRun))
+	return nil
+}
+
+func PauseScheduleCmd(ctx *grumble.Context, con *console.Console, paused bool) {
+	schedule, err := con.Rpc.PauseSchedule(context.Background(), &clientpb.Schedule{
+		Id:     uint32(ctx.Args.Uint("id")),
+		Paused: paused,
+	})
+	if err != nil {
+		console.Log.Errorf("Error updating schedule: %v", err)
+		return
+	}
+	if paused {
+		console.Log.Infof("Schedule %d paused\n", schedule.Id)
+	} else {
+		console.Log.Infof("Schedule %d resumed, next run at %s\n", schedule.Id, formatTime(schedule.NextRun))
+	}
+}
+
+func RemoveScheduleCmd(ctx *grumble.Context, con *console.Console) error {
+	id := uint32(ctx.Args.Uint("id"))
+	_, err := con.Rpc.RemoveSchedule(context.Background(), &clientpb.Schedule{Id: id})
+	if err != nil {
+		return fmt.Errorf("Error removing schedule: %v", err)
+	}
+	console.Log.Infof("Schedule %d removed\n", id)
+	return nil
+}
+
+func PrintSchedules(schedules []*clientpb.Schedule, con *console.Console, format string) {
+	var rowEntries []table.Row
+	tableModel := tui.NewTable([]table.Column{
+		{Title: "ID", Width: 4},
+		{Title: "Name", Width: 10},
+		{Title: "When", Width: 16},
+		{Title: "Target", Width: 12},
+		{Title: "Module", Width: 15},
+		{Title: "Runs", Width: 4},
+		{Title: "Last", Width: 16},
+		{Title: "Next", Width: 16},
+		{Title: "Status", Width: 7},
+	}, true)
+	for _, schedule := range schedules {
+		when := schedule.Spec
+		if when == "" {
+			when = formatTime(schedule.At)
+		}
+		target := schedule.SessionId
+		if schedule.Filter != "" {
+			target = "filter:" + schedule.Filter
+		} else if len(target) > 8 {
+			target = target[:8]
+		}
+		status := "active"
+		if schedule.Paused {
+			status = "paused"
+		}
+		rowEntries = append(rowEntries, table.Row{
+			strconv.Itoa(int(schedule.Id)),
+			schedule.Name,
+			when,
+			target,
+			schedule.Request.GetName(),
+			strconv.Itoa(int(schedule.Runs)),
+			formatTime(schedule.LastRun),
+			formatTime(schedule.NextRun),
+			status,
+		})
+	}
+	tableModel.SetRows(rowEntries)
+	con.PrintTable(format, tableModel)
+}
+
+// parseAt - "2006-01-02 15:04", or "15:04" for the next occurrence of the clock time
+func parseAt(at string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02 15:04", at, now.Location()); err == nil {
+		return t, nil
+	}
+	clock, err := time.ParseInLocation("15:04", at, now.Location())
+	if err != nil {
+		return time.Time{}, errors.New("invalid time, use \"2006-01-02 15:04\" or \"15:04\"")
+	}
+	t := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+	if !t.After(now) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+func formatTime(unix int64) string {
+	if unix == 0 {
+		return ""
+	}
+	return time.Unix(unix, 0).Format("2006-01-02 15:04")
+}
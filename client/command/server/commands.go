@@ -0,0 +1,35 @@
+package server
+
+import (
+	"github.com/chainreactors/grumble"
+	"github.com/chainreactors/malice-network/client/command/help"
+	"github.com/chainreactors/malice-network/client/console"
+)
+
+func Commands(con *console.Console) []*grumble.Command {
+	serverCmd := &grumble.Command{
+		Name:     "server",
+		Help:     "List connected servers",
+		LongHelp: help.GetHelpFor("server"),
+		Flags:    console.OutputFlags,
+		Run: func(ctx *grumble.Context) error {
+			return ServersCmd(ctx, con)
+		},
+	}
+
+	serverCmd.AddCommand(&grumble.Command{
+		Name:     "use",
+		Help:     "Switch to a connected server",
+		LongHelp: help.GetHelpFor("server use"),
+		Args: func(a *grumble.Args) {
+			a.String("name", "server name")
+		},
+		Run: func(ctx *grumble.Context) error {
+			return UseServerCmd(ctx, con)
+		},
+		Completer: func(prefix string, args []string) []string {
+			return con.ServerNames()
+		},
+	})
+	return []*grumble.Command{serverCmd}
+}
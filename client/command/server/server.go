@@ -0,0 +1,57 @@
+package server
+
+import (
+	"github.com/chainreactors/grumble"
+	"github.com/chainreactors/malice-network/client/console"
+	"github.com/chainreactors/tui"
+	"github.com/charmbracelet/bubbles/table"
+	"strconv"
+)
+
+// ServersCmd - connected servers, the active one is marked by *
+func ServersCmd(ctx *grumble.Context, con *console.Console) error {
+	if len(con.Servers) == 0 {
+		console.Log.Info("No servers connected, use `login`")
+		return nil
+	}
+	var rowEntries []table.Row
+	tableModel := tui.NewTable([]table.Column{
+		{Title: "Name", Width: 15},
+		{Title: "Address", Width: 20},
+		{Title: "Operator", Width: 10},
+		{Title: "Sessions", Width: 8},
+		{Title: "Status", Width: 12},
+		{Title: "Active", Width: 6},
+	}, true)
+	for _, name := range con.ServerNames() {
+		server := con.Servers[name]
+		status := "connected"
+		if !server.Alive.Load() {
+			status = "disconnected"
+		}
+		active := ""
+		if server == con.ServerStatus {
+			active = "*"
+		}
+		rowEntries = append(rowEntries, table.Row{
+			name,
+			server.Addr,
+			server.Operator(),
+			strconv.Itoa(len(server.Sessions())),
+			status,
+			active,
+		})
+	}
+	tableModel.SetRows(rowEntries)
+	con.PrintTable(con.OutputFormat(ctx), tableModel)
+	return nil
+}
+
+func UseServerCmd(ctx *grumble.Context, con *console.Console) error {
+	name := ctx.Args.String("name")
+	if err := con.UseServer(name); err != nil {
+		return err
+	}
+	console.Log.Infof("Switched to server %s (%s)\n", name, con.Addr)
+	return nil
+}
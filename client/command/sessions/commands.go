@@ -23,10 +23,13 @@ func Commands(con *console.Console) []*grumble.Command {
 				//f.String("f", "filter", "", "filter sessions by substring")
 				//f.String("e", "filter-re", "", "filter sessions by regular expression")
 				//f.Int("t", "timeout", assets.DefaultSettings.DefaultTimeout, "command timeout in seconds")
+				f.Bool("a", "all", false, "show all sessions")
+				f.String("f", "filter", "", "list sessions matched by saved filter")
+				filterFlags(f)
+				console.OutputFlags(f)
 			},
 			Run: func(ctx *grumble.Context) error {
-				SessionsCmd(ctx, con)
-				return nil
+				return SessionsCmd(ctx, con)
 			},
 		},
 		{
@@ -40,8 +43,7 @@ func Commands(con *console.Console) []*grumble.Command {
 				f.StringL("id", "", "session id")
 			},
 			Run: func(ctx *grumble.Context) error {
-				noteCmd(ctx, con)
-				return nil
+				return noteCmd(ctx, con)
 			},
 			Completer: func(prefix string, args []string) []string {
 				if len(args) == 0 {
@@ -59,10 +61,10 @@ func Commands(con *console.Console) []*grumble.Command {
 			},
 			Flags: func(f *grumble.Flags) {
 				f.StringL("id", "", "session id")
+				f.String("f", "filter", "", "group all sessions matched by saved filter")
 			},
 			Run: func(ctx *grumble.Context) error {
-				groupCmd(ctx, con)
-				return nil
+				return groupCmd(ctx, con)
 			},
 			Completer: func(prefix string, args []string) []string {
 				if len(args) == 0 {
@@ -71,6 +73,23 @@ func Commands(con *console.Console) []*grumble.Command {
 				return nil
 			},
 		},
+		{
+			Name:     "tag",
+			Help:     "tag sessions",
+			LongHelp: help.GetHelpFor("tag"),
+			Args: func(a *grumble.Args) {
+				a.StringList("tags", "tags")
+			},
+			Flags: func(f *grumble.Flags) {
+				f.StringL("id", "", "session id")
+				f.String("f", "filter", "", "tag all sessions matched by saved filter")
+				f.Bool("r", "remove", false, "remove tags instead of adding")
+			},
+			Run: func(ctx *grumble.Context) error {
+				return tagCmd(ctx, con)
+			},
+		},
+		filterCommand(con),
 		{
 			Name:     "remove",
 			Help:     "remove session",
@@ -79,8 +98,7 @@ func Commands(con *console.Console) []*grumble.Command {
 				f.StringL("id", "", "session id")
 			},
 			Run: func(ctx *grumble.Context) error {
-				removeCmd(ctx, con)
-				return nil
+				return removeCmd(ctx, con)
 			},
 			Completer: func(prefix string, args []string) []string {
 				if len(args) == 0 {
@@ -91,3 +109,39 @@ func Commands(con *console.Console) []*grumble.Command {
 		},
 	}
 }
+
+func filterCommand(con *console.Console) *grumble.Command {
+	filterCmd := &grumble.Command{
+		Name:     "filter",
+		Help:     "list saved session filters",
+		LongHelp: help.GetHelpFor("filter"),
+		Flags:    console.OutputFlags,
+		Run: func(ctx *grumble.Context) error {
+			return listFiltersCmd(ctx, con)
+		},
+	}
+
+	filterCmd.AddCommand(&grumble.Command{
+		Name: "save",
+		Help: "save a session filter",
+		Args: func(a *grumble.Args) {
+			a.String("name", "filter name")
+		},
+		Flags: filterFlags,
+		Run: func(ctx *grumble.Context) error {
+			return saveFilterCmd(ctx, con)
+		},
+	})
+
+	filterCmd.AddCommand(&grumble.Command{
+		Name: "rm",
+		Help: "remove a saved session filter",
+		Args: func(a *grumble.Args) {
+			a.String("name", "filter name")
+		},
+		Run: func(ctx *grumble.Context) error {
+			return removeFilterCmd(ctx, con)
+		},
+	})
+	return filterCmd
+}
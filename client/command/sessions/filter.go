@@ -0,0 +1,160 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/chainreactors/grumble"
+	"github.com/chainreactors/malice-network/client/console"
+	"github.com/chainreactors/malice-network/client/utils"
+	"github.com/chainreactors/malice-network/proto/client/clientpb"
+	"github.com/chainreactors/tui"
+	"github.com/charmbracelet/bubbles/table"
+	"strings"
+)
+
+// filterFlags - flags describing a session filter, shared by sessions and filter save
+func filterFlags(f *grumble.Flags) {
+	f.StringL("os", "", "filter by os, e.g. windows")
+	f.StringL("arch", "", "filter by arch, e.g. x64")
+	f.StringL("hostname", "", "filter by hostname regex")
+	f.StringL("group", "", "filter by group")
+	f.StringL("pipeline", "", "filter by pipeline")
+	f.StringSliceL("tag", []string{}, "filter by tag, sessions must have all tags")
+	f.StringL("liveness", "", "filter by liveness, alive/dead")
+}
+
+// parseFilter - filter from filterFlags, nil if no flag is set
+func parseFilter(ctx *grumble.Context) *clientpb.SessionFilter {
+	filter := &clientpb.SessionFilter{
+		Os:       ctx.Flags.String("os"),
+		Arch:     ctx.Flags.String("arch"),
+		Hostname: ctx.Flags.String("hostname"),
+		Group:    ctx.Flags.String("group"),
+		Pipeline: ctx.Flags.String("pipeline"),
+		Tags:     ctx.Flags.StringSlice("tag"),
+		Liveness: ctx.Flags.String("liveness"),
+	}
+	if filter.Os == "" && filter.Arch == "" && filter.Hostname == "" && filter.Group == "" &&
+		filter.Pipeline == "" && len(filter.Tags) == 0 && filter.Liveness == "" {
+		return nil
+	}
+	return filter
+}
+
+func listFiltersCmd(ctx *grumble.Context, con *console.Console) error {
+	filters, err := con.Rpc.ListSessionFilters(context.Background(), &clientpb.Empty{})
+	if err != nil {
+		return fmt.Errorf("Failed to list filters %s", err)
+	}
+	if len(filters.Filters) == 0 {
+		console.Log.Info("No saved filters")
+		return nil
+	}
+	var rowEntries []table.Row
+	tableModel := tui.NewTable([]table.Column{
+		{Title: "Name", Width: 15},
+		{Title: "OS", Width: 8},
+		{Title: "Arch", Width: 6},
+		{Title: "Hostname", Width: 15},
+		{Title: "Group", Width: 10},
+		{Title: "Pipeline", Width: 10},
+		{Title: "Tags", Width: 15},
+		{Title: "Liveness", Width: 8},
+	}, true)
+	for _, filter := range filters.Filters {
+		rowEntries = append(rowEntries, table.Row{
+			filter.Name,
+			filter.Os,
+			filter.Arch,
+			filter.Hostname,
+			filter.Group,
+			filter.Pipeline,
+			strings.Join(filter.Tags, ","),
+			filter.Liveness,
+		})
+	}
+	tableModel.SetRows(rowEntries)
+	if format := con.OutputFormat(ctx); format != utils.OutputText {
+		con.PrintTable(format, tableModel)
+		return nil
+	}
+	newTable := tui.NewModel(tableModel, nil, false, false)
+	err = newTable.Run()
+	if err != nil {
+		return nil
+	}
+	return nil
+}
+
+func saveFilterCmd(ctx *grumble.Context, con *console.Console) error {
+	name := ctx.Args.String("name")
+	filter := parseFilter(ctx)
+	if name == "" || filter == nil {
+		return errors.New("Require filter name and at least one condition, see --help")
+	}
+	filter.Name = name
+	_, err := con.Rpc.SaveSessionFilter(context.Background(), filter)
+	if err != nil {
+		return fmt.Errorf("Failed to save filter %s", err)
+	}
+	console.Log.Infof("Saved filter %s\n", name)
+	return nil
+}
+
+func removeFilterCmd(ctx *grumble.Context, con *console.Console) error {
+	name := ctx.Args.String("name")
+	_, err := con.Rpc.RemoveSessionFilter(context.Background(), &clientpb.SessionFilter{Name: name})
+	if err != nil {
+		return fmt.Errorf("Failed to remove filter %s", err)
+	}
+	console.Log.Infof("Removed filter %s\n", name)
+	return nil
+}
+
+// updateSessions - apply group or tags to the interactive session, --id or all sessions matched by --filter
+func updateSessions(ctx *grumble.Context, con *console.Console, req *clientpb.SessionsUpdate) {
+	if name := ctx.Flags.String("filter"); name != "" {
+		req.Filter = &clientpb.SessionFilter{Name: name}
+	} else if con.GetInteractive().SessionId != "" {
+		req.SessionIds = []string{con.GetInteractive().SessionId}
+	} else if ctx.Flags.String("id") != "" {
+		req.SessionIds = []string{ctx.Flags.String("id")}
+	} else {
+		console.Log.Errorf("Require session id or filter")
+		return
+	}
+	sessions, err := con.Rpc.UpdateSessions(context.Background(), req)
+	if err != nil {
+		console.Log.Errorf("Session error: %v", err)
+		return
+	}
+	con.SetSessions(sessions.Sessions...)
+	if req.Filter != nil {
+		console.Log.Infof("Updated %d sessions\n", len(sessions.Sessions))
+	} else if len(sessions.Sessions) == 1 {
+		con.ActiveTarget.Set(sessions.Sessions[0])
+	}
+}
+
+func tagCmd(ctx *grumble.Context, con *console.Console) error {
+	tags := ctx.Args.StringList("tags")
+	if len(tags) == 0 {
+		return errors.New("Require at least one tag")
+	}
+	req := &clientpb.SessionsUpdate{}
+	if ctx.Flags.Bool("remove") {
+		req.RemoveTags = tags
+	} else {
+		req.AddTags = tags
+	}
+	updateSessions(ctx, con, req)
+	return nil
+}
+
+func formatTags(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("[%s]", strings.Join(tags, ","))
+}
@@ -1,32 +1,19 @@
 package sessions
 
 import (
-	"context"
+	"errors"
 	"github.com/chainreactors/grumble"
-	"github.com/chainreactors/logs"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/proto/client/clientpb"
 )
 
-func groupCmd(ctx *grumble.Context, con *console.Console) {
+func groupCmd(ctx *grumble.Context, con *console.Console) error {
 	group := ctx.Args.String("group")
-	var id string
-	if con.GetInteractive().SessionId != "" {
-		id = con.GetInteractive().SessionId
-	} else if ctx.Flags.String("id") != "" {
-		id = ctx.Flags.String("id")
-	} else {
-		console.Log.Errorf("Require session id")
-		return
+	if group == "" {
+		return errors.New("Require group name")
 	}
-	_, err := con.Rpc.BasicSessionOP(context.Background(), &clientpb.BasicUpdateSession{
-		SessionId: id,
-		GroupName: group,
+	updateSessions(ctx, con, &clientpb.SessionsUpdate{
+		Group: group,
 	})
-	if err != nil {
-		logs.Log.Errorf("Session error: %v", err)
-		return
-	}
-	session := con.Sessions[id]
-	con.ActiveTarget.Set(session)
+	return nil
 }
@@ -2,31 +2,32 @@ package sessions
 
 import (
 	"context"
+	"fmt"
 	"github.com/chainreactors/grumble"
-	"github.com/chainreactors/logs"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/proto/client/clientpb"
 )
 
-func noteCmd(ctx *grumble.Context, con *console.Console) {
+func noteCmd(ctx *grumble.Context, con *console.Console) error {
 	name := ctx.Args.String("name")
-	var id string
-	if con.GetInteractive().SessionId != "" {
-		id = con.GetInteractive().SessionId
-	} else if ctx.Flags.String("id") != "" {
-		id = ctx.Flags.String("id")
-	} else {
-		console.Log.Errorf("Require session id")
-		return
+	server, session, err := targetSession(ctx, con)
+	if err != nil {
+		return err
 	}
-	_, err := con.Rpc.BasicSessionOP(context.Background(), &clientpb.BasicUpdateSession{
-		SessionId: id,
+	_, err = server.Rpc.BasicSessionOP(context.Background(), &clientpb.BasicUpdateSession{
+		SessionId: session.SessionId,
 		Note:      name,
 	})
 	if err != nil {
-		logs.Log.Errorf("Session error: %v", err)
-		return
+		return fmt.Errorf("Session error: %v", err)
+	}
+	if err = server.UpdateSession(session.SessionId); err == nil {
+		session = server.Sessions()[session.SessionId]
+	}
+	// session of another server, `note --id server/session_id`
+	if server != con.ServerStatus {
+		con.UseServer(server.Name)
 	}
-	session := con.Sessions[id]
 	con.ActiveTarget.Set(session)
+	return nil
 }
@@ -2,31 +2,44 @@ package sessions
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/chainreactors/grumble"
-	"github.com/chainreactors/logs"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/proto/client/clientpb"
 )
 
-func removeCmd(ctx *grumble.Context, con *console.Console) {
-	var id string
-	if con.GetInteractive().SessionId != "" {
-		id = con.GetInteractive().SessionId
-	} else if ctx.Flags.String("id") != "" {
-		id = ctx.Flags.String("id")
-	} else {
-		console.Log.Errorf("Require session id")
-		return
+func removeCmd(ctx *grumble.Context, con *console.Console) error {
+	server, session, err := targetSession(ctx, con)
+	if err != nil {
+		return err
 	}
-	_, err := con.Rpc.BasicSessionOP(context.Background(), &clientpb.BasicUpdateSession{
-		SessionId: id,
+	_, err = server.Rpc.BasicSessionOP(context.Background(), &clientpb.BasicUpdateSession{
+		SessionId: session.SessionId,
 		IsDelete:  true,
 	})
 	if err != nil {
-		logs.Log.Errorf("Session error: %v", err)
-		return
+		return fmt.Errorf("Session error: %v", err)
+	}
+	server.UpdateSessions(false)
+	if active := con.ActiveTarget.Get(); active != nil && server == con.ServerStatus && active.SessionId == session.SessionId {
+		con.ActiveTarget.Set(server.Sessions()[session.SessionId])
+	}
+	return nil
+}
+
+// targetSession - the active session, or the session of --id, `server/session_id` for sessions of other servers
+func targetSession(ctx *grumble.Context, con *console.Console) (*console.ServerStatus, *clientpb.Session, error) {
+	if session := con.ActiveTarget.Get(); session != nil {
+		return con.ServerStatus, session, nil
+	}
+	id := ctx.Flags.String("id")
+	if id == "" {
+		return nil, nil, errors.New("Require session id")
+	}
+	server, session := con.FindSession(id)
+	if session == nil {
+		return nil, nil, fmt.Errorf("%w: %s", console.ErrNotFoundSession, id)
 	}
-	con.UpdateSessions(false)
-	session := con.Sessions[id]
-	con.ActiveTarget.Set(session)
+	return server, session, nil
 }
@@ -1,9 +1,12 @@
 package sessions
 
 import (
+	"context"
 	"fmt"
 	"github.com/chainreactors/grumble"
+	"github.com/chainreactors/malice-network/client/command/profile"
 	"github.com/chainreactors/malice-network/client/console"
+	"github.com/chainreactors/malice-network/client/utils"
 	"github.com/chainreactors/malice-network/proto/client/clientpb"
 	"github.com/chainreactors/tui"
 	"github.com/charmbracelet/bubbles/table"
@@ -13,17 +16,35 @@ import (
 	"time"
 )
 
-func SessionsCmd(ctx *grumble.Context, con *console.Console) {
+func SessionsCmd(ctx *grumble.Context, con *console.Console) error {
 	con.UpdateSessions(true)
 	isAll := ctx.Flags.Bool("all")
-	if 0 < len(con.Sessions) {
-		PrintSessions(con.Sessions, con, isAll)
+	filter := parseFilter(ctx)
+	if name := ctx.Flags.String("filter"); name != "" {
+		filter = &clientpb.SessionFilter{Name: name}
+	}
+	sessions := con.Sessions()
+	if filter != nil {
+		matched, err := con.Rpc.ListSessionsByFilter(context.Background(), filter)
+		if err != nil {
+			return fmt.Errorf("Failed to filter sessions %s", err)
+		}
+		sessions = make(map[string]*clientpb.Session)
+		for _, session := range matched.Sessions {
+			sessions[session.SessionId] = session
+		}
+		// liveness is already filtered by server
+		isAll = true
+	}
+	if 0 < len(sessions) {
+		PrintSessions(sessions, con, isAll, con.OutputFormat(ctx))
 	} else {
 		console.Log.Info("No sessions")
 	}
+	return nil
 }
 
-func PrintSessions(sessions map[string]*clientpb.Session, con *console.Console, isAll bool) {
+func PrintSessions(sessions map[string]*clientpb.Session, con *console.Console, isAll bool, format string) {
 	//var colorIndex = 1
 	var rowEntries []table.Row
 	var row table.Row
@@ -32,6 +53,7 @@ func PrintSessions(sessions map[string]*clientpb.Session, con *console.Console,
 		{Title: "ID", Width: 15},
 		{Title: "Group", Width: 7},
 		{Title: "Note", Width: 7},
+		{Title: "Tags", Width: 10},
 		{Title: "Transport", Width: 10},
 		{Title: "Remote Address", Width: 15},
 		{Title: "Hostname", Width: 10},
@@ -56,9 +78,10 @@ func PrintSessions(sessions map[string]*clientpb.Session, con *console.Console,
 		secondsDiff := uint64(timeDiff.Seconds())
 		username := strings.TrimPrefix(session.Os.Username, session.Os.Hostname+"\\")
 		row = table.Row{
-			session.SessionId,
+			con.SessionName(con.ServerStatus, session.SessionId),
 			session.GroupName,
 			session.Note,
+			formatTags(session.Tags),
 			"",
 			session.RemoteAddr,
 			session.Os.Hostname,
@@ -71,6 +94,10 @@ func PrintSessions(sessions map[string]*clientpb.Session, con *console.Console,
 	}
 	var err error
 	tableModel.SetRows(rowEntries)
+	if format != utils.OutputText {
+		con.PrintTable(format, tableModel)
+		return
+	}
 	tableModel.SetHandle(func() {
 		SessionLogin(tableModel, con)()
 	})
@@ -84,19 +111,13 @@ func PrintSessions(sessions map[string]*clientpb.Session, con *console.Console,
 }
 
 func SessionLogin(tableModel *tui.TableModel, con *console.Console) func() {
-	var sessionId string
 	selectRow := tableModel.GetSelectedRow()
 	if selectRow == nil {
 		return func() {
 			console.Log.Errorf("No row selected")
 		}
 	}
-	for _, s := range con.Sessions {
-		if strings.HasPrefix(s.SessionId, selectRow[0]) {
-			sessionId = s.SessionId
-		}
-	}
-	session := con.Sessions[sessionId]
+	_, session := con.FindSession(selectRow[0])
 
 	if session == nil {
 		return func() {
@@ -108,5 +129,6 @@ func SessionLogin(tableModel *tui.TableModel, con *console.Console) func() {
 		con.ActiveTarget.Set(session)
 		con.EnableImplantCommands()
 		console.Log.Infof("Active session %s (%s)\n", session.Note, session.SessionId)
+		profile.LoadSessionProfiles(session, con)
 	}
 }
@@ -0,0 +1,35 @@
+package settings
+
+import (
+	"github.com/chainreactors/grumble"
+	"github.com/chainreactors/malice-network/client/command/help"
+	"github.com/chainreactors/malice-network/client/console"
+	"github.com/chainreactors/malice-network/client/utils"
+)
+
+func Commands(con *console.Console) []*grumble.Command {
+	settingsCmd := &grumble.Command{
+		Name:     "settings",
+		Help:     "Show client settings",
+		LongHelp: help.GetHelpFor("settings"),
+		Run: func(ctx *grumble.Context) error {
+			return SettingsCmd(ctx, con)
+		},
+	}
+
+	settingsCmd.AddCommand(&grumble.Command{
+		Name:     "output",
+		Help:     "Set default output format of table commands",
+		LongHelp: help.GetHelpFor("settings output"),
+		Args: func(a *grumble.Args) {
+			a.String("format", "text, json or csv")
+		},
+		Run: func(ctx *grumble.Context) error {
+			return OutputCmd(ctx, con)
+		},
+		Completer: func(prefix string, args []string) []string {
+			return utils.OutputFormats
+		},
+	})
+	return []*grumble.Command{settingsCmd}
+}
@@ -0,0 +1,29 @@
+package settings
+
+import (
+	"fmt"
+	"github.com/chainreactors/grumble"
+	"github.com/chainreactors/malice-network/client/assets"
+	"github.com/chainreactors/malice-network/client/console"
+	"github.com/chainreactors/malice-network/client/utils"
+	"slices"
+)
+
+func SettingsCmd(ctx *grumble.Context, con *console.Console) error {
+	console.Log.Infof("output format: %s\n", con.OutputFormat(nil))
+	return nil
+}
+
+// OutputCmd - save default output format, `--json` and `--csv` of a command still take precedence
+func OutputCmd(ctx *grumble.Context, con *console.Console) error {
+	format := ctx.Args.String("format")
+	if !slices.Contains(utils.OutputFormats, format) {
+		return fmt.Errorf("Unknown output format %s, expect one of %v", format, utils.OutputFormats)
+	}
+	con.Settings.OutputFormat = format
+	if err := assets.SaveSettings(con.Settings); err != nil {
+		return fmt.Errorf("Failed to save settings: %s", err)
+	}
+	console.Log.Infof("Output format set to %s\n", format)
+	return nil
+}
@@ -5,8 +5,21 @@ import (
 	"github.com/chainreactors/malice-network/client/command/help"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/helper/consts"
+	"google.golang.org/protobuf/proto"
 )
 
+// output handlers of tasks recovered after the console restarts or reconnects
+func init() {
+	console.RegisterTaskHandler(consts.ModuleWhoami, printWhoami)
+	console.RegisterTaskHandler(consts.ModuleEnv, printEnv)
+	console.RegisterTaskHandler(consts.ModulePs, func(con *console.Console, sid string, msg proto.Message) {
+		printPs(con, con.OutputFormat(nil), msg)
+	})
+	console.RegisterTaskHandler(consts.ModuleNetstat, func(con *console.Console, sid string, msg proto.Message) {
+		printNetstat(con, con.OutputFormat(nil), msg)
+	})
+}
+
 func Commands(con *console.Console) []*grumble.Command {
 	return []*grumble.Command{
 		&grumble.Command{
@@ -14,8 +27,7 @@ func Commands(con *console.Console) []*grumble.Command {
 			Help:     "Print current user",
 			LongHelp: help.GetHelpFor(consts.ModuleWhoami),
 			Run: func(ctx *grumble.Context) error {
-				WhoamiCmd(ctx, con)
-				return nil
+				return WhoamiCmd(ctx, con)
 			},
 			HelpGroup: consts.ImplantGroup,
 		},
@@ -27,8 +39,7 @@ func Commands(con *console.Console) []*grumble.Command {
 			},
 			LongHelp: help.GetHelpFor(consts.ModuleKill),
 			Run: func(ctx *grumble.Context) error {
-				KillCmd(ctx, con)
-				return nil
+				return KillCmd(ctx, con)
 			},
 			HelpGroup: consts.ImplantGroup,
 		},
@@ -36,9 +47,9 @@ func Commands(con *console.Console) []*grumble.Command {
 			Name:     consts.ModulePs,
 			Help:     "List processes",
 			LongHelp: help.GetHelpFor(consts.ModulePs),
+			Flags:    console.OutputFlags,
 			Run: func(ctx *grumble.Context) error {
-				PsCmd(ctx, con)
-				return nil
+				return PsCmd(ctx, con)
 			},
 			HelpGroup: consts.ImplantGroup,
 		},
@@ -47,8 +58,7 @@ func Commands(con *console.Console) []*grumble.Command {
 			Help:     "List environment variables",
 			LongHelp: help.GetHelpFor(consts.ModuleEnv),
 			Run: func(ctx *grumble.Context) error {
-				EnvCmd(ctx, con)
-				return nil
+				return EnvCmd(ctx, con)
 			},
 			HelpGroup: consts.ImplantGroup,
 		},
@@ -61,8 +71,7 @@ func Commands(con *console.Console) []*grumble.Command {
 				f.String("v", "value", "", "Value")
 			},
 			Run: func(ctx *grumble.Context) error {
-				SetEnvCmd(ctx, con)
-				return nil
+				return SetEnvCmd(ctx, con)
 			},
 			HelpGroup: consts.ImplantGroup,
 		},
@@ -74,8 +83,7 @@ func Commands(con *console.Console) []*grumble.Command {
 			},
 			LongHelp: help.GetHelpFor(consts.ModuleUnsetEnv),
 			Run: func(ctx *grumble.Context) error {
-				UnsetEnvCmd(ctx, con)
-				return nil
+				return UnsetEnvCmd(ctx, con)
 			},
 			HelpGroup: consts.ImplantGroup,
 		},
@@ -83,9 +91,9 @@ func Commands(con *console.Console) []*grumble.Command {
 			Name:     consts.ModuleNetstat,
 			Help:     "List network connections",
 			LongHelp: help.GetHelpFor(consts.ModuleNetstat),
+			Flags:    console.OutputFlags,
 			Run: func(ctx *grumble.Context) error {
-				NetstatCmd(ctx, con)
-				return nil
+				return NetstatCmd(ctx, con)
 			},
 			HelpGroup: consts.ImplantGroup,
 		},
@@ -94,8 +102,7 @@ func Commands(con *console.Console) []*grumble.Command {
 			Help:     "get basic sys info",
 			LongHelp: help.GetHelpFor(consts.ModuleInfo),
 			Run: func(ctx *grumble.Context) error {
-				InfoCmd(ctx, con)
-				return nil
+				return InfoCmd(ctx, con)
 			},
 			HelpGroup: consts.ImplantGroup,
 		},
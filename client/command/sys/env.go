@@ -1,6 +1,7 @@
 package sys
 
 import (
+	"fmt"
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/helper/consts"
@@ -8,24 +9,21 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
-func EnvCmd(ctx *grumble.Context, con *console.Console) {
+func EnvCmd(ctx *grumble.Context, con *console.Console) error {
 	sid := con.GetInteractive().SessionId
 	envTask, err := con.Rpc.Env(con.ActiveTarget.Context(), &implantpb.Request{
 		Name: consts.ModuleEnv,
 	})
 	if err != nil {
-		console.Log.Errorf("Env error: %v", err)
-		return
+		return fmt.Errorf("Env error: %v", err)
 	}
-	con.AddCallback(envTask.TaskId, func(msg proto.Message) {
-		env := msg.(*implantpb.Spite).GetResponse().GetKv()
-		for k, v := range env {
-			con.SessionLog(sid).Consolef("export %s = %s\n", k, v)
-		}
+	con.AddCallback(envTask, func(msg proto.Message) {
+		printEnv(con, sid, msg)
 	})
+	return nil
 }
 
-func SetEnvCmd(ctx *grumble.Context, con *console.Console) {
+func SetEnvCmd(ctx *grumble.Context, con *console.Console) error {
 	sid := con.GetInteractive().SessionId
 	env := ctx.Flags.String("env")
 	value := ctx.Flags.String("value")
@@ -35,15 +33,15 @@ func SetEnvCmd(ctx *grumble.Context, con *console.Console) {
 		Args: args,
 	})
 	if err != nil {
-		console.Log.Errorf("SetEnv error: %v", err)
-		return
+		return fmt.Errorf("SetEnv error: %v", err)
 	}
-	con.AddCallback(setEnvTask.TaskId, func(msg proto.Message) {
+	con.AddCallback(setEnvTask, func(msg proto.Message) {
 		con.SessionLog(sid).Consolef("Set environment variable success\n")
 	})
+	return nil
 }
 
-func UnsetEnvCmd(ctx *grumble.Context, con *console.Console) {
+func UnsetEnvCmd(ctx *grumble.Context, con *console.Console) error {
 	sid := con.GetInteractive().SessionId
 	env := ctx.Flags.String("env")
 	unsetEnvTask, err := con.Rpc.UnsetEnv(con.ActiveTarget.Context(), &implantpb.Request{
@@ -51,10 +49,17 @@ func UnsetEnvCmd(ctx *grumble.Context, con *console.Console) {
 		Input: env,
 	})
 	if err != nil {
-		console.Log.Errorf("UnsetEnv error: %v", err)
-		return
+		return fmt.Errorf("UnsetEnv error: %v", err)
 	}
-	con.AddCallback(unsetEnvTask.TaskId, func(msg proto.Message) {
+	con.AddCallback(unsetEnvTask, func(msg proto.Message) {
 		con.SessionLog(sid).Consolef("Unset environment variable success\n")
 	})
+	return nil
+}
+
+func printEnv(con *console.Console, sid string, msg proto.Message) {
+	env := msg.(*implantpb.Spite).GetResponse().GetKv()
+	for k, v := range env {
+		con.SessionLog(sid).Consolef("export %s = %s\n", k, v)
+	}
 }
@@ -1,6 +1,7 @@
 package sys
 
 import (
+	"fmt"
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/helper/consts"
@@ -8,19 +9,19 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
-func InfoCmd(ctx *grumble.Context, con *console.Console) {
+func InfoCmd(ctx *grumble.Context, con *console.Console) error {
 	session := con.GetInteractive()
 	if session == nil {
-		return
+		return nil
 	}
 	infoTask, err := con.Rpc.Info(con.ActiveTarget.Context(), &implantpb.Request{
 		Name: consts.ModuleInfo,
 	})
 	if err != nil {
-		console.Log.Errorf("Info error: %v", err)
-		return
+		return fmt.Errorf("Info error: %v", err)
 	}
-	con.AddCallback(infoTask.TaskId, func(msg proto.Message) {
+	con.AddCallback(infoTask, func(msg proto.Message) {
 		con.SessionLog(session.SessionId).Consolef("Info: %v\n", msg.(*implantpb.Spite).Body)
 	})
+	return nil
 }
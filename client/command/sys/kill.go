@@ -1,6 +1,7 @@
 package sys
 
 import (
+	"fmt"
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/helper/consts"
@@ -8,11 +9,11 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
-func KillCmd(ctx *grumble.Context, con *console.Console) {
+func KillCmd(ctx *grumble.Context, con *console.Console) error {
 	session := con.GetInteractive()
 	sid := con.GetInteractive().SessionId
 	if session == nil {
-		return
+		return nil
 	}
 	pid := ctx.Flags.String("pid")
 	killTask, err := con.Rpc.Kill(con.ActiveTarget.Context(), &implantpb.Request{
@@ -20,11 +21,11 @@ func KillCmd(ctx *grumble.Context, con *console.Console) {
 		Input: pid,
 	})
 	if err != nil {
-		console.Log.Errorf("Kill error: %v", err)
-		return
+		return fmt.Errorf("Kill error: %v", err)
 	}
-	con.AddCallback(killTask.TaskId, func(msg proto.Message) {
+	con.AddCallback(killTask, func(msg proto.Message) {
 		_ = msg.(*implantpb.Spite)
 		con.SessionLog(sid).Consolef("Killed process\n")
 	})
+	return nil
 }
@@ -1,7 +1,6 @@
 package sys
 
 import (
-	"fmt"
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/helper/consts"
@@ -9,45 +8,24 @@ import (
 	"github.com/chainreactors/tui"
 	"github.com/charmbracelet/bubbles/table"
 	"google.golang.org/protobuf/proto"
-	"os"
 )
 
-func NetstatCmd(ctx *grumble.Context, con *console.Console) {
+func NetstatCmd(ctx *grumble.Context, con *console.Console) error {
 	session := con.GetInteractive()
 	sid := con.GetInteractive().SessionId
 	if session == nil {
-		return
+		return nil
 	}
 	killTask, err := con.Rpc.Netstat(con.ActiveTarget.Context(), &implantpb.Request{
 		Name: consts.ModuleNetstat,
 	})
 	if err != nil {
 		con.SessionLog(sid).Errorf("Kill error: %v", err)
-		return
+		return nil
 	}
-	con.AddCallback(killTask.TaskId, func(msg proto.Message) {
-		resp := msg.(*implantpb.Spite).GetNetstatResponse()
-		var rowEntries []table.Row
-		var row table.Row
-		tableModel := tui.NewTable([]table.Column{
-			{Title: "LocalAddr", Width: 15},
-			{Title: "RemoteAddr", Width: 15},
-			{Title: "SkState", Width: 7},
-			{Title: "Pid", Width: 7},
-			{Title: "Protocol", Width: 10},
-		}, true)
-		for _, sock := range resp.GetSocks() {
-			row = table.Row{
-				sock.LocalAddr,
-				sock.RemoteAddr,
-				sock.SkState,
-				sock.Pid,
-				sock.Protocol,
-			}
-			rowEntries = append(rowEntries, row)
-		}
-		tableModel.SetRows(rowEntries)
-		fmt.Printf(tableModel.View(), os.Stdout)
+	format := con.OutputFormat(ctx)
+	con.AddCallback(killTask, func(msg proto.Message) {
+		printNetstat(con, format, msg)
 	})
 
 	//newTable := tui.NewModel(tableModel, nil, false, false)
@@ -55,4 +33,38 @@ func NetstatCmd(ctx *grumble.Context, con *console.Console) {
 	//if err != nil {
 	//	con.SessionLog(sid).Errorf("Error running table: %v", err)
 	//}
+	return nil
+}
+
+func printNetstat(con *console.Console, format string, msg proto.Message) {
+	tableModel := tui.NewTable(NetstatColumns(), true)
+	tableModel.SetRows(NetstatRows(msg.(*implantpb.Spite).GetNetstatResponse()))
+	con.PrintTable(format, tableModel)
+}
+
+// NetstatColumns - columns of NetstatRows, also used by the merged table of broadcast
+func NetstatColumns() []table.Column {
+	return []table.Column{
+		{Title: "LocalAddr", Width: 15},
+		{Title: "RemoteAddr", Width: 15},
+		{Title: "SkState", Width: 7},
+		{Title: "Pid", Width: 7},
+		{Title: "Protocol", Width: 10},
+	}
+}
+
+func NetstatRows(resp *implantpb.NetstatResponse) []table.Row {
+	var rowEntries []table.Row
+	var row table.Row
+	for _, sock := range resp.GetSocks() {
+		row = table.Row{
+			sock.LocalAddr,
+			sock.RemoteAddr,
+			sock.SkState,
+			sock.Pid,
+			sock.Protocol,
+		}
+		rowEntries = append(rowEntries, row)
+	}
+	return rowEntries
 }
@@ -9,49 +9,23 @@ import (
 	"github.com/chainreactors/tui"
 	"github.com/charmbracelet/bubbles/table"
 	"google.golang.org/protobuf/proto"
-	"os"
 	"strconv"
 )
 
-func PsCmd(ctx *grumble.Context, con *console.Console) {
+func PsCmd(ctx *grumble.Context, con *console.Console) error {
 	session := con.GetInteractive()
 	if session == nil {
-		return
+		return nil
 	}
 	psTask, err := con.Rpc.Ps(con.ActiveTarget.Context(), &implantpb.Request{
 		Name: consts.ModulePs,
 	})
 	if err != nil {
-		console.Log.Errorf("Ps error: %v", err)
-		return
+		return fmt.Errorf("Ps error: %v", err)
 	}
-	con.AddCallback(psTask.TaskId, func(msg proto.Message) {
-		resp := msg.(*implantpb.Spite).GetPsResponse()
-		var rowEntries []table.Row
-		var row table.Row
-		tableModel := tui.NewTable([]table.Column{
-			{Title: "Name", Width: 10},
-			{Title: "PID", Width: 5},
-			{Title: "PPID", Width: 5},
-			{Title: "Arch", Width: 7},
-			{Title: "Owner", Width: 7},
-			{Title: "Path", Width: 15},
-			{Title: "Args", Width: 10},
-		}, true)
-		for _, process := range resp.GetProcesses() {
-			row = table.Row{
-				process.Name,
-				strconv.Itoa(int(process.Pid)),
-				strconv.Itoa(int(process.Ppid)),
-				process.Arch,
-				process.Owner,
-				process.Path,
-				process.Args,
-			}
-			rowEntries = append(rowEntries, row)
-		}
-		tableModel.SetRows(rowEntries)
-		fmt.Printf(tableModel.View(), os.Stdout)
+	format := con.OutputFormat(ctx)
+	con.AddCallback(psTask, func(msg proto.Message) {
+		printPs(con, format, msg)
 	})
 
 	//newTable := tui.NewModel(tableModel, nil, false, false)
@@ -59,4 +33,42 @@ func PsCmd(ctx *grumble.Context, con *console.Console) {
 	//if err != nil {
 	//	con.SessionLog(sid).Errorf("Error running table: %v", err)
 	//}
+	return nil
+}
+
+func printPs(con *console.Console, format string, msg proto.Message) {
+	tableModel := tui.NewTable(PsColumns(), true)
+	tableModel.SetRows(PsRows(msg.(*implantpb.Spite).GetPsResponse()))
+	con.PrintTable(format, tableModel)
+}
+
+// PsColumns - columns of PsRows, also used by the merged table of broadcast
+func PsColumns() []table.Column {
+	return []table.Column{
+		{Title: "Name", Width: 10},
+		{Title: "PID", Width: 5},
+		{Title: "PPID", Width: 5},
+		{Title: "Arch", Width: 7},
+		{Title: "Owner", Width: 7},
+		{Title: "Path", Width: 15},
+		{Title: "Args", Width: 10},
+	}
+}
+
+func PsRows(resp *implantpb.PsResponse) []table.Row {
+	var rowEntries []table.Row
+	var row table.Row
+	for _, process := range resp.GetProcesses() {
+		row = table.Row{
+			process.Name,
+			strconv.Itoa(int(process.Pid)),
+			strconv.Itoa(int(process.Ppid)),
+			process.Arch,
+			process.Owner,
+			process.Path,
+			process.Args,
+		}
+		rowEntries = append(rowEntries, row)
+	}
+	return rowEntries
 }
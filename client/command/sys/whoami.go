@@ -1,6 +1,7 @@
 package sys
 
 import (
+	"fmt"
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/helper/consts"
@@ -8,21 +9,25 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
-func WhoamiCmd(ctx *grumble.Context, con *console.Console) {
+func WhoamiCmd(ctx *grumble.Context, con *console.Console) error {
 	session := con.GetInteractive()
 	sid := con.GetInteractive().SessionId
 	if session == nil {
-		return
+		return nil
 	}
 	whoamiTask, err := con.Rpc.Whoami(con.ActiveTarget.Context(), &implantpb.Request{
 		Name: consts.ModuleWhoami,
 	})
 	if err != nil {
-		console.Log.Errorf("Whoami error: %v", err)
-		return
+		return fmt.Errorf("Whoami error: %v", err)
 	}
-	con.AddCallback(whoamiTask.TaskId, func(msg proto.Message) {
-		resp := msg.(*implantpb.Spite).GetResponse()
-		con.SessionLog(sid).Consolef("Username: %v\n", resp.GetOutput())
+	con.AddCallback(whoamiTask, func(msg proto.Message) {
+		printWhoami(con, sid, msg)
 	})
+	return nil
+}
+
+func printWhoami(con *console.Console, sid string, msg proto.Message) {
+	resp := msg.(*implantpb.Spite).GetResponse()
+	con.SessionLog(sid).Consolef("Username: %v\n", resp.GetOutput())
 }
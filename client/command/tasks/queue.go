@@ -0,0 +1,159 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"github.com/chainreactors/grumble"
+	"github.com/chainreactors/malice-network/client/command/help"
+	"github.com/chainreactors/malice-network/client/console"
+	"github.com/chainreactors/malice-network/proto/client/clientpb"
+	"github.com/chainreactors/tui"
+	"github.com/charmbracelet/bubbles/table"
+	"strconv"
+	"time"
+)
+
+func queueCommand(con *console.Console) *grumble.Command {
+	queueCmd := &grumble.Command{
+		Name:     "queue",
+		Help:     "List tasks queued for offline sessions",
+		LongHelp: help.GetHelpFor("queue"),
+		Flags: func(f *grumble.Flags) {
+			f.String("s", "session", "", "session id, default the interactive session")
+			f.Bool("a", "all", false, "list queued tasks of all sessions")
+			console.OutputFlags(f)
+		},
+		Run: func(ctx *grumble.Context) error {
+			return QueueCmd(ctx, con)
+		},
+	}
+
+	queueCmd.AddCommand(&grumble.Command{
+		Name: "move",
+		Help: "Move a queued task to position, 0 is delivered first",
+		Args: func(a *grumble.Args) {
+			a.Uint("task_id", "task id")
+			a.Int("position", "position in queue")
+		},
+		Flags: func(f *grumble.Flags) {
+			f.String("s", "session", "", "session id, default the interactive session")
+		},
+		Run: func(ctx *grumble.Context) error {
+			return MoveQueuedCmd(ctx, con)
+		},
+	})
+
+	queueCmd.AddCommand(&grumble.Command{
+		Name: "cancel",
+		Help: "Cancel a queued task",
+		Args: func(a *grumble.Args) {
+			a.Uint("task_id", "task id")
+		},
+		Flags: func(f *grumble.Flags) {
+			f.String("s", "session", "", "session id, default the interactive session")
+		},
+		Run: func(ctx *grumble.Context) error {
+			return CancelQueuedCmd(ctx, con)
+		},
+	})
+	return queueCmd
+}
+
+func QueueCmd(ctx *grumble.Context, con *console.Console) error {
+	var sid string
+	if !ctx.Flags.Bool("all") {
+		sid = queueSession(ctx, con)
+		if sid == "" {
+			return nil
+		}
+	}
+	queued, err := con.Rpc.ListQueuedTasks(context.Background(), &clientpb.Session{SessionId: sid})
+	if err != nil {
+		return fmt.Errorf("Error listing queued tasks: %v", err)
+	}
+	if len(queued.Tasks) == 0 {
+		console.Log.Info("No queued tasks")
+		return nil
+	}
+	PrintQueuedTasks(queued.Tasks, con, con.OutputFormat(ctx))
+	return nil
+}
+
+func MoveQueuedCmd(ctx *grumble.Context, con *console.Console) error {
+	sid := queueSession(ctx, con)
+	if sid == "" {
+		return nil
+	}
+	queued, err := con.Rpc.MoveQueuedTask(context.Background(), &clientpb.QueuedTask{
+		SessionId: sid,
+		TaskId:    uint32(ctx.Args.Uint("task_id")),
+		Position:  int32(ctx.Args.Int("position")),
+	})
+	if err != nil {
+		return fmt.Errorf("Error moving queued task: %v", err)
+	}
+	PrintQueuedTasks(queued.Tasks, con, con.OutputFormat(ctx))
+	return nil
+}
+
+func CancelQueuedCmd(ctx *grumble.Context, con *console.Console) error {
+	sid := queueSession(ctx, con)
+	if sid == "" {
+		return nil
+	}
+	taskID := uint32(ctx.Args.Uint("task_id"))
+	_, err := con.Rpc.CancelQueuedTask(context.Background(), &clientpb.QueuedTask{
+		SessionId: sid,
+		TaskId:    taskID,
+	})
+	if err != nil {
+		return fmt.Errorf("Error cancelling queued task: %v", err)
+	}
+	console.Log.Infof("Queued task %d cancelled\n", taskID)
+	return nil
+}
+
+func queueSession(ctx *grumble.Context, con *console.Console) string {
+	if sid := ctx.Flags.String("session"); sid != "" {
+		return sid
+	}
+	if session := con.GetInteractive(); session != nil {
+		return session.SessionId
+	}
+	console.Log.Errorf("Require --session, or use a session")
+	return ""
+}
+
+func PrintQueuedTasks(tasks []*clientpb.QueuedTask, con *console.Console, format string) {
+	var rowEntries []table.Row
+	tableModel := tui.NewTable([]table.Column{
+		{Title: "Session", Width: 10},
+		{Title: "Pos", Width: 4},
+		{Title: "Task", Width: 5},
+		{Title: "Type", Width: 15},
+		{Title: "Operator", Width: 10},
+		{Title: "Queued", Width: 20},
+		{Title: "Expire", Width: 20},
+	}, true)
+	// tasks are ordered by session and position, positions are shown as index in the session queue
+	positions := map[string]int{}
+	for _, task := range tasks {
+		position := positions[task.SessionId]
+		positions[task.SessionId]++
+		sid := task.SessionId
+		if len(sid) > 8 {
+			sid = sid[:8]
+		}
+		rowEntries = append(rowEntries, table.Row{
+			sid,
+			strconv.Itoa(position),
+			strconv.Itoa(int(task.TaskId)),
+			task.Type,
+			task.Callby,
+			time.Unix(task.CreatedAt, 0).Format("2006-01-02 15:04:05"),
+			time.Unix(task.ExpireAt, 0).Format("2006-01-02 15:04:05"),
+		})
+	}
+	tableModel.SetRows(rowEntries)
+	con.PrintTable(format, tableModel)
+}
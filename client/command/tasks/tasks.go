@@ -6,6 +6,7 @@ import (
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/command/help"
 	"github.com/chainreactors/malice-network/client/console"
+	"github.com/chainreactors/malice-network/client/utils"
 	"github.com/chainreactors/malice-network/proto/client/clientpb"
 	"github.com/chainreactors/tui"
 	"github.com/charmbracelet/bubbles/table"
@@ -26,20 +27,20 @@ func Command(con *console.Console) []*grumble.Command {
 				////f.String("e", "filter-re", "", "filter sessions by regular expression")
 				//
 				//f.Int("t", "timeout", assets.DefaultSettings.DefaultTimeout, "command timeout in seconds")
+				console.OutputFlags(f)
 			},
 			Run: func(ctx *grumble.Context) error {
-				TasksCmd(ctx, con)
-				return nil
+				return TasksCmd(ctx, con)
 			},
 		},
+		queueCommand(con),
 	}
 }
 
-func TasksCmd(ctx *grumble.Context, con *console.Console) {
+func TasksCmd(ctx *grumble.Context, con *console.Console) error {
 	err := con.UpdateTasks(con.GetInteractive())
 	if err != nil {
-		console.Log.Errorf("Error updating tasks: %v", err)
-		return
+		return fmt.Errorf("Error updating tasks: %v", err)
 	}
 	sid := con.GetInteractive().SessionId
 	Tasks, err := con.Rpc.GetTaskDescs(con.ActiveTarget.Context(), con.GetInteractive())
@@ -47,10 +48,11 @@ func TasksCmd(ctx *grumble.Context, con *console.Console) {
 		con.SessionLog(sid).Errorf("Error getting tasks: %v", err)
 	}
 	if 0 < len(Tasks.Tasks) {
-		PrintTasks(Tasks.Tasks, con)
+		PrintTasks(Tasks.Tasks, con, con.OutputFormat(ctx))
 	} else {
 		console.Log.Info("No sessions")
 	}
+	return nil
 }
 
 type description struct {
@@ -58,7 +60,7 @@ type description struct {
 	Path string `json:"path"`
 }
 
-func PrintTasks(tasks []*clientpb.TaskDesc, con *console.Console) {
+func PrintTasks(tasks []*clientpb.TaskDesc, con *console.Console, format string) {
 	sid := con.GetInteractive().SessionId
 	var rowEntries []table.Row
 	var row table.Row
@@ -100,6 +102,10 @@ func PrintTasks(tasks []*clientpb.TaskDesc, con *console.Console) {
 		rowEntries = append(rowEntries, row)
 	}
 	tableModel.SetRows(rowEntries)
+	if format != utils.OutputText {
+		con.PrintTable(format, tableModel)
+		return
+	}
 	newTable := tui.NewModel(tableModel, nil, false, false)
 	err := newTable.Run()
 	if err != nil {
@@ -4,6 +4,7 @@ import (
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/command/completer"
 	"github.com/chainreactors/malice-network/client/command/help"
+	"github.com/chainreactors/malice-network/client/command/profile"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/proto/client/clientpb"
 )
@@ -18,8 +19,7 @@ func Command(con *console.Console) []*grumble.Command {
 				a.String("sid", "session id")
 			},
 			Run: func(ctx *grumble.Context) error {
-				UseSessionCmd(ctx, con)
-				return nil
+				return UseSessionCmd(ctx, con)
 			},
 			Completer: func(prefix string, args []string) []string {
 				return completer.SessionIDCompleter(con, prefix)
@@ -37,20 +37,26 @@ func Command(con *console.Console) []*grumble.Command {
 	}
 }
 
-func UseSessionCmd(ctx *grumble.Context, con *console.Console) {
+func UseSessionCmd(ctx *grumble.Context, con *console.Console) error {
 	var session *clientpb.Session
 	con.UpdateSessions(false)
 	idArg := ctx.Args.String("sid")
+	var server *console.ServerStatus
 	if idArg != "" {
-		session = con.Sessions[idArg]
+		server, session = con.FindSession(idArg)
 	}
 
 	if session == nil {
-		console.Log.Errorf(console.ErrNotFoundSession.Error())
-		return
+		return console.ErrNotFoundSession
+	}
+	// session of another server, `use server/session_id`
+	if server != con.ServerStatus {
+		con.UseServer(server.Name)
 	}
 
 	con.ActiveTarget.Set(session)
 	con.EnableImplantCommands()
 	console.Log.Infof("Active session %s (%s)\n", session.Note, session.SessionId)
+	profile.LoadSessionProfiles(session, con)
+	return nil
 }
@@ -15,15 +15,15 @@ func Command(con *console.Console) []*grumble.Command {
 			Help:     "show server version",
 			LongHelp: help.GetHelpFor("version"),
 			Run: func(ctx *grumble.Context) error {
-				VersionCmd(ctx, con)
-				return nil
+				return VersionCmd(ctx, con)
 			},
 		},
 	}
 }
 
-func VersionCmd(ctx *grumble.Context, con *console.Console) {
+func VersionCmd(ctx *grumble.Context, con *console.Console) error {
 	printVersion(con)
+	return nil
 }
 
 func printVersion(con *console.Console) {
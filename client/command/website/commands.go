@@ -3,6 +3,7 @@ package website
 import (
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/command/completer"
+	"github.com/chainreactors/malice-network/client/command/help"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/helper/consts"
 )
@@ -35,10 +36,14 @@ func Commands(con *console.Console) []*grumble.Command {
 			f.String("n", "name", "", "name of the website")
 			f.String("", "content-type", "", "content type")
 			f.Bool("", "recursive", false, "add content recursively")
+			f.StringSlice("", "header", []string{}, "custom response header, e.g. \"Server: nginx\"")
+			f.Int("", "status", 0, "custom response status code")
+			f.String("", "redirect", "", "redirect to location instead of serving content")
+			f.Int("", "max-hits", 0, "remove content after n requests, 0 means unlimited")
+			f.Duration("", "expire", 0, "remove content after duration, e.g. 1h")
 		},
 		Run: func(c *grumble.Context) error {
-			websiteAddCmd(c, con)
-			return nil
+			return websiteAddCmd(c, con)
 		},
 		Completer: func(prefix string, args []string) []string {
 			return completer.LocalPathCompleter(prefix, args, con)
@@ -56,8 +61,7 @@ func Commands(con *console.Console) []*grumble.Command {
 			f.Bool("r", "recursive", false, "remove content recursively")
 		},
 		Run: func(c *grumble.Context) error {
-			webRmContentCmd(c, con)
-			return nil
+			return webRmContentCmd(c, con)
 		},
 	})
 
@@ -69,8 +73,7 @@ func Commands(con *console.Console) []*grumble.Command {
 			f.String("n", "name", "", "name of the website")
 		},
 		Run: func(c *grumble.Context) error {
-			websitesRmCmd(c, con)
-			return nil
+			return websitesRmCmd(c, con)
 		},
 	})
 
@@ -82,10 +85,13 @@ func Commands(con *console.Console) []*grumble.Command {
 			f.String("n", "name", "", "name of the website")
 			f.String("", "web-path", "", "path to the website")
 			f.String("", "content-type", "", "content type")
+			f.StringSlice("", "header", []string{}, "custom response headers, replace all headers of the content, e.g. \"Server: nginx\"")
+			f.Bool("", "clear-headers", false, "remove all custom response headers")
+			f.Int("", "status", 0, "custom response status code, 0 to reset")
+			f.String("", "redirect", "", "redirect to location instead of serving content, empty to reset")
 		},
 		Run: func(c *grumble.Context) error {
-			websiteUpdateContentCmd(c, con)
-			return nil
+			return websiteUpdateContentCmd(c, con)
 		},
 	})
 
@@ -95,10 +101,29 @@ func Commands(con *console.Console) []*grumble.Command {
 		Help: "List the contents of a website",
 		Flags: func(f *grumble.Flags) {
 			f.String("n", "name", "website name", "name of the website")
+			console.OutputFlags(f)
 		},
 		Run: func(c *grumble.Context) error {
-			listWebsitesCmd(c, con)
-			return nil
+			return listWebsitesCmd(c, con)
+		},
+	})
+
+	// access logs
+	webCmd.AddCommand(&grumble.Command{
+		Name:     "logs",
+		Help:     "Show access logs of a website",
+		LongHelp: help.GetHelpFor("website logs"),
+		Args: func(a *grumble.Args) {
+			a.String("name", "name of the website")
+		},
+		Flags: func(f *grumble.Flags) {
+			f.String("", "path", "", "filter by path")
+			f.String("", "ip", "", "filter by source ip prefix")
+			f.Int("", "limit", 50, "max number of logs, 0 for all")
+			console.OutputFlags(f)
+		},
+		Run: func(c *grumble.Context) error {
+			return websiteLogsCmd(c, con)
 		},
 	})
 
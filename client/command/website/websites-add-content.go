@@ -3,6 +3,7 @@ package website
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/proto/listener/lispb"
@@ -12,54 +13,97 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
+	"time"
 )
 
-func websiteAddCmd(c *grumble.Context, con *console.Console) {
+func websiteAddCmd(c *grumble.Context, con *console.Console) error {
 	cPath := c.Args.String("content-path")
 	webPath := c.Flags.String("web-path")
 	name := c.Flags.String("name")
 	contentType := c.Flags.String("content-type")
 	recursive := c.Flags.Bool("recursive")
+	headers, err := parseHeaders(c.Flags.StringSlice("header"))
+	if err != nil {
+		return err
+	}
+	status := int32(c.Flags.Int("status"))
+	redirect := c.Flags.String("redirect")
+	maxHits := int32(c.Flags.Int("max-hits"))
+	var expireAt int64
+	if expire := c.Flags.Duration("expire"); expire > 0 {
+		expireAt = time.Now().Add(expire).Unix()
+	}
 	if name == "" {
-		console.Log.Errorf("Must specify a website name via --name, see --help")
-		return
+		return errors.New("Must specify a website name via --name, see --help")
 	}
 	if webPath == "" {
-		console.Log.Errorf("Must specify a web path via --path, see --help")
-		return
+		return errors.New("Must specify a web path via --path, see --help")
+	}
+	addWeb := &lispb.WebsiteAddContent{
+		Name:     name,
+		Contents: map[string]*lispb.WebContent{},
+	}
+	if redirect != "" {
+		addWeb.Contents[webPath] = &lispb.WebContent{
+			Path:     webPath,
+			Headers:  headers,
+			Status:   status,
+			Redirect: redirect,
+			MaxHits:  maxHits,
+			ExpireAt: expireAt,
+		}
+		_, err = con.Rpc.WebsiteAddContent(context.Background(), addWeb)
+		if err != nil {
+			return err
+		}
+		console.Log.Importantf("Redirect %s -> %s added to website %s", webPath, redirect, name)
+		return nil
 	}
 	if cPath == "" {
-		console.Log.Errorf("Must specify some --content-path\n")
-		return
+		return errors.New("Must specify some --content-path")
 	}
 	cPath, _ = filepath.Abs(cPath)
 
 	fileIfo, err := os.Stat(cPath)
 	if err != nil {
-		console.Log.Errorf("Error adding content %s\n", err)
-		return
-	}
-	addWeb := &lispb.WebsiteAddContent{
-		Name:     name,
-		Contents: map[string]*lispb.WebContent{},
+		return fmt.Errorf("Error adding content %s", err)
 	}
 
 	if fileIfo.IsDir() {
 		if !recursive && !ConfirmAddDirectory() {
-			return
+			return nil
 		}
 		WebAddDirectory(addWeb, webPath, cPath)
 	} else {
 		WebAddFile(addWeb, webPath, contentType, cPath)
 	}
+	for _, content := range addWeb.Contents {
+		content.Headers = headers
+		content.Status = status
+		content.MaxHits = maxHits
+		content.ExpireAt = expireAt
+	}
 	_, err = con.Rpc.WebsiteAddContent(context.Background(), addWeb)
 	if err != nil {
-		console.Log.Errorf("%s", err)
-		return
+		return err
 	}
 	console.Log.Importantf("Content added to website %s", name)
 	// TODO - PrintWebsite(web, con)
-	return
+	return nil
+}
+
+// parseHeaders - parse "Key: Value" pairs
+func parseHeaders(values []string) (map[string]string, error) {
+	headers := map[string]string{}
+	for _, value := range values {
+		k, v, ok := strings.Cut(value, ":")
+		if !ok || strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("invalid header %q, expect \"Key: Value\"", value)
+		}
+		headers[http.CanonicalHeaderKey(strings.TrimSpace(k))] = strings.TrimSpace(v)
+	}
+	return headers, nil
 }
 
 func WebAddDirectory(web *lispb.WebsiteAddContent, webpath string, contentPath string) {
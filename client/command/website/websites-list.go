@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/console"
+	"github.com/chainreactors/malice-network/client/utils"
 	"github.com/chainreactors/malice-network/proto/client/clientpb"
 	"github.com/chainreactors/malice-network/proto/listener/lispb"
 	"github.com/chainreactors/tui"
@@ -12,55 +13,67 @@ import (
 	"strconv"
 )
 
-func listWebsitesCmd(c *grumble.Context, con *console.Console) {
+func listWebsitesCmd(c *grumble.Context, con *console.Console) error {
 	name := c.Flags.String("name")
 	if name == "" {
 		websites, err := con.Rpc.Websites(context.Background(), &clientpb.Empty{})
 		if err != nil {
-			console.Log.Errorf("Failed to list websites %s", err)
-			return
+			return fmt.Errorf("Failed to list websites %s", err)
 		}
 		for _, website := range websites.Websites {
 			fmt.Printf("List the contents of website '%s':\n", website.Name)
 		}
-		return
+		return nil
 	} else {
 		website, err := con.Rpc.Website(context.Background(), &lispb.Website{
 			Name: name,
 		})
 		if err != nil {
 			fmt.Printf("Failed to list website content %s", err)
-			return
+			return nil
 		}
 		if 0 < len(website.Contents) {
-			PrintWebsite(website)
+			PrintWebsite(website, con, con.OutputFormat(c))
 		} else {
 			fmt.Printf("No content for '%s'", name)
 		}
 	}
-	return
+	return nil
 }
 
-func PrintWebsite(web *lispb.Website) {
+func PrintWebsite(web *lispb.Website, con *console.Console, format string) {
 	var rowEntries []table.Row
 	var row table.Row
 	tableModel := tui.NewTable([]table.Column{
 		{Title: "Path", Width: 20},
 		{Title: "Content-type", Width: 20},
 		{Title: "Size", Width: 10},
+		{Title: "Hits", Width: 10},
 	}, true)
 	for _, content := range web.Contents {
 		row = table.Row{
 			content.Path,
 			content.ContentType,
 			strconv.FormatUint(content.Size, 10),
+			formatHits(content),
 		}
 		rowEntries = append(rowEntries, row)
 	}
 	tableModel.SetRows(rowEntries)
+	if format != utils.OutputText {
+		con.PrintTable(format, tableModel)
+		return
+	}
 	newTable := tui.NewModel(tableModel, nil, false, false)
 	err := newTable.Run()
 	if err != nil {
 		return
 	}
 }
+
+func formatHits(content *lispb.WebContent) string {
+	if content.MaxHits == 0 {
+		return strconv.Itoa(int(content.Hits))
+	}
+	return fmt.Sprintf("%d/%d", content.Hits, content.MaxHits)
+}
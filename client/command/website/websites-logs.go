@@ -0,0 +1,71 @@
+package website
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/chainreactors/grumble"
+	"github.com/chainreactors/malice-network/client/console"
+	"github.com/chainreactors/malice-network/client/utils"
+	"github.com/chainreactors/malice-network/proto/listener/lispb"
+	"github.com/chainreactors/tui"
+	"github.com/charmbracelet/bubbles/table"
+	"strconv"
+	"time"
+)
+
+func websiteLogsCmd(c *grumble.Context, con *console.Console) error {
+	name := c.Args.String("name")
+	if name == "" {
+		return errors.New("Must specify a website name, see --help")
+	}
+	logs, err := con.Rpc.WebsiteLogs(context.Background(), &lispb.WebsiteLogRequest{
+		Name:       name,
+		Path:       c.Flags.String("path"),
+		RemoteAddr: c.Flags.String("ip"),
+		Limit:      int32(c.Flags.Int("limit")),
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to get website logs %s", err)
+	}
+	if len(logs.Logs) == 0 {
+		fmt.Printf("No access logs for '%s'\n", name)
+		return nil
+	}
+	PrintWebsiteLogs(logs.Logs, con, con.OutputFormat(c))
+	return nil
+}
+
+func PrintWebsiteLogs(logs []*lispb.WebsiteAccess, con *console.Console, format string) {
+	var rowEntries []table.Row
+	tableModel := tui.NewTable([]table.Column{
+		{Title: "Time", Width: 20},
+		{Title: "Source", Width: 16},
+		{Title: "Method", Width: 7},
+		{Title: "Path", Width: 20},
+		{Title: "Status", Width: 6},
+		{Title: "Bytes", Width: 10},
+		{Title: "User-Agent", Width: 30},
+	}, true)
+	for _, log := range logs {
+		rowEntries = append(rowEntries, table.Row{
+			time.Unix(log.Timestamp, 0).Format("2006-01-02 15:04:05"),
+			log.RemoteAddr,
+			log.Method,
+			log.Path,
+			strconv.Itoa(int(log.Status)),
+			strconv.FormatInt(log.Bytes, 10),
+			log.UserAgent,
+		})
+	}
+	tableModel.SetRows(rowEntries)
+	if format != utils.OutputText {
+		con.PrintTable(format, tableModel)
+		return
+	}
+	newTable := tui.NewModel(tableModel, nil, false, false)
+	err := newTable.Run()
+	if err != nil {
+		return
+	}
+}
@@ -2,31 +2,30 @@ package website
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/proto/listener/lispb"
 	"strings"
 )
 
-func webRmContentCmd(c *grumble.Context, con *console.Console) {
+func webRmContentCmd(c *grumble.Context, con *console.Console) error {
 	name := c.Flags.String("name")
 	webPath := c.Flags.String("web-path")
 	recursive := c.Flags.Bool("recursive")
 	if name == "" {
-		console.Log.Errorf("Must specify a website name via --name, see --help")
-		return
+		return errors.New("Must specify a website name via --name, see --help")
 	}
 	if webPath == "" {
-		console.Log.Errorf("Must specify a web path via --path, see --help")
-		return
+		return errors.New("Must specify a web path via --path, see --help")
 	}
 
 	website, err := con.Rpc.Website(context.Background(), &lispb.Website{
 		Name: name,
 	})
 	if err != nil {
-		console.Log.Errorf("%s", err)
-		return
+		return err
 	}
 
 	rmWebContent := &lispb.WebsiteRemoveContent{
@@ -44,8 +43,8 @@ func webRmContentCmd(c *grumble.Context, con *console.Console) {
 	}
 	_, err = con.Rpc.WebsiteRemoveContent(context.Background(), rmWebContent)
 	if err != nil {
-		console.Log.Errorf("Failed to remove content %s", err)
-		return
+		return fmt.Errorf("Failed to remove content %s", err)
 	}
 	// TODO - PrintWebsite(web, con)
+	return nil
 }
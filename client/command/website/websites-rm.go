@@ -2,23 +2,23 @@ package website
 
 import (
 	"context"
+	"errors"
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/proto/listener/lispb"
 )
 
-func websitesRmCmd(c *grumble.Context, con *console.Console) {
+func websitesRmCmd(c *grumble.Context, con *console.Console) error {
 	name := c.Flags.String("name")
 	if name == "" {
-		console.Log.Errorf("Must specify a website name via --name, see --help")
-		return
+		return errors.New("Must specify a website name via --name, see --help")
 	}
 
 	_, err := con.Rpc.WebsiteRemove(context.Background(), &lispb.Website{
 		Name: name,
 	})
 	if err != nil {
-		console.Log.Errorf("%s", err)
-		return
+		return err
 	}
+	return nil
 }
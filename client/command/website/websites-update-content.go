@@ -2,26 +2,45 @@ package website
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/chainreactors/grumble"
 	"github.com/chainreactors/malice-network/client/console"
 	"github.com/chainreactors/malice-network/proto/listener/lispb"
+	"slices"
 )
 
-func websiteUpdateContentCmd(c *grumble.Context, con *console.Console) {
+func websiteUpdateContentCmd(c *grumble.Context, con *console.Console) error {
 	name := c.Flags.String("name")
 	webPath := c.Flags.String("web-path")
 	contentType := c.Flags.String("content-type")
+	headers, err := parseHeaders(c.Flags.StringSlice("header"))
+	if err != nil {
+		return err
+	}
+	status := int32(c.Flags.Int("status"))
+	redirect := c.Flags.String("redirect")
 	if name == "" {
-		console.Log.Errorf("Must specify a website name via --name, see --help")
-		return
+		return errors.New("Must specify a website name via --name, see --help")
 	}
 	if webPath == "" {
-		console.Log.Errorf("Must specify a web path via --wen-path, see --help")
-		return
+		return errors.New("Must specify a web path via --wen-path, see --help")
 	}
-	if contentType == "" {
-		console.Log.Errorf("Must specify a content type via --content-type, see --help")
-		return
+	// fields given on command line are updated even to empty values, e.g. --redirect "" clears the redirect
+	var mask []string
+	for flag, field := range map[string]string{
+		"content-type":  "content_type",
+		"header":        "headers",
+		"clear-headers": "headers",
+		"status":        "status",
+		"redirect":      "redirect",
+	} {
+		if !c.Flags[flag].IsDefault && !slices.Contains(mask, field) {
+			mask = append(mask, field)
+		}
+	}
+	if len(mask) == 0 {
+		return errors.New("Must specify --content-type, --header, --clear-headers, --status or --redirect, see --help")
 	}
 
 	updateWeb := &lispb.WebsiteAddContent{
@@ -29,12 +48,19 @@ func websiteUpdateContentCmd(c *grumble.Context, con *console.Console) {
 		Contents: map[string]*lispb.WebContent{},
 	}
 	updateWeb.Contents[webPath] = &lispb.WebContent{
+		Path:        webPath,
 		ContentType: contentType,
+		Status:      status,
+		Redirect:    redirect,
+		UpdateMask:  mask,
+	}
+	if len(headers) > 0 {
+		updateWeb.Contents[webPath].Headers = headers
 	}
-	_, err := con.Rpc.WebsiteUpdateContent(context.Background(), updateWeb)
+	_, err = con.Rpc.WebsiteUpdateContent(context.Background(), updateWeb)
 	if err != nil {
-		console.Log.Errorf("Failed to update content %s", err)
-		return
+		return fmt.Errorf("Failed to update content %s", err)
 	}
 	// TODO - PrintWebsite(web, con)
+	return nil
 }
@@ -10,31 +10,60 @@ import (
 	"github.com/chainreactors/malice-network/proto/client/clientpb"
 	"github.com/chainreactors/tui"
 	"github.com/fatih/color"
+	"github.com/muesli/termenv"
 	"google.golang.org/protobuf/proto"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 )
 
 var (
-	ErrNotFoundTask    = errors.New("task not found")
-	ErrNotFoundSession = errors.New("session not found")
-	Prompt             = "IOM"
-	LogLevel           = logs.Warn
-	Log                = logs.NewLogger(LogLevel)
-	MuteLog            = logs.NewLogger(logs.Important)
-	implantGroups      = []string{consts.ImplantGroup, consts.AliasesGroup, consts.ExtensionGroup}
+	ErrNotFoundTask     = errors.New("task not found")
+	ErrNotFoundSession  = errors.New("session not found")
+	ErrAmbiguousSession = errors.New("ambiguous session prefix")
+	ErrTaskFailed       = errors.New("task failed")
+	Prompt              = "IOM"
+	LogLevel            = logs.Warn
+	Log                 = logs.NewLogger(LogLevel)
+	MuteLog             = logs.NewLogger(logs.Important)
+	implantGroups       = []string{consts.ImplantGroup, consts.AliasesGroup, consts.ExtensionGroup}
 )
 
 type TaskCallback func(resp proto.Message)
 
+type BatchCallback func(batch *clientpb.Batch)
+
 // BindCmds - Bind extra commands to the app object
 type BindCmds func(console *Console)
 
-// Start - Console entrypoint
-func Start(bindCmds ...BindCmds) error {
+// Start - Console entrypoint, login with config if given
+func Start(config string, bindCmds ...BindCmds) error {
 	//assets.Setup(false, false)
 	tui.Reset()
+	con, err := NewConsole(config, bindCmds...)
+	if err != nil {
+		// choose another config by login
+		con, _ = NewConsole("", bindCmds...)
+	} else if config != "" {
+		// imported config is listed by login later
+		if err = assets.MvConfig(config); err != nil {
+			logs.Log.Warnf("Error saving config: %v", err)
+		}
+		con.RecoverCallbacks()
+	}
+
+	//go core.TunnelLoop(rpc)
+	os.Args = []string{}
+	err = con.App.Run()
+	if err != nil {
+		logs.Log.Errorf("Run loop returned error: %v", err)
+	}
+	return err
+}
+
+// NewConsole - create console and bind commands, login with config before binding if config is given
+func NewConsole(config string, bindCmds ...BindCmds) (*Console, error) {
 	settings, _ := assets.LoadSettings()
 	con := &Console{
 		App: grumble.New(&grumble.Config{
@@ -50,28 +79,26 @@ func Start(bindCmds ...BindCmds) error {
 		ActiveTarget: &ActiveTarget{},
 		Settings:     settings,
 		Observers:    map[string]*Observer{},
+		Servers:      map[string]*ServerStatus{},
 	}
 	//con.App.SetPrintASCIILogo(func(_ *grumble.App) {
 	//con.PrintLogo()
 	//})
 	//con.UpdatePrompt()
-	con.readConfig()
+	if config != "" {
+		if err := con.readConfig(config); err != nil {
+			return nil, err
+		}
+	}
 	for _, bind := range bindCmds {
 		bind(con)
 	}
 	con.DisableImplantCommands()
 	con.ActiveTarget.callback = func(sess *clientpb.Session) {
-		con.ActiveTarget.activeObserver = NewObserver(sess)
+		con.ActiveTarget.activeObserver.Store(NewObserver(sess))
 		con.UpdatePrompt()
 	}
-
-	//go core.TunnelLoop(rpc)
-	os.Args = []string{}
-	err := con.App.Run()
-	if err != nil {
-		logs.Log.Errorf("Run loop returned error: %v", err)
-	}
-	return err
+	return con, nil
 }
 
 type Console struct {
@@ -80,39 +107,54 @@ type Console struct {
 	Settings     *assets.Settings
 	Callbacks    *sync.Map
 	Observers    map[string]*Observer
+	// observersLock - guards Observers, which is read by the event loop
+	observersLock sync.RWMutex
+	// Servers - connected servers by name, the embedded ServerStatus is the one commands are sent to
+	Servers map[string]*ServerStatus
 	*ServerStatus
 }
 
-func (c *Console) Login(config *mtls.ClientConfig) error {
+// Login - connect to the server and switch to it, other connected servers are kept
+func (c *Console) Login(name string, config *mtls.ClientConfig) error {
 	conn, err := mtls.Connect(config)
 	if err != nil {
 		logs.Log.Errorf("Failed to connect: %v", err)
 		return err
 	}
 	logs.Log.Importantf("Connected to server %s:%d", config.LHost, config.LPort)
-	c.ServerStatus, err = InitServerStatus(conn)
+	server, err := InitServerStatus(conn, config)
 	if err != nil {
 		logs.Log.Errorf("init server failed : %v", err)
 		return err
 	}
-	logs.Log.Importantf("%d listeners, %d clients , %d sessions", len(c.Listeners), len(c.Clients), len(c.Sessions))
+	c.addServer(name, server)
+	c.useServer(server)
+	logs.Log.Importantf("%d listeners, %d clients , %d sessions", len(c.Listeners), len(c.Clients), len(c.Sessions()))
 	return nil
 }
 
 func (c *Console) UpdatePrompt() {
 	c.App.Config().NoColor = true
-	if c.ActiveTarget.session != nil {
-		groupName := c.ActiveTarget.session.GroupName
-		if c.ActiveTarget.session.Note != "" {
-			c.App.SetPrompt(tui.AdaptSessionColor(groupName, c.ActiveTarget.session.Note))
+	var prompt string
+	if session := c.ActiveTarget.Get(); session != nil {
+		groupName := session.GroupName
+		if session.Note != "" {
+			prompt = tui.AdaptSessionColor(groupName, session.Note)
 		} else {
-			sessionID := c.ActiveTarget.session.SessionId
-			c.App.SetPrompt(tui.AdaptSessionColor(groupName, sessionID[:8]))
+			sessionID := session.SessionId
+			prompt = tui.AdaptSessionColor(groupName, sessionID[:8])
 		}
 
 	} else {
-		c.App.SetPrompt(tui.AdaptTermColor(Prompt))
+		prompt = tui.AdaptTermColor(Prompt)
+	}
+	if len(c.Servers) > 1 && c.ServerStatus != nil {
+		prompt = termenv.String("["+c.Name+"] ").Foreground(tui.Blue).String() + prompt
 	}
+	if c.ServerStatus != nil && !c.Alive.Load() {
+		prompt = termenv.String("[disconnected] ").Foreground(tui.Red).String() + prompt
+	}
+	c.App.SetPrompt(prompt)
 }
 
 func (c *Console) AddAliasCommand(cmd *grumble.Command) {
@@ -140,14 +182,29 @@ func (c *Console) DisableImplantCommands() {
 // AddObserver - Observers to notify when the active session changes
 func (c *Console) AddObserver(session *clientpb.Session) string {
 	Log.Infof("Add observer to %s", session.SessionId)
+	c.observersLock.Lock()
+	defer c.observersLock.Unlock()
 	c.Observers[session.SessionId] = NewObserver(session)
 	return session.SessionId
 }
 
 func (c *Console) RemoveObserver(observerID string) {
+	c.observersLock.Lock()
+	defer c.observersLock.Unlock()
 	delete(c.Observers, observerID)
 }
 
+// observers - snapshot of Observers, safe to range while observers are added or removed
+func (c *Console) observers() map[string]*Observer {
+	c.observersLock.RLock()
+	defer c.observersLock.RUnlock()
+	observers := make(map[string]*Observer, len(c.Observers))
+	for sid, ob := range c.Observers {
+		observers[sid] = ob
+	}
+	return observers
+}
+
 func (c *Console) GetInteractive() *clientpb.Session {
 	if c.ActiveTarget != nil {
 		return c.ActiveTarget.GetInteractive()
@@ -155,43 +212,47 @@ func (c *Console) GetInteractive() *clientpb.Session {
 	return nil
 }
 
+// RefreshActiveSession - fetch the interactive session from server, e.g. to see modules and extensions loaded since use
 func (c *Console) RefreshActiveSession() {
-	if c.ActiveTarget != nil {
-		c.UpdateSession(c.ActiveTarget.session.SessionId)
+	if c.ActiveTarget == nil {
+		return
+	}
+	if session := c.ActiveTarget.Get(); session != nil {
+		if err := c.UpdateSession(session.SessionId); err == nil {
+			c.ActiveTarget.refresh(c.Sessions()[session.SessionId])
+		}
 	}
 }
 
 func (c *Console) SessionLog(sid string) *logs.Logger {
-	if ob, ok := c.Observers[sid]; ok {
+	c.observersLock.RLock()
+	ob, ok := c.Observers[sid]
+	c.observersLock.RUnlock()
+	if ok {
 		return ob.log
 	} else if c.ActiveTarget.GetInteractive() != nil {
-		return c.ActiveTarget.activeObserver.log
+		return c.ActiveTarget.activeObserver.Load().log
 	} else {
 		return MuteLog
 	}
 }
 
-// readConfig
-func (c *Console) readConfig() {
-	var yamlFile string
-
-	if len(os.Args) > 1 {
-		yamlFile = os.Args[1]
-	} else {
-		return
-	}
+// readConfig - login with the client config file
+func (c *Console) readConfig(yamlFile string) error {
 	clientFile, err := mtls.ReadConfig(yamlFile)
 	if err != nil {
 		logs.Log.Errorf("Error reading config file: %v", err)
-		return
+		return err
 	}
-	err = c.Login(clientFile)
+	err = c.Login(ServerName(yamlFile), clientFile)
 	if err != nil {
 		logs.Log.Errorf("Error login: %v", err)
-		return
-	}
-	err = assets.MvConfig(yamlFile)
-	if err != nil {
-		return
+		return err
 	}
+	return nil
+}
+
+// ServerName - name of the server connected by the config file, the file name without extension
+func ServerName(configFile string) string {
+	return strings.TrimSuffix(filepath.Base(configFile), filepath.Ext(configFile))
 }
@@ -5,18 +5,21 @@ import (
 	"github.com/chainreactors/logs"
 	"github.com/chainreactors/malice-network/proto/client/clientpb"
 	"google.golang.org/grpc/metadata"
+	"sync/atomic"
 )
 
 func NewObserver(session *clientpb.Session) *Observer {
-	return &Observer{
-		session: session,
-		log:     logs.NewLogger(LogLevel),
+	ob := &Observer{
+		log: logs.NewLogger(LogLevel),
 	}
+	ob.session.Store(session)
+	return ob
 }
 
 // Observer - A function to call when the sessions changes
 type Observer struct {
-	session *clientpb.Session
+	// session - replaced by the event loop with the session fetched after reconnect
+	session atomic.Pointer[clientpb.Session]
 	log     *logs.Logger
 }
 
@@ -25,32 +28,34 @@ func (o *Observer) Logger() *logs.Logger {
 }
 
 func (o *Observer) SessionId() string {
-	return o.session.SessionId
+	return o.session.Load().SessionId
 }
 
+// ActiveTarget - the session commands are sent to, session and observer are read by the event loop
 type ActiveTarget struct {
-	session        *clientpb.Session
-	activeObserver *Observer
+	session        atomic.Pointer[clientpb.Session]
+	activeObserver atomic.Pointer[Observer]
 	callback       func(*clientpb.Session)
 }
 
 func (s *ActiveTarget) GetInteractive() *clientpb.Session {
-	if s.session == nil {
+	session := s.session.Load()
+	if session == nil {
 		logs.Log.Warn("Please select a session or beacon via `use`")
 		return nil
 	}
-	return s.session
+	return session
 }
 
 // GetSessionInteractive - Get the active target(s)
 func (s *ActiveTarget) Get() *clientpb.Session {
-	return s.session
+	return s.session.Load()
 }
 
 func (s *ActiveTarget) Context() context.Context {
-	if s.session != nil {
+	if session := s.session.Load(); session != nil {
 		return metadata.NewOutgoingContext(context.Background(), metadata.Pairs(
-			"session_id", s.session.SessionId),
+			"session_id", session.SessionId),
 		)
 	} else {
 		return nil
@@ -59,13 +64,21 @@ func (s *ActiveTarget) Context() context.Context {
 
 // Set - Change the active session
 func (s *ActiveTarget) Set(session *clientpb.Session) {
-	s.session = session
+	s.session.Store(session)
 	s.callback(session)
 	return
 }
 
 // Background - Background the active session
 func (s *ActiveTarget) Background() {
-	s.session = nil
+	s.session.Store(nil)
 	s.callback(nil)
 }
+
+// refresh - replace the active session by its copy fetched again, the observer is kept
+func (s *ActiveTarget) refresh(session *clientpb.Session) {
+	s.session.Store(session)
+	if ob := s.activeObserver.Load(); ob != nil {
+		ob.session.Store(session)
+	}
+}
@@ -0,0 +1,42 @@
+package console
+
+import (
+	"fmt"
+	"github.com/chainreactors/grumble"
+	"github.com/chainreactors/malice-network/client/utils"
+	"github.com/chainreactors/tui"
+	"os"
+)
+
+// OutputFlags - `--json` and `--csv` of table commands, override the output format of settings
+func OutputFlags(f *grumble.Flags) {
+	f.BoolL("json", false, "print table as json")
+	f.BoolL("csv", false, "print table as csv")
+}
+
+// OutputFormat - output format of the command, flags of OutputFlags take precedence over settings
+func (c *Console) OutputFormat(ctx *grumble.Context) string {
+	if ctx != nil && ctx.Flags != nil {
+		if _, ok := ctx.Flags["json"]; ok && ctx.Flags.Bool("json") {
+			return utils.OutputJSON
+		}
+		if _, ok := ctx.Flags["csv"]; ok && ctx.Flags.Bool("csv") {
+			return utils.OutputCSV
+		}
+	}
+	if c.Settings != nil && c.Settings.OutputFormat != "" {
+		return c.Settings.OutputFormat
+	}
+	return utils.OutputText
+}
+
+// PrintTable - print static table in the output format
+func (c *Console) PrintTable(format string, tableModel *tui.TableModel) {
+	if format == utils.OutputText || format == "" {
+		fmt.Println(tableModel.View())
+		return
+	}
+	if err := utils.RenderTable(os.Stdout, format, tableModel.Columns, tableModel.Rows); err != nil {
+		Log.Errorf("Failed to render table: %s", err)
+	}
+}
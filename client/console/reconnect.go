@@ -0,0 +1,84 @@
+package console
+
+import (
+	"github.com/chainreactors/malice-network/client/utils"
+	"github.com/chainreactors/malice-network/helper/consts"
+	"github.com/chainreactors/malice-network/helper/mtls"
+	"time"
+)
+
+// reconnect - connect again with the client config until the server is back, then subscribe events again
+func (s *ServerStatus) reconnect(cause error) {
+	if s.closed.Load() {
+		return
+	}
+	s.Alive.Store(false)
+	if cause != nil {
+		s.eventLog().Warnf("Lost connection to server %s: %s, reconnecting", s.Addr, cause)
+	} else {
+		s.eventLog().Warnf("Lost connection to server %s, reconnecting", s.Addr)
+	}
+	if s.StateChanged != nil {
+		s.StateChanged(false)
+	}
+	for attempt := 0; ; attempt++ {
+		time.Sleep(utils.Backoff(attempt, consts.ReconnectMinBackoff, consts.ReconnectMaxBackoff))
+		if s.closed.Load() {
+			return
+		}
+		conn, err := mtls.Connect(s.config)
+		if err != nil {
+			Log.Debugf("Reconnect to %s failed: %s", s.Addr, err)
+			continue
+		}
+		old := s.conn.Load()
+		if err = s.connect(conn); err != nil {
+			Log.Debugf("Reconnect to %s failed: %s", s.Addr, err)
+			conn.Close()
+			continue
+		}
+		if old != nil {
+			old.Close()
+		}
+		break
+	}
+	s.Alive.Store(true)
+	s.eventLog().Importantf("Reconnected to server %s, %d sessions", s.Addr, len(s.Sessions()))
+	go s.EventHandler()
+	if s.StateChanged != nil {
+		s.StateChanged(true)
+	}
+}
+
+// Close - close the connection without reconnecting, used when the console logs in to another server
+func (s *ServerStatus) Close() {
+	s.closed.Store(true)
+	s.stateLock.Lock()
+	defer s.stateLock.Unlock()
+	if conn := s.conn.Load(); conn != nil {
+		conn.Close()
+	}
+}
+
+// stateChanged - update the prompt, after reconnect refresh the active and observed sessions and recover task outputs
+func (c *Console) stateChanged(server *ServerStatus, alive bool) {
+	if alive && server == c.ServerStatus {
+		sessions := server.Sessions()
+		if session := c.ActiveTarget.Get(); session != nil {
+			if sess, ok := sessions[session.SessionId]; ok {
+				c.ActiveTarget.refresh(sess)
+			} else {
+				Log.Warnf("Active session %s is not found after reconnect", session.SessionId)
+			}
+		}
+		for sid, ob := range c.observers() {
+			if sess, ok := sessions[sid]; ok {
+				ob.session.Store(sess)
+			}
+		}
+	}
+	if alive && c.pending == nil {
+		c.recoverCallbacks(server)
+	}
+	c.UpdatePrompt()
+}
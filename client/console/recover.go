@@ -0,0 +1,175 @@
+package console
+
+import (
+	"context"
+	"github.com/chainreactors/malice-network/client/assets"
+	"github.com/chainreactors/malice-network/proto/client/clientpb"
+	"github.com/chainreactors/malice-network/proto/implant/implantpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"sync"
+	"time"
+)
+
+// TaskHandler - output of a task type, used for tasks whose callback is lost by restart or reconnect
+type TaskHandler func(con *Console, sessionID string, msg proto.Message)
+
+var taskHandlers = map[string]TaskHandler{}
+
+// requestType - task type of generic module requests, all modules share the Request message
+var requestType = string(proto.MessageName(&implantpb.Request{}).Name())
+
+// RegisterTaskHandler - output handler of task type, the request message name of the task,
+// or the module name for generic module requests
+func RegisterTaskHandler(typ string, handler TaskHandler) {
+	taskHandlers[typ] = handler
+}
+
+// lookupTaskHandler - handler of the task type, tasks of generic module requests are handled by the module
+// named in the spite
+func lookupTaskHandler(typ string, msg proto.Message) TaskHandler {
+	if spite, ok := msg.(*implantpb.Spite); ok && typ == requestType && spite.Name != "" {
+		typ = spite.Name
+	}
+	if handler, ok := taskHandlers[typ]; ok {
+		return handler
+	}
+	return defaultTaskHandler
+}
+
+// defaultTaskHandler - print the spite of tasks without handler
+func defaultTaskHandler(con *Console, sessionID string, msg proto.Message) {
+	data, err := protojson.MarshalOptions{Multiline: true}.Marshal(msg)
+	if err != nil {
+		con.SessionLog(sessionID).Errorf("Failed to marshal task output: %s", err)
+		return
+	}
+	con.SessionLog(sessionID).Consolef("%s\n", data)
+}
+
+func (c *Console) taskHandler(task *clientpb.Task) TaskCallback {
+	return func(msg proto.Message) {
+		Log.Importantf("Recovered output of task %d %s of %s", task.TaskId, task.Type, task.SessionId)
+		lookupTaskHandler(task.Type, msg)(c, task.SessionId, msg)
+	}
+}
+
+// AddCallback - callback of the task, recorded by the session the task is sent to, to recover after the console
+// restarts or reconnects
+func (c *Console) AddCallback(task *clientpb.Task, callback TaskCallback) {
+	if task.SessionId != "" && c.pending == nil {
+		c.watchTask(task.SessionId, task.TaskId)
+	}
+	c.ServerStatus.AddCallback(task.SessionId, task.TaskId, callback)
+}
+
+func (c *Console) CancelCallback(sessionID string, taskId uint32) {
+	c.ServerStatus.CancelCallback(sessionID, taskId)
+	c.forgetTask(sessionID, taskId)
+}
+
+// RecoverCallbacks - re-attach output handlers to unfinished tasks of recorded and observed sessions,
+// and print the output of recorded or attached tasks finished while the console was away
+func (c *Console) RecoverCallbacks() {
+	c.recoverCallbacks(c.ServerStatus)
+}
+
+// recoverCallbacks - recover tasks of the server, observed sessions belong to the active server
+func (c *Console) recoverCallbacks(s *ServerStatus) {
+	s.waiting.Lock()
+	recorded := s.waiting.Server(s.Addr)
+	s.waiting.Unlock()
+	waiting := map[string]map[uint32]bool{}
+	for _, task := range recorded {
+		if waiting[task.SessionID] == nil {
+			waiting[task.SessionID] = map[uint32]bool{}
+		}
+		waiting[task.SessionID][task.TaskID] = true
+	}
+	if s == c.ServerStatus {
+		for sid := range c.observers() {
+			if waiting[sid] == nil {
+				waiting[sid] = map[uint32]bool{}
+			}
+		}
+		if session := c.ActiveTarget.Get(); session != nil && waiting[session.SessionId] == nil {
+			waiting[session.SessionId] = map[uint32]bool{}
+		}
+	}
+
+	for sid, taskIDs := range waiting {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		tasks, err := s.Rpc.GetTasks(ctx, &clientpb.Session{SessionId: sid})
+		cancel()
+		if err != nil {
+			if status.Code(err) == codes.Unavailable || status.Code(err) == codes.DeadlineExceeded {
+				Log.Warnf("Failed to recover tasks of %s: %s", sid, err)
+				continue
+			}
+			if len(taskIDs) > 0 {
+				Log.Warnf("Tasks of %s are lost by server, %d outputs dropped: %s", sid, len(taskIDs), err)
+			}
+			for taskID := range taskIDs {
+				s.forgetTask(sid, taskID)
+			}
+			continue
+		}
+		for _, task := range tasks.GetTasks() {
+			recordedTask := taskIDs[task.TaskId]
+			delete(taskIDs, task.TaskId)
+			finished := task.Total > 0 && task.Cur >= task.Total
+			_, attached := s.Callbacks.Load(taskKey{sid, task.TaskId})
+			if finished && !recordedTask && !attached {
+				continue
+			}
+			if !attached {
+				if !recordedTask {
+					s.watchTask(sid, task.TaskId)
+				}
+				s.AddCallback(sid, task.TaskId, c.taskHandler(task))
+			}
+			// task done event is missed while the console was away
+			if finished {
+				s.triggerTaskDone(&clientpb.Event{Task: task})
+			}
+		}
+		for taskID := range taskIDs {
+			Log.Warnf("Task %d of %s is lost by server, output dropped", taskID, sid)
+			s.forgetTask(sid, taskID)
+		}
+	}
+}
+
+// waitingTasks - waiting tasks of all servers, shared by the connections of the console
+type waitingTasks struct {
+	sync.Mutex
+	*assets.WaitingTasks
+}
+
+// watchTask - record the task until its output is shown
+func (s *ServerStatus) watchTask(sessionID string, taskID uint32) {
+	s.waiting.Lock()
+	defer s.waiting.Unlock()
+	s.waiting.Add(&assets.WaitingTask{
+		Server:    s.Addr,
+		SessionID: sessionID,
+		TaskID:    taskID,
+		CreatedAt: time.Now(),
+	})
+	if err := assets.SaveWaitingTasks(assets.GetWaitingTasksPath(), s.waiting.WaitingTasks); err != nil {
+		Log.Debugf("Failed to save waiting tasks: %s", err)
+	}
+}
+
+func (s *ServerStatus) forgetTask(sessionID string, taskID uint32) {
+	s.waiting.Lock()
+	defer s.waiting.Unlock()
+	if !s.waiting.Remove(s.Addr, sessionID, taskID) {
+		return
+	}
+	if err := assets.SaveWaitingTasks(assets.GetWaitingTasksPath(), s.waiting.WaitingTasks); err != nil {
+		Log.Debugf("Failed to save waiting tasks: %s", err)
+	}
+}
@@ -3,15 +3,21 @@ package console
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/chainreactors/logs"
+	"github.com/chainreactors/malice-network/client/assets"
 	"github.com/chainreactors/malice-network/helper/consts"
+	"github.com/chainreactors/malice-network/helper/mtls"
 	"github.com/chainreactors/malice-network/proto/client/clientpb"
 	"github.com/chainreactors/malice-network/proto/implant/implantpb"
 	"github.com/chainreactors/malice-network/proto/services/clientrpc"
 	"github.com/chainreactors/tui"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
 	"io"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -23,44 +29,77 @@ type Client struct {
 	*clientpb.Client
 }
 
-func InitServerStatus(conn *grpc.ClientConn) (*ServerStatus, error) {
-	var err error
+func InitServerStatus(conn *grpc.ClientConn, config *mtls.ClientConfig) (*ServerStatus, error) {
 	s := &ServerStatus{
-		Rpc:       clientrpc.NewMaliceRPCClient(conn),
-		Sessions:  make(map[string]*clientpb.Session),
-		Alive:     true,
-		Callbacks: &sync.Map{},
+		sessions:       make(map[string]*clientpb.Session),
+		Callbacks:      &sync.Map{},
+		BatchCallbacks: &sync.Map{},
+		waiting:        &waitingTasks{WaitingTasks: &assets.WaitingTasks{}},
+		config:         config,
+		Addr:           fmt.Sprintf("%s:%d", config.LHost, config.LPort),
 	}
-
-	s.Info, err = s.Rpc.GetBasic(context.Background(), &clientpb.Empty{})
+	// the client is kept across reconnects, calls go to the current connection
+	s.Rpc = clientrpc.NewMaliceRPCClient(&s.conn)
+	err := s.connect(conn)
 	if err != nil {
 		return nil, err
 	}
+	s.Alive.Store(true)
+
+	go s.EventHandler()
 
-	clients, err := s.Rpc.GetClients(context.Background(), &clientpb.Empty{})
+	return s, nil
+}
+
+// connect - refresh the server state by the connection, the state is swapped in only after all of it is fetched
+func (s *ServerStatus) connect(conn *grpc.ClientConn) error {
+	rpc := clientrpc.NewMaliceRPCClient(conn)
+	info, err := rpc.GetBasic(context.Background(), &clientpb.Empty{})
 	if err != nil {
-		return nil, err
+		return err
 	}
+
+	clients, err := rpc.GetClients(context.Background(), &clientpb.Empty{})
+	if err != nil {
+		return err
+	}
+	var newClients []*Client
 	for _, client := range clients.GetClients() {
-		s.Clients = append(s.Clients, &Client{client})
+		newClients = append(newClients, &Client{client})
 	}
 
-	listeners, err := s.Rpc.GetListeners(context.Background(), &clientpb.Empty{})
+	listeners, err := rpc.GetListeners(context.Background(), &clientpb.Empty{})
 	if err != nil {
-		return nil, err
+		return err
 	}
+	var newListeners []*Listener
 	for _, listener := range listeners.GetListeners() {
-		s.Listeners = append(s.Listeners, &Listener{listener})
+		newListeners = append(newListeners, &Listener{listener})
 	}
 
-	err = s.UpdateSessions(true)
+	sessions, err := fetchSessions(rpc, true)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	go s.EventHandler()
+	s.stateLock.Lock()
+	defer s.stateLock.Unlock()
+	s.Info, s.Clients, s.Listeners, s.sessions = info, newClients, newListeners, sessions
+	s.conn.Store(conn)
+	return nil
+}
 
-	return s, nil
+// serverConn - connection of the server replaced on reconnect, Rpc is built on it once and never replaced
+type serverConn struct {
+	atomic.Pointer[grpc.ClientConn]
+}
+
+func (c *serverConn) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	return c.Load().Invoke(ctx, method, args, reply, opts...)
+}
+
+func (c *serverConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return c.Load().NewStream(ctx, desc, method, opts...)
 }
 
 type ServerStatus struct {
@@ -68,44 +107,94 @@ type ServerStatus struct {
 	Info      *clientpb.Basic
 	Clients   []*Client
 	Listeners []*Listener
-	Sessions  map[string]*clientpb.Session
+	// sessions - guarded by stateLock, read by Sessions
+	sessions map[string]*clientpb.Session
+	// Callbacks - taskKey -> TaskCallback
 	Callbacks *sync.Map
-	Alive     bool
+	// BatchCallbacks - batch id -> BatchCallback, called once all tasks of the batch end
+	BatchCallbacks *sync.Map
+	Alive          atomic.Bool
+	// pending - task id -> TaskCallback in wait mode, called by the waiter instead of task events
+	pending *sync.Map
+	// Name - name of the connection, sessions of other servers are referred as name/session_id
+	Name string
+	// Addr - host:port of the server, waiting tasks are recorded per server
+	Addr    string
+	waiting *waitingTasks
+	// StateChanged - called after the connection is lost or recovered
+	StateChanged func(alive bool)
+	conn         serverConn
+	config       *mtls.ClientConfig
+	closed       atomic.Bool
+	// stateLock - serializes replacing the state fetched from the server, sessions is copied on write
+	stateLock sync.Mutex
+	// label - event log prefixed by the server name, set while more than one server is connected
+	label atomic.Pointer[logs.Logger]
 }
 
 func (s *ServerStatus) UpdateSessions(all bool) error {
-	var sessions *clientpb.Sessions
-	var err error
-	if all {
-		sessions, err = s.Rpc.GetSessions(context.Background(), &clientpb.Empty{})
-	} else {
-		sessions, err = s.Rpc.GetAlivedSessions(context.Background(), &clientpb.Empty{})
-	}
+	sessions, err := fetchSessions(s.Rpc, all)
 	if err != nil {
 		return err
 	}
-
-	newSessions := make(map[string]*clientpb.Session)
-
-	for _, session := range sessions.GetSessions() {
-		newSessions[session.SessionId] = session
-	}
-
-	s.Sessions = newSessions
+	s.stateLock.Lock()
+	s.sessions = sessions
+	s.stateLock.Unlock()
 	return nil
 }
 
+// Sessions - sessions of the server by id, the map is replaced on update and must not be modified
+func (s *ServerStatus) Sessions() map[string]*clientpb.Session {
+	s.stateLock.Lock()
+	defer s.stateLock.Unlock()
+	return s.sessions
+}
+
 func (s *ServerStatus) UpdateSession(sid string) error {
 	session, err := s.Rpc.GetSession(context.Background(), &clientpb.SessionRequest{SessionId: sid})
 	if err != nil {
 		return err
 	}
 
-	s.Sessions[session.SessionId] = session
+	s.SetSessions(session)
 	return nil
 
 }
 
+// SetSessions - replace the sessions by a copy of the map, maps read by other goroutines are never modified
+func (s *ServerStatus) SetSessions(sessions ...*clientpb.Session) {
+	s.stateLock.Lock()
+	defer s.stateLock.Unlock()
+	newSessions := make(map[string]*clientpb.Session, len(s.sessions)+len(sessions))
+	for id, session := range s.sessions {
+		newSessions[id] = session
+	}
+	for _, session := range sessions {
+		newSessions[session.SessionId] = session
+	}
+	s.sessions = newSessions
+}
+
+func fetchSessions(rpc clientrpc.MaliceRPCClient, all bool) (map[string]*clientpb.Session, error) {
+	var sessions *clientpb.Sessions
+	var err error
+	if all {
+		sessions, err = rpc.GetSessions(context.Background(), &clientpb.Empty{})
+	} else {
+		sessions, err = rpc.GetAlivedSessions(context.Background(), &clientpb.Empty{})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	newSessions := make(map[string]*clientpb.Session)
+
+	for _, session := range sessions.GetSessions() {
+		newSessions[session.SessionId] = session
+	}
+	return newSessions, nil
+}
+
 func (s *ServerStatus) UpdateTasks(session *clientpb.Session) error {
 	if session == nil {
 		return errors.New("session is nil")
@@ -119,12 +208,100 @@ func (s *ServerStatus) UpdateTasks(session *clientpb.Session) error {
 	return nil
 }
 
-func (s *ServerStatus) CancelCallback(taskId uint32) {
-	s.Callbacks.Delete(taskId)
+// taskKey - task ids are counted per session, tasks of a server are identified by session and task id
+type taskKey struct {
+	sessionID string
+	taskID    uint32
+}
+
+func (s *ServerStatus) CancelCallback(sessionID string, taskId uint32) {
+	s.Callbacks.Delete(taskKey{sessionID, taskId})
+}
+
+func (s *ServerStatus) AddCallback(sessionID string, taskId uint32, callback TaskCallback) {
+	if s.pending != nil {
+		s.pending.Store(taskId, callback)
+		return
+	}
+	s.Callbacks.Store(taskKey{sessionID, taskId}, callback)
+}
+
+// EnableWait - keep callbacks for WaitTask instead of triggering them by task events, used without the shell
+func (s *ServerStatus) EnableWait() {
+	s.pending = &sync.Map{}
+}
+
+type PendingTask struct {
+	TaskId   uint32
+	Callback TaskCallback
+}
+
+// TakePending - tasks added since the last call in wait mode, ordered by task id
+func (s *ServerStatus) TakePending() []*PendingTask {
+	var tasks []*PendingTask
+	if s.pending == nil {
+		return tasks
+	}
+	s.pending.Range(func(key, value any) bool {
+		s.pending.Delete(key)
+		tasks = append(tasks, &PendingTask{TaskId: key.(uint32), Callback: value.(TaskCallback)})
+		return true
+	})
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].TaskId < tasks[j].TaskId
+	})
+	return tasks
+}
+
+// WaitTask - wait until the task is finished, error status of the task is logged and returned wrapping ErrTaskFailed
+func (s *ServerStatus) WaitTask(ctx context.Context, sessionID string, taskID uint32) (*implantpb.Spite, error) {
+	content, err := s.Rpc.WaitTaskContent(ctx, &clientpb.Task{
+		TaskId:    taskID,
+		SessionId: sessionID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if content.GetError() != 0 {
+		s.handleMaleficError(content)
+		return content, taskError(content)
+	}
+	if content.GetStatus().GetStatus() != 0 {
+		s.handleTaskError(content.GetStatus())
+		return content, taskError(content)
+	}
+	return content, nil
+}
+
+func (s *ServerStatus) AddBatchCallback(batchId uint32, callback BatchCallback) {
+	s.BatchCallbacks.Store(batchId, callback)
 }
 
-func (s *ServerStatus) AddCallback(taskId uint32, callback TaskCallback) {
-	s.Callbacks.Store(taskId, callback)
+func (s *ServerStatus) triggerBatch(event *clientpb.Event) {
+	batch := &clientpb.Batch{}
+	err := proto.Unmarshal(event.Data, batch)
+	if err != nil {
+		s.eventLog().Errorf("Failed to parse batch: %s", err)
+		return
+	}
+	if _, ok := s.BatchCallbacks.Load(batch.Id); !ok {
+		return
+	}
+	if !batch.Done {
+		s.eventLog().Infof("Batch %d %s: %d/%d done, %d failed", batch.Id, batch.Type, batch.Finished, batch.Total, batch.Failed)
+		return
+	}
+	s.eventLog().Importantf("Batch %d %s: all %d tasks done, %d failed", batch.Id, batch.Type, batch.Total, batch.Failed)
+	s.TriggerBatchCallback(batch)
+}
+
+// TriggerBatchCallback - run the callback of a done batch at most once, off the event loop
+func (s *ServerStatus) TriggerBatchCallback(batch *clientpb.Batch) {
+	callback, ok := s.BatchCallbacks.LoadAndDelete(batch.Id)
+	if !ok {
+		return
+	}
+	go callback.(BatchCallback)(batch)
 }
 
 func (s *ServerStatus) triggerTaskCallback(event *clientpb.Event) {
@@ -134,7 +311,8 @@ func (s *ServerStatus) triggerTaskCallback(event *clientpb.Event) {
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	if _, ok := s.Callbacks.Load(task.TaskId); ok {
+	key := taskKey{task.SessionId, task.TaskId}
+	if _, ok := s.Callbacks.Load(key); ok {
 		_, err := s.Rpc.GetTaskContent(ctx, &clientpb.Task{
 			TaskId:    task.TaskId,
 			SessionId: task.SessionId,
@@ -144,7 +322,7 @@ func (s *ServerStatus) triggerTaskCallback(event *clientpb.Event) {
 			return
 		}
 		//callback.(TaskCallback)(content)
-		s.Callbacks.Delete(task.TaskId)
+		s.Callbacks.Delete(key)
 	}
 }
 
@@ -155,7 +333,8 @@ func (s *ServerStatus) triggerTaskDone(event *clientpb.Event) {
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	if callback, ok := s.Callbacks.Load(task.TaskId); ok {
+	if callback, ok := s.Callbacks.LoadAndDelete(taskKey{task.SessionId, task.TaskId}); ok {
+		defer s.forgetTask(task.SessionId, task.TaskId)
 		content, err := s.Rpc.GetTaskContent(ctx, &clientpb.Task{
 			TaskId:    task.TaskId,
 			SessionId: task.SessionId,
@@ -163,6 +342,7 @@ func (s *ServerStatus) triggerTaskDone(event *clientpb.Event) {
 		Log.Console("\n")
 		if err != nil {
 			Log.Errorf(err.Error())
+			return
 		}
 		if content.GetError() != 0 {
 			s.handleMaleficError(content)
@@ -181,32 +361,35 @@ func (s *ServerStatus) EventHandler() {
 	eventStream, err := s.Rpc.Events(context.Background(), &clientpb.Empty{})
 	if err != nil {
 		logs.Log.Warnf("Error getting event stream: %v", err)
+		s.reconnect(err)
 		return
 	}
 	for {
 		event, err := eventStream.Recv()
 		if err == io.EOF || event == nil {
+			s.reconnect(err)
 			return
 		}
 
+		log := s.eventLog()
 		// Trigger event based on type
 		switch event.Type {
 
 		case consts.EventJoin:
 			tui.Clear()
-			Log.Infof("%s has joined the game", event.Client.Name)
+			log.Infof("%s has joined the game", event.Client.Name)
 		case consts.EventLeft:
 			tui.Clear()
-			Log.Infof("%s left the game", event.Client.Name)
+			log.Infof("%s left the game", event.Client.Name)
 		case consts.EventBroadcast:
 			tui.Clear()
-			Log.Infof("%s broadcasted: %s  %s", event.Source, string(event.Data), event.Err)
+			log.Infof("%s broadcasted: %s  %s", event.Source, string(event.Data), event.Err)
 		case consts.EventSession:
 			tui.Clear()
-			Log.Importantf("%s session: %s ", event.Session.SessionId, event.Message)
+			log.Importantf("%s session: %s ", event.Session.SessionId, event.Message)
 		case consts.EventNotify:
 			tui.Clear()
-			Log.Importantf("%s notified: %s %s", event.Source, string(event.Data), event.Err)
+			log.Importantf("%s notified: %s %s", event.Source, string(event.Data), event.Err)
 		case consts.EventTaskCallback:
 			tui.Clear()
 			s.triggerTaskCallback(event)
@@ -216,48 +399,96 @@ func (s *ServerStatus) EventHandler() {
 		case consts.EventPipeline:
 			tui.Clear()
 			if event.GetErr() != "" {
-				Log.Errorf("Pipeline error: %s", event.GetErr())
-				return
+				log.Errorf("Pipeline error: %s", event.GetErr())
+				continue
 			}
-			Log.Importantf("Pipeline: %s", event.Message)
+			log.Importantf("Pipeline: %s", event.Message)
 		case consts.EventWebsite:
 			tui.Clear()
 			if event.GetErr() != "" {
-				Log.Errorf("Website error: %s", event.GetErr())
-				return
+				log.Errorf("Website error: %s", event.GetErr())
+				continue
+			}
+			log.Importantf("Website: %s", event.Message)
+		case consts.EventBatch:
+			tui.Clear()
+			s.triggerBatch(event)
+		case consts.EventCert:
+			tui.Clear()
+			if event.GetErr() != "" {
+				log.Errorf("Cert error: %s", event.GetErr())
+				continue
+			}
+			log.Warnf("Cert: %s", event.Message)
+		case consts.EventSchedule:
+			tui.Clear()
+			if event.GetErr() != "" {
+				log.Errorf("Schedule error: %s, %s", event.Message, event.GetErr())
+				continue
+			}
+			log.Importantf("Schedule: %s", event.Message)
+		case consts.EventQueue:
+			tui.Clear()
+			if event.GetErr() != "" {
+				log.Warnf("Queue: %s, %s", event.Message, event.GetErr())
+				continue
 			}
-			Log.Importantf("Website: %s", event.Message)
+			log.Importantf("Queue: %s", event.Message)
+		case consts.EventShutdown:
+			tui.Clear()
+			log.Warnf("Server: %s", event.Message)
 		}
 		//con.triggerReactions(event)
 	}
 }
 
 func (s *ServerStatus) handleMaleficError(content *implantpb.Spite) {
-	switch content.Error {
+	Log.Error(maleficErrorText(content.Error))
+	if content.Error == consts.MaleficErrorTaskError {
+		s.handleTaskError(content.Status)
+	}
+}
+
+// maleficErrorText - description of the error code reported by implant
+func maleficErrorText(code uint32) string {
+	switch code {
 	case consts.MaleficErrorPanic:
-		Log.Errorf("Module Panic")
+		return "Module Panic"
 	case consts.MaleficErrorUnpackError:
-		Log.Errorf("Module unpack error")
+		return "Module unpack error"
 	case consts.MaleficErrorMissbody:
-		Log.Errorf("Module miss body")
+		return "Module miss body"
 	case consts.MaleficErrorModuleError:
-		Log.Errorf("Module error")
+		return "Module error"
 	case consts.MaleficErrorModuleNotFound:
-		Log.Errorf("Module not found")
+		return "Module not found"
 	case consts.MaleficErrorTaskError:
-		Log.Errorf("Task error")
-		s.handleTaskError(content.Status)
+		return "Task error"
 	case consts.MaleficErrorTaskNotFound:
-		Log.Errorf("Task not found")
+		return "Task not found"
 	case consts.MaleficErrorTaskOperatorNotFound:
-		Log.Errorf("Task operator not found")
+		return "Task operator not found"
 	case consts.MaleficErrorExtensionNotFound:
-		Log.Errorf("Extension not found")
+		return "Extension not found"
 	case consts.MaleficErrorUnexceptBody:
-		Log.Errorf("Unexcept body")
+		return "Unexcept body"
 	default:
-		Log.Errorf("unknown Malefic error, %d", content.Error)
+		return fmt.Sprintf("unknown Malefic error, %d", code)
+	}
+}
+
+// taskError - ErrTaskFailed with the error reported by implant
+func taskError(content *implantpb.Spite) error {
+	if content.GetError() != 0 {
+		if content.GetError() == consts.MaleficErrorTaskError && content.GetStatus().GetError() != "" {
+			return fmt.Errorf("%w: %s", ErrTaskFailed, content.GetStatus().GetError())
+		}
+		return fmt.Errorf("%w: %s", ErrTaskFailed, maleficErrorText(content.GetError()))
+	}
+	if msg := content.GetStatus().GetError(); msg != "" {
+		return fmt.Errorf("%w: %s", ErrTaskFailed, msg)
 	}
+	return fmt.Errorf("%w: status %d", ErrTaskFailed, content.GetStatus().GetStatus())
 }
 
 func (s *ServerStatus) handleTaskError(status *implantpb.Status) {
@@ -278,3 +509,11 @@ func (s *ServerStatus) handleTaskError(status *implantpb.Status) {
 		Log.Errorf("unknown error, %v", status)
 	}
 }
+
+// eventLog - log of events, labeled by the server name while more than one server is connected
+func (s *ServerStatus) eventLog() *logs.Logger {
+	if label := s.label.Load(); label != nil {
+		return label
+	}
+	return Log
+}
@@ -0,0 +1,126 @@
+package console
+
+import (
+	"fmt"
+	"github.com/chainreactors/logs"
+	"github.com/chainreactors/malice-network/client/assets"
+	"github.com/chainreactors/malice-network/client/utils"
+	"github.com/chainreactors/malice-network/proto/client/clientpb"
+	"sort"
+	"strings"
+)
+
+// Operator - operator name of the client config
+func (s *ServerStatus) Operator() string {
+	return s.config.Operator
+}
+
+// ServerNames - names of connected servers, sorted
+func (c *Console) ServerNames() []string {
+	names := utils.Keys(c.Servers)
+	sort.Strings(names)
+	return names
+}
+
+// UseServer - switch commands to the connected server, the active session and observers of the previous server are dropped
+func (c *Console) UseServer(name string) error {
+	server, ok := c.Servers[name]
+	if !ok {
+		return fmt.Errorf("server %s is not connected", name)
+	}
+	c.useServer(server)
+	return nil
+}
+
+func (c *Console) useServer(server *ServerStatus) {
+	if c.ServerStatus == server {
+		return
+	}
+	c.ServerStatus = server
+	c.observersLock.Lock()
+	c.Observers = map[string]*Observer{}
+	c.observersLock.Unlock()
+	if c.ActiveTarget.Get() != nil {
+		c.ActiveTarget.Background()
+		c.DisableImplantCommands()
+	}
+	c.UpdatePrompt()
+}
+
+// addServer - register the connection by name, the connection of the same name is replaced
+func (c *Console) addServer(name string, server *ServerStatus) {
+	server.Name = name
+	server.StateChanged = func(alive bool) {
+		c.stateChanged(server, alive)
+	}
+	if old, ok := c.Servers[name]; ok {
+		old.Close()
+	}
+	// waiting tasks of all servers are saved to the same file
+	var err error
+	if len(c.Servers) > 0 {
+		for _, other := range c.Servers {
+			server.waiting = other.waiting
+			break
+		}
+	} else if server.waiting.WaitingTasks, err = assets.LoadWaitingTasks(assets.GetWaitingTasksPath()); err != nil {
+		logs.Log.Warnf("Failed to read waiting tasks: %v", err)
+	}
+	c.Servers[name] = server
+	for _, s := range c.Servers {
+		if len(c.Servers) > 1 {
+			s.label.Store(labeledLog(s.Name))
+		} else {
+			s.label.Store(nil)
+		}
+	}
+}
+
+// SessionName - session id prefixed by the server name, only when more than one server is connected
+func (c *Console) SessionName(server *ServerStatus, sid string) string {
+	if len(c.Servers) > 1 && server != nil {
+		return server.Name + "/" + sid
+	}
+	return sid
+}
+
+// FindSession - session by id of the active server, or by `server/id` of any connected server
+func (c *Console) FindSession(id string) (*ServerStatus, *clientpb.Session) {
+	server := c.ServerStatus
+	if name, sid, ok := strings.Cut(id, "/"); ok {
+		server, id = c.Servers[name], sid
+	}
+	if server == nil {
+		return nil, nil
+	}
+	session, ok := server.Sessions()[id]
+	if !ok {
+		return nil, nil
+	}
+	return server, session
+}
+
+// SessionNames - namespaced ids of sessions of all connected servers, for completion
+func (c *Console) SessionNames(prefix string) []string {
+	var results []string
+	for _, name := range c.ServerNames() {
+		server := c.Servers[name]
+		for _, session := range server.Sessions() {
+			if id := c.SessionName(server, session.SessionId); strings.HasPrefix(id, prefix) {
+				results = append(results, id)
+			}
+		}
+	}
+	return results
+}
+
+// labeledLog - event log of the server, lines are prefixed by the server name
+func labeledLog(name string) *logs.Logger {
+	log := logs.NewLogger(LogLevel)
+	style := make(map[logs.Level]string, len(utils.DefaultLogStyle))
+	for level, format := range utils.DefaultLogStyle {
+		style[level] = strings.Replace(format, "%s", "["+name+"] %s", 1)
+	}
+	log.SetFormatter(style)
+	return log
+}
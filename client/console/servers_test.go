@@ -0,0 +1,138 @@
+package console
+
+import (
+	"github.com/chainreactors/malice-network/client/assets"
+	"github.com/chainreactors/malice-network/proto/client/clientpb"
+	"google.golang.org/protobuf/proto"
+	"sync"
+	"testing"
+)
+
+// testServer - server status without connection, holding the given session ids
+func testServer(sids ...string) *ServerStatus {
+	s := &ServerStatus{
+		sessions:       map[string]*clientpb.Session{},
+		Callbacks:      &sync.Map{},
+		BatchCallbacks: &sync.Map{},
+		waiting:        &waitingTasks{WaitingTasks: &assets.WaitingTasks{}},
+	}
+	for _, sid := range sids {
+		s.sessions[sid] = &clientpb.Session{SessionId: sid}
+	}
+	return s
+}
+
+func testConsole(servers map[string]*ServerStatus, active string) *Console {
+	con := &Console{Servers: map[string]*ServerStatus{}}
+	for _, name := range []string{"a", "b"} {
+		if server, ok := servers[name]; ok {
+			con.addServer(name, server)
+		}
+	}
+	con.ServerStatus = con.Servers[active]
+	return con
+}
+
+func TestFindSession(t *testing.T) {
+	a, b := testServer("s1", "s2"), testServer("s3")
+	con := testConsole(map[string]*ServerStatus{"a": a, "b": b}, "a")
+
+	for _, c := range []struct {
+		id      string
+		server  *ServerStatus
+		session string
+	}{
+		{"s1", a, "s1"},
+		{"a/s2", a, "s2"},
+		{"b/s3", b, "s3"},
+		{"s3", nil, ""},
+		{"b/s1", nil, ""},
+		{"c/s1", nil, ""},
+		{"", nil, ""},
+	} {
+		server, session := con.FindSession(c.id)
+		if server != c.server || session.GetSessionId() != c.session {
+			t.Errorf("FindSession(%q) = %v, %q, expect %v, %q", c.id, server, session.GetSessionId(), c.server, c.session)
+		}
+	}
+}
+
+func TestSessionName(t *testing.T) {
+	a, b := testServer("s1"), testServer("s2")
+	single := testConsole(map[string]*ServerStatus{"a": a}, "a")
+	multi := testConsole(map[string]*ServerStatus{"a": testServer("s1"), "b": b}, "a")
+
+	for _, c := range []struct {
+		name   string
+		con    *Console
+		server *ServerStatus
+		sid    string
+		expect string
+	}{
+		{"single server", single, a, "s1", "s1"},
+		{"multiple servers", multi, b, "s2", "b/s2"},
+		{"without server", multi, nil, "s2", "s2"},
+	} {
+		if name := c.con.SessionName(c.server, c.sid); name != c.expect {
+			t.Errorf("%s: expect %q, got %q", c.name, c.expect, name)
+		}
+	}
+}
+
+func TestAddServer(t *testing.T) {
+	a, b := testServer(), testServer()
+	con := testConsole(map[string]*ServerStatus{"a": a}, "a")
+	if a.label.Load() != nil {
+		t.Error("single server is labeled")
+	}
+	con.addServer("b", b)
+
+	for _, c := range []struct {
+		name   string
+		server *ServerStatus
+	}{
+		{"a", a},
+		{"b", b},
+	} {
+		if c.server.Name != c.name {
+			t.Errorf("server %s is named %s", c.name, c.server.Name)
+		}
+		if c.server.waiting != a.waiting {
+			t.Errorf("server %s does not share waiting tasks", c.name)
+		}
+		if c.server.label.Load() == nil {
+			t.Errorf("server %s is not labeled", c.name)
+		}
+	}
+
+	// the connection of the same name is replaced and closed
+	replaced := testServer()
+	con.addServer("b", replaced)
+	if !b.closed.Load() || con.Servers["b"] != replaced || replaced.waiting != a.waiting {
+		t.Error("server b is not replaced")
+	}
+}
+
+func TestCallbacksOfSessions(t *testing.T) {
+	// task ids are counted per session, sessions of one server share them
+	s := testServer("s1", "s2")
+	var called []string
+	s.AddCallback("s1", 1, func(proto.Message) { called = append(called, "s1") })
+	s.AddCallback("s2", 1, func(proto.Message) { called = append(called, "s2") })
+
+	for _, sid := range []string{"s1", "s2"} {
+		callback, ok := s.Callbacks.Load(taskKey{sid, 1})
+		if !ok {
+			t.Fatalf("callback of %s replaced", sid)
+		}
+		callback.(TaskCallback)(nil)
+	}
+	if len(called) != 2 || called[0] != "s1" || called[1] != "s2" {
+		t.Errorf("unexpected callbacks %v", called)
+	}
+
+	s.CancelCallback("s1", 1)
+	if _, ok := s.Callbacks.Load(taskKey{"s2", 1}); !ok {
+		t.Error("callback of s2 canceled with s1")
+	}
+}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/charmbracelet/bubbles/table"
+	"io"
+	"regexp"
+	"strings"
+)
+
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+	OutputCSV  = "csv"
+)
+
+var (
+	OutputFormats = []string{OutputText, OutputJSON, OutputCSV}
+	ansiPattern   = regexp.MustCompile(`\x1b\[[0-9;]*[a-zA-Z]`)
+)
+
+// RenderTable - write rows as json array of objects keyed by column, or as csv with header, colors are stripped
+func RenderTable(w io.Writer, format string, columns []table.Column, rows []table.Row) error {
+	keys := make([]string, len(columns))
+	for i, column := range columns {
+		keys[i] = ColumnKey(column.Title)
+	}
+	switch format {
+	case OutputJSON:
+		objects := make([]map[string]string, 0, len(rows))
+		for _, row := range rows {
+			object := make(map[string]string, len(keys))
+			for i, key := range keys {
+				if i < len(row) {
+					object[key] = StripANSI(row[i])
+				} else {
+					object[key] = ""
+				}
+			}
+			objects = append(objects, object)
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(objects)
+	case OutputCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(keys); err != nil {
+			return err
+		}
+		for _, row := range rows {
+			record := make([]string, len(keys))
+			for i := range record {
+				if i < len(row) {
+					record[i] = StripANSI(row[i])
+				}
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		return fmt.Errorf("unknown output format %s", format)
+	}
+}
+
+// ColumnKey - snake case key of column title, "Remote Address" -> "remote_address"
+func ColumnKey(title string) string {
+	return strings.Join(strings.Fields(strings.ToLower(StripANSI(title))), "_")
+}
+
+func StripANSI(s string) string {
+	return ansiPattern.ReplaceAllString(s, "")
+}
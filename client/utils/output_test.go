@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/charmbracelet/bubbles/table"
+	"testing"
+)
+
+func TestRenderTable(t *testing.T) {
+	columns := []table.Column{
+		{Title: "ID", Width: 15},
+		{Title: "Remote Address", Width: 15},
+		{Title: "Health", Width: 15},
+	}
+	rows := []table.Row{
+		{"08d6c05a", "10.0.0.1:5555", "\x1b[32m[ALIVE]\x1b[0m"},
+		{"1b9a3e7f", "10.0.0.2:5555, 10.0.0.3:5555"},
+	}
+
+	var buf bytes.Buffer
+	if err := RenderTable(&buf, OutputJSON, columns, rows); err != nil {
+		t.Fatal(err)
+	}
+	var objects []map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &objects); err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 2 {
+		t.Fatalf("got %d objects", len(objects))
+	}
+	if objects[0]["remote_address"] != "10.0.0.1:5555" || objects[0]["health"] != "[ALIVE]" {
+		t.Errorf("unexpected object %v", objects[0])
+	}
+	if value, ok := objects[1]["health"]; !ok || value != "" {
+		t.Errorf("missing column of short row %v", objects[1])
+	}
+
+	buf.Reset()
+	if err := RenderTable(&buf, OutputCSV, columns, rows); err != nil {
+		t.Fatal(err)
+	}
+	expected := "id,remote_address,health\n" +
+		"08d6c05a,10.0.0.1:5555,[ALIVE]\n" +
+		"1b9a3e7f,\"10.0.0.2:5555, 10.0.0.3:5555\",\n"
+	if buf.String() != expected {
+		t.Errorf("unexpected csv\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := RenderTable(&buf, OutputJSON, columns, nil); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "[]\n" {
+		t.Errorf("empty table rendered as %q", buf.String())
+	}
+	if err := RenderTable(&buf, "xml", columns, rows); err == nil {
+		t.Error("unknown format accepted")
+	}
+}
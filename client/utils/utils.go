@@ -12,6 +12,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 var (
@@ -134,3 +135,15 @@ func Keys[M ~map[K]V, K comparable, V any](m M) []K {
 
 	return r
 }
+
+// Backoff - delay before the attempt, doubled from min until max
+func Backoff(attempt int, min, max time.Duration) time.Duration {
+	delay := min
+	for i := 0; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
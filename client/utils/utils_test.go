@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	expected := []time.Duration{
+		time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second,
+		16 * time.Second, 32 * time.Second, time.Minute, time.Minute,
+	}
+	for attempt, delay := range expected {
+		if got := Backoff(attempt, time.Second, time.Minute); got != delay {
+			t.Errorf("attempt %d: got %s, want %s", attempt, got, delay)
+		}
+	}
+	if got := Backoff(1000, time.Second, time.Minute); got != time.Minute {
+		t.Errorf("large attempt: got %s", got)
+	}
+}
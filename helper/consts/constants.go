@@ -21,6 +21,10 @@ const (
 	ClientPrompt = "IoM"
 )
 
+const (
+	Version = "v0.0.1"
+)
+
 // Group
 const (
 	GenericGroup   = "generic"
@@ -34,6 +38,7 @@ const (
 const (
 	MaxPacketLength = "server.config.packet_length"
 	AuditLevel      = "server.audit"
+	QueueExpiry     = "server.config.queue_expiry"
 )
 
 const (
@@ -44,10 +49,22 @@ const (
 
 // Time
 const (
-	DefaultMaxBodyLength   = 2 * 1024 * 1024 * 1024 // 2Gb
-	DefaultHTTPTimeout     = time.Minute
-	DefaultLongPollTimeout = time.Second
-	DefaultLongPollJitter  = time.Second
-	minPollTimeout         = time.Second
-	DefaultCacheJitter     = 60 * 60
+	DefaultMaxBodyLength        = 2 * 1024 * 1024 * 1024 // 2Gb
+	DefaultHTTPTimeout          = time.Minute
+	DefaultLongPollTimeout      = time.Second
+	DefaultLongPollJitter       = time.Second
+	minPollTimeout              = time.Second
+	DefaultCacheJitter          = 60 * 60
+	CertExpiryCheckJitter       = 24 * 60 * 60
+	CertExpiryWarning           = 30 * 24 * time.Hour
+	DefaultQueueExpiry          = 24 * time.Hour
+	QueueExpiryCheckJitter      = 60
+	WebContentExpiryCheckJitter = 60
+	BatchExpiry                 = time.Hour
+	BatchExpiryCheckJitter      = 60
+	ParserTimeout               = 10 * time.Second
+	ShutdownTimeout             = 5 * time.Second
+	ClientKeepalive             = 30 * time.Second
+	ReconnectMinBackoff         = time.Second
+	ReconnectMaxBackoff         = time.Minute
 )
@@ -13,4 +13,14 @@ const (
 	EventTaskDone     = "task_done"
 	EventTaskError    = "task_error"
 	EventWebsite      = "website"
+	EventCert         = "cert"
+	EventBatch        = "batch"
+	EventSchedule     = "schedule"
+	EventQueue        = "queue"
+	EventShutdown     = "shutdown"
+)
+
+// website event ops
+const (
+	WebsiteHit = "hit"
 )
@@ -55,6 +55,11 @@ const (
 	CommandArmoryUpdate     = "update"
 	CommandArmorySearch     = "search"
 	CommandArmoryLoad       = "load"
+	CommandArmoryAdd        = "add"
+	CommandArmoryMirror     = "mirror"
+	CommandArmoryHost       = "host"
+	CommandArmoryLock       = "lock"
+	CommandArmorySync       = "sync"
 	CommandExtension        = "extension"
 	CommandExtensionList    = "list"
 	CommandExtensionLoad    = "load"
@@ -68,6 +73,8 @@ const (
 	CtrlPipelineStop
 	CtrlWebsiteStart = 0 + iota
 	CtrlWebsiteStop
+	CtrlCertRotate
+	CtrlServerShutdown
 )
 
 // ctrl status
@@ -0,0 +1,82 @@
+package traffic
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+// CreateResultParser - Initialize an WASM runtime for a parser module, which exports
+// `parse(ptr, size) (ptr << 32 | size)` turning tool output into json records
+func CreateResultParser(name string, wasm []byte, logger TrafficEncoderLogCallback) (*ResultParser, error) {
+	ctx := context.Background()
+	wasmRuntime, mod, err := instantiate(ctx, name, wasm, logger, parserRuntimeConfig())
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(wasm)
+	return &ResultParser{
+		ID:      hex.EncodeToString(digest[:]),
+		Name:    name,
+		wasm:    wasm,
+		logger:  logger,
+		ctx:     ctx,
+		runtime: wasmRuntime,
+		mod:     mod,
+		parser:  mod.ExportedFunction("parse"),
+	}, nil
+}
+
+// parserRuntimeConfig - a parse running past its context is stopped by closing the module
+func parserRuntimeConfig() wazero.RuntimeConfig {
+	return newRuntimeConfig().WithCloseOnContextDone(true)
+}
+
+// ResultParser - parse extension and alias output using a wasm backend
+type ResultParser struct {
+	ID   string // sha256 of the wasm bin
+	Name string
+
+	lock    sync.Mutex
+	wasm    []byte
+	logger  TrafficEncoderLogCallback
+	ctx     context.Context
+	runtime wazero.Runtime
+	mod     api.Module
+
+	parser api.Function
+}
+
+// Parse - Parse output using the wasm backend, stopped once ctx is done.
+// The module closed by a stopped parse is instantiated again by the next call.
+func (p *ResultParser) Parse(ctx context.Context, data []byte) ([]byte, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.mod.IsClosed() {
+		if err := p.reset(); err != nil {
+			return nil, err
+		}
+	}
+	return call(ctx, p.mod, p.parser, data)
+}
+
+// reset - replace the closed module with a new runtime, called with lock held
+func (p *ResultParser) reset() error {
+	p.runtime.Close(p.ctx)
+	wasmRuntime, mod, err := instantiate(p.ctx, p.Name, p.wasm, p.logger, parserRuntimeConfig())
+	if err != nil {
+		return err
+	}
+	p.runtime, p.mod, p.parser = wasmRuntime, mod, mod.ExportedFunction("parse")
+	return nil
+}
+
+func (p *ResultParser) Close() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.runtime.Close(p.ctx)
+}
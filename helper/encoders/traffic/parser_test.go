@@ -0,0 +1,91 @@
+package traffic
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// echoWasm - module exporting memory, malloc, free and parse, parse returns its input
+var echoWasm = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+	// types: (i32) -> i32, (i32) -> (), (i32, i32) -> i64
+	0x01, 0x10, 0x03, 0x60, 0x01, 0x7f, 0x01, 0x7f, 0x60, 0x01, 0x7f, 0x00, 0x60, 0x02, 0x7f, 0x7f, 0x01, 0x7e,
+	// functions
+	0x03, 0x04, 0x03, 0x00, 0x01, 0x02,
+	// memory, 1 page
+	0x05, 0x03, 0x01, 0x00, 0x01,
+	// exports
+	0x07, 0x22, 0x04,
+	0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00,
+	0x06, 'm', 'a', 'l', 'l', 'o', 'c', 0x00, 0x00,
+	0x04, 'f', 'r', 'e', 'e', 0x00, 0x01,
+	0x05, 'p', 'a', 'r', 's', 'e', 0x00, 0x02,
+	// code
+	0x0a, 0x17, 0x03,
+	// malloc: i32.const 1024
+	0x05, 0x00, 0x41, 0x80, 0x08, 0x0b,
+	// free: nop
+	0x02, 0x00, 0x0b,
+	// parse: ptr << 32 | size
+	0x0c, 0x00, 0x20, 0x00, 0xad, 0x42, 0x20, 0x86, 0x20, 0x01, 0xad, 0x84, 0x0b,
+}
+
+// loopWasm - echoWasm whose parse never returns
+var loopWasm = append(append([]byte{}, echoWasm[:len(echoWasm)-25]...),
+	// code
+	0x0a, 0x14, 0x03,
+	0x05, 0x00, 0x41, 0x80, 0x08, 0x0b,
+	0x02, 0x00, 0x0b,
+	// parse: loop br 0 end, i64.const 0
+	0x09, 0x00, 0x03, 0x40, 0x0c, 0x00, 0x0b, 0x42, 0x00, 0x0b,
+)
+
+func TestResultParser(t *testing.T) {
+	parser, err := CreateResultParser("echo", echoWasm, func(string) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer parser.Close()
+
+	for _, input := range []string{`{"hosts":[{"hostname":"dc01"}]}`, "second"} {
+		output, err := parser.Parse(context.Background(), []byte(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(output) != input {
+			t.Errorf("unexpected output %q", output)
+		}
+	}
+
+	encoder, err := CreateTrafficEncoder("echo", echoWasm, func(string) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer encoder.Close()
+	if _, err := encoder.Encode([]byte("data")); err == nil {
+		t.Error("module without encode accepted")
+	}
+}
+
+func TestResultParserTimeout(t *testing.T) {
+	parser, err := CreateResultParser("loop", loopWasm, func(string) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer parser.Close()
+
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		start := time.Now()
+		if _, err := parser.Parse(ctx, []byte("output")); err == nil {
+			t.Fatal("endless parse returned")
+		}
+		cancel()
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Fatalf("parse stopped after %s", elapsed)
+		} else if elapsed < 50*time.Millisecond {
+			t.Fatalf("closed module not instantiated again, %v", err)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package traffic
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	wasi "github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+// instantiate - Initialize an WASM runtime using the provided module name, code, log callback and runtime config
+func instantiate(ctx context.Context, name string, wasm []byte, logger TrafficEncoderLogCallback, config wazero.RuntimeConfig) (wazero.Runtime, api.Module, error) {
+	wasmRuntime := wazero.NewRuntimeWithConfig(ctx, config)
+
+	// Build the runtime and expose helper functions
+	_, err := wasmRuntime.NewHostModuleBuilder(name).
+
+		// Rand function
+		NewFunctionBuilder().WithFunc(func() uint64 {
+		buf := make([]byte, 8)
+		rand.Read(buf)
+		return binary.LittleEndian.Uint64(buf)
+	}).Export("rand").
+
+		// Time function
+		NewFunctionBuilder().WithFunc(func() int64 {
+		return time.Now().UnixNano()
+	}).Export("time").
+
+		// Log function
+		NewFunctionBuilder().WithFunc(func(_ context.Context, m api.Module, offset, byteCount uint32) {
+		buf, ok := m.Memory().Read(offset, byteCount)
+		if !ok {
+			logger(fmt.Sprintf("Log error: Memory.Read(%d, %d) out of range", offset, byteCount))
+		}
+		logger(string(buf))
+	}).Export("log").Instantiate(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, err = wasi.Instantiate(ctx, wasmRuntime)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	compiledMod, err := wasmRuntime.CompileModule(ctx, wasm)
+	if err != nil {
+		return nil, nil, err
+	}
+	mod, err := wasmRuntime.InstantiateModule(ctx, compiledMod, wazero.NewModuleConfig())
+	if err != nil {
+		return nil, nil, err
+	}
+	return wasmRuntime, mod, nil
+}
+
+// call - copy data into wasm memory, call fn(ptr, size) and read the returned (ptr << 32 | size) buffer.
+// malloc and free are undocumented, but exported. See tinygo-org/tinygo#2788
+func call(ctx context.Context, mod api.Module, fn api.Function, data []byte) ([]byte, error) {
+	malloc, free := mod.ExportedFunction("malloc"), mod.ExportedFunction("free")
+	if fn == nil || malloc == nil || free == nil {
+		return nil, fmt.Errorf("wasm module does not export the required functions")
+	}
+	// Allocate a buffer in the wasm runtime for the input data
+	size := uint64(len(data))
+	buf, err := malloc.Call(ctx, size)
+	if err != nil {
+		return nil, err
+	}
+	bufPtr := buf[0]
+	defer free.Call(ctx, bufPtr)
+
+	// Copy input data into wasm memory
+	if !mod.Memory().Write(uint32(bufPtr), data) {
+		return nil, fmt.Errorf("Memory.Write(%d, %d) out of range of memory size %d",
+			bufPtr, size, mod.Memory().Size())
+	}
+
+	ptrSize, err := fn.Call(ctx, bufPtr, size)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the output buffer from wasm memory
+	resultPtr := uint32(ptrSize[0] >> 32)
+	resultSize := uint32(ptrSize[0])
+	result, ok := mod.Memory().Read(resultPtr, resultSize)
+	if !ok {
+		return nil, fmt.Errorf("Memory.Read(%d, %d) out of range of memory size %d",
+			resultPtr, resultSize, mod.Memory().Size())
+	}
+	// the view is overwritten by the next call
+	return append([]byte{}, result...), nil
+}
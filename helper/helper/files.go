@@ -139,3 +139,9 @@ func CalculateSHA256Checksum(filePath string) (string, error) {
 	checksum := hex.EncodeToString(hash.Sum(nil))
 	return checksum, nil
 }
+
+// SHA256Checksum - hex encoded sha256 of data
+func SHA256Checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
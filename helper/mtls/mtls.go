@@ -8,6 +8,7 @@ import (
 	"github.com/chainreactors/malice-network/helper/consts"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
 	"log"
 )
 
@@ -74,6 +75,12 @@ func Connect(config *ClientConfig) (*grpc.ClientConn, error) {
 	if err != nil {
 		return nil, err
 	}
+	// keepalive pings detect a dead connection, the console reconnects when the event stream breaks
+	options = append(options, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+		Time:                consts.ClientKeepalive,
+		Timeout:             consts.DefaultDuration,
+		PermitWithoutStream: true,
+	}))
 	ctx, cancel := context.WithTimeout(context.Background(), consts.DefaultDuration)
 	defer cancel()
 	connection, err := grpc.DialContext(ctx, fmt.Sprintf("%s:%d", config.LHost, config.LPort), options...)
@@ -64,6 +64,7 @@ func Execute() {
 
 	// start listeners
 	if opt.Listeners != nil {
+		configs.InitLog(opt.Listeners.LogConfig, "listener")
 		// init forwarder
 		clientConf, err := mtls.ReadConfig(opt.Listeners.Name + ".yaml")
 		if err != nil {
@@ -5,18 +5,23 @@ import (
 	"errors"
 	"fmt"
 	"github.com/chainreactors/logs"
+	"github.com/chainreactors/malice-network/helper/consts"
 	"github.com/chainreactors/malice-network/server/internal/certs"
 	"github.com/chainreactors/malice-network/server/internal/configs"
 	"github.com/chainreactors/malice-network/server/internal/core"
 	"github.com/chainreactors/malice-network/server/internal/db"
+	"github.com/chainreactors/malice-network/server/internal/metrics"
+	"github.com/chainreactors/malice-network/server/internal/website"
 	"github.com/chainreactors/malice-network/server/listener"
 	"github.com/chainreactors/malice-network/server/rpc"
 	"github.com/gookit/config/v2"
 	"github.com/gookit/config/v2/yaml"
 	"github.com/jessevdk/go-flags"
+	"google.golang.org/grpc"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
 func init() {
@@ -74,6 +79,9 @@ func Execute() {
 	if opt.Debug {
 		logs.Log.SetLevel(logs.Debug)
 	}
+	if opt.Server != nil {
+		configs.InitLog(opt.Server.LogConfig, "server")
+	}
 
 	db.Client = db.NewDBClient()
 	_, _, err = certs.ServerGenerateCertificate("root", true, opt.Listeners.Auth)
@@ -90,11 +98,42 @@ func Execute() {
 	//	rpc.DaemonStart(opt.Server, opt.Listeners)
 	//}
 
-	err = StartGrpc(opt.Server.GRPCPort)
+	grpcServer, err := StartGrpc(opt.Server.GRPCPort)
 	if err != nil {
 		logs.Log.Errorf("cannot start grpc , %s ", err.Error())
 		return
 	}
+	StartCertExpiryCheck()
+	err = rpc.StartScheduler()
+	if err != nil {
+		logs.Log.Errorf("cannot start scheduler , %s ", err.Error())
+	}
+	err = rpc.StartTaskQueue()
+	if err != nil {
+		logs.Log.Errorf("cannot start task queue , %s ", err.Error())
+	}
+	err = website.StartExpirySweep()
+	if err != nil {
+		logs.Log.Errorf("cannot start website content expiry , %s ", err.Error())
+	}
+	err = core.StartBatchSweep()
+	if err != nil {
+		logs.Log.Errorf("cannot start batch expiry , %s ", err.Error())
+	}
+	err = rpc.LoadParsers()
+	if err != nil {
+		logs.Log.Errorf("cannot load parsers , %s ", err.Error())
+	}
+
+	if opt.Server.MetricsConfig != nil && opt.Server.MetricsConfig.Enable {
+		core.RegisterServerMetrics()
+		core.RegisterListenerMetrics()
+		_, err = metrics.Start(opt.Server.MetricsConfig.Address(), opt.Server.MetricsConfig.Path)
+		if err != nil {
+			logs.Log.Errorf("cannot start metrics , %s ", err.Error())
+			return
+		}
+	}
 
 	// start listeners
 	if opt.Listeners.Auth != "" {
@@ -121,10 +160,10 @@ func Execute() {
 
 		signal.Stop(c)
 
-		for _, session := range core.Sessions.All() {
-			session.Save()
+		err := rpc.Shutdown(grpcServer, consts.ShutdownTimeout)
+		if err != nil {
+			logs.Log.Errorf("shutdown error, %s", err.Error())
 		}
-		core.GlobalTicker.RemoveAll()
 		cancel()
 		os.Exit(0)
 	}()
@@ -132,18 +171,47 @@ func Execute() {
 }
 
 // Start - Starts the server console
-func StartGrpc(port uint16) error {
+func StartGrpc(port uint16) (*grpc.Server, error) {
 	// start alive session
 	err := StartAliveSession()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, _, err = rpc.StartClientListener(port)
+	grpcServer, _, err := rpc.StartClientListener(port)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	return grpcServer, nil
+}
+
+// StartCertExpiryCheck - warn about certificates expiring soon at startup and then daily
+func StartCertExpiryCheck() {
+	check := func() {
+		expiring, parsed, err := certs.ExpiringCertificates(time.Now().Add(consts.CertExpiryWarning))
+		if err != nil {
+			logs.Log.Errorf("cannot check certificates expiry , %s ", err.Error())
+			return
+		}
+		for i, certificate := range expiring {
+			var msg string
+			if parsed[i].NotAfter.Before(time.Now()) {
+				msg = fmt.Sprintf("certificate %s expired at %s", certificate.CommonName, parsed[i].NotAfter.Format(time.DateTime))
+			} else {
+				msg = fmt.Sprintf("certificate %s expires at %s", certificate.CommonName, parsed[i].NotAfter.Format(time.DateTime))
+			}
+			logs.Log.Warn(msg)
+			core.EventBroker.Publish(core.Event{
+				EventType: consts.EventCert,
+				Message:   msg,
+			})
+		}
+	}
+	check()
+	_, err := core.GlobalTicker.Start(consts.CertExpiryCheckJitter, check)
+	if err != nil {
+		logs.Log.Errorf("cannot start certificates expiry check , %s ", err.Error())
 	}
-	return nil
 }
 
 func StartAliveSession() error {
@@ -161,6 +229,10 @@ func StartAliveSession() error {
 			if err != nil {
 				logs.Log.Debugf("cannot load session , %s ", err.Error())
 			}
+			err = db.RecoverSessionMeta(newSession)
+			if err != nil {
+				logs.Log.Errorf("cannot recover session group and tags , %s ", err.Error())
+			}
 			tasks, taskID, err := db.FindTaskAndMaxTasksID(session.SessionId)
 			if err != nil {
 				logs.Log.Errorf("cannot find max task id , %s ", err.Error())
@@ -174,6 +246,7 @@ func StartAliveSession() error {
 				}
 				newSession.Tasks.Add(newTask)
 			}
+			rpc.RecoverQueuedTasks(newSession)
 			core.Sessions.Add(newSession)
 		}
 	}
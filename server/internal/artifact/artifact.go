@@ -0,0 +1,119 @@
+package artifact
+
+import (
+	"errors"
+	"github.com/chainreactors/malice-network/helper/helper"
+	"github.com/chainreactors/malice-network/server/internal/configs"
+	"github.com/chainreactors/malice-network/server/internal/db"
+	"github.com/chainreactors/malice-network/server/internal/db/models"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+var (
+	ErrInvalidHash  = errors.New("invalid artifact hash, must be hex encoded sha256")
+	ErrHashMismatch = errors.New("artifact content does not match hash")
+
+	hashRegexp = regexp.MustCompile(`^[0-9a-f]{64}$`)
+)
+
+func getArtifactDir() (string, error) {
+	if _, err := os.Stat(configs.ArtifactPath); os.IsNotExist(err) {
+		err = os.MkdirAll(configs.ArtifactPath, 0700)
+		if err != nil {
+			return "", err
+		}
+	}
+	return configs.ArtifactPath, nil
+}
+
+func artifactPath(hash string) (string, error) {
+	if !hashRegexp.MatchString(hash) {
+		return "", ErrInvalidHash
+	}
+	dir, err := getArtifactDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, hash), nil
+}
+
+// Add - store content, the hash given by client is checked. Content already stored is not written again.
+func Add(artifact *models.Artifact, content []byte) (*models.Artifact, error) {
+	hash := helper.SHA256Checksum(content)
+	if artifact.Hash != "" && artifact.Hash != hash {
+		return nil, ErrHashMismatch
+	}
+	artifact.Hash = hash
+	artifact.Size = int64(len(content))
+	path, err := artifactPath(hash)
+	if err != nil {
+		return nil, err
+	}
+	if !helper.FileExists(path) {
+		if err = writeAtomic(path, content); err != nil {
+			return nil, err
+		}
+	}
+	if err = db.SaveArtifact(artifact); err != nil {
+		return nil, err
+	}
+	return artifact, nil
+}
+
+// writeAtomic - write to a temp file unique to this upload first, a half written artifact would be served by hash
+func writeAtomic(path string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Get - artifact info, content is not read
+func Get(hash string) (*models.Artifact, error) {
+	if !hashRegexp.MatchString(hash) {
+		return nil, ErrInvalidHash
+	}
+	return db.FindArtifact(hash)
+}
+
+// Read - content of artifact
+func Read(hash string) ([]byte, error) {
+	if _, err := Get(hash); err != nil {
+		return nil, err
+	}
+	path, err := artifactPath(hash)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+func List() ([]*models.Artifact, error) {
+	return db.ListArtifacts()
+}
+
+// Remove - delete artifact record and content
+func Remove(hash string) error {
+	path, err := artifactPath(hash)
+	if err != nil {
+		return err
+	}
+	if err = db.DeleteArtifact(hash); err != nil {
+		return err
+	}
+	if err = os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
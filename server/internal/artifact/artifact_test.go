@@ -0,0 +1,93 @@
+package artifact
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/chainreactors/malice-network/helper/helper"
+	"github.com/chainreactors/malice-network/server/internal/configs"
+	"github.com/chainreactors/malice-network/server/internal/db"
+	"github.com/chainreactors/malice-network/server/internal/db/models"
+	"gorm.io/gorm"
+)
+
+func TestArtifactStore(t *testing.T) {
+	dir := t.TempDir()
+	configs.ArtifactPath = filepath.Join(dir, "artifacts")
+	client, err := gorm.Open(db.Open(filepath.Join(dir, "malice.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.AutoMigrate(&models.Artifact{}); err != nil {
+		t.Fatal(err)
+	}
+	db.Client = client
+	defer db.Close()
+
+	content := []byte("MZ assembly")
+	hash := helper.SHA256Checksum(content)
+	if _, err := Add(&models.Artifact{Hash: hash[:63] + "0", Name: "bad"}, content); err != ErrHashMismatch {
+		t.Errorf("hash mismatch accepted, %v", err)
+	}
+	stored, err := Add(&models.Artifact{Name: "seatbelt", Type: "alias"}, content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Hash != hash || stored.Size != int64(len(content)) {
+		t.Errorf("unexpected artifact %+v", stored)
+	}
+	if _, err := Add(&models.Artifact{Hash: hash, Name: "again"}, content); err != nil {
+		t.Fatal(err)
+	}
+	if artifacts, _ := List(); len(artifacts) != 1 || artifacts[0].Name != "seatbelt" {
+		t.Errorf("same content stored twice, %v", artifacts)
+	}
+
+	data, err := Read(hash)
+	if err != nil || string(data) != string(content) {
+		t.Fatalf("unexpected content %q, %v", data, err)
+	}
+	if _, err := Read("../malice.db"); err != ErrInvalidHash {
+		t.Errorf("invalid hash accepted, %v", err)
+	}
+
+	if err := Remove(hash); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(configs.ArtifactPath, hash)); !os.IsNotExist(err) {
+		t.Error("artifact content not removed")
+	}
+	if _, err := Get(hash); err != gorm.ErrRecordNotFound {
+		t.Errorf("removed artifact still found, %v", err)
+	}
+}
+
+func TestWriteAtomicConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "artifact")
+	content := []byte("MZ assembly")
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- writeAtomic(path, content)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != string(content) {
+		t.Errorf("unexpected content %q, %v", data, err)
+	}
+	if tmps, _ := filepath.Glob(filepath.Join(dir, "*.tmp")); len(tmps) != 0 {
+		t.Errorf("temp files left %v", tmps)
+	}
+}
@@ -1,10 +1,17 @@
 package certs
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"github.com/chainreactors/logs"
+	"github.com/chainreactors/malice-network/server/internal/configs"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 
+	"golang.org/x/crypto/acme"
 	"golang.org/x/crypto/acme/autocert"
 )
 
@@ -15,6 +22,8 @@ const (
 
 var (
 	acmeLog = logs.Log
+
+	ErrACMEDomains = errors.New("acme requires at least one domain")
 )
 
 // GetACMEDir - Dir to store ACME certs
@@ -27,11 +36,41 @@ func GetACMEDir() string {
 	return acmePath
 }
 
-// GetACMEManager - Get an ACME cert/tls config with the certs
-func GetACMEManager(domain string) *autocert.Manager {
+// GetACMEManager - Get an ACME manager for the domains of config, certs are cached in GetACMEDir
+// and renewed by the manager before expiry
+func GetACMEManager(config *configs.TlsConfig) (*autocert.Manager, error) {
+	if len(config.Domains) == 0 {
+		return nil, ErrACMEDomains
+	}
 	acmeDir := GetACMEDir()
-	return &autocert.Manager{
-		Cache:  autocert.DirCache(acmeDir),
-		Prompt: autocert.AcceptTOS,
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(config.Domains...),
+		Email:      config.Email,
+	}
+	if config.AcmeDirectory != "" {
+		directory, err := url.Parse(config.AcmeDirectory)
+		if err != nil {
+			return nil, err
+		}
+		// keep account and certs of other directories (e.g. staging or pebble) apart
+		acmeDir = filepath.Join(acmeDir, directory.Hostname())
+		client := &acme.Client{DirectoryURL: config.AcmeDirectory}
+		if config.AcmeCA != "" {
+			caPem, err := os.ReadFile(config.AcmeCA)
+			if err != nil {
+				return nil, err
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(caPem) {
+				return nil, errors.New("invalid acme ca " + config.AcmeCA)
+			}
+			client.HTTPClient = &http.Client{
+				Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+			}
+		}
+		manager.Client = client
 	}
+	manager.Cache = autocert.DirCache(acmeDir)
+	return manager, nil
 }
@@ -0,0 +1,175 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"github.com/chainreactors/malice-network/server/internal/configs"
+	"github.com/chainreactors/malice-network/server/internal/db"
+	"github.com/chainreactors/malice-network/server/internal/db/models"
+	"os"
+	"path"
+	"time"
+)
+
+var (
+	ErrInvalidCertificate = errors.New("invalid certificate pem")
+	ErrCertAmbiguous      = errors.New("certificate name matches more than one certificate, use the id")
+)
+
+// CATypeName - readable name of ca type
+func CATypeName(caType int) string {
+	switch caType {
+	case OperatorCA:
+		return "operator"
+	case ListenerCA:
+		return "listener"
+	case ImplantCA:
+		return "implant"
+	case RootCA:
+		return "root"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseCertificatePEM - parse the first certificate of pem
+func ParseCertificatePEM(certPEM []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, ErrInvalidCertificate
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// Fingerprint - sha256 fingerprint of certificate
+func Fingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// ListCertificates - all certificates in the cert store
+func ListCertificates() ([]*models.Certificate, error) {
+	var certificates []*models.Certificate
+	err := db.Session().Order("created_at").Find(&certificates).Error
+	return certificates, err
+}
+
+// FindCertificate - find certificate by id or common name
+func FindCertificate(idOrName string) (*models.Certificate, error) {
+	certificate := &models.Certificate{}
+	err := db.Session().Where("id = ? OR common_name = ?", idOrName, idOrName).First(certificate).Error
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return nil, ErrCertDoesNotExist
+	}
+	return certificate, err
+}
+
+// FindPipelineCertificate - find pipeline certificate by id or common name, certificates of other
+// ca types (operator, listener, root) are never returned, a name matching several certificates is rejected
+func FindPipelineCertificate(idOrName string) (*models.Certificate, error) {
+	var certificates []*models.Certificate
+	err := db.Session().Where("ca_type = ?", ImplantCA).
+		Where("id = ? OR common_name = ?", idOrName, idOrName).
+		Limit(2).Find(&certificates).Error
+	if err != nil {
+		return nil, err
+	}
+	switch len(certificates) {
+	case 0:
+		return nil, ErrCertDoesNotExist
+	case 1:
+		return certificates[0], nil
+	default:
+		return nil, ErrCertAmbiguous
+	}
+}
+
+// PeerCAType - ca type of the verified peer certificate, the root client presents the root ca itself,
+// operator and listener certificates must be the ones issued by server
+func PeerCAType(cert *x509.Certificate) (int, error) {
+	ca, _, err := GetCertificateAuthority()
+	if err != nil {
+		return 0, err
+	}
+	if cert.Equal(ca) {
+		return RootCA, nil
+	}
+	for caType, commonName := range map[int]string{
+		OperatorCA: cert.Subject.CommonName,
+		ListenerCA: ListenerNamespace + "." + cert.Subject.CommonName,
+	} {
+		certPEM, _, err := GetRSACertificate(caType, commonName)
+		if err != nil {
+			continue
+		}
+		if issued, err := ParseCertificatePEM(certPEM); err == nil && issued.Equal(cert) {
+			return caType, nil
+		}
+	}
+	return 0, ErrCertDoesNotExist
+}
+
+// ImportCertificate - save uploaded certificate and key as a pipeline certificate
+func ImportCertificate(name string, cert, key []byte) (*models.Certificate, error) {
+	pair, err := tls.X509KeyPair(cert, key)
+	if err != nil {
+		return nil, err
+	}
+	keyType := RSAKey
+	if _, ok := pair.PrivateKey.(*ecdsa.PrivateKey); ok {
+		keyType = ECCKey
+	}
+	err = saveCertificate(ImplantCA, keyType, name, cert, key)
+	if err != nil {
+		return nil, err
+	}
+	return FindCertificate(name)
+}
+
+// GeneratePipelineCertificate - generate a pipeline certificate for cn signed by the root ca
+func GeneratePipelineCertificate(name, cn string) (*models.Certificate, error) {
+	cert, key := GenerateRSACertificate(ImplantCA, cn, false, false, nil)
+	err := saveCertificate(ImplantCA, RSAKey, name, cert, key)
+	if err != nil {
+		return nil, err
+	}
+	return FindCertificate(name)
+}
+
+// ExpiringCertificates - certificates expire before deadline
+func ExpiringCertificates(deadline time.Time) ([]*models.Certificate, []*x509.Certificate, error) {
+	certificates, err := ListCertificates()
+	if err != nil {
+		return nil, nil, err
+	}
+	var expiring []*models.Certificate
+	var parsed []*x509.Certificate
+	for _, certificate := range certificates {
+		cert, err := ParseCertificatePEM([]byte(certificate.CertificatePEM))
+		if err != nil {
+			continue
+		}
+		if cert.NotAfter.Before(deadline) {
+			expiring = append(expiring, certificate)
+			parsed = append(parsed, cert)
+		}
+	}
+	return expiring, parsed, nil
+}
+
+// RemoveListenerCertificate - remove cached certificate files of pipeline, the next
+// GenerateListenerCertificate saves config.CertFile or generates a new one
+func RemoveListenerCertificate(config *configs.TlsConfig) error {
+	for _, suffix := range []string{"_ca_cert.pem", "_crt.pem", "_key.pem"} {
+		err := os.Remove(path.Join(configs.ListenerPath, config.Name+suffix))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
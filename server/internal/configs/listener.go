@@ -24,6 +24,8 @@ type ListenerConfig struct {
 	TcpPipelines  []*TcpPipelineConfig  `config:"tcp"`
 	HttpPipelines []*HttpPipelineConfig `config:"http"`
 	Websites      []*WebsiteConfig      `config:"websites"`
+	LogConfig     *LogConfig            `config:"log"`
+	MetricsConfig *MetricsConfig        `config:"metrics"`
 }
 
 type TcpPipelineConfig struct {
@@ -48,6 +50,7 @@ type WebsiteConfig struct {
 	RootPath    string     `config:"rootPath"`
 	WebsiteName string     `config:"websiteName"`
 	Port        uint16     `config:"port"`
+	Host        string     `config:"host"`
 	TlsConfig   *TlsConfig `config:"tls"`
 }
 
@@ -63,6 +66,14 @@ type TlsConfig struct {
 	Validity string `config:"validity"`
 	CertFile string `config:"cert"`
 	KeyFile  string `config:"key"`
+
+	// acme, certificates of Domains are issued and renewed automatically
+	Acme          bool     `config:"acme"`
+	Domains       []string `config:"domains"`
+	Email         string   `config:"email"`
+	AcmeDirectory string   `config:"acme_directory"` // empty for Let's Encrypt
+	AcmeCA        string   `config:"acme_ca"`        // pem file trusted to connect acme directory, e.g. pebble.minica.pem
+	AcmeHTTPPort  uint16   `config:"acme_http_port"` // port serving HTTP-01 challenge, 0 for 80
 }
 
 func (t *TlsConfig) ToPkix() *pkix.Name {
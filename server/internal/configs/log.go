@@ -0,0 +1,298 @@
+package configs
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/chainreactors/logs"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+var (
+	currentLogConfig = &LogConfig{
+		Format:     LogFormatText,
+		MaxSize:    100,
+		MaxBackups: 5,
+	}
+	rotateWriters = map[string]*RotateWriter{}
+	rotateMu      sync.Mutex
+)
+
+// InitLog - apply log config, component is used as the component field of global logger in json mode
+func InitLog(cfg *LogConfig, component string) {
+	if cfg == nil {
+		return
+	}
+	if cfg.Format == "" {
+		cfg.Format = LogFormatText
+	}
+	currentLogConfig = cfg
+	if cfg.IsJSON() {
+		logs.Log.SetOutput(newJSONLineWriter(os.Stdout, component))
+		logs.Log.SetFormatter(jsonFormatter)
+	}
+}
+
+// LogEntry - structured log fields, shared by all components
+type LogEntry struct {
+	Ts        time.Time     `json:"ts"`
+	Level     string        `json:"level"`
+	Component string        `json:"component"`
+	Operator  string        `json:"operator,omitempty"`
+	SessionID string        `json:"session_id,omitempty"`
+	TaskID    uint32        `json:"task_id,omitempty"`
+	Method    string        `json:"method,omitempty"`
+	Duration  time.Duration `json:"-"`
+	Error     string        `json:"error,omitempty"`
+	Message   string        `json:"msg,omitempty"`
+}
+
+func (e *LogEntry) MarshalJSON() ([]byte, error) {
+	type entry LogEntry
+	return json.Marshal(&struct {
+		*entry
+		Duration float64 `json:"duration,omitempty"`
+	}{
+		entry:    (*entry)(e),
+		Duration: float64(e.Duration.Microseconds()) / 1000,
+	})
+}
+
+// String - text format of entry
+func (e *LogEntry) String() string {
+	var s strings.Builder
+	if e.SessionID != "" {
+		s.WriteString("[implant] ")
+	} else {
+		s.WriteString("[malice] ")
+	}
+	s.WriteString(e.Operator)
+	if e.Method != "" {
+		s.WriteString(" call " + e.Method)
+	}
+	if e.SessionID != "" {
+		s.WriteString(" with " + e.SessionID)
+	}
+	if e.TaskID != 0 {
+		fmt.Fprintf(&s, " task %d", e.TaskID)
+	}
+	if e.Message != "" {
+		s.WriteString(": " + e.Message)
+	}
+	if e.Duration != 0 {
+		fmt.Fprintf(&s, " (%s)", e.Duration)
+	}
+	if e.Error != "" {
+		s.WriteString(", error: " + e.Error)
+	}
+	return s.String()
+}
+
+// StructuredLog - logs.Logger compatible logger, which can also write LogEntry
+type StructuredLog struct {
+	*logs.Logger
+	component string
+	level     logs.Level
+	writer    io.Writer
+	json      bool
+}
+
+func (l *StructuredLog) IsJSON() bool {
+	return l.json
+}
+
+// Entry - write a structured entry, in text mode entry is rendered as a normal log line
+func (l *StructuredLog) Entry(level logs.Level, entry *LogEntry) {
+	if level < l.level {
+		return
+	}
+	if !l.json {
+		l.Logger.Log(level, entry.String())
+		return
+	}
+	if entry.Ts.IsZero() {
+		entry.Ts = time.Now()
+	}
+	entry.Level = level.Name()
+	entry.Component = l.component
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	l.writer.Write(append(content, '\n'))
+}
+
+func NewFileLog(filename string) *StructuredLog {
+	return newStructuredLog(filename, logs.Info, false)
+}
+
+func NewDebugLog(filename string) *StructuredLog {
+	return newStructuredLog(filename, logs.Debug, true)
+}
+
+func newStructuredLog(filename string, level logs.Level, console bool) *StructuredLog {
+	rotate := GetRotateWriter(path.Join(LogPath, fmt.Sprintf("%s.log", filename)))
+	var writer io.Writer = rotate
+	if console {
+		writer = io.MultiWriter(os.Stdout, rotate)
+	}
+	logger := logs.NewLogger(level)
+	l := &StructuredLog{
+		Logger:    logger,
+		component: filename,
+		level:     level,
+		writer:    writer,
+		json:      currentLogConfig.IsJSON(),
+	}
+	if l.json {
+		logger.SetOutput(newJSONLineWriter(writer, filename))
+		logger.SetFormatter(jsonFormatter)
+	} else {
+		logger.SetOutput(writer)
+	}
+	return l
+}
+
+// jsonFormatter - keep level name in front of message, jsonLineWriter split it to level field
+var jsonFormatter = map[logs.Level]string{
+	logs.Debug:     "debug\t%s",
+	logs.Warn:      "warn\t%s",
+	logs.Info:      "info\t%s",
+	logs.Error:     "error\t%s",
+	logs.Important: "important\t%s",
+}
+
+// jsonLineWriter - convert free-form lines of logs.Logger to json entries
+type jsonLineWriter struct {
+	w         io.Writer
+	component string
+}
+
+func newJSONLineWriter(w io.Writer, component string) io.Writer {
+	return &jsonLineWriter{w: w, component: component}
+}
+
+func (j *jsonLineWriter) Write(p []byte) (int, error) {
+	line := strings.TrimRight(string(p), "\r\n ")
+	entry := &LogEntry{
+		Ts:        time.Now(),
+		Level:     "info",
+		Component: j.component,
+	}
+	if level, msg, ok := strings.Cut(line, "\t"); ok {
+		entry.Level = level
+		line = msg
+	}
+	entry.Message = strings.TrimSpace(line)
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return 0, err
+	}
+	_, err = j.w.Write(append(content, '\n'))
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// GetRotateWriter - get or create the rotate writer of filename, writers of same file are shared
+func GetRotateWriter(filename string) *RotateWriter {
+	rotateMu.Lock()
+	defer rotateMu.Unlock()
+	if w, ok := rotateWriters[filename]; ok {
+		return w
+	}
+	w := NewRotateWriter(filename, int64(currentLogConfig.MaxSize)*1024*1024, currentLogConfig.MaxBackups)
+	rotateWriters[filename] = w
+	return w
+}
+
+// RotateWriter - file writer that rotates file to filename.1 ... filename.N when exceeds maxSize
+type RotateWriter struct {
+	filename   string
+	maxSize    int64
+	maxBackups int
+	size       int64
+	file       *os.File
+	mu         sync.Mutex
+}
+
+func NewRotateWriter(filename string, maxSize int64, maxBackups int) *RotateWriter {
+	return &RotateWriter{
+		filename:   filename,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+}
+
+func (w *RotateWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+	if w.maxSize > 0 && w.size+int64(len(p)) > w.maxSize && w.size > 0 {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *RotateWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+func (w *RotateWriter) open() error {
+	file, err := os.OpenFile(w.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	w.file = file
+	w.size = info.Size()
+	return nil
+}
+
+func (w *RotateWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	w.file = nil
+	if w.maxBackups > 0 {
+		os.Remove(fmt.Sprintf("%s.%d", w.filename, w.maxBackups))
+		for i := w.maxBackups - 1; i > 0; i-- {
+			os.Rename(fmt.Sprintf("%s.%d", w.filename, i), fmt.Sprintf("%s.%d", w.filename, i+1))
+		}
+		if err := os.Rename(w.filename, w.filename+".1"); err != nil {
+			return err
+		}
+	} else if err := os.Remove(w.filename); err != nil {
+		return err
+	}
+	return w.open()
+}
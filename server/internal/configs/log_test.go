@@ -0,0 +1,95 @@
+package configs
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"github.com/chainreactors/logs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotateWriter(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "rpc.log")
+	w := NewRotateWriter(filename, 16, 2)
+	defer w.Close()
+	for i := 0; i < 5; i++ {
+		if _, err := w.Write([]byte("0123456789\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{filename, filename + ".1", filename + ".2"} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() > 16 {
+			t.Fatalf("%s exceeds max size: %d", name, info.Size())
+		}
+	}
+	if _, err := os.Stat(filename + ".3"); !os.IsNotExist(err) {
+		t.Fatal("backups exceed max backups")
+	}
+}
+
+func TestStructuredLogJSON(t *testing.T) {
+	LogPath = t.TempDir()
+	InitLog(&LogConfig{Format: LogFormatJSON, MaxSize: 1}, "test")
+	defer func() {
+		currentLogConfig = &LogConfig{Format: LogFormatText, MaxSize: 100, MaxBackups: 5}
+	}()
+
+	l := NewFileLog("rpc")
+	l.Entry(logs.Info, &LogEntry{
+		Operator:  "admin",
+		SessionID: "sid",
+		TaskID:    3,
+		Method:    "/clientrpc.MaliceRPC/Execute",
+		Duration:  1500 * time.Microsecond,
+		Error:     errors.New("boom").Error(),
+	})
+	l.Infof("free %s", "form")
+	l.Debugf("filtered")
+
+	file, err := os.Open(filepath.Join(LogPath, "rpc.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var entries []map[string]any
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("invalid json line %q: %s", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expect 2 entries, got %d", len(entries))
+	}
+	first := entries[0]
+	for key, value := range map[string]any{
+		"component":  "rpc",
+		"level":      "info",
+		"operator":   "admin",
+		"session_id": "sid",
+		"task_id":    float64(3),
+		"method":     "/clientrpc.MaliceRPC/Execute",
+		"duration":   1.5,
+		"error":      "boom",
+	} {
+		if first[key] != value {
+			t.Errorf("field %s: expect %v, got %v", key, value, first[key])
+		}
+	}
+	if _, ok := first["ts"]; !ok {
+		t.Error("missing ts field")
+	}
+	if msg, _ := entries[1]["msg"].(string); !strings.HasPrefix(msg, "free form") {
+		t.Errorf("unexpected free-form message %q", msg)
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"github.com/chainreactors/logs"
 	"github.com/gookit/config/v2"
 	"gopkg.in/yaml.v3"
-	"io"
 	insecureRand "math/rand"
 	"os"
 	"path"
@@ -28,6 +27,7 @@ var (
 	CachePath                   = path.Join(TempPath, "cache")
 	ErrNoConfig                 = errors.New("no config found")
 	WebsitePath                 = path.Join(ServerRootPath, "web")
+	ArtifactPath                = path.Join(ServerRootPath, "artifacts")
 )
 
 func InitConfig() error {
@@ -43,6 +43,7 @@ func InitConfig() error {
 	os.MkdirAll(AuditPath, perm)
 	os.MkdirAll(CachePath, perm)
 	os.MkdirAll(WebsitePath, perm)
+	os.MkdirAll(ArtifactPath, perm)
 	os.MkdirAll(ListenerPath, perm)
 	return nil
 }
@@ -57,21 +58,6 @@ func GetServerConfig() *ServerConfig {
 	return s
 }
 
-func NewFileLog(filename string) *logs.Logger {
-	logger := logs.NewLogger(logs.Info)
-	logger.SetFile(path.Join(LogPath, fmt.Sprintf("%s.log", filename)))
-	logger.SetOutput(io.Discard)
-	logger.Init()
-	return logger
-}
-
-func NewDebugLog(filename string) *logs.Logger {
-	logger := logs.NewLogger(logs.Debug)
-	logger.SetFile(path.Join(LogPath, fmt.Sprintf("%s.log", filename)))
-	logger.Init()
-	return logger
-}
-
 func GetConfig(key string) any {
 	return config.Get("server.config." + key)
 }
@@ -89,11 +75,12 @@ func LoadConfig(filename string, v interface{}) error {
 }
 
 type ServerConfig struct {
-	GRPCPort     uint16        `config:"grpc_port" default:"5004"`
-	GRPCHost     string        `config:"grpc_host" default:"0.0.0.0"`
-	DaemonConfig *DaemonConfig `config:"daemon"`
-	LogConfig    *LogConfig    `config:"log" default:""`
-	MiscConfig   *MiscConfig   `config:"config" default:""`
+	GRPCPort      uint16         `config:"grpc_port" default:"5004"`
+	GRPCHost      string         `config:"grpc_host" default:"0.0.0.0"`
+	DaemonConfig  *DaemonConfig  `config:"daemon"`
+	LogConfig     *LogConfig     `config:"log" default:""`
+	MiscConfig    *MiscConfig    `config:"config" default:""`
+	MetricsConfig *MetricsConfig `config:"metrics"`
 }
 
 func (c *ServerConfig) Address() string {
@@ -127,6 +114,14 @@ type LogConfig struct {
 	GRPCUnaryPayloads  bool `json:"grpc_unary_payloads"`
 	GRPCStreamPayloads bool `json:"grpc_stream_payloads"`
 	TLSKeyLogger       bool `json:"tls_key_logger"`
+	// Format - text or json
+	Format     string `json:"format" config:"format" default:"text"`
+	MaxSize    int    `json:"max_size" config:"max_size" default:"100"`
+	MaxBackups int    `json:"max_backups" config:"max_backups" default:"5"`
+}
+
+func (c *LogConfig) IsJSON() bool {
+	return c != nil && c.Format == LogFormatJSON
 }
 
 // DaemonConfig - Configure daemon mode
@@ -135,8 +130,21 @@ type DaemonConfig struct {
 	Port int    `json:"port" default:"5001"`
 }
 
+// MetricsConfig - prometheus metrics endpoint
+type MetricsConfig struct {
+	Enable bool   `config:"enable" default:"false"`
+	Host   string `config:"host" default:"127.0.0.1"`
+	Port   uint16 `config:"port" default:"9100"`
+	Path   string `config:"path" default:"/metrics"`
+}
+
+func (c *MetricsConfig) Address() string {
+	return fmt.Sprintf("%s:%d", c.Host, c.Port)
+}
+
 type MiscConfig struct {
 	PacketLength int    `config:"packet_length" default:"4194304"`
+	QueueExpiry  int    `config:"queue_expiry" default:"86400"`
 	Certificate  string `config:"certificate" default:""`
 	PrivateKey   string `config:"certificate_key" default:""`
 }
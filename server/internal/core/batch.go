@@ -0,0 +1,171 @@
+package core
+
+import (
+	"fmt"
+	"github.com/chainreactors/malice-network/helper/consts"
+	"github.com/chainreactors/malice-network/proto/client/clientpb"
+	"google.golang.org/protobuf/proto"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// Batches - tasks broadcast to many sessions, tracked under one batch id
+	Batches = &batches{active: &sync.Map{}}
+	batchID uint32
+)
+
+type batches struct {
+	active *sync.Map
+}
+
+func (b *batches) Get(id uint32) *Batch {
+	if val, ok := b.active.Load(id); ok {
+		return val.(*Batch)
+	}
+	return nil
+}
+
+// sweep - remove batches finished longer than BatchExpiry ago
+func (b *batches) sweep() {
+	b.active.Range(func(key, value interface{}) bool {
+		if value.(*Batch).expired(consts.BatchExpiry) {
+			b.active.Delete(key)
+		}
+		return true
+	})
+}
+
+// StartBatchSweep - evict finished batches periodically
+func StartBatchSweep() error {
+	_, err := GlobalTicker.Start(consts.BatchExpiryCheckJitter, Batches.sweep)
+	return err
+}
+
+func (b *batches) All() []*Batch {
+	all := []*Batch{}
+	b.active.Range(func(key, value interface{}) bool {
+		all = append(all, value.(*Batch))
+		return true
+	})
+	return all
+}
+
+// NewBatch - create a batch, tasks are added by Add and failed dispatches by Fail
+func NewBatch(typ, callby string) *Batch {
+	batch := &Batch{
+		Id:        atomic.AddUint32(&batchID, 1),
+		Type:      typ,
+		Callby:    callby,
+		CreatedAt: time.Now(),
+		Errors:    map[string]string{},
+	}
+	Batches.active.Store(batch.Id, batch)
+	return batch
+}
+
+type Batch struct {
+	Id         uint32
+	Type       string
+	Callby     string
+	CreatedAt  time.Time
+	Tasks      []*Task
+	Errors     map[string]string // session id -> dispatch error
+	mu         sync.Mutex
+	dispatched bool
+	finished   int
+	failed     int
+	doneAt     time.Time
+}
+
+// Add - track task, progress is published as EventBatch when the task ends
+func (b *Batch) Add(task *Task) {
+	b.mu.Lock()
+	task.BatchId = b.Id
+	b.Tasks = append(b.Tasks, task)
+	b.mu.Unlock()
+	go func() {
+		<-task.Ctx.Done()
+		b.mu.Lock()
+		b.finished++
+		if task.Status != nil {
+			b.failed++
+		}
+		b.markDone()
+		b.mu.Unlock()
+		b.publish()
+	}()
+}
+
+// Fail - record session the request could not be dispatched to
+func (b *Batch) Fail(sessionID string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.Errors[sessionID] = err.Error()
+}
+
+// Dispatched - all requests are sent, the batch is done once the tasks end
+func (b *Batch) Dispatched() {
+	b.mu.Lock()
+	b.dispatched = true
+	b.markDone()
+	b.mu.Unlock()
+	b.publish()
+}
+
+// markDone - record when the batch is done, caller holds mu
+func (b *Batch) markDone() {
+	if b.doneAt.IsZero() && b.dispatched && b.finished == len(b.Tasks) {
+		b.doneAt = time.Now()
+	}
+}
+
+func (b *Batch) expired(ttl time.Duration) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.doneAt.IsZero() && time.Since(b.doneAt) > ttl
+}
+
+// Progress - finished and total tasks
+func (b *Batch) Progress() (int, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.finished, len(b.Tasks)
+}
+
+func (b *Batch) ToProtobuf() *clientpb.Batch {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	batch := &clientpb.Batch{
+		Id:        b.Id,
+		Type:      b.Type,
+		Callby:    b.Callby,
+		CreatedAt: b.CreatedAt.Unix(),
+		Total:     int32(len(b.Tasks)),
+		Finished:  int32(b.finished),
+		Failed:    int32(b.failed),
+		Done:      b.dispatched && b.finished == len(b.Tasks),
+		Errors:    map[string]string{},
+	}
+	for _, task := range b.Tasks {
+		batch.Tasks = append(batch.Tasks, task.ToProtobuf())
+	}
+	for sid, err := range b.Errors {
+		batch.Errors[sid] = err
+	}
+	return batch
+}
+
+func (b *Batch) publish() {
+	batch := b.ToProtobuf()
+	data, err := proto.Marshal(batch)
+	if err != nil {
+		return
+	}
+	EventBroker.Publish(Event{
+		EventType: consts.EventBatch,
+		Message:   fmt.Sprintf("%s %d/%d", b.Type, batch.Finished, batch.Total),
+		Data:      data,
+	})
+}
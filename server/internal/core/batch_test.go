@@ -0,0 +1,83 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/chainreactors/malice-network/helper/consts"
+	"github.com/chainreactors/malice-network/proto/client/clientpb"
+	"github.com/chainreactors/malice-network/proto/implant/implantpb"
+	"google.golang.org/protobuf/proto"
+)
+
+func newTestTask(id uint32, sid string) *Task {
+	task := &Task{Id: id, SessionId: sid, Type: "whoami", Total: 1}
+	task.Ctx, task.Cancel = context.WithCancel(context.Background())
+	return task
+}
+
+func TestBatch(t *testing.T) {
+	events := EventBroker.Subscribe()
+	defer EventBroker.Unsubscribe(events)
+
+	batch := NewBatch("whoami", "admin")
+	ok, failed := newTestTask(1, "a"), newTestTask(1, "b")
+	batch.Add(ok)
+	batch.Add(failed)
+	batch.Fail("c", ErrImplantSendTimeout)
+	batch.Dispatched()
+	if Batches.Get(batch.Id) != batch {
+		t.Fatal("batch not registered")
+	}
+
+	ok.Cancel()
+	failed.Status = &implantpb.Spite{}
+	failed.Cancel()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			if event.EventType != consts.EventBatch {
+				continue
+			}
+			pb := &clientpb.Batch{}
+			if err := proto.Unmarshal(event.Data, pb); err != nil {
+				t.Fatal(err)
+			}
+			if pb.Id != batch.Id || !pb.Done {
+				continue
+			}
+			if pb.Total != 2 || pb.Finished != 2 || pb.Failed != 1 || len(pb.Errors) != 1 {
+				t.Fatalf("unexpected batch %v", pb)
+			}
+			for _, task := range pb.Tasks {
+				if task.BatchId != batch.Id {
+					t.Fatalf("task %s not in batch", task.SessionId)
+				}
+			}
+			return
+		case <-timeout:
+			t.Fatal("batch not done")
+		}
+	}
+}
+
+func TestBatchSweep(t *testing.T) {
+	running, done := NewBatch("whoami", "admin"), NewBatch("whoami", "admin")
+	running.Add(newTestTask(1, "a"))
+	running.Dispatched()
+	done.Dispatched()
+	done.mu.Lock()
+	done.doneAt = time.Now().Add(-consts.BatchExpiry - time.Minute)
+	done.mu.Unlock()
+
+	Batches.sweep()
+	if Batches.Get(running.Id) == nil {
+		t.Fatal("running batch evicted")
+	}
+	if Batches.Get(done.Id) != nil {
+		t.Fatal("expired batch not evicted")
+	}
+}
@@ -11,12 +11,14 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 type Cache struct {
 	cache    cache.Cache
 	savePath string
 	maxSize  int
+	saveMu   sync.Mutex
 }
 
 func NewCache(maxSize int, savePath string) *Cache {
@@ -63,18 +65,34 @@ func (c *Cache) GetMessages(taskID int) ([]*implantpb.Spite, bool) {
 	return spite, true
 }
 
+func (c *Cache) Count() int {
+	return c.cache.ItemCount()
+}
+
 func (c *Cache) GetAll() {
 	for k, v := range c.cache.Items() {
 		logs.Log.Importantf(k, v)
 	}
 }
 
+// Save - write cache to a temp file and rename it, so that an interrupted save never leaves a half written gob
 func (c *Cache) Save() error {
-	err := c.cache.SaveFile(c.savePath)
+	c.saveMu.Lock()
+	defer c.saveMu.Unlock()
+	tmp := c.savePath + ".tmp"
+	f, err := os.Create(tmp)
 	if err != nil {
 		return err
 	}
-	return nil
+	err = c.cache.Save(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, c.savePath)
 }
 
 func (c *Cache) Load() error {
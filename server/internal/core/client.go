@@ -1,6 +1,7 @@
 package core
 
 import (
+	"fmt"
 	"github.com/chainreactors/malice-network/helper/consts"
 	"github.com/chainreactors/malice-network/proto/client/clientpb"
 	"sync"
@@ -26,15 +27,17 @@ type clients struct {
 func NewClient(operatorName string) *Client {
 	return &Client{
 		Client: &clientpb.Client{
-			ID:   getClientID(),
-			Name: operatorName,
+			ID:     getClientID(),
+			Name:   operatorName,
+			Online: true,
 		},
 	}
 }
 
-// Client - Single client connection
+// Client - Single client connection, Name is the verified CN of operator certificate
 type Client struct {
 	*clientpb.Client
+	RemoteAddr string
 }
 
 func (c *Client) ToProtobuf() *clientpb.Client {
@@ -46,8 +49,10 @@ func (cc *clients) Add(client *Client) {
 	defer cc.mutex.Unlock()
 	cc.active[int(client.ID)] = client
 	EventBroker.Publish(Event{
-		EventType: consts.EventJoin,
-		Client:    client,
+		EventType:  consts.EventJoin,
+		Client:     client,
+		SourceName: client.Name,
+		Message:    fmt.Sprintf("%s joined from %s", client.Name, client.RemoteAddr),
 	})
 }
 
@@ -66,11 +71,17 @@ func (cc *clients) ActiveOperators() []string {
 func (cc *clients) Remove(clientID int) {
 	cc.mutex.Lock()
 	defer cc.mutex.Unlock()
-	client := cc.active[clientID]
+	client, ok := cc.active[clientID]
+	if !ok {
+		return
+	}
 	delete(cc.active, clientID)
+	client.Online = false
 	EventBroker.Publish(Event{
-		EventType: consts.EventLeft,
-		Client:    client,
+		EventType:  consts.EventLeft,
+		Client:     client,
+		SourceName: client.Name,
+		Message:    fmt.Sprintf("%s left from %s", client.Name, client.RemoteAddr),
 	})
 }
 
@@ -68,6 +68,11 @@ func (broker *eventBroker) Unsubscribe(events chan Event) {
 	close(events)
 }
 
+// QueueDepth - Number of events waiting to be distributed
+func (broker *eventBroker) QueueDepth() int {
+	return len(broker.publish)
+}
+
 // Publish - Push a message to all subscribers
 func (broker *eventBroker) Publish(event Event) {
 	broker.publish <- event
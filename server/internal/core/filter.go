@@ -0,0 +1,164 @@
+package core
+
+import (
+	"errors"
+	"github.com/chainreactors/malice-network/proto/client/clientpb"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	LivenessAlive = "alive"
+	LivenessDead  = "dead"
+)
+
+var ErrInvalidLiveness = errors.New("liveness must be alive or dead")
+
+// SessionFilter - match sessions by os, arch, hostname regex, group, pipeline, tags and liveness,
+// empty fields match everything
+type SessionFilter struct {
+	Os       string
+	Arch     string
+	Hostname *regexp.Regexp
+	Group    string
+	Pipeline string
+	Tags     []string
+	Liveness string
+}
+
+func NewSessionFilter(pb *clientpb.SessionFilter) (*SessionFilter, error) {
+	filter := &SessionFilter{
+		Os:       strings.ToLower(pb.Os),
+		Arch:     strings.ToLower(pb.Arch),
+		Group:    pb.Group,
+		Pipeline: pb.Pipeline,
+		Tags:     pb.Tags,
+		Liveness: strings.ToLower(pb.Liveness),
+	}
+	if filter.Liveness != "" && filter.Liveness != LivenessAlive && filter.Liveness != LivenessDead {
+		return nil, ErrInvalidLiveness
+	}
+	if pb.Hostname != "" {
+		var err error
+		filter.Hostname, err = regexp.Compile(pb.Hostname)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return filter, nil
+}
+
+func (f *SessionFilter) Match(s *Session) bool {
+	if f.Os != "" && (s.Os == nil || !strings.EqualFold(s.Os.Name, f.Os)) {
+		return false
+	}
+	if f.Arch != "" && (s.Os == nil || !strings.EqualFold(s.Os.Arch, f.Arch)) {
+		return false
+	}
+	if f.Hostname != nil && (s.Os == nil || !f.Hostname.MatchString(s.Os.Hostname)) {
+		return false
+	}
+	if f.Group != "" && s.GetGroup() != f.Group {
+		return false
+	}
+	if f.Pipeline != "" && s.PipelineID != f.Pipeline {
+		return false
+	}
+	for _, tag := range f.Tags {
+		if !s.HasTag(tag) {
+			return false
+		}
+	}
+	switch f.Liveness {
+	case LivenessAlive:
+		return s.IsAlive()
+	case LivenessDead:
+		return !s.IsAlive()
+	}
+	return true
+}
+
+// Filter - sessions matched by filter
+func (s *sessions) Filter(filter *SessionFilter) []*Session {
+	var matched []*Session
+	for _, session := range s.All() {
+		if filter.Match(session) {
+			matched = append(matched, session)
+		}
+	}
+	return matched
+}
+
+// IsAlive - session checked in within two intervals
+func (s *Session) IsAlive() bool {
+	if s.Timer == nil {
+		return false
+	}
+	lastCheckin := time.Unix(int64(s.Timer.LastCheckin), 0)
+	return time.Since(lastCheckin) <= time.Duration(s.Timer.Interval*2)*time.Second
+}
+
+// SetGroup - group is read by filters of batch and schedule runs concurrently
+func (s *Session) SetGroup(group string) {
+	s.labelMu.Lock()
+	defer s.labelMu.Unlock()
+	s.Group = group
+}
+
+func (s *Session) GetGroup() string {
+	s.labelMu.RLock()
+	defer s.labelMu.RUnlock()
+	return s.Group
+}
+
+// GetTags - copy of tags, safe to use after later AddTags and RemoveTags
+func (s *Session) GetTags() []string {
+	s.labelMu.RLock()
+	defer s.labelMu.RUnlock()
+	return append([]string(nil), s.Tags...)
+}
+
+func (s *Session) HasTag(tag string) bool {
+	s.labelMu.RLock()
+	defer s.labelMu.RUnlock()
+	return s.hasTag(tag)
+}
+
+func (s *Session) hasTag(tag string) bool {
+	for _, t := range s.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Session) AddTags(tags ...string) {
+	s.labelMu.Lock()
+	defer s.labelMu.Unlock()
+	for _, tag := range tags {
+		if !s.hasTag(tag) {
+			s.Tags = append(s.Tags, tag)
+		}
+	}
+}
+
+func (s *Session) RemoveTags(tags ...string) {
+	s.labelMu.Lock()
+	defer s.labelMu.Unlock()
+	var kept []string
+	for _, t := range s.Tags {
+		removed := false
+		for _, tag := range tags {
+			if t == tag {
+				removed = true
+				break
+			}
+		}
+		if !removed {
+			kept = append(kept, t)
+		}
+	}
+	s.Tags = kept
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chainreactors/malice-network/proto/client/clientpb"
+	"github.com/chainreactors/malice-network/proto/implant/implantpb"
+)
+
+func TestSessionFilter(t *testing.T) {
+	now := uint64(time.Now().Unix())
+	win := &Session{
+		ID:         "win",
+		Group:      "dc",
+		PipelineID: "tcp_default",
+		Tags:       []string{"admin", "domain"},
+		Os:         &implantpb.Os{Name: "windows", Arch: "x64", Hostname: "DC01"},
+		Timer:      &implantpb.Timer{Interval: 10, LastCheckin: now},
+	}
+	linux := &Session{
+		ID:         "linux",
+		Group:      "default",
+		PipelineID: "tcp_default",
+		Os:         &implantpb.Os{Name: "linux", Arch: "x64", Hostname: "web01"},
+		Timer:      &implantpb.Timer{Interval: 10, LastCheckin: now - 60},
+	}
+
+	cases := []struct {
+		filter *clientpb.SessionFilter
+		match  []bool // win, linux
+	}{
+		{&clientpb.SessionFilter{}, []bool{true, true}},
+		{&clientpb.SessionFilter{Os: "Windows"}, []bool{true, false}},
+		{&clientpb.SessionFilter{Arch: "x64", Pipeline: "tcp_default"}, []bool{true, true}},
+		{&clientpb.SessionFilter{Hostname: "^web\\d+$"}, []bool{false, true}},
+		{&clientpb.SessionFilter{Group: "dc"}, []bool{true, false}},
+		{&clientpb.SessionFilter{Tags: []string{"admin", "domain"}}, []bool{true, false}},
+		{&clientpb.SessionFilter{Tags: []string{"admin", "other"}}, []bool{false, false}},
+		{&clientpb.SessionFilter{Liveness: LivenessAlive}, []bool{true, false}},
+		{&clientpb.SessionFilter{Liveness: LivenessDead}, []bool{false, true}},
+	}
+	for i, c := range cases {
+		filter, err := NewSessionFilter(c.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j, sess := range []*Session{win, linux} {
+			if got := filter.Match(sess); got != c.match[j] {
+				t.Errorf("case %d: match %s = %t, want %t", i, sess.ID, got, c.match[j])
+			}
+		}
+	}
+
+	if _, err := NewSessionFilter(&clientpb.SessionFilter{Hostname: "("}); err == nil {
+		t.Error("invalid hostname regex accepted")
+	}
+	if _, err := NewSessionFilter(&clientpb.SessionFilter{Liveness: "zombie"}); err != ErrInvalidLiveness {
+		t.Errorf("invalid liveness: %v", err)
+	}
+}
+
+func TestSessionTags(t *testing.T) {
+	sess := &Session{}
+	sess.AddTags("a", "b", "a")
+	sess.AddTags("c")
+	sess.RemoveTags("b", "x")
+	if len(sess.Tags) != 2 || !sess.HasTag("a") || !sess.HasTag("c") || sess.HasTag("b") {
+		t.Fatalf("unexpected tags %v", sess.Tags)
+	}
+}
@@ -34,6 +34,15 @@ func (f *forwarders) Add(fw *Forward) {
 	f.forwarders.Store(fw.ID(), fw)
 }
 
+func (f *forwarders) All() []*Forward {
+	all := []*Forward{}
+	f.forwarders.Range(func(key, value interface{}) bool {
+		all = append(all, value.(*Forward))
+		return true
+	})
+	return all
+}
+
 func (f *forwarders) Get(id string) *Forward {
 	fw, ok := f.forwarders.Load(id)
 	if !ok {
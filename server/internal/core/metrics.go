@@ -0,0 +1,59 @@
+package core
+
+import (
+	"github.com/chainreactors/malice-network/server/internal/metrics"
+)
+
+// RegisterServerMetrics - register gauges of sessions, tasks, broker and cache, registering again is no-op
+func RegisterServerMetrics() {
+	metrics.RegisterGaugeVec("sessions", "Active sessions per pipeline", "pipeline", func() map[string]float64 {
+		count := map[string]float64{}
+		for _, sess := range Sessions.All() {
+			count[sess.PipelineID]++
+		}
+		return count
+	})
+	metrics.RegisterGauge("tasks_in_flight", "Tasks not finished yet", func() float64 {
+		var count float64
+		for _, sess := range Sessions.All() {
+			for _, task := range sess.Tasks.All() {
+				if task.Cur < task.Total {
+					count++
+				}
+			}
+		}
+		return count
+	})
+	metrics.RegisterGauge("event_broker_queue_depth", "Events waiting in broker queue", func() float64 {
+		return float64(EventBroker.QueueDepth())
+	})
+	metrics.RegisterGauge("cache_items", "Cached spite count of all sessions", func() float64 {
+		var count float64
+		for _, sess := range Sessions.All() {
+			if sess.Cache != nil {
+				count += float64(sess.Cache.Count())
+			}
+		}
+		return count
+	})
+}
+
+// RegisterListenerMetrics - register metrics of forwarders and connections, registering again is no-op
+func RegisterListenerMetrics() {
+	metrics.RegisterCounterVec("forward_messages_total", "Messages forwarded per pipeline", "pipeline", func() map[string]float64 {
+		count := map[string]float64{}
+		for _, fw := range Forwarders.All() {
+			count[fw.ID()] += float64(fw.Count())
+		}
+		return count
+	})
+	metrics.RegisterGauge("connections", "Alive implant connections", func() float64 {
+		var count float64
+		for _, conn := range Connections.All() {
+			if conn.Alive {
+				count++
+			}
+		}
+		return count
+	})
+}
@@ -0,0 +1,36 @@
+package core
+
+import (
+	"github.com/chainreactors/malice-network/server/internal/metrics"
+	"testing"
+)
+
+func TestRegisterMetrics(t *testing.T) {
+	// server and listener in the same process register both, the listener registers again on start
+	RegisterServerMetrics()
+	RegisterListenerMetrics()
+	RegisterListenerMetrics()
+
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	types := map[string]string{}
+	for _, family := range families {
+		types[family.GetName()] = family.GetType().String()
+	}
+	for name, typ := range map[string]string{
+		"malice_sessions":                 "GAUGE",
+		"malice_tasks_in_flight":          "GAUGE",
+		"malice_event_broker_queue_depth": "GAUGE",
+		"malice_cache_items":              "GAUGE",
+		"malice_connections":              "GAUGE",
+	} {
+		got, ok := types[name]
+		if !ok {
+			t.Errorf("metric %s not registered", name)
+		} else if got != typ {
+			t.Errorf("metric %s type %s, expect %s", name, got, typ)
+		}
+	}
+}
@@ -20,9 +20,7 @@ import (
 
 var (
 	// Sessions - Manages implant connections
-	Sessions = &sessions{
-		active: &sync.Map{},
-	}
+	Sessions         = NewSessions()
 	ExtensionModules = []string{consts.ModuleExecuteBof, consts.ModuleExecuteDll}
 	// ErrUnknownMessageType - Returned if the implant did not understand the message for
 	//                         example when the command is not supported on the platform
@@ -39,6 +37,7 @@ func NewSession(req *lispb.RegisterSession) *Session {
 		Modules:    req.RegisterData.Module,
 		Extensions: req.RegisterData.Extension,
 		ID:         req.SessionId,
+		Group:      "default",
 		PipelineID: req.ListenerId,
 		RemoteAddr: req.RemoteAddr,
 		Timer:      req.RegisterData.Timer,
@@ -59,7 +58,8 @@ type Session struct {
 	PipelineID string
 	ID         string
 	Name       string
-	Group      string
+	Group      string   // guarded by labelMu once the session is registered, see SetGroup
+	Tags       []string // guarded by labelMu once the session is registered, see AddTags
 	RemoteAddr string
 	Os         *implantpb.Os
 	Process    *implantpb.Process
@@ -75,6 +75,7 @@ type Session struct {
 	*Cache
 	responses *sync.Map
 	log       *logs.Logger
+	labelMu   sync.RWMutex
 }
 
 func (s *Session) Logger() *logs.Logger {
@@ -102,7 +103,8 @@ func (s *Session) ToProtobuf() *clientpb.Session {
 	return &clientpb.Session{
 		SessionId:  s.ID,
 		Note:       s.Name,
-		GroupName:  s.Group,
+		GroupName:  s.GetGroup(),
+		Tags:       s.GetTags(),
 		IsDead:     !isAlive,
 		RemoteAddr: s.RemoteAddr,
 		ListenerId: s.PipelineID,
@@ -147,11 +149,24 @@ func (s *Session) SetLastTaskId(id uint32) {
 }
 
 func (s *Session) NewTask(name string, total int) *Task {
+	return s.newTask(s.nextTaskId(), name, total)
+}
+
+// RecoverTask - restore task by id, e.g. a task queued before server restart
+func (s *Session) RecoverTask(id uint32, name string, total int) *Task {
+	if id > s.taskseq {
+		s.taskseq = id
+	}
+	return s.newTask(id, name, total)
+}
+
+func (s *Session) newTask(id uint32, name string, total int) *Task {
 	task := &Task{
 		Type:      name,
 		Total:     total,
-		Id:        s.nextTaskId(),
+		Id:        id,
 		SessionId: s.ID,
+		CreatedAt: time.Now(),
 		done:      make(chan bool),
 		end:       make(chan struct{}),
 	}
@@ -166,6 +181,9 @@ func (s *Session) AllTask() []*Task {
 }
 
 func (s *Session) UpdateLastCheckin() {
+	if s.Timer == nil {
+		return
+	}
 	s.Timer.LastCheckin = uint64(time.Now().Unix())
 }
 
@@ -214,26 +232,29 @@ func (s *Session) RequestWithStream(msg *lispb.SpiteSession, stream grpc.ServerS
 	}
 
 	in := make(chan *implantpb.Spite)
-	go func() {
-		defer close(respCh)
-		var c = 0
-		for spite := range in {
-			err := stream.SendMsg(&lispb.SpiteSession{
-				SessionId: s.ID,
-				TaskId:    msg.TaskId,
-				Spite:     spite,
-			})
-			if err != nil {
-				logs.Log.Debugf(err.Error())
-				return
-			}
-			logs.Log.Debugf("send message %s, %d", spite.Name, c)
-			c++
-		}
-	}()
+	go s.ForwardStream(msg.TaskId, in, respCh, stream)
 	return in, respCh, nil
 }
 
+// ForwardStream - send spites of in to the implant until in is closed
+func (s *Session) ForwardStream(taskId uint32, in, respCh chan *implantpb.Spite, stream grpc.ServerStream) {
+	defer close(respCh)
+	var c = 0
+	for spite := range in {
+		err := stream.SendMsg(&lispb.SpiteSession{
+			SessionId: s.ID,
+			TaskId:    taskId,
+			Spite:     spite,
+		})
+		if err != nil {
+			logs.Log.Debugf(err.Error())
+			return
+		}
+		logs.Log.Debugf("send message %s, %d", spite.Name, c)
+		c++
+	}
+}
+
 func (s *Session) RequestWithAsync(msg *lispb.SpiteSession, stream grpc.ServerStream, timeout time.Duration) (chan *implantpb.Spite, error) {
 	respCh := make(chan *implantpb.Spite)
 	s.StoreResp(msg.TaskId, respCh)
@@ -270,6 +291,13 @@ type sessions struct {
 	active *sync.Map // map[uint32]*Session
 }
 
+// NewSessions - empty session registry
+func NewSessions() *sessions {
+	return &sessions{
+		active: &sync.Map{},
+	}
+}
+
 // All - Return a list of all sessions
 func (s *sessions) All() []*Session {
 	all := []*Session{}
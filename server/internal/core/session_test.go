@@ -0,0 +1,33 @@
+package core
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestRecoverTask(t *testing.T) {
+	sess := &Session{ID: "test", Tasks: &Tasks{active: &sync.Map{}}}
+	for _, c := range []struct {
+		recover uint32 // 0 for new task
+		want    uint32
+	}{
+		{0, 1},
+		{5, 5},
+		{0, 6},
+		{3, 3},
+		{0, 7},
+	} {
+		var task *Task
+		if c.recover == 0 {
+			task = sess.NewTask("test", 1)
+		} else {
+			task = sess.RecoverTask(c.recover, "test", 1)
+		}
+		if task.Id != c.want {
+			t.Fatalf("task id %d, want %d", task.Id, c.want)
+		}
+		if sess.Tasks.Get(task.Id) != task {
+			t.Fatalf("task %d not registered", task.Id)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/chainreactors/malice-network/proto/client/clientpb"
 	"github.com/chainreactors/malice-network/proto/implant/implantpb"
 	"sync"
+	"time"
 )
 
 type Tasks struct {
@@ -54,8 +55,11 @@ type Task struct {
 	Id        uint32
 	Type      string
 	SessionId string
+	BatchId   uint32
 	Cur       int
 	Total     int
+	Callby    string
+	CreatedAt time.Time
 	Callback  func()
 	Ctx       context.Context
 	Cancel    context.CancelFunc
@@ -82,6 +86,7 @@ func (t *Task) ToProtobuf() *clientpb.Task {
 	task := &clientpb.Task{
 		TaskId:    t.Id,
 		SessionId: t.SessionId,
+		BatchId:   t.BatchId,
 		Type:      t.Type,
 		Cur:       int32(t.Cur),
 		Total:     int32(t.Total),
@@ -1,8 +1,10 @@
 package core
 
 import (
+	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/robfig/cron/v3"
 )
@@ -33,6 +35,36 @@ func (t *Ticker) Start(interval int, cmd func()) (cron.EntryID, error) {
 	return t.cron.AddFunc(fmt.Sprintf("@every %ds", interval), cmd)
 }
 
+// Cron - run cmd by a standard cron expression or descriptor, e.g. "0 * * * *", "@hourly"
+func (t *Ticker) Cron(spec string, cmd func()) (cron.EntryID, error) {
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return 0, err
+	}
+	return t.cron.Schedule(schedule, cron.FuncJob(cmd)), nil
+}
+
+// At - run cmd once at the time
+func (t *Ticker) At(at time.Time, cmd func()) cron.EntryID {
+	return t.cron.Schedule(onceSchedule(at), cron.FuncJob(cmd))
+}
+
+// Next - next run time of the entry, zero if the entry does not exist
+func (t *Ticker) Next(id cron.EntryID) time.Time {
+	return t.cron.Entry(id).Next
+}
+
+// onceSchedule - fires once, cron drops entries whose next time is zero
+type onceSchedule time.Time
+
+func (o onceSchedule) Next(now time.Time) time.Time {
+	at := time.Time(o)
+	if at.After(now) {
+		return at
+	}
+	return time.Time{}
+}
+
 func (t *Ticker) Remove(id cron.EntryID) {
 	t.cron.Remove(id)
 }
@@ -40,3 +72,8 @@ func (t *Ticker) Remove(id cron.EntryID) {
 func (t *Ticker) RemoveAll() {
 	t.cron.Stop()
 }
+
+// Stop - stop scheduling, the returned context is done when running jobs complete
+func (t *Ticker) Stop() context.Context {
+	return t.cron.Stop()
+}
@@ -0,0 +1,40 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTickerSchedule(t *testing.T) {
+	ticker := NewTicker()
+
+	if _, err := ticker.Cron("61 * * * *", func() {}); err == nil {
+		t.Error("invalid cron spec accepted")
+	}
+	id, err := ticker.Cron("@hourly", func() {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next := ticker.Next(id); next.IsZero() || next.Sub(time.Now()) > time.Hour {
+		t.Errorf("unexpected next run %s", next)
+	}
+	ticker.Remove(id)
+	if !ticker.Next(id).IsZero() {
+		t.Error("removed entry still scheduled")
+	}
+
+	fired := make(chan struct{}, 2)
+	ticker.At(time.Now().Add(time.Second), func() {
+		fired <- struct{}{}
+	})
+	select {
+	case <-fired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("one-shot entry not fired")
+	}
+	select {
+	case <-fired:
+		t.Fatal("one-shot entry fired twice")
+	case <-time.After(2 * time.Second):
+	}
+}
@@ -18,3 +18,15 @@ func Session() *gorm.DB {
 		PrepareStmt:          true,
 	})
 }
+
+// Close - close database connections, called once at shutdown after all writes
+func Close() error {
+	if Client == nil {
+		return nil
+	}
+	sqlDB, err := Client.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
@@ -12,6 +12,7 @@ import (
 	"gorm.io/gorm/utils"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -138,7 +139,10 @@ func UpdateSessionInfo(coreSession *core.Session) error {
 // Basic Session OP
 func DeleteSession(sessionID string) error {
 	result := Session().Where("session_id = ?", sessionID).Delete(&models.Session{})
-	return result.Error
+	if result.Error != nil {
+		return result.Error
+	}
+	return Session().Where("session_id = ?", sessionID).Delete(&models.SessionTag{}).Error
 }
 
 func UpdateSession(sessionID, note, group string) error {
@@ -157,6 +161,95 @@ func UpdateSession(sessionID, note, group string) error {
 	return result.Error
 }
 
+// RecoverSessionMeta - restore group and tags of session recovered from db
+func RecoverSessionMeta(sess *core.Session) error {
+	var session models.Session
+	err := Session().Select("group_name").Where("session_id = ?", sess.ID).First(&session).Error
+	if err != nil {
+		return err
+	}
+	if session.GroupName != "" {
+		sess.Group = session.GroupName
+	}
+	sess.Tags, err = FindSessionTags(sess.ID)
+	return err
+}
+
+func FindSessionTags(sessionID string) ([]string, error) {
+	var tags []string
+	err := Session().Model(&models.SessionTag{}).Where("session_id = ?", sessionID).Order("id").Pluck("name", &tags).Error
+	return tags, err
+}
+
+func AddSessionTags(sessionID string, tags []string) error {
+	for _, tag := range tags {
+		err := Session().Where(models.SessionTag{SessionID: sessionID, Name: tag}).
+			FirstOrCreate(&models.SessionTag{}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func RemoveSessionTags(sessionID string, tags []string) error {
+	return Session().Where("session_id = ? AND name IN ?", sessionID, tags).Delete(&models.SessionTag{}).Error
+}
+
+func SaveSessionFilter(filter *models.SessionFilter) error {
+	return Session().Save(filter).Error
+}
+
+func FindSessionFilter(name string) (*models.SessionFilter, error) {
+	filter := &models.SessionFilter{}
+	err := Session().Where("name = ?", name).First(filter).Error
+	return filter, err
+}
+
+func ListSessionFilters() ([]*models.SessionFilter, error) {
+	var filters []*models.SessionFilter
+	err := Session().Order("name").Find(&filters).Error
+	return filters, err
+}
+
+func DeleteSessionFilter(name string) error {
+	return Session().Where("name = ?", name).Delete(&models.SessionFilter{}).Error
+}
+
+func AddSchedule(schedule *models.Schedule) error {
+	return Session().Create(schedule).Error
+}
+
+func FindSchedule(id uint32) (*models.Schedule, error) {
+	schedule := &models.Schedule{}
+	err := Session().Where("id = ?", id).First(schedule).Error
+	return schedule, err
+}
+
+func ListSchedules() ([]*models.Schedule, error) {
+	var schedules []*models.Schedule
+	err := Session().Order("id").Find(&schedules).Error
+	return schedules, err
+}
+
+func PauseSchedule(id uint32, paused bool) error {
+	return Session().Model(&models.Schedule{}).Where("id = ?", id).Update("paused", paused).Error
+}
+
+// RecordScheduleRun - count a run of schedule, one-shot schedules are paused after running
+func RecordScheduleRun(schedule *models.Schedule, batchID uint32) error {
+	return Session().Model(schedule).Updates(map[string]interface{}{
+		"runs":       gorm.Expr("runs + ?", 1),
+		"last_run":   time.Now(),
+		"last_batch": batchID,
+		"paused":     schedule.Once(),
+	}).Error
+}
+
+func DeleteSchedule(id uint32) error {
+	return Session().Where("id = ?", id).Delete(&models.Schedule{}).Error
+}
+
 func CreateOperator(name string) error {
 	var operator models.Operator
 	result := Session().Where("name = ?", name).Delete(&operator)
@@ -171,6 +264,26 @@ func CreateOperator(name string) error {
 
 }
 
+func GetOrCreateOperator(name string) (*models.Operator, error) {
+	var operator models.Operator
+	err := Session().Where(&models.Operator{Name: name}).FirstOrCreate(&operator).Error
+	return &operator, err
+}
+
+func AddLoginHistory(history *models.LoginHistory) error {
+	return Session().Create(history).Error
+}
+
+func ListLoginHistory(name string) ([]models.LoginHistory, error) {
+	var histories []models.LoginHistory
+	query := Session().Order("created_at")
+	if name != "" {
+		query = query.Where("operator = ?", name)
+	}
+	err := query.Find(&histories).Error
+	return histories, err
+}
+
 func ListOperators() (*clientpb.Clients, error) {
 	var operators []models.Operator
 	err := Session().Find(&operators).Error
@@ -297,6 +410,7 @@ func AddTask(typ string, task *core.Task, td *models.FileDescription) error {
 		SessionID:   task.SessionId,
 		Cur:         task.Cur,
 		Total:       task.Total,
+		Operator:    task.Callby,
 		Description: tdString,
 	}
 	Session().Create(taskModel)
@@ -325,9 +439,108 @@ func ToTask(task models.Task) (*core.Task, error) {
 		SessionId: task.SessionID,
 		Cur:       task.Cur,
 		Total:     task.Total,
+		Callby:    task.Operator,
+		CreatedAt: task.CreatedAt,
 	}, nil
 }
 
+// EnqueueTask - append request to the end of session queue
+func EnqueueTask(queued *models.QueuedTask) error {
+	return Session().Transaction(func(tx *gorm.DB) error {
+		var last models.QueuedTask
+		err := tx.Where("session_id = ? AND delivered = ?", queued.SessionID, false).Order("position desc").First(&last).Error
+		if err == nil {
+			queued.Position = last.Position + 1
+		} else if errors.Is(err, gorm.ErrRecordNotFound) {
+			queued.Position = 0
+		} else {
+			return err
+		}
+		return tx.Create(queued).Error
+	})
+}
+
+// FindQueuedTasks - queued requests of session in delivery order, all sessions if sessionID is empty
+func FindQueuedTasks(sessionID string) ([]*models.QueuedTask, error) {
+	var queued []*models.QueuedTask
+	query := Session().Where("delivered = ?", false).Order("session_id").Order("position")
+	if sessionID != "" {
+		query = query.Where("session_id = ?", sessionID)
+	}
+	err := query.Find(&queued).Error
+	return queued, err
+}
+
+// FindDeliveredTasks - tasks sent to session but not responded before shutdown
+func FindDeliveredTasks(sessionID string) ([]*models.QueuedTask, error) {
+	var delivered []*models.QueuedTask
+	err := Session().Where("session_id = ? AND delivered = ?", sessionID, true).Find(&delivered).Error
+	return delivered, err
+}
+
+// FindExpiredQueuedTasks - queued tasks not sent before expiry, delivered tasks are waiting for responses
+// and are not expired
+func FindExpiredQueuedTasks() ([]*models.QueuedTask, error) {
+	var queued []*models.QueuedTask
+	err := Session().Where("expire_at < ? AND delivered = ?", time.Now(), false).Find(&queued).Error
+	return queued, err
+}
+
+func DeleteQueuedTask(sessionID string, taskID uint32) error {
+	result := Session().Where("session_id = ? AND task_id = ?", sessionID, taskID).Delete(&models.QueuedTask{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RequeueTask - put back a queued request claimed by DeleteQueuedTask, it keeps its position
+func RequeueTask(queued *models.QueuedTask) error {
+	return Session().Create(queued).Error
+}
+
+// MoveQueuedTask - move queued request to position of session queue, other requests keep their order
+func MoveQueuedTask(sessionID string, taskID uint32, position int) error {
+	return Session().Transaction(func(tx *gorm.DB) error {
+		var queued []*models.QueuedTask
+		err := tx.Where("session_id = ? AND delivered = ?", sessionID, false).Order("position").Find(&queued).Error
+		if err != nil {
+			return err
+		}
+		index := -1
+		for i, q := range queued {
+			if q.TaskID == taskID {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return gorm.ErrRecordNotFound
+		}
+		moved := queued[index]
+		queued = append(queued[:index], queued[index+1:]...)
+		if position < 0 {
+			position = 0
+		} else if position > len(queued) {
+			position = len(queued)
+		}
+		queued = append(queued[:position], append([]*models.QueuedTask{moved}, queued[position:]...)...)
+		for i, q := range queued {
+			if q.Position == i {
+				continue
+			}
+			err = tx.Model(q).Update("position", i).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 // website
 // WebsiteByName - Get website by name
 func WebsiteByName(name string, webContentDir string) (*lispb.Website, error) {
@@ -408,6 +621,13 @@ func AddContent(pbWebContent *lispb.WebContent, webContentDir string) (*lispb.We
 	} else {
 		dbWebContent.ContentType = pbWebContent.ContentType
 		dbWebContent.Size = pbWebContent.Size
+		dbWebContent.Etag = pbWebContent.Etag
+		dbWebContent.Headers = pbWebContent.Headers
+		dbWebContent.Status = pbWebContent.Status
+		dbWebContent.Redirect = pbWebContent.Redirect
+		dbWebContent.MaxHits = pbWebContent.MaxHits
+		dbWebContent.Hits = 0
+		dbWebContent.ExpireAt = pbWebContent.ExpireAt
 
 		dbModelWebContent := models.WebContentFromProtobuf(dbWebContent)
 		err = Session().Save(&dbModelWebContent).Error
@@ -418,6 +638,106 @@ func AddContent(pbWebContent *lispb.WebContent, webContentDir string) (*lispb.We
 	return dbWebContent, nil
 }
 
+// UpdateContentMeta - Update content type, headers, status, redirect, max hits and expire time of content,
+// fields in UpdateMask are set even to empty values, without mask only non-empty fields are updated.
+// The content itself is unchanged
+func UpdateContentMeta(pbWebContent *lispb.WebContent, webContentDir string) (*lispb.WebContent, error) {
+	dbWebContent, err := WebContentByIDAndPath(pbWebContent.WebsiteID, pbWebContent.Path, webContentDir, false)
+	if err != nil {
+		return nil, err
+	}
+	update := func(field string, set bool) bool {
+		if len(pbWebContent.UpdateMask) == 0 {
+			return set
+		}
+		return slices.Contains(pbWebContent.UpdateMask, field)
+	}
+	if update("content_type", pbWebContent.ContentType != "") {
+		dbWebContent.ContentType = pbWebContent.ContentType
+	}
+	if update("headers", pbWebContent.Headers != nil) {
+		dbWebContent.Headers = pbWebContent.Headers
+	}
+	if update("status", pbWebContent.Status != 0) {
+		dbWebContent.Status = pbWebContent.Status
+	}
+	if update("redirect", pbWebContent.Redirect != "") {
+		dbWebContent.Redirect = pbWebContent.Redirect
+	}
+	if update("max_hits", pbWebContent.MaxHits != 0) {
+		dbWebContent.MaxHits = pbWebContent.MaxHits
+	}
+	if update("expire_at", pbWebContent.ExpireAt != 0) {
+		dbWebContent.ExpireAt = pbWebContent.ExpireAt
+	}
+	dbModelWebContent := models.WebContentFromProtobuf(dbWebContent)
+	err = Session().Save(&dbModelWebContent).Error
+	if err != nil {
+		return nil, err
+	}
+	return dbWebContent, nil
+}
+
+// HitContent - Count a request of content, return false if the content has reached its max hits,
+// last is true if this request used up the hits
+func HitContent(id string) (ok bool, last bool, err error) {
+	uuid, _ := uuid.FromString(id)
+	result := Session().Model(&models.WebContent{}).
+		Where("id = ? AND (max_hits = 0 OR hits < max_hits)", uuid).
+		UpdateColumn("hits", gorm.Expr("hits + ?", 1))
+	if result.Error != nil {
+		return false, false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, false, nil
+	}
+	var content models.WebContent
+	err = Session().Select("hits", "max_hits").Where("id = ?", uuid).First(&content).Error
+	if err != nil {
+		return true, false, err
+	}
+	return true, content.MaxHits > 0 && content.Hits >= content.MaxHits, nil
+}
+
+// ExpiredWebContents - content past its expire time, with the website preloaded
+func ExpiredWebContents() ([]*models.WebContent, error) {
+	var contents []*models.WebContent
+	err := Session().Preload("Website").
+		Where("expire_at > ? AND expire_at < ?", time.Time{}, time.Now()).
+		Find(&contents).Error
+	return contents, err
+}
+
+func AddWebsiteAccess(access *lispb.WebsiteAccess) error {
+	return Session().Create(models.WebsiteAccessFromProtobuf(access)).Error
+}
+
+// ListWebsiteAccess - access logs of website, filter by path and remote addr prefix, latest first
+func ListWebsiteAccess(name, path, remoteAddr string, limit int) ([]*models.WebsiteAccess, error) {
+	var accesses []*models.WebsiteAccess
+	query := Session().Where("website = ?", name).Order("created_at desc")
+	if path != "" {
+		query = query.Where("path = ?", path)
+	}
+	if remoteAddr != "" {
+		query = query.Where("remote_addr LIKE ?", remoteAddr+"%")
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&accesses).Error
+	return accesses, err
+}
+
+// WebsiteIDByName - Get website id without loading its contents
+func WebsiteIDByName(name string) (string, error) {
+	var website models.Website
+	if err := Session().Select("id").Where("name = ?", name).First(&website).Error; err != nil {
+		return "", err
+	}
+	return website.ID.String(), nil
+}
+
 func GetWebContentIDByWebsiteID(websiteID string) ([]string, error) {
 	uuid, err := uuid.FromString(websiteID)
 	if err != nil {
@@ -453,3 +773,92 @@ func RemoveWebSite(id string) error {
 	err := Session().Delete(models.Website{}, uuid).Error
 	return err
 }
+
+// report
+func ListSessions() ([]models.Session, error) {
+	var sessions []models.Session
+	err := Session().Order("created_at").Find(&sessions).Error
+	return sessions, err
+}
+
+func ListTasks() ([]models.Task, error) {
+	var tasks []models.Task
+	err := Session().Order("created_at").Find(&tasks).Error
+	return tasks, err
+}
+
+func AddPipelineHistory(name, listenerID, typ, action, errMsg string) error {
+	return Session().Create(&models.PipelineHistory{
+		Name:       name,
+		ListenerID: listenerID,
+		Type:       typ,
+		Action:     action,
+		Error:      errMsg,
+	}).Error
+}
+
+func ListPipelineHistory() ([]models.PipelineHistory, error) {
+	var histories []models.PipelineHistory
+	err := Session().Order("created_at").Find(&histories).Error
+	return histories, err
+}
+
+// SaveArtifact - record artifact, an artifact with the same hash is kept as it is
+func SaveArtifact(artifact *models.Artifact) error {
+	return Session().Where("hash = ?", artifact.Hash).FirstOrCreate(artifact).Error
+}
+
+func FindArtifact(hash string) (*models.Artifact, error) {
+	artifact := &models.Artifact{}
+	err := Session().Where("hash = ?", hash).First(artifact).Error
+	return artifact, err
+}
+
+func ListArtifacts() ([]*models.Artifact, error) {
+	var artifacts []*models.Artifact
+	err := Session().Order("created_at").Find(&artifacts).Error
+	return artifacts, err
+}
+
+func DeleteArtifact(hash string) error {
+	result := Session().Where("hash = ?", hash).Delete(&models.Artifact{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SaveParser - record parser of name, replaces the former one
+func SaveParser(p *models.Parser) error {
+	return Session().Save(p).Error
+}
+
+func ListParsers() ([]*models.Parser, error) {
+	var parsers []*models.Parser
+	err := Session().Order("name").Find(&parsers).Error
+	return parsers, err
+}
+
+func DeleteParser(name string) error {
+	result := Session().Where("name = ?", name).Delete(&models.Parser{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func SaveTaskResult(result *models.TaskResult) error {
+	return Session().Create(result).Error
+}
+
+func FindTaskResult(sessionID string, taskID uint32) (*models.TaskResult, error) {
+	result := &models.TaskResult{}
+	err := Session().Where("session_id = ? AND task_id = ?", sessionID, taskID).First(result).Error
+	return result, err
+}
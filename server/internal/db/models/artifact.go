@@ -0,0 +1,27 @@
+package models
+
+import (
+	"github.com/chainreactors/malice-network/proto/client/clientpb"
+	"time"
+)
+
+// Artifact - extension or alias binary shared by all operators, the content is stored as a file named by Hash
+type Artifact struct {
+	Hash      string    `gorm:"primaryKey"` // sha256 of content
+	CreatedAt time.Time `gorm:"->;<-:create;"`
+	Name      string
+	Type      string
+	Size      int64
+	Operator  string
+}
+
+func (a *Artifact) ToProtobuf() *clientpb.Artifact {
+	return &clientpb.Artifact{
+		Hash:      a.Hash,
+		Name:      a.Name,
+		Type:      a.Type,
+		Size:      a.Size,
+		Callby:    a.Operator,
+		CreatedAt: a.CreatedAt.Unix(),
+	}
+}
@@ -23,3 +23,24 @@ func (o *Operator) BeforeCreate(tx *gorm.DB) (err error) {
 	o.CreatedAt = time.Now()
 	return nil
 }
+
+// LoginHistory - every login attempt of operators
+type LoginHistory struct {
+	ID            uuid.UUID `gorm:"primaryKey;->;<-:create;type:uuid;"`
+	CreatedAt     time.Time `gorm:"->;<-:create;"`
+	Operator      string    `gorm:"index"`
+	RemoteAddr    string
+	ClientVersion string
+	Success       bool
+	Reason        string
+}
+
+// BeforeCreate - GORM hook
+func (l *LoginHistory) BeforeCreate(tx *gorm.DB) (err error) {
+	l.ID, err = uuid.NewV4()
+	if err != nil {
+		return err
+	}
+	l.CreatedAt = time.Now()
+	return nil
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"github.com/chainreactors/malice-network/proto/client/clientpb"
+	"time"
+)
+
+// Parser - wasm artifact parsing output of the extension or alias Name
+type Parser struct {
+	Name      string    `gorm:"primaryKey"`
+	CreatedAt time.Time `gorm:"->;<-:create;"`
+	Hash      string    // artifact hash of the wasm module
+	Operator  string
+}
+
+func (p *Parser) ToProtobuf() *clientpb.Parser {
+	return &clientpb.Parser{
+		Name:      p.Name,
+		Artifact:  p.Hash,
+		Callby:    p.Operator,
+		CreatedAt: p.CreatedAt.Unix(),
+	}
+}
+
+// TaskResult - records parsed from output of a task
+type TaskResult struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	CreatedAt time.Time `gorm:"->;<-:create;"`
+	SessionID string    `gorm:"uniqueIndex:idx_task_result"`
+	TaskID    uint32    `gorm:"uniqueIndex:idx_task_result"`
+	Parser    string
+	Result    string // json encoded parser.Result
+}
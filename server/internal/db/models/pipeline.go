@@ -0,0 +1,28 @@
+package models
+
+import (
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// PipelineHistory - start/stop records of pipelines and websites
+type PipelineHistory struct {
+	ID         uuid.UUID `gorm:"primaryKey;->;<-:create;type:uuid;"`
+	CreatedAt  time.Time `gorm:"->;<-:create;"`
+	Name       string
+	ListenerID string
+	Type       string
+	Action     string
+	Error      string
+}
+
+// BeforeCreate - GORM hook
+func (p *PipelineHistory) BeforeCreate(tx *gorm.DB) (err error) {
+	p.ID, err = uuid.NewV4()
+	if err != nil {
+		return err
+	}
+	p.CreatedAt = time.Now()
+	return nil
+}
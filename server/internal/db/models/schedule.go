@@ -0,0 +1,84 @@
+package models
+
+import (
+	"encoding/json"
+	"github.com/chainreactors/malice-network/proto/client/clientpb"
+	"github.com/chainreactors/malice-network/proto/implant/implantpb"
+	"time"
+)
+
+// Schedule - task run by cron expression or once at a time, on a session or sessions matched by a saved filter
+type Schedule struct {
+	ID        uint32    `gorm:"primaryKey"`
+	CreatedAt time.Time `gorm:"->;<-:create;"`
+	Name      string
+	Spec      string    // cron expression, empty for one-shot
+	At        time.Time // one-shot time
+	SessionID string
+	Filter    string // name of saved session filter
+	Module    string
+	Input     string
+	Args      string // json encoded args
+	Paused    bool
+	Operator  string
+	Runs      int
+	LastRun   time.Time
+	LastBatch uint32
+}
+
+func (s *Schedule) ToProtobuf() *clientpb.Schedule {
+	var args []string
+	if s.Args != "" {
+		json.Unmarshal([]byte(s.Args), &args)
+	}
+	pb := &clientpb.Schedule{
+		Id:        s.ID,
+		Name:      s.Name,
+		Spec:      s.Spec,
+		SessionId: s.SessionID,
+		Filter:    s.Filter,
+		Request: &implantpb.Request{
+			Name:  s.Module,
+			Input: s.Input,
+			Args:  args,
+		},
+		Paused:    s.Paused,
+		Callby:    s.Operator,
+		CreatedAt: s.CreatedAt.Unix(),
+		Runs:      int32(s.Runs),
+		LastBatch: s.LastBatch,
+	}
+	if !s.At.IsZero() {
+		pb.At = s.At.Unix()
+	}
+	if !s.LastRun.IsZero() {
+		pb.LastRun = s.LastRun.Unix()
+	}
+	return pb
+}
+
+func ScheduleFromProtobuf(pb *clientpb.Schedule) *Schedule {
+	schedule := &Schedule{
+		Name:      pb.Name,
+		Spec:      pb.Spec,
+		SessionID: pb.SessionId,
+		Filter:    pb.Filter,
+		Module:    pb.GetRequest().GetName(),
+		Input:     pb.GetRequest().GetInput(),
+		Paused:    pb.Paused,
+		Operator:  pb.Callby,
+	}
+	if pb.At != 0 {
+		schedule.At = time.Unix(pb.At, 0)
+	}
+	if args := pb.GetRequest().GetArgs(); len(args) > 0 {
+		data, _ := json.Marshal(args)
+		schedule.Args = string(data)
+	}
+	return schedule
+}
+
+// Once - one-shot schedule
+func (s *Schedule) Once() bool {
+	return s.Spec == ""
+}
@@ -40,9 +40,13 @@ func (s *Session) BeforeCreate(tx *gorm.DB) (err error) {
 
 func ConvertToSessionDB(session *core.Session) *Session {
 	currentTime := time.Now()
+	group := session.GetGroup()
+	if group == "" {
+		group = "default"
+	}
 	return &Session{
 		SessionID:  session.ID,
-		GroupName:  "default",
+		GroupName:  group,
 		RemoteAddr: session.RemoteAddr,
 		ListenerId: session.PipelineID,
 		Modules:    convertToModuleDB(session.Modules),
@@ -192,3 +196,53 @@ func (t *Timer) toProtobuf() *implantpb.Timer {
 		LastCheckin: t.LastCheckin,
 	}
 }
+
+// SessionTag - tag of session, a session has many tags
+type SessionTag struct {
+	ID        uint   `gorm:"primaryKey"`
+	SessionID string `gorm:"uniqueIndex:idx_session_tag"`
+	Name      string `gorm:"uniqueIndex:idx_session_tag"`
+}
+
+// SessionFilter - named filter of sessions
+type SessionFilter struct {
+	Name      string    `gorm:"primaryKey"`
+	CreatedAt time.Time `gorm:"->;<-:create;"`
+	Os        string
+	Arch      string
+	Hostname  string
+	GroupName string
+	Pipeline  string
+	Tags      string
+	Liveness  string
+}
+
+func (f *SessionFilter) ToProtobuf() *clientpb.SessionFilter {
+	var tags []string
+	if f.Tags != "" {
+		tags = strings.Split(f.Tags, ",")
+	}
+	return &clientpb.SessionFilter{
+		Name:     f.Name,
+		Os:       f.Os,
+		Arch:     f.Arch,
+		Hostname: f.Hostname,
+		Group:    f.GroupName,
+		Pipeline: f.Pipeline,
+		Tags:     tags,
+		Liveness: f.Liveness,
+	}
+}
+
+func SessionFilterFromProtobuf(pb *clientpb.SessionFilter) *SessionFilter {
+	return &SessionFilter{
+		Name:      pb.Name,
+		Os:        pb.Os,
+		Arch:      pb.Arch,
+		Hostname:  pb.Hostname,
+		GroupName: pb.Group,
+		Pipeline:  pb.Pipeline,
+		Tags:      strings.Join(pb.Tags, ","),
+		Liveness:  pb.Liveness,
+	}
+}
@@ -17,6 +17,7 @@ type Task struct {
 	Session     Session `gorm:"foreignKey:SessionID"`
 	Cur         int
 	Total       int
+	Operator    string
 	Description string
 }
 
@@ -79,3 +80,36 @@ func (t *Task) ToDescProtobuf() *clientpb.TaskDesc {
 		Description: t.Description,
 	}
 }
+
+// QueuedTask - request to a late or dead session, delivered in position order when the session checks in.
+// Delivered tasks are saved at shutdown while waiting for responses, and restored when the session comes back
+type QueuedTask struct {
+	ID        uint      `gorm:"primaryKey"`
+	CreatedAt time.Time `gorm:"->;<-:create;"`
+	SessionID string    `gorm:"uniqueIndex:idx_queued_task"`
+	TaskID    uint32    `gorm:"uniqueIndex:idx_queued_task"`
+	Type      string
+	Total     int
+	Cur       int // responses handled before shutdown, for delivered tasks
+	Operator  string
+	Position  int
+	ExpireAt  time.Time
+	Delivered bool
+	Spite     []byte // proto encoded lispb.SpiteSession
+}
+
+func (q *QueuedTask) Expired() bool {
+	return time.Now().After(q.ExpireAt)
+}
+
+func (q *QueuedTask) ToProtobuf() *clientpb.QueuedTask {
+	return &clientpb.QueuedTask{
+		SessionId: q.SessionID,
+		TaskId:    q.TaskID,
+		Type:      q.Type,
+		Callby:    q.Operator,
+		Position:  int32(q.Position),
+		CreatedAt: q.CreatedAt.Unix(),
+		ExpireAt:  q.ExpireAt.Unix(),
+	}
+}
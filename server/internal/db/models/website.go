@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"github.com/chainreactors/malice-network/proto/listener/lispb"
 	"github.com/gofrs/uuid"
 	"gorm.io/gorm"
@@ -52,16 +53,35 @@ type WebContent struct {
 	Path        string `gorm:"primaryKey"`
 	Size        uint64
 	ContentType string
+	Hash        string    // sha256 of content, used as ETag
+	Headers     string    // json encoded custom response headers
+	Status      int32     // custom response status code, 0 means 200
+	Redirect    string    // redirect location, content is ignored if set
+	UpdatedAt   time.Time // used as Last-Modified
+	MaxHits     int32     // content is removed after MaxHits requests, 0 means unlimited
+	Hits        int32
+	ExpireAt    time.Time // content is removed after ExpireAt, zero means never
 }
 
-// BeforeCreate - GORM hook to automatically set values
+// BeforeCreate - GORM hook to automatically set values, an id assigned by the caller is kept
 func (wc *WebContent) BeforeCreate(tx *gorm.DB) (err error) {
+	if wc.ID != uuid.Nil {
+		return nil
+	}
 	wc.ID, err = uuid.NewV4()
 	return err
 }
 
 // ToProtobuf - Converts to protobuf object
 func (wc *WebContent) ToProtobuf(content *[]byte) *lispb.WebContent {
+	headers := map[string]string{}
+	if wc.Headers != "" {
+		json.Unmarshal([]byte(wc.Headers), &headers)
+	}
+	var expireAt int64
+	if !wc.ExpireAt.IsZero() {
+		expireAt = wc.ExpireAt.Unix()
+	}
 	return &lispb.WebContent{
 		ID:          wc.ID.String(),
 		WebsiteID:   wc.WebsiteID.String(),
@@ -69,6 +89,14 @@ func (wc *WebContent) ToProtobuf(content *[]byte) *lispb.WebContent {
 		Size:        uint64(wc.Size),
 		ContentType: wc.ContentType,
 		Content:     *content,
+		Etag:        wc.Hash,
+		Headers:     headers,
+		Status:      wc.Status,
+		Redirect:    wc.Redirect,
+		ModifiedAt:  wc.UpdatedAt.Unix(),
+		MaxHits:     wc.MaxHits,
+		Hits:        wc.Hits,
+		ExpireAt:    expireAt,
 	}
 }
 
@@ -76,11 +104,85 @@ func WebContentFromProtobuf(pbWebContent *lispb.WebContent) WebContent {
 	siteUUID, _ := uuid.FromString(pbWebContent.ID)
 	websiteUUID, _ := uuid.FromString(pbWebContent.WebsiteID)
 
+	var headers string
+	if len(pbWebContent.Headers) > 0 {
+		data, _ := json.Marshal(pbWebContent.Headers)
+		headers = string(data)
+	}
+	var expireAt time.Time
+	if pbWebContent.ExpireAt != 0 {
+		expireAt = time.Unix(pbWebContent.ExpireAt, 0)
+	}
+
 	return WebContent{
 		ID:          siteUUID,
 		WebsiteID:   websiteUUID,
 		Path:        pbWebContent.Path,
 		Size:        pbWebContent.Size,
 		ContentType: pbWebContent.ContentType,
+		Hash:        pbWebContent.Etag,
+		Headers:     headers,
+		Status:      pbWebContent.Status,
+		Redirect:    pbWebContent.Redirect,
+		MaxHits:     pbWebContent.MaxHits,
+		Hits:        pbWebContent.Hits,
+		ExpireAt:    expireAt,
+	}
+}
+
+// WebsiteAccess - access log of website content
+type WebsiteAccess struct {
+	ID         uuid.UUID `gorm:"primaryKey;->;<-:create;type:uuid;"`
+	CreatedAt  time.Time `gorm:"->;<-:create;index"`
+	Website    string    `gorm:"index"`
+	ListenerID string
+	Path       string
+	RemoteAddr string
+	Method     string
+	UserAgent  string
+	Status     int32
+	Bytes      int64
+}
+
+// BeforeCreate - GORM hook
+func (a *WebsiteAccess) BeforeCreate(tx *gorm.DB) (err error) {
+	a.ID, err = uuid.NewV4()
+	if err != nil {
+		return err
+	}
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = time.Now()
+	}
+	return nil
+}
+
+func (a *WebsiteAccess) ToProtobuf() *lispb.WebsiteAccess {
+	return &lispb.WebsiteAccess{
+		Name:       a.Website,
+		ListenerId: a.ListenerID,
+		Path:       a.Path,
+		RemoteAddr: a.RemoteAddr,
+		Method:     a.Method,
+		UserAgent:  a.UserAgent,
+		Status:     a.Status,
+		Bytes:      a.Bytes,
+		Timestamp:  a.CreatedAt.Unix(),
+	}
+}
+
+func WebsiteAccessFromProtobuf(access *lispb.WebsiteAccess) *WebsiteAccess {
+	a := &WebsiteAccess{
+		Website:    access.Name,
+		ListenerID: access.ListenerId,
+		Path:       access.Path,
+		RemoteAddr: access.RemoteAddr,
+		Method:     access.Method,
+		UserAgent:  access.UserAgent,
+		Status:     access.Status,
+		Bytes:      access.Bytes,
+	}
+	if access.Timestamp != 0 {
+		a.CreatedAt = time.Unix(access.Timestamp, 0)
 	}
+	return a
 }
@@ -23,12 +23,22 @@ func NewDBClient() *gorm.DB {
 	}
 	_ = dbClient.AutoMigrate(
 		&models.WebContent{},
+		&models.WebsiteAccess{},
 		&models.Website{},
 		&models.Operator{},
+		&models.LoginHistory{},
 		&models.Certificate{},
 		&models.Session{},
+		&models.SessionTag{},
+		&models.SessionFilter{},
+		&models.Schedule{},
 		&models.Task{},
+		&models.QueuedTask{},
+		&models.Artifact{},
+		&models.Parser{},
+		&models.TaskResult{},
 		&models.Listener{},
+		&models.PipelineHistory{},
 	)
 	if dbClient == nil {
 		logs.Log.Errorf("Failed to initialize database")
@@ -46,6 +56,10 @@ func NewDBClient() *gorm.DB {
 
 		// SetConnMaxLifetime sets the maximum amount of time a connection may be reused.
 		sqlDB.SetConnMaxLifetime(time.Hour)
+
+		if err := registerMetrics(dbClient); err != nil {
+			logs.Log.Errorf("Failed to register db metrics: %v", err)
+		}
 	}
 	return dbClient
 }
@@ -0,0 +1,153 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"github.com/chainreactors/logs"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net"
+	"net/http"
+	"time"
+)
+
+const namespace = "malice"
+
+var (
+	Registry = prometheus.NewRegistry()
+
+	RPCLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rpc_duration_seconds",
+		Help:      "Latency of grpc calls per method",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	DBLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Latency of database queries per operation",
+		Buckets:   []float64{.0005, .001, .005, .01, .05, .1, .5, 1},
+	}, []string{"operation", "table"})
+)
+
+func init() {
+	Registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		RPCLatency,
+		DBLatency,
+	)
+}
+
+// RegisterGauge - register a gauge whose value is collected by fn on every scrape
+func RegisterGauge(name, help string, fn func() float64) {
+	register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      name,
+		Help:      help,
+	}, fn))
+}
+
+// RegisterCounter - register a counter of a cumulative value collected by fn on every scrape
+func RegisterCounter(name, help string, fn func() float64) {
+	register(prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      name,
+		Help:      help,
+	}, fn))
+}
+
+// RegisterGaugeVec - register a gauge with one label, values are collected by fn on every scrape
+func RegisterGaugeVec(name, help, label string, fn func() map[string]float64) {
+	registerVec(name, help, label, prometheus.GaugeValue, fn)
+}
+
+// RegisterCounterVec - register a counter with one label, cumulative values are collected by fn on every scrape
+func RegisterCounterVec(name, help, label string, fn func() map[string]float64) {
+	registerVec(name, help, label, prometheus.CounterValue, fn)
+}
+
+func registerVec(name, help, label string, typ prometheus.ValueType, fn func() map[string]float64) {
+	register(&vecFunc{
+		desc: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", name), help, []string{label}, nil),
+		typ:  typ,
+		fn:   fn,
+	})
+}
+
+// register - server and listener in the same process share the registry, the collector registered first is kept
+func register(c prometheus.Collector) {
+	err := Registry.Register(c)
+	if err == nil {
+		return
+	}
+	var already prometheus.AlreadyRegisteredError
+	if errors.As(err, &already) {
+		return
+	}
+	logs.Log.Errorf("register metrics failed, %s", err.Error())
+}
+
+type vecFunc struct {
+	desc *prometheus.Desc
+	typ  prometheus.ValueType
+	fn   func() map[string]float64
+}
+
+func (g *vecFunc) Describe(ch chan<- *prometheus.Desc) {
+	ch <- g.desc
+}
+
+func (g *vecFunc) Collect(ch chan<- prometheus.Metric) {
+	for label, value := range g.fn() {
+		ch <- prometheus.MustNewConstMetric(g.desc, g.typ, value, label)
+	}
+}
+
+func ObserveRPC(method, code string, d time.Duration) {
+	RPCLatency.WithLabelValues(method, code).Observe(d.Seconds())
+}
+
+func ObserveDB(operation, table string, d time.Duration) {
+	DBLatency.WithLabelValues(operation, table).Observe(d.Seconds())
+}
+
+// Server - metrics http endpoint
+type Server struct {
+	server *http.Server
+	ln     net.Listener
+}
+
+func (s *Server) Addr() string {
+	return s.ln.Addr().String()
+}
+
+func (s *Server) Close() error {
+	return s.server.Close()
+}
+
+// Start - start metrics http endpoint on addr, requests on other path return 404
+func Start(addr, path string) (*Server, error) {
+	if path == "" {
+		path = "/metrics"
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle(path, promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}))
+	s := &Server{
+		server: &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second},
+		ln:     ln,
+	}
+	go func() {
+		err := s.server.Serve(ln)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logs.Log.Errorf("metrics server exited, %s", err.Error())
+		}
+	}()
+	logs.Log.Importantf("Starting metrics endpoint on %s", fmt.Sprintf("http://%s%s", ln.Addr(), path))
+	return s, nil
+}
@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScrape(t *testing.T) {
+	RegisterGauge("test_tasks_in_flight", "test gauge", func() float64 {
+		return 3
+	})
+	RegisterGaugeVec("test_sessions", "test gauge vec", "pipeline", func() map[string]float64 {
+		return map[string]float64{"tcp_default": 2, "http_default": 1}
+	})
+	RegisterCounterVec("test_forward_total", "test counter vec", "pipeline", func() map[string]float64 {
+		return map[string]float64{"tcp_default": 5}
+	})
+	// registered by server and listener in the same process
+	RegisterGauge("test_tasks_in_flight", "test gauge", func() float64 {
+		return 4
+	})
+	ObserveRPC("/clientrpc.MaliceRPC/GetSessions", "OK", 20*time.Millisecond)
+	ObserveDB("query", "sessions", time.Millisecond)
+
+	s, err := Start("127.0.0.1:0", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	resp, err := http.Get("http://" + s.Addr() + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expect := range []string{
+		"malice_test_tasks_in_flight 3",
+		"# TYPE malice_test_forward_total counter",
+		`malice_test_forward_total{pipeline="tcp_default"} 5`,
+		`malice_test_sessions{pipeline="tcp_default"} 2`,
+		`malice_test_sessions{pipeline="http_default"} 1`,
+		`malice_rpc_duration_seconds_count{code="OK",method="/clientrpc.MaliceRPC/GetSessions"} 1`,
+		`malice_db_query_duration_seconds_count{operation="query",table="sessions"} 1`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(string(body), expect) {
+			t.Errorf("metric %q not found", expect)
+		}
+	}
+
+	resp, err = http.Get("http://" + s.Addr() + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expect 404 outside metrics path, got %d", resp.StatusCode)
+	}
+}
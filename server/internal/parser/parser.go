@@ -0,0 +1,170 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/chainreactors/logs"
+	"github.com/chainreactors/malice-network/helper/consts"
+	"github.com/chainreactors/malice-network/helper/encoders/traffic"
+	"strconv"
+	"sync"
+)
+
+var (
+	ErrNotFoundParser = errors.New("parser not found")
+
+	parsers = &registry{
+		names:   map[string]string{},
+		modules: map[string]*traffic.ResultParser{},
+	}
+)
+
+// Result - records a parser returns as json, every kind is shown as a table
+type Result struct {
+	Tables      []*Table      `json:"tables,omitempty"`
+	Credentials []*Credential `json:"credentials,omitempty"`
+	Hosts       []*Host       `json:"hosts,omitempty"`
+	Files       []*File       `json:"files,omitempty"`
+}
+
+type Table struct {
+	Name    string     `json:"name"`
+	Columns []string   `json:"columns"`
+	Rows    [][]string `json:"rows"`
+}
+
+type Credential struct {
+	Username string `json:"username"`
+	Password string `json:"password,omitempty"`
+	Hash     string `json:"hash,omitempty"`
+	Domain   string `json:"domain,omitempty"`
+	Source   string `json:"source,omitempty"`
+}
+
+type Host struct {
+	Hostname string `json:"hostname"`
+	IP       string `json:"ip,omitempty"`
+	OS       string `json:"os,omitempty"`
+	Note     string `json:"note,omitempty"`
+}
+
+type File struct {
+	Path string `json:"path"`
+	Size int64  `json:"size,omitempty"`
+	Hash string `json:"hash,omitempty"`
+	Note string `json:"note,omitempty"`
+}
+
+func (r *Result) Empty() bool {
+	return len(r.Tables) == 0 && len(r.Credentials) == 0 && len(r.Hosts) == 0 && len(r.Files) == 0
+}
+
+// ToTables - parser tables followed by credentials, hosts and files tables
+func (r *Result) ToTables() []*Table {
+	tables := append([]*Table{}, r.Tables...)
+	if len(r.Credentials) > 0 {
+		table := &Table{Name: "credentials", Columns: []string{"username", "password", "hash", "domain", "source"}}
+		for _, c := range r.Credentials {
+			table.Rows = append(table.Rows, []string{c.Username, c.Password, c.Hash, c.Domain, c.Source})
+		}
+		tables = append(tables, table)
+	}
+	if len(r.Hosts) > 0 {
+		table := &Table{Name: "hosts", Columns: []string{"hostname", "ip", "os", "note"}}
+		for _, h := range r.Hosts {
+			table.Rows = append(table.Rows, []string{h.Hostname, h.IP, h.OS, h.Note})
+		}
+		tables = append(tables, table)
+	}
+	if len(r.Files) > 0 {
+		table := &Table{Name: "files", Columns: []string{"path", "size", "hash", "note"}}
+		for _, f := range r.Files {
+			table.Rows = append(table.Rows, []string{f.Path, strconv.FormatInt(f.Size, 10), f.Hash, f.Note})
+		}
+		tables = append(tables, table)
+	}
+	return tables
+}
+
+// registry - extension or alias name -> parser id, modules are shared by names with the same wasm
+type registry struct {
+	lock    sync.RWMutex
+	names   map[string]string
+	modules map[string]*traffic.ResultParser
+}
+
+// Register - use wasm to parse output of extension or alias name, replaces the former parser of name
+func Register(name string, wasm []byte) error {
+	module, err := traffic.CreateResultParser(name, wasm, func(msg string) {
+		logs.Log.Debugf("[parser] %s: %s", name, msg)
+	})
+	if err != nil {
+		return err
+	}
+	parsers.lock.Lock()
+	defer parsers.lock.Unlock()
+	if loaded, ok := parsers.modules[module.ID]; ok {
+		module.Close()
+		module = loaded
+	} else {
+		parsers.modules[module.ID] = module
+	}
+	parsers.names[name] = module.ID
+	parsers.release()
+	return nil
+}
+
+func Remove(name string) error {
+	parsers.lock.Lock()
+	defer parsers.lock.Unlock()
+	if _, ok := parsers.names[name]; !ok {
+		return ErrNotFoundParser
+	}
+	delete(parsers.names, name)
+	parsers.release()
+	return nil
+}
+
+func Has(name string) bool {
+	parsers.lock.RLock()
+	defer parsers.lock.RUnlock()
+	_, ok := parsers.names[name]
+	return ok
+}
+
+// release - close modules no name refers to, called with lock held
+func (r *registry) release() {
+	used := map[string]bool{}
+	for _, id := range r.names {
+		used[id] = true
+	}
+	for id, module := range r.modules {
+		if !used[id] {
+			module.Close()
+			delete(r.modules, id)
+		}
+	}
+}
+
+// Parse - parse output of extension or alias name, ErrNotFoundParser if name has no parser.
+// The module is not closed by Register or Remove while parsing, a parse is stopped after ParserTimeout.
+func Parse(name string, output []byte) (*Result, error) {
+	parsers.lock.RLock()
+	defer parsers.lock.RUnlock()
+	module, ok := parsers.modules[parsers.names[name]]
+	if !ok {
+		return nil, ErrNotFoundParser
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), consts.ParserTimeout)
+	defer cancel()
+	data, err := module.Parse(ctx, output)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{}
+	if err = json.Unmarshal(data, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,39 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestResultToTables(t *testing.T) {
+	result := &Result{
+		Tables:      []*Table{{Name: "shares", Columns: []string{"name"}, Rows: [][]string{{"C$"}}}},
+		Credentials: []*Credential{{Username: "admin", Hash: "aad3b435", Domain: "CORP"}},
+		Files:       []*File{{Path: `C:\secrets.kdbx`, Size: 2048}},
+	}
+	tables := result.ToTables()
+	if len(tables) != 3 {
+		t.Fatalf("unexpected tables %d", len(tables))
+	}
+	if tables[0].Name != "shares" || tables[1].Name != "credentials" || tables[2].Name != "files" {
+		t.Errorf("unexpected table order %s %s %s", tables[0].Name, tables[1].Name, tables[2].Name)
+	}
+	if row := tables[1].Rows[0]; row[0] != "admin" || row[2] != "aad3b435" || row[3] != "CORP" {
+		t.Errorf("unexpected credential row %v", row)
+	}
+	if row := tables[2].Rows[0]; row[1] != "2048" {
+		t.Errorf("unexpected file row %v", row)
+	}
+	if !(&Result{}).Empty() || result.Empty() {
+		t.Error("unexpected empty result")
+	}
+
+	if _, err := Parse("unknown", []byte("output")); err != ErrNotFoundParser {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := Register("broken", []byte("not wasm")); err == nil {
+		t.Error("invalid wasm registered")
+	}
+	if Has("broken") {
+		t.Error("broken parser registered")
+	}
+}
@@ -0,0 +1,245 @@
+package report
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatJSON     = "json"
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown report format")
+
+	//go:embed templates/*.tmpl
+	templates embed.FS
+)
+
+// Report - all data collected from server for one engagement
+type Report struct {
+	Name        string      `json:"name"`
+	Operator    string      `json:"operator"`
+	GeneratedAt time.Time   `json:"generated_at"`
+	Sessions    []*Session  `json:"sessions"`
+	Hosts       []*Host     `json:"hosts"`
+	Tasks       []*Task     `json:"tasks"`
+	Loot        []*Loot     `json:"loot"`
+	Listeners   []*Listener `json:"listeners"`
+	Pipelines   []*Pipeline `json:"pipelines"`
+	Operators   []*Operator `json:"operators"`
+}
+
+type Session struct {
+	ID         string    `json:"id"`
+	Note       string    `json:"note"`
+	Group      string    `json:"group"`
+	RemoteAddr string    `json:"remote_addr"`
+	ListenerID string    `json:"listener_id"`
+	Hostname   string    `json:"hostname"`
+	Username   string    `json:"username"`
+	Os         string    `json:"os"`
+	Arch       string    `json:"arch"`
+	Process    string    `json:"process"`
+	Pid        int32     `json:"pid"`
+	IsAlive    bool      `json:"is_alive"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeen   time.Time `json:"last_seen"`
+}
+
+type Host struct {
+	Hostname  string    `json:"hostname"`
+	Os        string    `json:"os"`
+	Arch      string    `json:"arch"`
+	Addrs     []string  `json:"addrs"`
+	Sessions  []string  `json:"sessions"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+type Task struct {
+	ID          uint32    `json:"id"`
+	SessionID   string    `json:"session_id"`
+	Type        string    `json:"type"`
+	Operator    string    `json:"operator"`
+	Cur         int       `json:"cur"`
+	Total       int       `json:"total"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (t *Task) Finished() bool {
+	return t.Total > 0 && t.Cur >= t.Total
+}
+
+type Loot struct {
+	SessionID string    `json:"session_id"`
+	TaskID    uint32    `json:"task_id"`
+	Type      string    `json:"type"`
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	Size      int64     `json:"size"`
+	Operator  string    `json:"operator"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Listener struct {
+	Name      string    `json:"name"`
+	Addr      string    `json:"addr"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Pipeline struct {
+	Name       string    `json:"name"`
+	ListenerID string    `json:"listener_id"`
+	Type       string    `json:"type"`
+	Action     string    `json:"action"`
+	Error      string    `json:"error"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type Operator struct {
+	Name string `json:"name"`
+}
+
+// BuildHosts - group sessions by hostname
+func (r *Report) BuildHosts() {
+	hosts := map[string]*Host{}
+	for _, sess := range r.Sessions {
+		name := sess.Hostname
+		if name == "" {
+			name = sess.RemoteAddr
+		}
+		host, ok := hosts[name]
+		if !ok {
+			host = &Host{
+				Hostname:  name,
+				Os:        sess.Os,
+				Arch:      sess.Arch,
+				FirstSeen: sess.CreatedAt,
+				LastSeen:  sess.LastSeen,
+			}
+			hosts[name] = host
+		}
+		host.Sessions = append(host.Sessions, sess.ID)
+		if sess.RemoteAddr != "" && !contains(host.Addrs, sess.RemoteAddr) {
+			host.Addrs = append(host.Addrs, sess.RemoteAddr)
+		}
+		if sess.CreatedAt.Before(host.FirstSeen) {
+			host.FirstSeen = sess.CreatedAt
+		}
+		if sess.LastSeen.After(host.LastSeen) {
+			host.LastSeen = sess.LastSeen
+		}
+	}
+	r.Hosts = make([]*Host, 0, len(hosts))
+	for _, host := range hosts {
+		r.Hosts = append(r.Hosts, host)
+	}
+	sort.Slice(r.Hosts, func(i, j int) bool {
+		return r.Hosts[i].FirstSeen.Before(r.Hosts[j].FirstSeen)
+	})
+}
+
+// Sort - order every section as a timeline
+func (r *Report) Sort() {
+	sort.SliceStable(r.Sessions, func(i, j int) bool {
+		return r.Sessions[i].CreatedAt.Before(r.Sessions[j].CreatedAt)
+	})
+	sort.SliceStable(r.Tasks, func(i, j int) bool {
+		return r.Tasks[i].CreatedAt.Before(r.Tasks[j].CreatedAt)
+	})
+	sort.SliceStable(r.Loot, func(i, j int) bool {
+		return r.Loot[i].CreatedAt.Before(r.Loot[j].CreatedAt)
+	})
+	sort.SliceStable(r.Pipelines, func(i, j int) bool {
+		return r.Pipelines[i].CreatedAt.Before(r.Pipelines[j].CreatedAt)
+	})
+}
+
+// Render - render report with the builtin template of format, or with tmpl if not empty
+func (r *Report) Render(format string, tmpl string) ([]byte, error) {
+	if format == "" {
+		format = FormatMarkdown
+	}
+	if tmpl == "" {
+		content, err := templates.ReadFile(path.Join("templates", format+".tmpl"))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+		}
+		tmpl = string(content)
+	}
+
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case FormatHTML:
+		err = renderHTML(&buf, tmpl, r)
+	case FormatMarkdown, FormatJSON:
+		err = renderText(&buf, tmpl, r)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func renderText(w io.Writer, tmpl string, r *Report) error {
+	t, err := template.New("report").Funcs(template.FuncMap(funcs)).Parse(tmpl)
+	if err != nil {
+		return err
+	}
+	return t.Execute(w, r)
+}
+
+func renderHTML(w io.Writer, tmpl string, r *Report) error {
+	t, err := htmltemplate.New("report").Funcs(htmltemplate.FuncMap(funcs)).Parse(tmpl)
+	if err != nil {
+		return err
+	}
+	return t.Execute(w, r)
+}
+
+var funcs = map[string]any{
+	"json": func(v any) (string, error) {
+		content, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(content), nil
+	},
+	"time": func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.Format("2006-01-02 15:04:05")
+	},
+	"join": strings.Join,
+	"md": func(s string) string {
+		return strings.NewReplacer("|", "\\|", "\n", " ").Replace(s)
+	},
+}
+
+func contains(s []string, e string) bool {
+	for _, v := range s {
+		if v == e {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testReport() *Report {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	r := &Report{
+		Name:        "engagement",
+		Operator:    "admin",
+		GeneratedAt: now,
+		Sessions: []*Session{
+			{ID: "s2", Hostname: "dc01", RemoteAddr: "10.0.0.2", Os: "windows", CreatedAt: now.Add(time.Hour), LastSeen: now.Add(2 * time.Hour)},
+			{ID: "s1", Hostname: "dc01", RemoteAddr: "10.0.0.1", Os: "windows", CreatedAt: now, LastSeen: now.Add(time.Hour)},
+			{ID: "s3", Hostname: "web<01>", RemoteAddr: "10.0.0.3", Os: "linux", CreatedAt: now.Add(3 * time.Hour)},
+		},
+		Tasks: []*Task{
+			{ID: 2, SessionID: "s1", Type: "download", Operator: "bob", Cur: 1, Total: 1, CreatedAt: now.Add(time.Minute)},
+			{ID: 1, SessionID: "s1", Type: "whoami", Operator: "alice|x", Cur: 0, Total: 1, CreatedAt: now},
+		},
+		Loot: []*Loot{
+			{SessionID: "s1", TaskID: 2, Type: "download", Name: "ntds.dit", Path: "C:\\ntds.dit", Size: 1024, Operator: "bob"},
+		},
+	}
+	r.BuildHosts()
+	r.Sort()
+	return r
+}
+
+func TestBuildHosts(t *testing.T) {
+	r := testReport()
+	if len(r.Hosts) != 2 {
+		t.Fatalf("expect 2 hosts, got %d", len(r.Hosts))
+	}
+	dc := r.Hosts[0]
+	if dc.Hostname != "dc01" || len(dc.Sessions) != 2 || len(dc.Addrs) != 2 {
+		t.Fatalf("unexpected host %+v", dc)
+	}
+	if !dc.LastSeen.Equal(r.GeneratedAt.Add(2 * time.Hour)) {
+		t.Fatalf("unexpected last seen %s", dc.LastSeen)
+	}
+	if r.Sessions[0].ID != "s1" || r.Tasks[0].ID != 1 {
+		t.Fatal("report not sorted by time")
+	}
+}
+
+func TestRender(t *testing.T) {
+	r := testReport()
+
+	md, err := r.Render(FormatMarkdown, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(md), "| 2024-06-01 12:00:00 | s1 | 1 | whoami | alice\\|x | 0/1 |") {
+		t.Fatalf("markdown task row missing:\n%s", md)
+	}
+
+	html, err := r.Render(FormatHTML, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(html), "web&lt;01&gt;") {
+		t.Fatal("html output is not escaped")
+	}
+
+	content, err := r.Render(FormatJSON, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(content, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Loot) != 1 || decoded.Loot[0].Name != "ntds.dit" {
+		t.Fatalf("unexpected json loot %+v", decoded.Loot)
+	}
+
+	custom, err := r.Render(FormatMarkdown, "{{ range .Tasks }}{{ .Operator }};{{ end }}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(custom) != "alice|x;bob;" {
+		t.Fatalf("unexpected custom output %q", custom)
+	}
+
+	if _, err := r.Render("pdf", ""); !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("expect ErrUnknownFormat, got %v", err)
+	}
+}
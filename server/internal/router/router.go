@@ -0,0 +1,92 @@
+package router
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Router - per website http router, routes can be added, updated and removed at runtime.
+// Pattern ending with "/" matches the whole subtree like http.ServeMux, the longest pattern wins.
+type Router struct {
+	mu       sync.RWMutex
+	routes   map[string]http.Handler
+	patterns []string // sorted by length, longest first
+	NotFound http.Handler
+}
+
+func NewRouter() *Router {
+	return &Router{
+		routes:   map[string]http.Handler{},
+		NotFound: http.NotFoundHandler(),
+	}
+}
+
+// Handle - add or replace handler of pattern
+func (r *Router) Handle(pattern string, handler http.Handler) {
+	if pattern == "" {
+		pattern = "/"
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.routes[pattern]; !ok {
+		r.patterns = append(r.patterns, pattern)
+		sort.SliceStable(r.patterns, func(i, j int) bool {
+			return len(r.patterns[i]) > len(r.patterns[j])
+		})
+	}
+	r.routes[pattern] = handler
+}
+
+func (r *Router) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	r.Handle(pattern, http.HandlerFunc(handler))
+}
+
+// Remove - remove pattern, return false if pattern not found
+func (r *Router) Remove(pattern string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.routes[pattern]; !ok {
+		return false
+	}
+	delete(r.routes, pattern)
+	for i, p := range r.patterns {
+		if p == pattern {
+			r.patterns = append(r.patterns[:i], r.patterns[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// Patterns - all registered patterns
+func (r *Router) Patterns() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]string{}, r.patterns...)
+}
+
+// Match - find the handler of path
+func (r *Router) Match(path string) (http.Handler, string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if h, ok := r.routes[path]; ok {
+		return h, path
+	}
+	for _, p := range r.patterns {
+		if strings.HasSuffix(p, "/") && strings.HasPrefix(path, p) {
+			return r.routes[p], p
+		}
+	}
+	return nil, ""
+}
+
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	h, _ := r.Match(req.URL.Path)
+	if h == nil {
+		r.NotFound.ServeHTTP(w, req)
+		return
+	}
+	h.ServeHTTP(w, req)
+}
@@ -0,0 +1,259 @@
+package router
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func text(s string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, s)
+	}
+}
+
+func get(t *testing.T, addr, host, path string) (int, string) {
+	req, err := http.NewRequest(http.MethodGet, "http://"+addr+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if host != "" {
+		req.Host = host
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func expect(t *testing.T, addr, host, path string, code int, body string) {
+	t.Helper()
+	c, b := get(t, addr, host, path)
+	if c != code || (body != "" && b != body) {
+		t.Fatalf("GET %s%s (host %q): expect %d %q, got %d %q", addr, path, host, code, body, c, b)
+	}
+}
+
+func TestRouterRuntimeRoutes(t *testing.T) {
+	r := NewRouter()
+	r.Handle("/", text("root"))
+	r.Handle("/static/", text("static"))
+	r.Handle("/static/a.js", text("a"))
+
+	s, err := Listen("127.0.0.1:0", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if err := s.AddHost("", r, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	expect(t, s.Addr(), "", "/static/a.js", 200, "a")
+	expect(t, s.Addr(), "", "/static/b.js", 200, "static")
+	expect(t, s.Addr(), "", "/index", 200, "root")
+
+	r.Handle("/static/a.js", text("a2"))
+	expect(t, s.Addr(), "", "/static/a.js", 200, "a2")
+
+	if !r.Remove("/static/a.js") {
+		t.Fatal("remove route failed")
+	}
+	expect(t, s.Addr(), "", "/static/a.js", 200, "static")
+	r.Remove("/static/")
+	r.Remove("/")
+	expect(t, s.Addr(), "", "/static/a.js", 404, "")
+	if r.Remove("/") {
+		t.Fatal("remove a missing route should return false")
+	}
+}
+
+func TestSameRootPathOnDifferentPorts(t *testing.T) {
+	site1, site2 := NewRouter(), NewRouter()
+	site1.Handle("/", text("site1"))
+	site2.Handle("/", text("site2"))
+
+	s1, err := Listen("127.0.0.1:0", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s1.Close()
+	s2, err := Listen("127.0.0.1:0", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s2.Close()
+	s1.AddHost("", site1, nil)
+	s2.AddHost("", site2, nil)
+
+	expect(t, s1.Addr(), "", "/payload", 200, "site1")
+	expect(t, s2.Addr(), "", "/payload", 200, "site2")
+}
+
+func TestVirtualHostOnSharedPort(t *testing.T) {
+	ln, err := Listen("127.0.0.1:0", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr()
+	ln.Close()
+
+	site1, site2, fallback := NewRouter(), NewRouter(), NewRouter()
+	site1.Handle("/", text("cdn"))
+	site2.Handle("/", text("update"))
+	fallback.Handle("/", text("default"))
+
+	s1, err := Listen(addr, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2, err := Listen(addr, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s1 != s2 {
+		t.Fatal("websites on the same port should share one server")
+	}
+	if err := s1.AddHost("cdn.example.com", site1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := s2.AddHost("Update.Example.com", site2, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := s2.AddHost("cdn.example.com", site2, nil); err != ErrHostExists {
+		t.Fatalf("expect ErrHostExists, got %v", err)
+	}
+
+	expect(t, addr, "cdn.example.com", "/", 200, "cdn")
+	expect(t, addr, "update.example.com:8080", "/", 200, "update")
+	expect(t, addr, "other.example.com", "/", 404, "")
+
+	s1.AddHost("", fallback, nil)
+	expect(t, addr, "other.example.com", "/", 200, "default")
+
+	if _, err := Listen(addr, nil); err != nil {
+		t.Fatal(err)
+	}
+	s1.RemoveHost("cdn.example.com")
+	expect(t, addr, "cdn.example.com", "/", 200, "default")
+	s1.RemoveHost("")
+	s1.RemoveHost("update.example.com")
+
+	// last host removed, port is released
+	s3, err := Listen(addr, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s3.Close()
+	if s3 == s1 {
+		t.Fatal("server should be closed after all hosts removed")
+	}
+}
+
+func TestGlobalRoute(t *testing.T) {
+	site := NewRouter()
+	site.Handle("/", text("site"))
+
+	s, err := Listen("127.0.0.1:0", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.AddHost("cdn.example.com", site, nil)
+	s.HandleGlobal("/.well-known/acme-challenge/", text("token"))
+
+	expect(t, s.Addr(), "cdn.example.com", "/.well-known/acme-challenge/abc", 200, "token")
+	expect(t, s.Addr(), "other.example.com", "/.well-known/acme-challenge/abc", 200, "token")
+	expect(t, s.Addr(), "cdn.example.com", "/index", 200, "site")
+
+	// global routes keep the server alive
+	s.RemoveHost("cdn.example.com")
+	expect(t, s.Addr(), "cdn.example.com", "/.well-known/acme-challenge/abc", 200, "token")
+
+	// and the server is closed with the last of them
+	s.RemoveGlobal("/.well-known/acme-challenge/")
+	if _, err := http.Get("http://" + s.Addr() + "/"); err == nil {
+		t.Fatal("server should be closed after all global routes removed")
+	}
+}
+
+func TestCertificateGetter(t *testing.T) {
+	static := tls.Certificate{Certificate: [][]byte{{1}}}
+	dynamic := &tls.Certificate{Certificate: [][]byte{{2}}}
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{static}}
+	s, err := Listen("127.0.0.1:0", tlsConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.AddHost("", NewRouter(), tlsConfig)
+	s.AddHost("acme.example.com", NewRouter(), &tls.Config{GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+		if hello.ServerName != "acme.example.com" {
+			return nil, errors.New("host not allowed")
+		}
+		return dynamic, nil
+	}})
+
+	cert, err := s.getCertificate(&tls.ClientHelloInfo{ServerName: "acme.example.com"})
+	if err != nil || cert != dynamic {
+		t.Fatalf("expect acme certificate, got %v %v", cert, err)
+	}
+	cert, err = s.getCertificate(&tls.ClientHelloInfo{ServerName: "other.example.com"})
+	if err != nil || cert.Certificate[0][0] != 1 {
+		t.Fatalf("expect static certificate, got %v %v", cert, err)
+	}
+}
+
+func TestCertificateByServerName(t *testing.T) {
+	newCert := func(name string) *tls.Certificate {
+		return &tls.Certificate{Certificate: [][]byte{{}}, Leaf: &x509.Certificate{DNSNames: []string{name}}}
+	}
+	cdn, update := newCert("cdn.example.com"), newCert("update.example.com")
+	getter := func(cert *tls.Certificate) *tls.Config {
+		return &tls.Config{GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return cert, nil
+		}}
+	}
+	s, err := Listen("127.0.0.1:0", getter(cdn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.AddHost("cdn.example.com", NewRouter(), getter(cdn))
+	s.AddHost("update.example.com", NewRouter(), getter(update))
+
+	for name, expect := range map[string]*tls.Certificate{
+		"cdn.example.com":    cdn,
+		"update.example.com": update,
+		"other.example.com":  cdn,
+		"":                   cdn,
+	} {
+		cert, err := s.getCertificate(&tls.ClientHelloInfo{ServerName: name})
+		if err != nil || cert != expect {
+			t.Fatalf("%q: unexpected certificate %v %v", name, cert, err)
+		}
+	}
+
+	// certificates of removed host are not served any more
+	s.RemoveHost("cdn.example.com")
+	for _, name := range []string{"cdn.example.com", "update.example.com"} {
+		cert, err := s.getCertificate(&tls.ClientHelloInfo{ServerName: name})
+		if err != nil || cert != update {
+			t.Fatalf("%q: unexpected certificate %v %v", name, cert, err)
+		}
+	}
+	s.RemoveHost("update.example.com")
+	if cert, err := s.getCertificate(&tls.ClientHelloInfo{ServerName: "update.example.com"}); err == nil {
+		t.Fatalf("expect no certificate, got %v", cert)
+	}
+	if err := s.AddHost("update.example.com", NewRouter(), nil); !errors.Is(err, http.ErrServerClosed) {
+		t.Fatalf("expect closed server, got %v", err)
+	}
+}
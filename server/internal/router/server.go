@@ -0,0 +1,254 @@
+package router
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chainreactors/logs"
+)
+
+var (
+	ErrHostExists  = errors.New("host already exists on this port")
+	ErrHostMissing = errors.New("host not found on this port")
+	ErrTLSMismatch = errors.New("tls settings conflict with other websites on this port")
+
+	servers   = map[string]*Server{}
+	serversMu sync.Mutex
+)
+
+// Server - http server shared by websites on the same port, requests are routed by Host header.
+// Website registered with empty host is the default of the port.
+type Server struct {
+	addr   string
+	mu     sync.RWMutex
+	hosts  map[string]*Router
+	global *Router // routes of all hosts, matched before hosts, e.g. acme challenge
+	// tls configs of hosts in the order of AddHost, removed with the host
+	tlsHosts []string
+	tlsCfgs  map[string]*tls.Config
+	tls      bool
+	closed   bool
+	server   *http.Server
+	ln       net.Listener
+}
+
+// Listen - get the running server of addr, or start a new one.
+// tlsConfig may be nil, it only decides whether the port serves tls,
+// certificates are registered with the host by AddHost.
+func Listen(addr string, tlsConfig *tls.Config) (*Server, error) {
+	serversMu.Lock()
+	defer serversMu.Unlock()
+	if s, ok := servers[addr]; ok {
+		if s.tls != (tlsConfig != nil) {
+			return nil, ErrTLSMismatch
+		}
+		return s, nil
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		addr:    addr,
+		hosts:   map[string]*Router{},
+		global:  NewRouter(),
+		tlsCfgs: map[string]*tls.Config{},
+		tls:     tlsConfig != nil,
+		ln:      ln,
+	}
+	s.server = &http.Server{Handler: s, ReadHeaderTimeout: 30 * time.Second}
+	if tlsConfig != nil {
+		s.server.TLSConfig = &tls.Config{GetCertificate: s.getCertificate, NextProtos: tlsConfig.NextProtos}
+		ln = tls.NewListener(ln, s.server.TLSConfig)
+	}
+	if !strings.HasSuffix(addr, ":0") {
+		servers[addr] = s
+	}
+	go func() {
+		logs.Log.Importantf("HTTP Server is running on %s", s.Addr())
+		if err := s.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logs.Log.Errorf("HTTP Server %s exited: %v", s.Addr(), err)
+		}
+	}()
+	return s, nil
+}
+
+// Addr - real listening address
+func (s *Server) Addr() string {
+	return s.ln.Addr().String()
+}
+
+func (s *Server) TLS() bool {
+	return s.tls
+}
+
+// AddHost - route requests of host to r, certificates of tlsConfig are selected by SNI among all hosts
+// on this port, tlsConfig.GetCertificate (e.g. acme) is tried before static certificates.
+func (s *Server) AddHost(host string, r *Router, tlsConfig *tls.Config) error {
+	host = normalizeHost(host)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return http.ErrServerClosed
+	}
+	if _, ok := s.hosts[host]; ok {
+		return ErrHostExists
+	}
+	s.hosts[host] = r
+	if tlsConfig != nil {
+		s.tlsHosts = append(s.tlsHosts, host)
+		s.tlsCfgs[host] = tlsConfig
+	}
+	return nil
+}
+
+// HandleGlobal - add route for all hosts on this server, matched before host routes
+func (s *Server) HandleGlobal(pattern string, handler http.Handler) {
+	s.global.Handle(pattern, handler)
+}
+
+// RemoveGlobal - remove route for all hosts, the server is closed when no host and global route left
+func (s *Server) RemoveGlobal(pattern string) error {
+	serversMu.Lock()
+	defer serversMu.Unlock()
+	s.mu.Lock()
+	s.global.Remove(pattern)
+	empty := len(s.hosts) == 0 && len(s.global.Patterns()) == 0
+	s.mu.Unlock()
+	if empty {
+		return s.close()
+	}
+	return nil
+}
+
+// RemoveHost - remove host and its certificates, the server is closed when no host and global route left
+func (s *Server) RemoveHost(host string) error {
+	host = normalizeHost(host)
+	// Listen must not return the server between the empty check and close
+	serversMu.Lock()
+	defer serversMu.Unlock()
+	s.mu.Lock()
+	if _, ok := s.hosts[host]; !ok {
+		s.mu.Unlock()
+		return ErrHostMissing
+	}
+	delete(s.hosts, host)
+	if _, ok := s.tlsCfgs[host]; ok {
+		delete(s.tlsCfgs, host)
+		for i, h := range s.tlsHosts {
+			if h == host {
+				s.tlsHosts = append(s.tlsHosts[:i:i], s.tlsHosts[i+1:]...)
+				break
+			}
+		}
+	}
+	empty := len(s.hosts) == 0 && len(s.global.Patterns()) == 0
+	s.mu.Unlock()
+	if empty {
+		return s.close()
+	}
+	return nil
+}
+
+// Hosts - all hosts on this server
+func (s *Server) Hosts() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	hosts := make([]string, 0, len(s.hosts))
+	for host := range s.hosts {
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+func (s *Server) Close() error {
+	serversMu.Lock()
+	defer serversMu.Unlock()
+	return s.close()
+}
+
+// close - caller must hold serversMu
+func (s *Server) close() error {
+	if servers[s.addr] == s {
+		delete(servers, s.addr)
+	}
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	err := s.server.Close()
+	// Serve may not have tracked the listener yet
+	s.ln.Close()
+	return err
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if h, _ := s.global.Match(req.URL.Path); h != nil {
+		h.ServeHTTP(w, req)
+		return
+	}
+	s.mu.RLock()
+	r, ok := s.hosts[normalizeHost(req.Host)]
+	if !ok {
+		r, ok = s.hosts[""]
+	}
+	s.mu.RUnlock()
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	r.ServeHTTP(w, req)
+}
+
+func (s *Server) getCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	s.mu.RLock()
+	var getters []func(*tls.ClientHelloInfo) (*tls.Certificate, error)
+	var certs []tls.Certificate
+	for _, host := range s.tlsHosts {
+		if get := s.tlsCfgs[host].GetCertificate; get != nil {
+			getters = append(getters, get)
+		}
+		certs = append(certs, s.tlsCfgs[host].Certificates...)
+	}
+	s.mu.RUnlock()
+	// acme may block for issuing, never hold the lock here
+	var fallback *tls.Certificate
+	for _, get := range getters {
+		cert, err := get(hello)
+		if err != nil || cert == nil {
+			continue
+		}
+		if hello.ServerName == "" || cert.Leaf == nil || cert.Leaf.VerifyHostname(hello.ServerName) == nil {
+			return cert, nil
+		}
+		if fallback == nil {
+			fallback = cert
+		}
+	}
+
+	if len(certs) == 0 {
+		if fallback != nil {
+			// no certificate matches server name, use the first one like static certificates
+			return fallback, nil
+		}
+		return nil, errors.New("no certificate")
+	}
+	for i := range certs {
+		if hello.SupportsCertificate(&certs[i]) == nil {
+			return &certs[i], nil
+		}
+	}
+	return &certs[0], nil
+}
+
+func normalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
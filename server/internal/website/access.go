@@ -0,0 +1,166 @@
+package website
+
+import (
+	"github.com/chainreactors/logs"
+	"github.com/chainreactors/malice-network/helper/consts"
+	"github.com/chainreactors/malice-network/proto/listener/lispb"
+	"github.com/chainreactors/malice-network/server/internal/core"
+	"github.com/chainreactors/malice-network/server/internal/db"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Hit - check whether content can be served, return false if the content is expired or has reached its max hits.
+// Only counted requests use up hits, last is true if this request used up the hits and the content
+// should be removed after served
+func Hit(name string, content *lispb.WebContent, count bool) (ok bool, last bool, err error) {
+	if content.ExpireAt != 0 && time.Now().Unix() >= content.ExpireAt {
+		return false, false, RemoveContent(name, content.Path)
+	}
+	if content.MaxHits == 0 {
+		return true, false, nil
+	}
+	if !count {
+		return content.Hits < content.MaxHits, false, nil
+	}
+	ok, last, err = db.HitContent(content.ID)
+	if err != nil {
+		return false, false, err
+	}
+	if !ok {
+		return false, false, RemoveContent(name, content.Path)
+	}
+	return true, last, nil
+}
+
+// Counted - only full 200 responses of GET use up max hits,
+// HEAD, Range, conditional (304), redirect and custom status responses are not counted
+func Counted(req *http.Request, content *lispb.WebContent) bool {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return false
+	}
+	if content.Redirect != "" || (content.Status != 0 && content.Status != http.StatusOK) {
+		return false
+	}
+	return !notModified(req, content)
+}
+
+// notModified - same validators as http.ServeContent
+func notModified(req *http.Request, content *lispb.WebContent) bool {
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		for _, etag := range strings.Split(inm, ",") {
+			etag = strings.TrimPrefix(strings.TrimSpace(etag), "W/")
+			if etag == "*" || (content.Etag != "" && etag == `"`+content.Etag+`"`) {
+				return true
+			}
+		}
+		return false
+	}
+	if content.ModifiedAt == 0 {
+		return false
+	}
+	since, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+	return err == nil && content.ModifiedAt <= since.Unix()
+}
+
+// StartExpirySweep - remove expired content periodically, expired content without requests is never hit
+func StartExpirySweep() error {
+	_, err := core.GlobalTicker.Start(consts.WebContentExpiryCheckJitter, sweepExpired)
+	return err
+}
+
+func sweepExpired() {
+	contents, err := db.ExpiredWebContents()
+	if err != nil {
+		logs.Log.Errorf("Failed to list expired web content: %s", err)
+		return
+	}
+	for _, content := range contents {
+		err = RemoveContent(content.Website.Name, content.Path)
+		if err != nil {
+			logs.Log.Errorf("Failed to remove expired content %s %s: %s", content.Website.Name, content.Path, err)
+			continue
+		}
+		logs.Log.Debugf("Removed expired content %s %s", content.Website.Name, content.Path)
+	}
+}
+
+// accessQueueSize - access records waiting to be recorded, records are dropped when the queue is full
+const accessQueueSize = 1024
+
+var (
+	accessQueue     = make(chan func(), accessQueueSize)
+	accessQueueOnce sync.Once
+)
+
+// enqueueAccess - record access in one background worker, requests never wait for it
+func enqueueAccess(record func()) {
+	accessQueueOnce.Do(func() {
+		go func() {
+			for record := range accessQueue {
+				record()
+			}
+		}()
+	})
+	select {
+	case accessQueue <- record:
+	default:
+		logs.Log.Debugf("Website access queue is full, access dropped")
+	}
+}
+
+type accessRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (r *accessRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *accessRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// AccessLog - wrap handler of website name, every request is passed to record after served
+func AccessLog(name string, next http.HandlerFunc, record func(*lispb.WebsiteAccess)) http.HandlerFunc {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		recorder := &accessRecorder{ResponseWriter: resp}
+		next(recorder, req)
+		if record == nil {
+			return
+		}
+		if recorder.status == 0 {
+			recorder.status = http.StatusOK
+		}
+		remoteAddr := req.RemoteAddr
+		if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+			remoteAddr = host
+		}
+		access := &lispb.WebsiteAccess{
+			Name:       name,
+			Path:       req.URL.Path,
+			RemoteAddr: remoteAddr,
+			Method:     req.Method,
+			UserAgent:  req.UserAgent(),
+			Status:     int32(recorder.status),
+			Bytes:      recorder.bytes,
+			Timestamp:  time.Now().Unix(),
+		}
+		enqueueAccess(func() {
+			record(access)
+		})
+	}
+}
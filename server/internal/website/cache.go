@@ -0,0 +1,97 @@
+package website
+
+import (
+	"container/list"
+	"sync"
+)
+
+// cacheSize - max bytes of content kept in memory
+const cacheSize = 64 * 1024 * 1024
+
+var contentCache = newLRU(cacheSize)
+
+type cacheEntry struct {
+	key  string
+	etag string
+	data []byte
+}
+
+// lru - in-memory cache of hot web content, bounded by total bytes
+type lru struct {
+	mu       sync.Mutex
+	capacity int64
+	size     int64
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+func newLRU(capacity int64) *lru {
+	return &lru{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    map[string]*list.Element{},
+	}
+}
+
+// Get - return cached data of key, etag must match the current content
+func (c *lru) Get(key, etag string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := e.Value.(*cacheEntry)
+	if entry.etag != etag {
+		c.remove(e)
+		return nil, false
+	}
+	c.ll.MoveToFront(e)
+	return entry.data, true
+}
+
+// Fits - content larger than a quarter of the cache would evict everything else, it is never cached
+func (c *lru) Fits(size int64) bool {
+	return size <= c.capacity/4
+}
+
+func (c *lru) Add(key, etag string, data []byte) {
+	size := int64(len(data))
+	if !c.Fits(size) {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[key]; ok {
+		c.remove(e)
+	}
+	c.items[key] = c.ll.PushFront(&cacheEntry{key: key, etag: etag, data: data})
+	c.size += size
+	for c.size > c.capacity {
+		c.remove(c.ll.Back())
+	}
+}
+
+func (c *lru) Remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[key]; ok {
+		c.remove(e)
+	}
+}
+
+func (c *lru) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+func (c *lru) remove(e *list.Element) {
+	entry := c.ll.Remove(e).(*cacheEntry)
+	delete(c.items, entry.key)
+	c.size -= int64(len(entry.data))
+}
+
+func cacheKey(name, path string) string {
+	return name + "\x00" + path
+}
@@ -0,0 +1,90 @@
+package website
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/chainreactors/logs"
+	"github.com/chainreactors/malice-network/proto/listener/lispb"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Etag - hash of content, used to validate cache and conditional requests
+func Etag(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:16])
+}
+
+// ServeWebsite - serve content of website name for req
+func ServeWebsite(name string, resp http.ResponseWriter, req *http.Request) {
+	content, body, err := GetContent(name, req.URL.Path)
+	if err != nil {
+		logs.Log.Debugf("Failed to get content %s", err)
+		http.NotFound(resp, req)
+		return
+	}
+	if body != nil {
+		defer body.Close()
+	}
+	ok, last, err := Hit(name, content, Counted(req, content))
+	if err != nil {
+		logs.Log.Errorf("Failed to count hit of %s %s: %s", name, content.Path, err)
+	}
+	if !ok {
+		http.NotFound(resp, req)
+		return
+	}
+	Serve(resp, req, content, body)
+	if last {
+		// the last request is still served, the file is removed after closed
+		if body != nil {
+			body.Close()
+		}
+		if err := RemoveContent(name, content.Path); err != nil {
+			logs.Log.Errorf("Failed to remove used up content %s %s: %s", name, content.Path, err)
+		}
+	}
+}
+
+// Serve - write body of content to resp, support redirect, custom headers and status,
+// conditional requests (ETag/Last-Modified) and Range requests
+func Serve(resp http.ResponseWriter, req *http.Request, content *lispb.WebContent, body io.ReadSeeker) {
+	for k, v := range content.Headers {
+		resp.Header().Set(k, v)
+	}
+
+	if content.Redirect != "" {
+		status := int(content.Status)
+		if status < 300 || status > 399 {
+			status = http.StatusFound
+		}
+		http.Redirect(resp, req, content.Redirect, status)
+		return
+	}
+
+	if resp.Header().Get("Content-Type") == "" {
+		resp.Header().Set("Content-Type", content.ContentType)
+	}
+	if resp.Header().Get("Cache-Control") == "" {
+		// always revalidate with ETag
+		resp.Header().Set("Cache-Control", "no-cache")
+	}
+
+	if content.Status != 0 && content.Status != http.StatusOK {
+		resp.WriteHeader(int(content.Status))
+		if req.Method != http.MethodHead && body != nil {
+			io.Copy(resp, body)
+		}
+		return
+	}
+
+	if content.Etag != "" {
+		resp.Header().Set("ETag", `"`+content.Etag+`"`)
+	}
+	var modtime time.Time
+	if content.ModifiedAt != 0 {
+		modtime = time.Unix(content.ModifiedAt, 0)
+	}
+	http.ServeContent(resp, req, content.Path, modtime, body)
+}
@@ -0,0 +1,145 @@
+package website
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chainreactors/malice-network/proto/listener/lispb"
+)
+
+func serve(content *lispb.WebContent, header http.Header) *http.Response {
+	req := httptest.NewRequest(http.MethodGet, "/payload.bin", nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	Serve(rec, req, content, bytes.NewReader(content.Content))
+	return rec.Result()
+}
+
+func TestServe(t *testing.T) {
+	data := []byte("0123456789")
+	content := &lispb.WebContent{
+		Path:        "/payload.bin",
+		ContentType: "application/octet-stream",
+		Content:     data,
+		Etag:        Etag(data),
+		ModifiedAt:  time.Now().Add(-time.Hour).Unix(),
+		Headers:     map[string]string{"Server": "nginx"},
+	}
+
+	resp := serve(content, nil)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Server") != "nginx" {
+		t.Fatalf("unexpected response %d %v", resp.StatusCode, resp.Header)
+	}
+	etag := resp.Header.Get("ETag")
+	if etag != fmt.Sprintf("%q", content.Etag) || resp.Header.Get("Last-Modified") == "" {
+		t.Fatalf("missing validators %v", resp.Header)
+	}
+
+	resp = serve(content, http.Header{"If-None-Match": {etag}})
+	if resp.StatusCode != http.StatusNotModified {
+		t.Fatalf("expect 304, got %d", resp.StatusCode)
+	}
+
+	resp = serve(content, http.Header{"Range": {"bytes=2-5"}})
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusPartialContent || string(body) != "2345" {
+		t.Fatalf("expect 206 \"2345\", got %d %q", resp.StatusCode, body)
+	}
+
+	resp = serve(&lispb.WebContent{Content: []byte("gone"), Status: http.StatusNotFound}, nil)
+	body, _ = io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusNotFound || string(body) != "gone" {
+		t.Fatalf("expect custom 404, got %d %q", resp.StatusCode, body)
+	}
+
+	resp = serve(&lispb.WebContent{Redirect: "https://example.com/", Status: http.StatusMovedPermanently}, nil)
+	if resp.StatusCode != http.StatusMovedPermanently || resp.Header.Get("Location") != "https://example.com/" {
+		t.Fatalf("unexpected redirect %d %v", resp.StatusCode, resp.Header)
+	}
+}
+
+func TestLRU(t *testing.T) {
+	c := newLRU(40)
+	c.Add("a", "1", make([]byte, 10))
+	c.Add("b", "1", make([]byte, 10))
+	c.Add("huge", "1", make([]byte, 11))
+	if _, ok := c.Get("huge", "1"); ok {
+		t.Fatal("content larger than a quarter of the cache should not be cached")
+	}
+	if _, ok := c.Get("a", "1"); !ok {
+		t.Fatal("a should be cached")
+	}
+	c.Add("c", "1", make([]byte, 10))
+	c.Add("d", "1", make([]byte, 10))
+	c.Add("e", "1", make([]byte, 10))
+	if _, ok := c.Get("b", "1"); ok {
+		t.Fatal("least recently used b should be evicted")
+	}
+	if _, ok := c.Get("a", "2"); ok {
+		t.Fatal("stale etag should miss")
+	}
+	if c.Len() != 3 {
+		t.Fatalf("expect 3 entries, got %d", c.Len())
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	records := make(chan *lispb.WebsiteAccess, 1)
+	handler := AccessLog("test", func(resp http.ResponseWriter, req *http.Request) {
+		http.NotFound(resp, req)
+	}, func(access *lispb.WebsiteAccess) {
+		records <- access
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/missing?a=1", nil)
+	req.RemoteAddr = "10.0.0.1:51234"
+	req.Header.Set("User-Agent", "curl/8.0")
+	handler(httptest.NewRecorder(), req)
+
+	select {
+	case access := <-records:
+		if access.Name != "test" || access.Path != "/missing" || access.RemoteAddr != "10.0.0.1" ||
+			access.UserAgent != "curl/8.0" || access.Status != http.StatusNotFound || access.Bytes == 0 {
+			t.Fatalf("unexpected access %+v", access)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("access not recorded")
+	}
+}
+
+func TestCounted(t *testing.T) {
+	modified := time.Now().Add(-time.Hour)
+	content := &lispb.WebContent{Etag: "abc", ModifiedAt: modified.Unix(), MaxHits: 1}
+	for _, c := range []struct {
+		name    string
+		method  string
+		header  http.Header
+		content *lispb.WebContent
+		counted bool
+	}{
+		{"full get", http.MethodGet, nil, content, true},
+		{"head", http.MethodHead, nil, content, false},
+		{"range", http.MethodGet, http.Header{"Range": {"bytes=0-1"}}, content, false},
+		{"etag matched", http.MethodGet, http.Header{"If-None-Match": {`W/"abc"`}}, content, false},
+		{"etag changed", http.MethodGet, http.Header{"If-None-Match": {`"old"`}}, content, true},
+		{"not modified since", http.MethodGet, http.Header{"If-Modified-Since": {time.Now().UTC().Format(http.TimeFormat)}}, content, false},
+		{"modified since", http.MethodGet, http.Header{"If-Modified-Since": {modified.Add(-time.Hour).UTC().Format(http.TimeFormat)}}, content, true},
+		{"redirect", http.MethodGet, nil, &lispb.WebContent{Redirect: "https://example.com/"}, false},
+		{"custom status", http.MethodGet, nil, &lispb.WebContent{Status: http.StatusNotFound}, false},
+	} {
+		req := httptest.NewRequest(c.method, "/payload.bin", nil)
+		for k, v := range c.header {
+			req.Header[k] = v
+		}
+		if Counted(req, c.content) != c.counted {
+			t.Errorf("%s: expect counted %v", c.name, c.counted)
+		}
+	}
+}
@@ -1,9 +1,16 @@
 package website
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"github.com/chainreactors/malice-network/proto/listener/lispb"
 	"github.com/chainreactors/malice-network/server/internal/configs"
 	"github.com/chainreactors/malice-network/server/internal/db"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+	"io"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -21,30 +28,88 @@ func getWebContentDir() (string, error) {
 	return webContentDir, nil
 }
 
-// GetContent - Get static content for a given path
-func GetContent(name string, path string) (*lispb.WebContent, error) {
+// GetContent - Get static content for a given path, the body is nil for redirect.
+// Hot content is served from memory, content too large for the cache is read from the file on each request.
+// The caller must close the body
+func GetContent(name string, path string) (*lispb.WebContent, io.ReadSeekCloser, error) {
 	webContentDir, err := getWebContentDir()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	website, err := db.WebsiteByName(name, webContentDir)
+	websiteID, err := db.WebsiteIDByName(name)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Use path without any query parameters
 	u, err := url.Parse(path)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	webContent, err := db.WebContentByIDAndPath(website.ID, u.Path, webContentDir, true)
+	webContent, err := db.WebContentByIDAndPath(websiteID, u.Path, webContentDir, false)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	if webContent.Redirect != "" {
+		return webContent, nil, nil
+	}
+
+	key := cacheKey(name, webContent.Path)
+	if webContent.Etag != "" {
+		if data, ok := contentCache.Get(key, webContent.Etag); ok {
+			return webContent, memoryContent{bytes.NewReader(data)}, nil
+		}
+	}
+	file, err := os.Open(filepath.Join(webContentDir, webContent.ID))
+	if err != nil {
+		return nil, nil, err
+	}
+	if webContent.Etag == "" {
+		// content added before etag was recorded
+		webContent.Etag, err = etagFile(file)
+		if err != nil {
+			file.Close()
+			return nil, nil, err
+		}
+		return webContent, file, nil
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	if !contentCache.Fits(info.Size()) {
+		return webContent, file, nil
 	}
+	data, err := io.ReadAll(file)
+	file.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+	contentCache.Add(key, webContent.Etag, data)
+	return webContent, memoryContent{bytes.NewReader(data)}, nil
+}
 
-	return webContent, err
+// memoryContent - cached content, nothing to close
+type memoryContent struct {
+	*bytes.Reader
+}
+
+func (memoryContent) Close() error {
+	return nil
+}
+
+func etagFile(file *os.File) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16]), nil
 }
 
 // AddContent - Add website content for a path
@@ -63,15 +128,49 @@ func AddContent(name string, pbWebContent *lispb.WebContent) error {
 		}
 		pbWebContent.WebsiteID = website.ID
 	}
+	pbWebContent.Etag = Etag(pbWebContent.Content)
 
+	// the file is named by the content id, a new row gets its id before the file is written
+	if existing, err := db.WebContentByIDAndPath(pbWebContent.WebsiteID, pbWebContent.Path, webContentDir, false); err == nil {
+		pbWebContent.ID = existing.ID
+	} else if errors.Is(err, gorm.ErrRecordNotFound) {
+		id, err := uuid.NewV4()
+		if err != nil {
+			return err
+		}
+		pbWebContent.ID = id.String()
+	} else {
+		return err
+	}
+
+	// Write content to disk, the row and cache are only updated once the file is complete
+	webContentPath := filepath.Join(webContentDir, pbWebContent.ID)
+	if err = writeAtomic(webContentPath, pbWebContent.Content); err != nil {
+		return err
+	}
 	webContent, err := db.AddContent(pbWebContent, webContentDir)
 	if err != nil {
 		return err
 	}
+	contentCache.Remove(cacheKey(name, webContent.Path))
+	return nil
+}
 
-	// Write content to disk
-	webContentPath := filepath.Join(webContentDir, webContent.ID)
-	return os.WriteFile(webContentPath, pbWebContent.Content, 0600)
+// writeAtomic - write to a temp file next to the content first, a request never reads a half written file
+func writeAtomic(path string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
 }
 
 // RemoveContent - Remove website content for a path
@@ -101,11 +200,27 @@ func RemoveContent(name string, path string) error {
 		return err
 	}
 
+	contentCache.Remove(cacheKey(name, content.Path))
+
 	// Delete row
 	err = db.RemoveContent(content.ID)
 	return err
 }
 
+// UpdateContent - Update content type, headers, status code and redirect of a path, the content is unchanged
+func UpdateContent(name string, pbWebContent *lispb.WebContent) (*lispb.WebContent, error) {
+	webContentDir, err := getWebContentDir()
+	if err != nil {
+		return nil, err
+	}
+	websiteID, err := db.WebsiteIDByName(name)
+	if err != nil {
+		return nil, err
+	}
+	pbWebContent.WebsiteID = websiteID
+	return db.UpdateContentMeta(pbWebContent, webContentDir)
+}
+
 // RemoveWebAllContent - Remove website content for website ID
 func RemoveWebAllContent(ID string) error {
 	webContentDir, err := getWebContentDir()
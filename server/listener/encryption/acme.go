@@ -0,0 +1,207 @@
+package encryption
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"github.com/chainreactors/logs"
+	"github.com/chainreactors/malice-network/server/internal/certs"
+	"github.com/chainreactors/malice-network/server/internal/configs"
+	"github.com/chainreactors/malice-network/server/internal/router"
+	"golang.org/x/crypto/acme/autocert"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const acmeChallengePath = "/.well-known/acme-challenge/"
+
+var (
+	acmeMu sync.Mutex
+	// domain -> manager, websites and pipelines sharing a domain share one manager,
+	// whose whitelist grows with the domains of every config using it
+	acmeManagers = map[string]*acmeManager{}
+	// domain -> number of configs using it, the domain leaves its manager with the last config
+	acmeDomains = map[string]int{}
+	// challenge ports serving acmeChallenge
+	acmePorts = map[uint16]*acmePort{}
+	// configs holding a reference on their domains and challenge port
+	acmeConfigs = map[*configs.TlsConfig]bool{}
+)
+
+type acmePort struct {
+	server *router.Server
+	refs   int
+}
+
+type acmeManager struct {
+	*autocert.Manager
+	domains map[string]bool // guarded by acmeMu
+}
+
+func (m *acmeManager) hostPolicy(_ context.Context, host string) error {
+	acmeMu.Lock()
+	defer acmeMu.Unlock()
+	if !m.domains[normalizeDomain(host)] {
+		return fmt.Errorf("acme: host %q not configured", host)
+	}
+	return nil
+}
+
+// WrapACMEConfig - tls config with certificates issued by acme, HTTP-01 challenge is served
+// on config.AcmeHTTPPort by the website router, certificates are requested in background.
+// Certificates are selected by SNI among the managers of all acme configs
+func WrapACMEConfig(config *configs.TlsConfig) (*tls.Config, error) {
+	acmeMu.Lock()
+	defer acmeMu.Unlock()
+	if len(config.Domains) == 0 {
+		return nil, certs.ErrACMEDomains
+	}
+	var manager *acmeManager
+	for _, domain := range config.Domains {
+		if m, ok := acmeManagers[normalizeDomain(domain)]; ok {
+			manager = m
+			break
+		}
+	}
+	if manager == nil {
+		m, err := certs.GetACMEManager(config)
+		if err != nil {
+			return nil, err
+		}
+		manager = &acmeManager{Manager: m, domains: map[string]bool{}}
+		m.HostPolicy = manager.hostPolicy
+	}
+	if !acmeConfigs[config] {
+		err := serveACMEChallenge(config.AcmeHTTPPort)
+		if err != nil {
+			return nil, err
+		}
+		var added []string
+		for _, domain := range config.Domains {
+			domain = normalizeDomain(domain)
+			acmeDomains[domain]++
+			if _, ok := acmeManagers[domain]; ok {
+				continue
+			}
+			manager.domains[domain] = true
+			acmeManagers[domain] = manager
+			added = append(added, domain)
+		}
+		acmeConfigs[config] = true
+		go obtainACMECertificates(manager.Manager, added)
+	}
+	tlsConfig := manager.TLSConfig()
+	tlsConfig.GetCertificate = acmeCertificate
+	return tlsConfig, nil
+}
+
+// acmeCertificate - certificate of the manager of server name
+func acmeCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	acmeMu.Lock()
+	manager, ok := acmeManagers[normalizeDomain(hello.ServerName)]
+	acmeMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("acme: host %q not configured", hello.ServerName)
+	}
+	return manager.GetCertificate(hello)
+}
+
+// releaseACMEConfig - drop the references of a stopped website or pipeline, domains without config
+// leave their manager and the challenge server is closed with its last user
+func releaseACMEConfig(config *configs.TlsConfig) {
+	acmeMu.Lock()
+	defer acmeMu.Unlock()
+	if !acmeConfigs[config] {
+		return
+	}
+	delete(acmeConfigs, config)
+	for _, domain := range config.Domains {
+		domain = normalizeDomain(domain)
+		if acmeDomains[domain]--; acmeDomains[domain] > 0 {
+			continue
+		}
+		delete(acmeDomains, domain)
+		if manager, ok := acmeManagers[domain]; ok {
+			delete(manager.domains, domain)
+			delete(acmeManagers, domain)
+		}
+	}
+	releaseACMEChallenge(config.AcmeHTTPPort)
+}
+
+// serveACMEChallenge - caller must hold acmeMu
+func serveACMEChallenge(port uint16) error {
+	if port == 0 {
+		port = 80
+	}
+	if p, ok := acmePorts[port]; ok {
+		p.refs++
+		return nil
+	}
+	server, err := router.Listen(fmt.Sprintf(":%d", port), nil)
+	if errors.Is(err, router.ErrTLSMismatch) {
+		return fmt.Errorf("acme challenge port %d is used by a tls website", port)
+	} else if err != nil {
+		return err
+	}
+	server.HandleGlobal(acmeChallengePath, http.HandlerFunc(acmeChallenge))
+	acmePorts[port] = &acmePort{server: server, refs: 1}
+	return nil
+}
+
+// releaseACMEChallenge - caller must hold acmeMu, websites sharing the port keep the server running
+func releaseACMEChallenge(port uint16) {
+	if port == 0 {
+		port = 80
+	}
+	p, ok := acmePorts[port]
+	if !ok {
+		return
+	}
+	if p.refs--; p.refs > 0 {
+		return
+	}
+	delete(acmePorts, port)
+	if err := p.server.RemoveGlobal(acmeChallengePath); err != nil {
+		logs.Log.Errorf("Failed to close acme challenge port %d: %s", port, err)
+	}
+}
+
+// acmeChallenge - dispatch challenge to the manager of request host
+func acmeChallenge(resp http.ResponseWriter, req *http.Request) {
+	acmeMu.Lock()
+	manager, ok := acmeManagers[normalizeDomain(req.Host)]
+	acmeMu.Unlock()
+	if !ok {
+		http.NotFound(resp, req)
+		return
+	}
+	manager.HTTPHandler(http.NotFoundHandler()).ServeHTTP(resp, req)
+}
+
+// obtainACMECertificates - request certificates at startup instead of the first handshake,
+// the manager renews them before expiry afterwards
+func obtainACMECertificates(manager *autocert.Manager, domains []string) {
+	for _, domain := range domains {
+		// hello of a modern client, so that the ecdsa certificate used by most clients is issued
+		_, err := manager.GetCertificate(&tls.ClientHelloInfo{
+			ServerName:   domain,
+			CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+		})
+		if err != nil {
+			logs.Log.Errorf("Failed to obtain acme certificate for %s: %s", domain, err)
+			continue
+		}
+		logs.Log.Importantf("Obtained acme certificate for %s", domain)
+	}
+}
+
+func normalizeDomain(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
@@ -0,0 +1,80 @@
+package encryption
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/chainreactors/malice-network/server/internal/configs"
+	"github.com/chainreactors/malice-network/server/internal/router"
+)
+
+// TestACMEPebble - issue a certificate from a local pebble, e.g.
+//
+//	pebble -config test/config/pebble-config.json  # httpPort 5002
+//	ACME_DIRECTORY=https://localhost:14000/dir ACME_CA=pebble/test/certs/pebble.minica.pem \
+//	ACME_HTTP_PORT=5002 go test -run TestACMEPebble ./server/listener/encryption
+//
+// pebble must resolve the domain to this host, "localhost" works with the default pebble config.
+func TestACMEPebble(t *testing.T) {
+	directory := os.Getenv("ACME_DIRECTORY")
+	if directory == "" {
+		t.Skip("ACME_DIRECTORY not set, skip acme test")
+	}
+	port, _ := strconv.Atoi(os.Getenv("ACME_HTTP_PORT"))
+	domain := os.Getenv("ACME_DOMAIN")
+	if domain == "" {
+		domain = "localhost"
+	}
+	configs.CertsPath = t.TempDir()
+
+	tlsConfig, err := WrapToTlsConfig(&configs.TlsConfig{
+		Acme:          true,
+		Domains:       []string{domain},
+		AcmeDirectory: directory,
+		AcmeCA:        os.Getenv("ACME_CA"),
+		AcmeHTTPPort:  uint16(port),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := router.Listen("127.0.0.1:0", tlsConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if err := s.AddHost(domain, router.NewRouter(), tlsConfig); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(time.Minute)
+	for {
+		conn, err := tls.DialWithDialer(&net.Dialer{Timeout: 5 * time.Second}, "tcp", s.Addr(), &tls.Config{
+			ServerName:         domain,
+			InsecureSkipVerify: true, // issued by pebble's random intermediate
+		})
+		if err == nil {
+			cert := conn.ConnectionState().PeerCertificates[0]
+			conn.Close()
+			if err := cert.VerifyHostname(domain); err != nil {
+				t.Fatal(err)
+			}
+			if isSelfSigned(cert) {
+				t.Fatal("certificate is not issued by acme")
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("acme certificate not issued: %v", err)
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+func isSelfSigned(cert *x509.Certificate) bool {
+	return cert.CheckSignatureFrom(cert) == nil
+}
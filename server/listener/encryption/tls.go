@@ -2,26 +2,112 @@ package encryption
 
 import (
 	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"github.com/chainreactors/malice-network/server/internal/certs"
 	"github.com/chainreactors/malice-network/server/internal/configs"
 	"net"
+	"sync"
 )
 
+var (
+	ErrCertNotRotatable = errors.New("certificate of acme pipeline is managed by acme")
+	ErrTLSNotRunning    = errors.New("tls of pipeline is not running")
+
+	holdersMu sync.Mutex
+	holders   = map[*configs.TlsConfig]*certHolder{}
+)
+
+// certHolder - certificate of a pipeline or website, swapped at runtime without restarting
+type certHolder struct {
+	mu   sync.RWMutex
+	cert *tls.Certificate
+}
+
+func (h *certHolder) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.cert, nil
+}
+
+func (h *certHolder) set(cert *tls.Certificate) {
+	h.mu.Lock()
+	h.cert = cert
+	h.mu.Unlock()
+}
+
 func WrapWithTls(lsn net.Listener, config *configs.TlsConfig) (net.Listener, error) {
-	cert, key, err := certs.GenerateListenerCertificate(config)
+	tlsConfig, err := WrapToTlsConfig(config)
 	if err != nil {
 		return nil, err
 	}
-	pair, err := tls.X509KeyPair(cert, key)
+	return tls.NewListener(lsn, tlsConfig), nil
+}
+
+func WrapToTlsConfig(config *configs.TlsConfig) (*tls.Config, error) {
+	if config.Acme {
+		return WrapACMEConfig(config)
+	}
+	pair, err := loadKeyPair(config)
 	if err != nil {
 		return nil, err
 	}
 
-	tlsConfig := &tls.Config{Certificates: []tls.Certificate{pair}}
-	return tls.NewListener(lsn, tlsConfig), nil
+	holdersMu.Lock()
+	holder, ok := holders[config]
+	if !ok {
+		holder = &certHolder{}
+		holders[config] = holder
+	}
+	holdersMu.Unlock()
+	holder.set(pair)
+	return &tls.Config{GetCertificate: holder.GetCertificate}, nil
 }
 
-func WrapToTlsConfig(config *configs.TlsConfig) (*tls.Config, error) {
+// RotateCertificate - replace certificate of running pipeline, new connections use the new certificate.
+// A new certificate is generated if cert and key are empty.
+func RotateCertificate(config *configs.TlsConfig, cert, key string) error {
+	if config.Acme {
+		return ErrCertNotRotatable
+	}
+	holdersMu.Lock()
+	holder, ok := holders[config]
+	holdersMu.Unlock()
+	if !ok {
+		return ErrTLSNotRunning
+	}
+	if cert != "" || key != "" {
+		if _, err := tls.X509KeyPair([]byte(cert), []byte(key)); err != nil {
+			return err
+		}
+	}
+
+	err := certs.RemoveListenerCertificate(config)
+	if err != nil {
+		return err
+	}
+	config.CertFile, config.KeyFile = cert, key
+	pair, err := loadKeyPair(config)
+	if err != nil {
+		return err
+	}
+	holder.set(pair)
+	return nil
+}
+
+// ReleaseCertificate - forget the certificate of a stopped pipeline or website,
+// acme configs release their domains and challenge port
+func ReleaseCertificate(config *configs.TlsConfig) {
+	if config.Acme {
+		releaseACMEConfig(config)
+		return
+	}
+	holdersMu.Lock()
+	delete(holders, config)
+	holdersMu.Unlock()
+}
+
+func loadKeyPair(config *configs.TlsConfig) (*tls.Certificate, error) {
 	cert, key, err := certs.GenerateListenerCertificate(config)
 	if err != nil {
 		return nil, err
@@ -30,7 +116,10 @@ func WrapToTlsConfig(config *configs.TlsConfig) (*tls.Config, error) {
 	if err != nil {
 		return nil, err
 	}
-
-	tlsConfig := &tls.Config{Certificates: []tls.Certificate{pair}}
-	return tlsConfig, nil
+	// leaf is used to select certificate by server name
+	pair.Leaf, err = x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+	return &pair, nil
 }
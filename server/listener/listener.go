@@ -14,6 +14,8 @@ import (
 	"github.com/chainreactors/malice-network/server/internal/certs"
 	"github.com/chainreactors/malice-network/server/internal/configs"
 	"github.com/chainreactors/malice-network/server/internal/core"
+	"github.com/chainreactors/malice-network/server/internal/metrics"
+	"github.com/chainreactors/malice-network/server/listener/encryption"
 	"github.com/chainreactors/malice-network/server/web"
 	"google.golang.org/grpc"
 	"net"
@@ -61,6 +63,13 @@ func NewListener(clientConf *mtls.ClientConfig, cfg *configs.ListenerConfig) err
 	if err != nil {
 		return err
 	}
+	if cfg.MetricsConfig != nil && cfg.MetricsConfig.Enable {
+		core.RegisterListenerMetrics()
+		_, err = metrics.Start(cfg.MetricsConfig.Address(), cfg.MetricsConfig.Path)
+		if err != nil {
+			return err
+		}
+	}
 
 	lis := &listener{
 		Rpc:       listenerrpc.NewListenerRPCClient(conn),
@@ -129,7 +138,9 @@ func (lns *listener) Start() {
 		if !website.Enable {
 			continue
 		}
-		httpServer := web.NewHTTPServer(int(website.Port), website.RootPath, website.WebsiteName)
+		httpServer := web.NewHTTPServer(int(website.Port), website.Host, website.RootPath, website.WebsiteName)
+		httpServer.TlsConfig = website.TlsConfig
+		httpServer.OnAccess = lns.websiteAccess
 		go httpServer.Start()
 	}
 }
@@ -161,6 +172,16 @@ func (lns *listener) Handler() {
 			resp = lns.startWebsite(msg.Job)
 		case consts.CtrlWebsiteStop:
 			resp = lns.stopWebsite(msg.Job)
+		case consts.CtrlCertRotate:
+			resp = lns.rotateCert(msg.Job)
+		case consts.CtrlServerShutdown:
+			// pipelines keep running, only the connection to the server goes away
+			logs.Log.Warnf("server is shutting down, listener %s loses connection", lns.Name)
+			resp = &clientpb.JobStatus{
+				ListenerId: lns.ID(),
+				Ctrl:       consts.CtrlServerShutdown,
+				Status:     consts.CtrlStatusSuccess,
+			}
 		}
 		err = stream.Send(resp)
 		if err != nil {
@@ -256,7 +277,7 @@ func (lns *listener) startWebsite(job *clientpb.Job) *clientpb.JobStatus {
 	getWeb := job.GetPipeline().GetWeb()
 	w := lns.websites.Get(getWeb.Name)
 	if w == nil {
-		starResult, err := StartWebsite(ToWebsiteConfig(getWeb, job.GetPipeline().GetTls()), getWeb.Contents["0"])
+		starResult, err := StartWebsite(ToWebsiteConfig(getWeb, job.GetPipeline().GetTls()), getWeb.Contents["0"], lns.websiteAccess)
 		if err != nil {
 			return &clientpb.JobStatus{
 				ListenerId: lns.ID(),
@@ -287,6 +308,52 @@ func (lns *listener) startWebsite(job *clientpb.Job) *clientpb.JobStatus {
 	}
 }
 
+// rotateCert - swap certificate of running pipeline or website
+func (lns *listener) rotateCert(job *clientpb.Job) *clientpb.JobStatus {
+	var tlsConfig *configs.TlsConfig
+	pipeline := job.GetPipeline()
+	switch pipeline.Body.(type) {
+	case *lispb.Pipeline_Tcp:
+		if p, ok := lns.pipelines.Get(pipeline.GetTcp().Name).(*TCPPipeline); ok {
+			tlsConfig = p.TlsConfig
+		}
+	case *lispb.Pipeline_Web:
+		if w, ok := lns.websites.Get(pipeline.GetWeb().Name).(*Website); ok {
+			tlsConfig = w.TlsConfig
+		}
+	}
+	var err error
+	if tlsConfig == nil {
+		err = errors.New("pipeline not found")
+	} else {
+		err = encryption.RotateCertificate(tlsConfig, pipeline.GetTls().GetCert(), pipeline.GetTls().GetKey())
+	}
+	if err != nil {
+		return &clientpb.JobStatus{
+			ListenerId: lns.ID(),
+			Ctrl:       consts.CtrlCertRotate,
+			Status:     consts.CtrlStatusFailed,
+			Error:      err.Error(),
+			Job:        job,
+		}
+	}
+	return &clientpb.JobStatus{
+		ListenerId: lns.ID(),
+		Ctrl:       consts.CtrlCertRotate,
+		Status:     consts.CtrlStatusSuccess,
+		Job:        job,
+	}
+}
+
+// websiteAccess - report access of website content to server
+func (lns *listener) websiteAccess(access *lispb.WebsiteAccess) {
+	access.ListenerId = lns.ID()
+	_, err := lns.Rpc.WebsiteAccess(context.Background(), access)
+	if err != nil {
+		logs.Log.Debugf("Failed to report website access %s", err)
+	}
+}
+
 func (lns *listener) registerWebsite(w core.Website, listenerID string) {
 	lns.websites.Add(w)
 	result := w.ToProtobuf().(*lispb.Website)
@@ -49,8 +49,15 @@ func ToTcpConfig(pipeline *lispb.TCPPipeline, tls *lispb.TLS) *configs.TcpPipeli
 		TlsConfig: &configs.TlsConfig{
 			Name:     fmt.Sprintf("%s_%v", pipeline.Name, uint16(pipeline.Port)),
 			Enable:   true,
-			CertFile: tls.Cert,
-			KeyFile:  tls.Key,
+			CertFile: tls.GetCert(),
+			KeyFile:  tls.GetKey(),
+			Acme:     tls.GetAcme(),
+			Domains:  tls.GetDomains(),
+			Email:    tls.GetEmail(),
+			// directory, ca and challenge port of the listener config are kept over rpc
+			AcmeDirectory: tls.GetAcmeDirectory(),
+			AcmeCA:        tls.GetAcmeCa(),
+			AcmeHTTPPort:  uint16(tls.GetAcmeHttpPort()),
 		},
 	}
 }
@@ -75,8 +82,15 @@ func (l *TCPPipeline) ToProtobuf() proto.Message {
 
 func (l *TCPPipeline) ToTLSProtobuf() proto.Message {
 	return &lispb.TLS{
-		Cert: l.TlsConfig.CertFile,
-		Key:  l.TlsConfig.KeyFile,
+		Cert:    l.TlsConfig.CertFile,
+		Key:     l.TlsConfig.KeyFile,
+		Acme:    l.TlsConfig.Acme,
+		Domains: l.TlsConfig.Domains,
+		Email:   l.TlsConfig.Email,
+
+		AcmeDirectory: l.TlsConfig.AcmeDirectory,
+		AcmeCa:        l.TlsConfig.AcmeCA,
+		AcmeHttpPort:  uint32(l.TlsConfig.AcmeHTTPPort),
 	}
 }
 func (l *TCPPipeline) ID() string {
@@ -88,6 +102,9 @@ func (l *TCPPipeline) Addr() string {
 }
 
 func (l *TCPPipeline) Close() error {
+	if l.TlsConfig != nil {
+		encryption.ReleaseCertificate(l.TlsConfig)
+	}
 	err := l.ln.Close()
 	if err != nil {
 		return err
@@ -114,7 +131,7 @@ func (l *TCPPipeline) handler() (net.Listener, error) {
 	if err != nil {
 		return nil, err
 	}
-	if l.TlsConfig != nil && l.TlsConfig.Enable {
+	if l.TlsConfig != nil && (l.TlsConfig.Enable || l.TlsConfig.Acme) {
 		ln, err = encryption.WrapWithTls(ln, l.TlsConfig)
 		if err != nil {
 			return nil, err
@@ -1,11 +1,13 @@
 package listener
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"github.com/chainreactors/logs"
 	"github.com/chainreactors/malice-network/proto/listener/lispb"
 	"github.com/chainreactors/malice-network/server/internal/configs"
+	"github.com/chainreactors/malice-network/server/internal/router"
 	"github.com/chainreactors/malice-network/server/internal/website"
 	"github.com/chainreactors/malice-network/server/listener/encryption"
 	"google.golang.org/protobuf/proto"
@@ -14,20 +16,26 @@ import (
 
 type Website struct {
 	port        int
-	server      *http.Server
+	host        string
+	server      *router.Server
+	router      *router.Router
 	rootPath    string
 	websiteName string
 	TlsConfig   *configs.TlsConfig
 	Content     *lispb.WebContent
+	onAccess    func(*lispb.WebsiteAccess)
 }
 
-func StartWebsite(cfg *configs.WebsiteConfig, content *lispb.WebContent) (*Website, error) {
+func StartWebsite(cfg *configs.WebsiteConfig, content *lispb.WebContent, onAccess func(*lispb.WebsiteAccess)) (*Website, error) {
 	web := &Website{
 		port:        int(cfg.Port),
+		host:        cfg.Host,
+		router:      router.NewRouter(),
 		rootPath:    cfg.RootPath,
 		websiteName: cfg.WebsiteName,
 		TlsConfig:   cfg.TlsConfig,
 		Content:     content,
+		onAccess:    onAccess,
 	}
 	err := web.Start()
 	if err != nil {
@@ -45,31 +53,40 @@ func (w *Website) Addr() string {
 }
 
 func (w *Website) Start() error {
-	http.HandleFunc(w.rootPath, w.websiteContentHandler)
+	if w.server != nil {
+		return errors.New("website is already running")
+	}
+	w.router.HandleFunc(w.rootPath, website.AccessLog(w.websiteName, w.websiteContentHandler, w.onAccess))
+	var tlsConfig *tls.Config
 	var err error
-	tlsConfig, err := encryption.WrapToTlsConfig(w.TlsConfig)
-	if err != nil {
-		return err
+	if w.TlsConfig != nil && (w.TlsConfig.Enable || w.TlsConfig.Acme) {
+		tlsConfig, err = encryption.WrapToTlsConfig(w.TlsConfig)
+		if err != nil {
+			return err
+		}
 	}
-	w.server = &http.Server{Addr: fmt.Sprintf(":%d", w.port),
-		TLSConfig: tlsConfig,
+	server, err := router.Listen(fmt.Sprintf(":%d", w.port), tlsConfig)
+	if err == nil {
+		err = server.AddHost(w.host, w.router, tlsConfig)
 	}
-	go func() {
-		logs.Log.Importantf("HTTP Server is running on port %d", w.port)
-		if err = w.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logs.Log.Errorf("HTTP Server failed to start: %v", err)
-		}
-	}()
 	if err != nil {
+		if tlsConfig != nil {
+			encryption.ReleaseCertificate(w.TlsConfig)
+		}
 		return err
 	}
+	w.server = server
 	return nil
 }
 
 func (w *Website) Close() error {
 	if w.server != nil {
-		logs.Log.Importantf("Stopping server")
-		err := w.server.Shutdown(nil)
+		logs.Log.Importantf("Stopping website %s", w.websiteName)
+		err := w.server.RemoveHost(w.host)
+		w.server = nil
+		if w.TlsConfig != nil {
+			encryption.ReleaseCertificate(w.TlsConfig)
+		}
 		if err != nil {
 			return err
 		}
@@ -85,46 +102,64 @@ func (w *Website) ToProtobuf() proto.Message {
 		Port:     uint32(w.port),
 		Name:     w.websiteName,
 		RootPath: w.rootPath,
+		Host:     w.host,
 	}
 }
 
 func (w *Website) ToTLSProtobuf() proto.Message {
 	return &lispb.TLS{
-		Cert: w.TlsConfig.CertFile,
-		Key:  w.TlsConfig.KeyFile,
+		Cert:    w.TlsConfig.CertFile,
+		Key:     w.TlsConfig.KeyFile,
+		Acme:    w.TlsConfig.Acme,
+		Domains: w.TlsConfig.Domains,
+		Email:   w.TlsConfig.Email,
+
+		AcmeDirectory: w.TlsConfig.AcmeDirectory,
+		AcmeCa:        w.TlsConfig.AcmeCA,
+		AcmeHttpPort:  uint32(w.TlsConfig.AcmeHTTPPort),
 	}
 }
 
 func ToWebsiteConfig(w *lispb.Website, tls *lispb.TLS) *configs.WebsiteConfig {
 	return &configs.WebsiteConfig{
 		Port:        uint16(w.Port),
+		Host:        w.Host,
 		RootPath:    w.RootPath,
 		WebsiteName: w.Name,
 		TlsConfig: &configs.TlsConfig{
 			Name:     fmt.Sprintf("%s_%v", w.Name, uint16(w.Port)),
-			Enable:   true,
-			CertFile: tls.Cert,
-			KeyFile:  tls.Key,
+			Enable:   tls.GetCert() != "" || tls.GetAcme(),
+			CertFile: tls.GetCert(),
+			KeyFile:  tls.GetKey(),
+			Acme:     tls.GetAcme(),
+			Domains:  tls.GetDomains(),
+			Email:    tls.GetEmail(),
+			// directory, ca and challenge port of the listener config are kept over rpc
+			AcmeDirectory: tls.GetAcmeDirectory(),
+			AcmeCA:        tls.GetAcmeCa(),
+			AcmeHTTPPort:  uint16(tls.GetAcmeHttpPort()),
 		},
 	}
 }
 
+// Handle - add or update the handler of path at runtime
+func (w *Website) Handle(path string, handler http.Handler) {
+	w.router.Handle(path, handler)
+}
+
+// Remove - remove path at runtime
+func (w *Website) Remove(path string) bool {
+	return w.router.Remove(path)
+}
+
 func (w *Website) AddFileRoute(routePath, localFilePath string) {
-	http.Handle(routePath, http.FileServer(http.Dir(localFilePath)))
+	w.router.Handle(routePath, http.FileServer(http.Dir(localFilePath)))
 }
 
 func (w *Website) DeleteFileRoute(routePath string) {
-	http.DefaultServeMux.Handle(routePath, nil)
-	http.DefaultServeMux.HandleFunc(routePath, nil)
+	w.router.Remove(routePath)
 }
 
 func (w *Website) websiteContentHandler(resp http.ResponseWriter, req *http.Request) {
-	content, err := website.GetContent(w.websiteName, req.URL.Path)
-	if err != nil {
-		logs.Log.Errorf("Failed to get content %s", err)
-		return
-	}
-	resp.Header().Set("Content-Type", content.ContentType)
-	resp.Header().Add("Cache-Control", "no-store, no-cache, must-revalidate")
-	resp.Write(w.Content.Content)
+	website.ServeWebsite(w.websiteName, resp, req)
 }
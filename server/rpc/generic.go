@@ -22,12 +22,16 @@ func newGenericRequest(ctx context.Context, msg proto.Message, opts ...int) (*Ge
 	} else {
 		return nil, err
 	}
+	if err := resolveArtifact(msg); err != nil {
+		return nil, err
+	}
 
 	if opts == nil {
 		req.Task = req.NewTask(1)
 	} else {
 		req.Task = req.NewTask(opts[0])
 	}
+	req.Task.Callby = getClientName(ctx)
 	return req, nil
 }
 
@@ -2,6 +2,8 @@ package rpc
 
 import (
 	"context"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"github.com/chainreactors/logs"
 	"github.com/chainreactors/malice-network/helper/consts"
@@ -17,6 +19,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
@@ -42,17 +45,28 @@ var (
 	ErrInvalidName     = status.Error(codes.InvalidArgument, "Invalid session name, alphanumerics and _-. only")
 	ErrNotFoundSession = status.Error(codes.NotFound, "Session ID not found")
 	ErrNotFoundTask    = status.Error(codes.NotFound, "Task ID not found")
+	ErrNotFoundBatch   = status.Error(codes.NotFound, "Batch ID not found")
 
-	ErrNotFoundListener    = status.Error(codes.NotFound, "Listener not found")
-	ErrNotFoundPipeline    = status.Error(codes.NotFound, "Pipeline not found")
-	ErrNotFoundClientName  = status.Error(codes.NotFound, "Client name not found")
-	ErrNotFoundTaskContent = status.Error(codes.NotFound, "Task content not found")
+	ErrNotFoundQueuedTask = status.Error(codes.NotFound, "Queued task not found")
+	ErrNotFoundArtifact   = status.Error(codes.NotFound, "Artifact not found")
+	ErrInvalidArtifact    = status.Error(codes.InvalidArgument, "Invalid artifact hash or content")
+	ErrNotFoundParser     = status.Error(codes.NotFound, "Parser not found")
+	ErrNotFoundTaskResult = status.Error(codes.NotFound, "Task has no parsed result")
+
+	ErrNotFoundListener     = status.Error(codes.NotFound, "Listener not found")
+	ErrNotFoundPipeline     = status.Error(codes.NotFound, "Pipeline not found")
+	ErrNotFoundClientName   = status.Error(codes.NotFound, "Client name not found")
+	ErrOperatorMismatch     = status.Error(codes.PermissionDenied, "Operator name does not match certificate")
+	ErrOperatorRevoked      = status.Error(codes.PermissionDenied, "Operator certificate not issued by server or revoked")
+	ErrNotFoundTaskContent  = status.Error(codes.NotFound, "Task content not found")
+	ErrInvalidSessionFilter = status.Error(codes.InvalidArgument, "Session filter must have a name")
+	ErrNotBroadcastable     = status.Error(codes.InvalidArgument, "Module can not be broadcast")
 	//ErrInvalidBeaconTaskCancelState = status.Error(codes.InvalidArgument, fmt.Sprintf("Invalid task state, must be '%s' to cancel", models.PENDING))
 )
 
 var (
 	pipelinesCh     = make(map[string]grpc.ServerStream)
-	authLog, rpcLog *logs.Logger
+	authLog, rpcLog *configs.StructuredLog
 )
 
 func InitLogs(debug bool) {
@@ -77,37 +91,56 @@ func StartClientListener(port uint16) (*grpc.Server, net.Listener, error) {
 		logs.Log.Errorf(err.Error())
 		return nil, nil, err
 	}
+	grpcServer := newGrpcServer(creds)
+	go func() {
+		panicked := true
+		defer func() {
+			if panicked {
+				logs.Log.Errorf("stacktrace from panic: %s", string(debug.Stack()))
+			}
+		}()
+		if err := grpcServer.Serve(ln); err != nil {
+			logs.Log.Warnf("gRPC server exited with error: %v", err)
+		} else {
+			panicked = false
+		}
+	}()
+	return grpcServer, ln, nil
+}
+
+// newGrpcServer - grpc server of clients and listeners, every rpc passes the metrics, log, audit and auth interceptors
+func newGrpcServer(creds credentials.TransportCredentials) *grpc.Server {
 	options := []grpc.ServerOption{
 		grpc.Creds(creds),
 		grpc.MaxRecvMsgSize(consts.ServerMaxMessageSize),
 		grpc.MaxSendMsgSize(consts.ServerMaxMessageSize),
+		// allow keepalive pings of clients, which detect broken connections and reconnect
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             consts.ClientKeepalive / 2,
+			PermitWithoutStream: true,
+		}),
 	}
 
 	//options = append(options, authInterceptor()...)
 	//rootOptions := buildOptions(options, authInterceptor()...)
-	grpcServer := grpc.NewServer(buildOptions(
+	options = buildOptions(
 		options,
+		metricsInterceptor(),
 		logInterceptor(rpcLog),
 		auditInterceptor(),
-		authInterceptor(rpcLog))...)
+		authInterceptor(rpcLog))
+	options = buildStreamOptions(
+		options,
+		streamMetricsInterceptor(),
+		streamLogInterceptor(rpcLog),
+		streamAuditInterceptor(),
+		streamAuthInterceptor(rpcLog))
+	grpcServer := grpc.NewServer(options...)
 	clientrpc.RegisterMaliceRPCServer(grpcServer, NewServer())
 	clientrpc.RegisterRootRPCServer(grpcServer, NewServer())
 	listenerrpc.RegisterImplantRPCServer(grpcServer, NewServer())
 	listenerrpc.RegisterListenerRPCServer(grpcServer, NewServer())
-	go func() {
-		panicked := true
-		defer func() {
-			if panicked {
-				logs.Log.Errorf("stacktrace from panic: %s", string(debug.Stack()))
-			}
-		}()
-		if err := grpcServer.Serve(ln); err != nil {
-			logs.Log.Warnf("gRPC server exited with error: %v", err)
-		} else {
-			panicked = false
-		}
-	}()
-	return grpcServer, ln, nil
+	return grpcServer
 }
 
 //
@@ -178,11 +211,16 @@ func (rpc *Server) asyncGenericHandler(ctx context.Context, req *GenericRequest)
 		return nil, err
 	}
 
-	out, err := req.Session.RequestWithAsync(
-		&lispb.SpiteSession{SessionId: req.Session.ID, TaskId: req.Task.Id, Spite: spite},
-		pipelinesCh[req.Session.PipelineID],
-		consts.MinTimeout)
-	if err != nil {
+	msg := &lispb.SpiteSession{SessionId: req.Session.ID, TaskId: req.Task.Id, Spite: spite}
+	stream, ok := pipelinesCh[req.Session.PipelineID]
+	if !ok || (req.Session.Timer != nil && !req.Session.IsAlive()) {
+		// session missed its check-in, deliver when it comes back
+		return rpc.queueRequest(req, msg)
+	}
+	out, err := req.Session.RequestWithAsync(msg, stream, consts.MinTimeout)
+	if errors.Is(err, core.ErrImplantSendTimeout) {
+		return rpc.queueRequest(req, msg)
+	} else if err != nil {
 		return nil, err
 	}
 
@@ -196,11 +234,15 @@ func (rpc *Server) streamGenericHandler(ctx context.Context, req *GenericRequest
 		logs.Log.Errorf(err.Error())
 		return nil, nil, err
 	}
-	in, out, err := req.Session.RequestWithStream(
-		&lispb.SpiteSession{SessionId: req.Session.ID, TaskId: req.Task.Id, Spite: spite},
-		pipelinesCh[req.Session.PipelineID],
-		consts.MinTimeout)
-	if err != nil {
+	msg := &lispb.SpiteSession{SessionId: req.Session.ID, TaskId: req.Task.Id, Spite: spite}
+	stream, ok := pipelinesCh[req.Session.PipelineID]
+	if !ok || (req.Session.Timer != nil && !req.Session.IsAlive()) {
+		return rpc.queueStream(req, msg)
+	}
+	in, out, err := req.Session.RequestWithStream(msg, stream, consts.MinTimeout)
+	if errors.Is(err, core.ErrImplantSendTimeout) {
+		return rpc.queueStream(req, msg)
+	} else if err != nil {
 		return nil, nil, err
 	}
 
@@ -304,20 +346,42 @@ func getPipelineID(ctx context.Context) (string, error) {
 	}
 }
 
+// getClientName - common name of the client certificate, calls made by the server itself, e.g. schedule runs,
+// have no peer and carry the operator in metadata instead
 func getClientName(ctx context.Context) string {
+	if _, ok := peer.FromContext(ctx); !ok {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if operator := md.Get("operator"); len(operator) > 0 {
+			return operator[0]
+		}
+		return ""
+	}
+	cert := getClientCertificate(ctx)
+	if cert == nil {
+		return ""
+	}
+	return cert.Subject.CommonName
+}
+
+func getClientCertificate(ctx context.Context) *x509.Certificate {
 	client, ok := peer.FromContext(ctx)
 	if !ok {
-		return ""
+		return nil
 	}
 	tlsAuth, ok := client.AuthInfo.(credentials.TLSInfo)
 	if !ok {
-		return ""
+		return nil
 	}
 	if len(tlsAuth.State.VerifiedChains) == 0 || len(tlsAuth.State.VerifiedChains[0]) == 0 {
-		return ""
+		return nil
 	}
-	if tlsAuth.State.VerifiedChains[0][0].Subject.CommonName != "" {
-		return tlsAuth.State.VerifiedChains[0][0].Subject.CommonName
+	return tlsAuth.State.VerifiedChains[0][0]
+}
+
+func getRemoteAddr(ctx context.Context) string {
+	client, ok := peer.FromContext(ctx)
+	if !ok || client.Addr == nil {
+		return ""
 	}
-	return ""
+	return client.Addr.String()
 }
@@ -2,13 +2,18 @@ package rpc
 
 import (
 	"context"
-	"errors"
+	"fmt"
 	"github.com/chainreactors/logs"
+	"github.com/chainreactors/malice-network/server/internal/certs"
+	"github.com/chainreactors/malice-network/server/internal/configs"
+	"github.com/chainreactors/malice-network/server/internal/metrics"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 	"reflect"
 	"strings"
+	"time"
 )
 
 type contextKey int
@@ -16,8 +21,9 @@ type contextKey int
 const (
 	Transport contextKey = iota
 	Operator
-	rootName = "Root"
-	rootAddr = "127.0.0.1"
+	rootName        = "Root"
+	rootAddr        = "127.0.0.1"
+	clientNamespace = "client"
 )
 
 func buildOptions(option []grpc.ServerOption, interceptors ...grpc.UnaryServerInterceptor) []grpc.ServerOption {
@@ -25,20 +31,45 @@ func buildOptions(option []grpc.ServerOption, interceptors ...grpc.UnaryServerIn
 	return option
 }
 
+func buildStreamOptions(option []grpc.ServerOption, interceptors ...grpc.StreamServerInterceptor) []grpc.ServerOption {
+	option = append(option, grpc.ChainStreamInterceptor(interceptors...))
+	return option
+}
+
 // logInterceptor - Log middleware
-func logInterceptor(log *logs.Logger) grpc.UnaryServerInterceptor {
+func logInterceptor(log *configs.StructuredLog) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		entry := &configs.LogEntry{
+			Operator: getClientName(ctx),
+			Method:   info.FullMethod,
+		}
 		if sid, err := getSessionID(ctx); err == nil {
-			log.Infof("[implant] %s call %s with %s: %s", getClientName(ctx), info.FullMethod, sid, reflect.TypeOf(req))
-			resp, err := handler(ctx, req)
-			log.Infof("[implant] %s back %s: %s", sid, info.FullMethod, reflect.TypeOf(resp))
-			return resp, err
+			entry.SessionID = sid
+		}
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		entry.Duration = time.Since(start)
+		if task, ok := resp.(interface{ GetTaskId() uint32 }); ok {
+			entry.TaskID = task.GetTaskId()
+		}
+		if err != nil {
+			entry.Error = err.Error()
+			log.Entry(logs.Error, entry)
 		} else {
-			log.Infof("[malice] %s call %s with %s", getClientName(ctx), info.FullMethod, reflect.TypeOf(req))
-			resp, err := handler(ctx, req)
-			log.Infof("[malice] %s back %s: %s", getClientName(ctx), info.FullMethod, reflect.TypeOf(resp))
-			return resp, err
+			entry.Message = fmt.Sprintf("%s -> %s", reflect.TypeOf(req), reflect.TypeOf(resp))
+			log.Entry(logs.Info, entry)
 		}
+		return resp, err
+	}
+}
+
+// metricsInterceptor - record rpc latency
+func metricsInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		metrics.ObserveRPC(info.FullMethod, status.Code(err).String(), time.Since(start))
+		return resp, err
 	}
 }
 
@@ -58,42 +89,118 @@ func auditInterceptor() grpc.UnaryServerInterceptor {
 }
 
 // authInterceptor - Auth middleware
-func authInterceptor(log *logs.Logger) grpc.UnaryServerInterceptor {
+func authInterceptor(log *configs.StructuredLog) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		client, ok := peer.FromContext(ctx)
-		if !ok {
-			log.Errorf("[auth] failed to get peers information from context")
-			return ctx, errors.New("failed to get peers information from context")
-		}
-		if client.AuthInfo == nil {
-			log.Errorf("[auth] auth info not found")
-			return ctx, errors.New("auth info not found")
-		}
-		caType := client.AuthInfo.(credentials.TLSInfo).State.ServerName
-		if len(caType) == 0 {
-			log.Errorf("[auth] certificate type not found")
-			return ctx, errors.New("certificate type not found")
-		}
-		if caType == rootName {
-			if !strings.Contains(info.FullMethod, "."+caType) {
-				log.Errorf("[auth] certificate type does not match method")
-				return ctx, errors.New("certificate type does not match method")
-			}
-			parts := strings.Split(client.Addr.String(), ":")
-			if len(parts) != 2 {
-				log.Errorf("[auth] invalid remote address format")
-				return ctx, errors.New("invalid remote address format")
-			}
-			if parts[0] != rootAddr {
-				log.Errorf("[auth] invalid remote address")
-				return ctx, errors.New("invalid remote address")
-			}
-		} else {
-			if !strings.HasPrefix(info.FullMethod, "/"+caType) {
-				log.Errorf("[auth] certificate type does not match method")
-				return ctx, errors.New("certificate type does not match method")
-			}
+		if err := authorize(ctx, info.FullMethod, log); err != nil {
+			return nil, err
 		}
 		return handler(ctx, req)
 	}
 }
+
+// streamLogInterceptor - Log middleware for streaming rpc
+func streamLogInterceptor(log *configs.StructuredLog) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		entry := &configs.LogEntry{
+			Operator: getClientName(ss.Context()),
+			Method:   info.FullMethod,
+			Message:  "stream open",
+		}
+		if sid, err := getSessionID(ss.Context()); err == nil {
+			entry.SessionID = sid
+		}
+		log.Entry(logs.Info, entry)
+		start := time.Now()
+		err := handler(srv, ss)
+		entry.Duration = time.Since(start)
+		entry.Message = "stream closed"
+		if err != nil {
+			entry.Error = err.Error()
+			log.Entry(logs.Error, entry)
+		} else {
+			log.Entry(logs.Info, entry)
+		}
+		return err
+	}
+}
+
+// streamMetricsInterceptor - record lifetime of streaming rpc
+func streamMetricsInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		metrics.ObserveRPC(info.FullMethod, status.Code(err).String(), time.Since(start))
+		return err
+	}
+}
+
+func streamAuditInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		sess, err := getSession(ss.Context())
+		if err == nil && sess.Logger() != nil {
+			sess.Logger().Consolef("[stream] %s open \n", info.FullMethod)
+			err := handler(srv, ss)
+			sess.Logger().Consolef("[stream] %s closed \n", info.FullMethod)
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// streamAuthInterceptor - Auth middleware for streaming rpc
+func streamAuthInterceptor(log *configs.StructuredLog) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := authorize(ss.Context(), info.FullMethod, log); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// authorize - check ca type of the verified peer certificate matches the method
+func authorize(ctx context.Context, method string, log *configs.StructuredLog) error {
+	client, ok := peer.FromContext(ctx)
+	if !ok {
+		log.Errorf("[auth] failed to get peers information from context")
+		return status.Error(codes.Unauthenticated, "failed to get peers information from context")
+	}
+	cert := getClientCertificate(ctx)
+	if cert == nil {
+		log.Errorf("[auth] verified certificate not found")
+		return status.Error(codes.Unauthenticated, "verified certificate not found")
+	}
+	caType, err := certs.PeerCAType(cert)
+	if err != nil {
+		log.Errorf("[auth] certificate %s not issued by server, %s", cert.Subject.CommonName, err)
+		return status.Error(codes.Unauthenticated, "certificate not issued by server")
+	}
+	switch caType {
+	case certs.RootCA:
+		if !strings.Contains(method, "."+rootName) {
+			log.Errorf("[auth] certificate type does not match method")
+			return status.Error(codes.PermissionDenied, "certificate type does not match method")
+		}
+		parts := strings.Split(client.Addr.String(), ":")
+		if len(parts) != 2 {
+			log.Errorf("[auth] invalid remote address format")
+			return status.Error(codes.PermissionDenied, "invalid remote address format")
+		}
+		if parts[0] != rootAddr {
+			log.Errorf("[auth] invalid remote address")
+			return status.Error(codes.PermissionDenied, "invalid remote address")
+		}
+	case certs.OperatorCA, certs.ListenerCA:
+		namespace := clientNamespace
+		if caType == certs.ListenerCA {
+			namespace = certs.ListenerNamespace
+		}
+		if !strings.HasPrefix(method, "/"+namespace) || strings.Contains(method, "."+rootName) {
+			log.Errorf("[auth] %s certificate does not match method %s", namespace, method)
+			return status.Error(codes.PermissionDenied, "certificate type does not match method")
+		}
+	default:
+		log.Errorf("[auth] %s certificate can not call %s", certs.CATypeName(caType), method)
+		return status.Error(codes.PermissionDenied, "certificate type does not match method")
+	}
+	return nil
+}
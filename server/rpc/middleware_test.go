@@ -0,0 +1,271 @@
+package rpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chainreactors/malice-network/helper/consts"
+	"github.com/chainreactors/malice-network/helper/mtls"
+	"github.com/chainreactors/malice-network/proto/client/clientpb"
+	"github.com/chainreactors/malice-network/server/internal/certs"
+	"github.com/chainreactors/malice-network/server/internal/configs"
+	"github.com/chainreactors/malice-network/server/internal/core"
+	"github.com/chainreactors/malice-network/server/internal/db"
+	"github.com/chainreactors/malice-network/server/internal/db/models"
+	"github.com/gookit/config/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"gorm.io/gorm"
+)
+
+// startAuthServer - the grpc server of StartClientListener with a fresh root ca and cert store
+func startAuthServer(t *testing.T) string {
+	dir := t.TempDir()
+	oldClient, oldCertsPath, oldLogPath := db.Client, configs.CertsPath, configs.LogPath
+	t.Cleanup(func() {
+		db.Client, configs.CertsPath, configs.LogPath = oldClient, oldCertsPath, oldLogPath
+	})
+	client, err := gorm.Open(db.Open(dir+"/malice.db"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.AutoMigrate(&models.Certificate{}, &models.Operator{}, &models.Listener{}); err != nil {
+		t.Fatal(err)
+	}
+	db.Client = client
+	configs.CertsPath = dir
+	configs.LogPath = dir
+	InitLogs(false)
+	if _, _, err := certs.ServerGenerateCertificate(certs.RootName, true, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	grpcServer := newGrpcServer(credentials.NewTLS(certs.GetOperatorServerMTLSConfig("server")))
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go grpcServer.Serve(ln)
+	t.Cleanup(grpcServer.Stop)
+	return ln.Addr().String()
+}
+
+// dial - connect with the certificate, serverName is the SNI sent by the client
+func dial(t *testing.T, ctx context.Context, addr string, conf *mtls.ClientConfig, serverName string) *grpc.ClientConn {
+	options, err := mtls.GetGrpcOptions([]byte(conf.CACertificate), []byte(conf.Certificate), []byte(conf.PrivateKey), serverName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := grpc.DialContext(ctx, addr, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+// call - invoke method with the certificate
+func call(t *testing.T, addr string, conf *mtls.ClientConfig, serverName, method string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn := dial(t, ctx, addr, conf, serverName)
+	defer conn.Close()
+	return conn.Invoke(ctx, method, &emptypb.Empty{}, &emptypb.Empty{})
+}
+
+// openStream - open server streaming method with the certificate and wait for the first message until timeout
+func openStream(t *testing.T, addr string, conf *mtls.ClientConfig, serverName, method string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	conn := dial(t, ctx, addr, conf, serverName)
+	defer conn.Close()
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, method)
+	if err != nil {
+		return err
+	}
+	// io.EOF means the stream is already ended by server, the status is returned by RecvMsg
+	if err := stream.SendMsg(&emptypb.Empty{}); err != nil && err != io.EOF {
+		return err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+	return stream.RecvMsg(&clientpb.Event{})
+}
+
+// waitLog - wait until the log file contains all lines
+func waitLog(t *testing.T, file string, lines ...string) {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		content, _ := os.ReadFile(file)
+		missing := ""
+		for _, line := range lines {
+			if !strings.Contains(string(content), line) {
+				missing = line
+				break
+			}
+		}
+		if missing == "" {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s not found in %s:\n%s", missing, file, content)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func denied(err error) bool {
+	return status.Code(err) == codes.PermissionDenied || status.Code(err) == codes.Unauthenticated
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	addr := startAuthServer(t)
+	operator, err := certs.ClientGenerateCertificate("127.0.0.1", "auth_operator", 0, certs.OperatorCA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listenerName := "auth_listener"
+	if _, err := os.Stat(listenerName + ".yaml"); err == nil {
+		t.Skipf("%s.yaml exists in working directory", listenerName)
+	}
+	listener, err := certs.ClientGenerateCertificate("127.0.0.1", listenerName, 0, certs.ListenerCA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		name       string
+		conf       *mtls.ClientConfig
+		serverName string
+		method     string
+		denied     bool
+	}{
+		{"operator calls client rpc", operator, "client", "/clientrpc.MaliceRPC/GetBasic", false},
+		{"operator calls listener rpc", operator, "client", "/listenerrpc.ListenerRPC/RegisterListener", true},
+		{"operator calls root rpc", operator, "client", "/clientrpc.RootRPC/AddClient", true},
+		{"operator spoofs listener sni", operator, "listener", "/listenerrpc.ListenerRPC/RegisterListener", true},
+		{"operator spoofs root sni", operator, certs.RootName, "/clientrpc.RootRPC/AddClient", true},
+		{"listener calls listener rpc", listener, "listener", "/listenerrpc.ListenerRPC/RegisterListener", false},
+		{"listener spoofs client sni", listener, "client", "/clientrpc.MaliceRPC/GetBasic", true},
+	} {
+		err := call(t, addr, c.conf, c.serverName, c.method)
+		if denied(err) != c.denied {
+			t.Errorf("%s: expect denied %v, got %v", c.name, c.denied, err)
+		}
+	}
+
+	// certificate signed by the ca but not in cert store, e.g. removed operator
+	if err := certs.RemoveCertificate(certs.OperatorCA, certs.RSAKey, "auth_operator"); err != nil {
+		t.Fatal(err)
+	}
+	if err := call(t, addr, operator, "client", "/clientrpc.MaliceRPC/GetBasic"); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("removed operator certificate accepted, err: %v", err)
+	}
+}
+
+func TestStreamInterceptors(t *testing.T) {
+	addr := startAuthServer(t)
+	operator, err := certs.ClientGenerateCertificate("127.0.0.1", "stream_operator", 0, certs.OperatorCA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listenerName := "stream_listener"
+	if _, err := os.Stat(listenerName + ".yaml"); err == nil {
+		t.Skipf("%s.yaml exists in working directory", listenerName)
+	}
+	listener, err := certs.ClientGenerateCertificate("127.0.0.1", listenerName, 0, certs.ListenerCA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := "/clientrpc.MaliceRPC/Events"
+
+	// listener certificate must not subscribe events of operators
+	if err := openStream(t, addr, listener, "listener", events, 5*time.Second); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("listener opens events stream, expect PermissionDenied, got %v", err)
+	}
+	// the events stream of operator stays open until the client gives up
+	if err := openStream(t, addr, operator, "client", events, 500*time.Millisecond); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("operator opens events stream, expect stream kept open, got %v", err)
+	}
+
+	rpcLogFile := filepath.Join(configs.LogPath, "rpc.log")
+	waitLog(t, rpcLogFile,
+		"stream_listener call "+events+": stream open",
+		"stream_listener call "+events+": stream closed",
+		"PermissionDenied",
+		"stream_operator call "+events+": stream open",
+		"stream_operator call "+events+": stream closed",
+	)
+}
+
+// auditStream - server stream of the session, only the context is used by interceptors
+type auditStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *auditStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamAuditInterceptor(t *testing.T) {
+	dir := t.TempDir()
+	oldAuditPath, oldCachePath, oldSessions, oldLevel := configs.AuditPath, configs.CachePath, core.Sessions, config.Int(consts.AuditLevel)
+	t.Cleanup(func() {
+		configs.AuditPath, configs.CachePath, core.Sessions = oldAuditPath, oldCachePath, oldSessions
+		config.Set(consts.AuditLevel, oldLevel)
+	})
+	configs.AuditPath, configs.CachePath = dir, dir
+	core.Sessions = core.NewSessions()
+	if err := config.Set(consts.AuditLevel, 1); err != nil {
+		t.Fatal(err)
+	}
+	core.Sessions.Add(newTestSession("audit"))
+
+	interceptor := streamAuditInterceptor()
+	method := "/clientrpc.MaliceRPC/Upload"
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("session_id", "audit"))
+	handled := false
+	err := interceptor(nil, &auditStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: method}, func(srv interface{}, stream grpc.ServerStream) error {
+		handled = true
+		return nil
+	})
+	if err != nil || !handled {
+		t.Fatalf("handler not called, err: %v", err)
+	}
+	waitLog(t, filepath.Join(dir, "audit.log"), "[stream] "+method+" open", "[stream] "+method+" closed")
+
+	// streams without session are passed through without audit
+	handled = false
+	err = interceptor(nil, &auditStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: method}, func(srv interface{}, stream grpc.ServerStream) error {
+		handled = true
+		return nil
+	})
+	if err != nil || !handled {
+		t.Fatalf("handler of stream without session not called, err: %v", err)
+	}
+}
+
+func TestClientNameOfServerCall(t *testing.T) {
+	md := metadata.Pairs("operator", "admin")
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+	if name := getClientName(ctx); name != "admin" {
+		t.Fatalf("expect operator of server call, got %q", name)
+	}
+	// clients can not claim an operator by metadata
+	remote := peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)}})
+	if name := getClientName(remote); name != "" {
+		t.Fatalf("operator of client call is taken from metadata: %q", name)
+	}
+}
@@ -0,0 +1,97 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"github.com/chainreactors/malice-network/proto/client/clientpb"
+	"github.com/chainreactors/malice-network/proto/implant/implantpb"
+	"github.com/chainreactors/malice-network/server/internal/artifact"
+	"github.com/chainreactors/malice-network/server/internal/db/models"
+	"google.golang.org/protobuf/proto"
+	"gorm.io/gorm"
+)
+
+func artifactError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFoundArtifact
+	case errors.Is(err, artifact.ErrInvalidHash), errors.Is(err, artifact.ErrHashMismatch):
+		return ErrInvalidArtifact
+	}
+	return err
+}
+
+// resolveArtifact - requests referencing an artifact by hash carry no binary, fill it from the store before sending to implant
+func resolveArtifact(msg proto.Message) error {
+	var hash *string
+	var bin *[]byte
+	switch req := msg.(type) {
+	case *implantpb.ExecuteBinary:
+		hash, bin = &req.Artifact, &req.Bin
+	case *implantpb.ExecuteExtension:
+		if req.ExecuteBinary == nil {
+			return nil
+		}
+		hash, bin = &req.ExecuteBinary.Artifact, &req.ExecuteBinary.Bin
+	case *implantpb.LoadExtension:
+		hash, bin = &req.Artifact, &req.Bin
+	default:
+		return nil
+	}
+	if *hash == "" {
+		return nil
+	}
+	content, err := artifact.Read(*hash)
+	if err != nil {
+		return artifactError(err)
+	}
+	*bin, *hash = content, ""
+	return nil
+}
+
+// UploadArtifact - store extension or alias binary, the same content is stored once
+func (rpc *Server) UploadArtifact(ctx context.Context, req *clientpb.Artifact) (*clientpb.Artifact, error) {
+	if len(req.Bin) == 0 {
+		return nil, ErrInvalidArtifact
+	}
+	stored, err := artifact.Add(&models.Artifact{
+		Hash:     req.Hash,
+		Name:     req.Name,
+		Type:     req.Type,
+		Operator: getClientName(ctx),
+	}, req.Bin)
+	if err != nil {
+		return nil, artifactError(err)
+	}
+	rpcLog.Infof("artifact %s %s (%s) uploaded by %s", stored.Name, stored.Hash, stored.Type, getClientName(ctx))
+	return stored.ToProtobuf(), nil
+}
+
+// GetArtifact - artifact info by hash, NotFound tells the client to upload it
+func (rpc *Server) GetArtifact(ctx context.Context, req *clientpb.Artifact) (*clientpb.Artifact, error) {
+	stored, err := artifact.Get(req.Hash)
+	if err != nil {
+		return nil, artifactError(err)
+	}
+	return stored.ToProtobuf(), nil
+}
+
+func (rpc *Server) ListArtifacts(ctx context.Context, req *clientpb.Empty) (*clientpb.Artifacts, error) {
+	artifacts, err := artifact.List()
+	if err != nil {
+		return nil, err
+	}
+	result := &clientpb.Artifacts{Artifacts: []*clientpb.Artifact{}}
+	for _, a := range artifacts {
+		result.Artifacts = append(result.Artifacts, a.ToProtobuf())
+	}
+	return result, nil
+}
+
+func (rpc *Server) DeleteArtifact(ctx context.Context, req *clientpb.Artifact) (*clientpb.Empty, error) {
+	if err := artifact.Remove(req.Hash); err != nil {
+		return nil, artifactError(err)
+	}
+	rpcLog.Infof("artifact %s deleted by %s", req.Hash, getClientName(ctx))
+	return &clientpb.Empty{}, nil
+}
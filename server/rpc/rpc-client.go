@@ -1,7 +1,9 @@
 package rpc
 
 import (
+	"bytes"
 	"context"
+	"encoding/pem"
 	"github.com/chainreactors/logs"
 	"github.com/chainreactors/malice-network/proto/client/clientpb"
 	"github.com/chainreactors/malice-network/proto/client/rootpb"
@@ -22,50 +24,60 @@ func (rpc *Server) GetClients(ctx context.Context, req *clientpb.Empty) (*client
 }
 
 func (rpc *Server) LoginClient(ctx context.Context, req *clientpb.LoginReq) (*clientpb.LoginResp, error) {
-	host, port := req.Host, uint16(req.Port)
-	var operator []*models.Operator
-	if host == "" || port == 0 {
-		logs.Log.Error("AddClient: host or user is empty")
+	history := &models.LoginHistory{
+		Operator:      getClientName(ctx),
+		RemoteAddr:    getRemoteAddr(ctx),
+		ClientVersion: req.ClientVersion,
+	}
+	err := verifyOperator(ctx, req.Name)
+	if err != nil {
+		history.Reason = err.Error()
+		if dbErr := db.AddLoginHistory(history); dbErr != nil {
+			logs.Log.Errorf("record login history failed, %s", dbErr.Error())
+		}
+		logs.Log.Warnf("%s login from %s rejected, %s", req.Name, history.RemoteAddr, err.Error())
 		return &clientpb.LoginResp{
 			Success: false,
-		}, nil
+		}, err
 	}
-	dbSession := db.Session()
-	//cert := models.Certificate{}
-	//err := dbSession.Where(&models.Certificate{
-	//	CommonName: req.Name,
-	//	CAType:     certs.OperatorCA,
-	//}).First(&cert).Error
-	//if err != nil {
-	//	if errors.Is(err, db.ErrRecordNotFound) {
-	//		return &clientpb.LoginResp{
-	//			Success: false,
-	//		}, errors.New("certificate not found")
-	//	}
-	//	return &clientpb.LoginResp{
-	//		Success: false,
-	//	}, err
-	//}
 
-	dbSession.Where(&models.Operator{Name: req.Name}).Find(&operator)
-	if len(operator) != 0 {
-		return &clientpb.LoginResp{
-			Success: true,
-		}, nil
-	}
-	err := dbSession.Create(&models.Operator{
-		Name: req.Name,
-	}).Error
+	_, err = db.GetOrCreateOperator(history.Operator)
 	if err != nil {
 		return &clientpb.LoginResp{
 			Success: false,
 		}, err
 	}
+	history.Success = true
+	err = db.AddLoginHistory(history)
+	if err != nil {
+		logs.Log.Errorf("record login history failed, %s", err.Error())
+	}
+	logs.Log.Importantf("%s login from %s, client %s", history.Operator, history.RemoteAddr, req.ClientVersion)
 	return &clientpb.LoginResp{
 		Success: true,
 	}, nil
 }
 
+// verifyOperator - the name must match the CN of presented certificate, and the certificate must be the one issued by server
+func verifyOperator(ctx context.Context, name string) error {
+	cert := getClientCertificate(ctx)
+	if cert == nil || cert.Subject.CommonName == "" {
+		return ErrNotFoundClientName
+	}
+	if name != "" && name != cert.Subject.CommonName {
+		return ErrOperatorMismatch
+	}
+	certPEM, _, err := certs.GetRSACertificate(certs.OperatorCA, cert.Subject.CommonName)
+	if err != nil {
+		return ErrOperatorRevoked
+	}
+	block, _ := pem.Decode(certPEM)
+	if block == nil || !bytes.Equal(block.Bytes, cert.Raw) {
+		return ErrOperatorRevoked
+	}
+	return nil
+}
+
 func (rpc *Server) AddClient(ctx context.Context, req *rootpb.Operator) (*rootpb.Response, error) {
 	cfg := configs.GetServerConfig()
 	clientConf, err := certs.ClientGenerateCertificate(cfg.GRPCHost, req.Args[0], int(cfg.GRPCPort), certs.OperatorCA)
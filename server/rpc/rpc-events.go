@@ -3,6 +3,7 @@ package rpc
 import (
 	"context"
 	"github.com/chainreactors/logs"
+	"github.com/chainreactors/malice-network/helper/consts"
 	"github.com/chainreactors/malice-network/proto/client/clientpb"
 	"github.com/chainreactors/malice-network/proto/services/clientrpc"
 	"github.com/chainreactors/malice-network/server/internal/core"
@@ -10,11 +11,15 @@ import (
 
 func (rpc *Server) Events(_ *clientpb.Empty, stream clientrpc.MaliceRPC_EventsServer) error {
 	clientName := getClientName(stream.Context())
+	if clientName == "" {
+		return ErrNotFoundClientName
+	}
 	events := core.EventBroker.Subscribe()
 	client := core.NewClient(clientName)
+	client.RemoteAddr = getRemoteAddr(stream.Context())
 	core.Clients.Add(client)
 	defer func() {
-		logs.Log.Infof("%d client disconnected", client.ID)
+		logs.Log.Infof("%s(%d) client disconnected", client.Name, client.ID)
 		core.EventBroker.Unsubscribe(events)
 		core.Clients.Remove(int(client.ID))
 	}()
@@ -23,6 +28,8 @@ func (rpc *Server) Events(_ *clientpb.Empty, stream clientrpc.MaliceRPC_EventsSe
 		select {
 		case <-stream.Context().Done():
 			return nil
+		case <-streamsCtx.Done():
+			return stream.Send(&clientpb.Event{Type: consts.EventShutdown, Message: "server is shutting down"})
 		case event := <-events:
 			pbEvent := &clientpb.Event{
 				Type:   event.EventType,
@@ -53,6 +60,9 @@ func (rpc *Server) Events(_ *clientpb.Empty, stream clientrpc.MaliceRPC_EventsSe
 				logs.Log.Warnf(err.Error())
 				return err
 			}
+			if event.EventType == consts.EventShutdown {
+				return nil
+			}
 		}
 	}
 }
@@ -31,6 +31,7 @@ func (rpc *Server) ExecuteAssembly(ctx context.Context, req *implantpb.ExecuteBi
 	if err != nil {
 		return nil, err
 	}
+	ch = parseResponse(greq.Task, req.Name, ch)
 	go greq.HandlerAsyncResponse(ch, types.MsgAssemblyResponse)
 	return greq.Task.ToProtobuf(), nil
 }
@@ -45,6 +46,7 @@ func (rpc *Server) ExecuteShellcode(ctx context.Context, req *implantpb.ExecuteB
 	if err != nil {
 		return nil, err
 	}
+	ch = parseResponse(greq.Task, req.Name, ch)
 	go greq.HandlerAsyncResponse(ch, types.MsgAssemblyResponse)
 	return greq.Task.ToProtobuf(), nil
 }
@@ -59,6 +61,7 @@ func (rpc *Server) ExecuteBof(ctx context.Context, req *implantpb.ExecuteBinary)
 	if err != nil {
 		return nil, err
 	}
+	ch = parseResponse(greq.Task, req.Name, ch)
 	go greq.HandlerAsyncResponse(ch, types.MsgAssemblyResponse)
 	return greq.Task.ToProtobuf(), nil
 }
@@ -73,6 +76,7 @@ func (rpc *Server) ExecutePE(ctx context.Context, req *implantpb.ExecuteBinary)
 	if err != nil {
 		return nil, err
 	}
+	ch = parseResponse(greq.Task, req.Name, ch)
 	go greq.HandlerAsyncResponse(ch, types.MsgAssemblyResponse)
 	return greq.Task.ToProtobuf(), nil
 }
@@ -87,6 +91,7 @@ func (rpc *Server) ExecuteDll(ctx context.Context, req *implantpb.ExecuteBinary)
 	if err != nil {
 		return nil, err
 	}
+	ch = parseResponse(greq.Task, req.Name, ch)
 	go greq.HandlerAsyncResponse(ch, types.MsgAssemblyResponse)
 	return greq.Task.ToProtobuf(), nil
 }
@@ -101,6 +106,7 @@ func (rpc *Server) ExecutePowershell(ctx context.Context, req *implantpb.Execute
 	if err != nil {
 		return nil, err
 	}
+	ch = parseResponse(greq.Task, req.Name, ch)
 	go greq.HandlerAsyncResponse(ch, types.MsgAssemblyResponse)
 	return greq.Task.ToProtobuf(), nil
 }
@@ -57,6 +57,7 @@ func (rpc *Server) ExecuteExtension(ctx context.Context, req *implantpb.ExecuteE
 	if err != nil {
 		return nil, err
 	}
+	ch = parseResponse(greq.Task, req.Extension, ch)
 	go greq.HandlerAsyncResponse(ch, types.MsgAssemblyResponse)
 	return greq.Task.ToProtobuf(), nil
 }
@@ -16,14 +16,17 @@ func (rpc *Server) Register(ctx context.Context, req *lispb.RegisterSession) (*i
 	sess, success := core.Sessions.Get(req.SessionId)
 	if success {
 		sess.Update(req)
+		sess.UpdateLastCheckin()
 		err := db.UpdateSessionInfo(sess)
 		if err != nil {
 			logs.Log.Errorf("update session %s info failed in db, %s", sess.ID, err.Error())
 		}
+		go deliverQueue(sess)
 		return &implantpb.Empty{}, nil
 	}
 
 	sess = core.NewSession(req)
+	sess.UpdateLastCheckin()
 	core.Sessions.Add(sess)
 	dbSession := db.Session()
 	d := dbSession.Create(models.ConvertToSessionDB(sess))
@@ -35,6 +38,8 @@ func (rpc *Server) Register(ctx context.Context, req *lispb.RegisterSession) (*i
 			Session:   sess,
 			Message:   "re-register",
 		})
+		RecoverQueuedTasks(sess)
+		go deliverQueue(sess)
 		return &implantpb.Empty{}, nil
 	} else {
 		core.EventBroker.Publish(core.Event{
@@ -65,28 +70,37 @@ func (rpc *Server) Ping(ctx context.Context, req *implantpb.Ping) (*implantpb.Em
 	if err != nil {
 		return nil, err
 	}
-	if s, ok := core.Sessions.Get(id); !ok {
+	s, ok := core.Sessions.Get(id)
+	if !ok {
 		sess, err := db.FindSession(id)
 		if err != nil {
 			return nil, err
 		}
 		newSess := core.NewSession(sess)
+		err = db.RecoverSessionMeta(newSess)
+		if err != nil {
+			logs.Log.Errorf("cannot recover session group and tags , %s ", err.Error())
+		}
 		_, taskID, err := db.FindTaskAndMaxTasksID(id)
 		if err != nil {
 			logs.Log.Errorf("cannot find max task id , %s ", err.Error())
 		}
 		newSess.SetLastTaskId(uint32(taskID))
+		RecoverQueuedTasks(newSess)
 		core.Sessions.Add(newSess)
 		newSess.Load()
 		logs.Log.Debugf("recover session %s", id)
-	} else {
-		s.UpdateLastCheckin()
+		s = newSess
 	}
+	// recovered sessions come back from offline
+	wasAlive := ok && s.IsAlive()
+	s.UpdateLastCheckin()
 
 	err = db.UpdateLast(id)
 	if err != nil {
 		return nil, err
 	}
+	deliverOnCheckin(s, wasAlive)
 
 	return &implantpb.Empty{}, nil
 }
@@ -2,13 +2,24 @@ package rpc
 
 import (
 	"fmt"
+	"github.com/chainreactors/logs"
 	"github.com/chainreactors/malice-network/helper/consts"
+	"github.com/chainreactors/malice-network/proto/client/clientpb"
+	"github.com/chainreactors/malice-network/proto/listener/lispb"
 	"github.com/chainreactors/malice-network/proto/services/listenerrpc"
 	"github.com/chainreactors/malice-network/server/internal/core"
+	"github.com/chainreactors/malice-network/server/internal/db"
 )
 
 func (rpc *Server) JobStream(stream listenerrpc.ListenerRPC_JobStreamServer) error {
+	ls := &listenerStream{
+		ctrl: make(chan *clientpb.JobCtrl, 1),
+		done: make(chan struct{}),
+	}
+	listenerStreams.Store(ls, true)
+	defer listenerStreams.Delete(ls)
 	go func() {
+		defer close(ls.done)
 		for {
 			select {
 			case msg := <-core.Jobs.Ctrl:
@@ -16,15 +27,38 @@ func (rpc *Server) JobStream(stream listenerrpc.ListenerRPC_JobStreamServer) err
 				if err != nil {
 					return
 				}
+			case msg := <-ls.ctrl:
+				err := stream.Send(msg)
+				if err != nil || msg.Ctrl == consts.CtrlServerShutdown {
+					return
+				}
+			case <-stream.Context().Done():
+				return
 			}
 		}
 	}()
 
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- recvJobStatus(stream)
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-streamsCtx.Done():
+		return nil
+	}
+}
+
+// recvJobStatus - handle job status reported by the listener until the stream breaks
+func recvJobStatus(stream listenerrpc.ListenerRPC_JobStreamServer) error {
+	listenerID, _ := getListenerID(stream.Context())
 	for {
 		msg, err := stream.Recv()
 		if err != nil {
 			return err
 		}
+		recordPipelineHistory(listenerID, msg)
 		if msg.Status == consts.CtrlStatusSuccess {
 			if msg.Ctrl == consts.CtrlPipelineStart {
 				core.EventBroker.Publish(core.Event{
@@ -47,9 +81,19 @@ func (rpc *Server) JobStream(stream listenerrpc.ListenerRPC_JobStreamServer) err
 					EventType: consts.EventWebsite,
 					Message:   fmt.Sprintf("%s stop", msg.Job.GetPipeline().GetWeb().GetName()),
 				})
+			} else if msg.Ctrl == consts.CtrlCertRotate {
+				core.EventBroker.Publish(core.Event{
+					EventType: consts.EventCert,
+					Message:   fmt.Sprintf("%s certificate rotated", pipelineName(msg.Job.GetPipeline())),
+				})
 			}
 		} else {
-			if msg.Ctrl == consts.CtrlWebsiteStart || msg.Ctrl == consts.CtrlWebsiteStop {
+			if msg.Ctrl == consts.CtrlCertRotate {
+				core.EventBroker.Publish(core.Event{
+					EventType: consts.EventCert,
+					Err:       fmt.Sprintf("%s certificate rotate failed, %s", pipelineName(msg.Job.GetPipeline()), msg.Error),
+				})
+			} else if msg.Ctrl == consts.CtrlWebsiteStart || msg.Ctrl == consts.CtrlWebsiteStop {
 				core.EventBroker.Publish(core.Event{
 					EventType: consts.EventWebsite,
 					Err:       fmt.Sprintf("%d, %s", msg.Status, msg.Error),
@@ -63,3 +107,38 @@ func (rpc *Server) JobStream(stream listenerrpc.ListenerRPC_JobStreamServer) err
 		}
 	}
 }
+
+func recordPipelineHistory(listenerID string, msg *clientpb.JobStatus) {
+	var name, typ, action, errMsg string
+	switch msg.Ctrl {
+	case consts.CtrlPipelineStart, consts.CtrlPipelineStop:
+		name, typ = msg.GetJob().GetPipeline().GetTcp().GetName(), "tcp"
+	case consts.CtrlWebsiteStart, consts.CtrlWebsiteStop:
+		name, typ = msg.GetJob().GetPipeline().GetWeb().GetName(), "website"
+	case consts.CtrlCertRotate:
+		name, typ = pipelineName(msg.GetJob().GetPipeline()), "cert"
+	default:
+		return
+	}
+	if msg.Ctrl == consts.CtrlPipelineStart || msg.Ctrl == consts.CtrlWebsiteStart {
+		action = "start"
+	} else if msg.Ctrl == consts.CtrlCertRotate {
+		action = "rotate"
+	} else {
+		action = "stop"
+	}
+	if msg.Status != consts.CtrlStatusSuccess {
+		errMsg = fmt.Sprintf("%d, %s", msg.Status, msg.Error)
+	}
+	err := db.AddPipelineHistory(name, listenerID, typ, action, errMsg)
+	if err != nil {
+		logs.Log.Errorf("record pipeline history failed: %s", err.Error())
+	}
+}
+
+func pipelineName(pipeline *lispb.Pipeline) string {
+	if web := pipeline.GetWeb(); web != nil {
+		return web.GetName()
+	}
+	return pipeline.GetTcp().GetName()
+}
@@ -42,6 +42,20 @@ func (rpc *Server) SpiteStream(stream listenerrpc.ListenerRPC_SpiteStreamServer)
 		return err
 	}
 	pipelinesCh[listenerID] = stream
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- recvSpites(stream)
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-streamsCtx.Done():
+		return nil
+	}
+}
+
+// recvSpites - pass responses of the pipeline to the waiting tasks until the stream breaks
+func recvSpites(stream listenerrpc.ListenerRPC_SpiteStreamServer) error {
 	for {
 		msg, err := stream.Recv()
 		if err != nil {
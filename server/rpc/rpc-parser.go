@@ -0,0 +1,151 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/chainreactors/malice-network/proto/client/clientpb"
+	"github.com/chainreactors/malice-network/proto/implant/implantpb"
+	"github.com/chainreactors/malice-network/server/internal/artifact"
+	"github.com/chainreactors/malice-network/server/internal/core"
+	"github.com/chainreactors/malice-network/server/internal/db"
+	"github.com/chainreactors/malice-network/server/internal/db/models"
+	"github.com/chainreactors/malice-network/server/internal/parser"
+	"gorm.io/gorm"
+)
+
+// LoadParsers - register parsers saved in database, a parser whose artifact is gone is skipped
+func LoadParsers() error {
+	parsers, err := db.ListParsers()
+	if err != nil {
+		return err
+	}
+	for _, p := range parsers {
+		wasm, err := artifact.Read(p.Hash)
+		if err != nil {
+			rpcLog.Errorf("cannot read parser %s artifact %s, %s", p.Name, p.Hash, err.Error())
+			continue
+		}
+		if err := parser.Register(p.Name, wasm); err != nil {
+			rpcLog.Errorf("cannot register parser %s, %s", p.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+// parseResponse - parse output of extension or alias name before the response is handled,
+// so that the records are stored against the task when the client is told it is done
+func parseResponse(task *core.Task, name string, in chan *implantpb.Spite) chan *implantpb.Spite {
+	if !parser.Has(name) {
+		return in
+	}
+	out := make(chan *implantpb.Spite)
+	go func() {
+		resp := <-in
+		if output := resp.GetAssemblyResponse(); output != nil && output.Status == 0 {
+			if err := saveTaskResult(task, name, output.Data); err != nil {
+				rpcLog.Errorf("cannot parse output of %s task %d, %s", name, task.Id, err.Error())
+			}
+		}
+		out <- resp
+	}()
+	return out
+}
+
+func saveTaskResult(task *core.Task, name string, output []byte) error {
+	result, err := parser.Parse(name, output)
+	if err != nil {
+		return err
+	}
+	if result.Empty() {
+		return nil
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return db.SaveTaskResult(&models.TaskResult{
+		SessionID: task.SessionId,
+		TaskID:    task.Id,
+		Parser:    name,
+		Result:    string(data),
+	})
+}
+
+// taskResultToProtobuf - every kind of record of the stored parser.Result is sent as a table
+func taskResultToProtobuf(r *models.TaskResult) (*clientpb.TaskResult, error) {
+	result := &parser.Result{}
+	if err := json.Unmarshal([]byte(r.Result), result); err != nil {
+		return nil, err
+	}
+	pb := &clientpb.TaskResult{
+		SessionId: r.SessionID,
+		TaskId:    r.TaskID,
+		Parser:    r.Parser,
+	}
+	for _, table := range result.ToTables() {
+		pbTable := &clientpb.ResultTable{Name: table.Name, Columns: table.Columns}
+		for _, row := range table.Rows {
+			pbTable.Rows = append(pbTable.Rows, &clientpb.ResultRow{Cells: row})
+		}
+		pb.Tables = append(pb.Tables, pbTable)
+	}
+	return pb, nil
+}
+
+// RegisterParser - parse output of extension or alias req.Name with the wasm artifact req.Artifact
+func (rpc *Server) RegisterParser(ctx context.Context, req *clientpb.Parser) (*clientpb.Parser, error) {
+	wasm, err := artifact.Read(req.Artifact)
+	if err != nil {
+		return nil, artifactError(err)
+	}
+	if err = parser.Register(req.Name, wasm); err != nil {
+		return nil, err
+	}
+	p := &models.Parser{
+		Name:     req.Name,
+		Hash:     req.Artifact,
+		Operator: getClientName(ctx),
+	}
+	if err = db.SaveParser(p); err != nil {
+		return nil, err
+	}
+	rpcLog.Infof("parser of %s registered by %s", req.Name, getClientName(ctx))
+	return p.ToProtobuf(), nil
+}
+
+func (rpc *Server) ListParsers(ctx context.Context, req *clientpb.Empty) (*clientpb.Parsers, error) {
+	parsers, err := db.ListParsers()
+	if err != nil {
+		return nil, err
+	}
+	result := &clientpb.Parsers{Parsers: []*clientpb.Parser{}}
+	for _, p := range parsers {
+		result.Parsers = append(result.Parsers, p.ToProtobuf())
+	}
+	return result, nil
+}
+
+func (rpc *Server) RemoveParser(ctx context.Context, req *clientpb.Parser) (*clientpb.Empty, error) {
+	err := db.DeleteParser(req.Name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFoundParser
+	} else if err != nil {
+		return nil, err
+	}
+	if err = parser.Remove(req.Name); err != nil && !errors.Is(err, parser.ErrNotFoundParser) {
+		return nil, err
+	}
+	return &clientpb.Empty{}, nil
+}
+
+// GetTaskResult - records parsed from the output of task
+func (rpc *Server) GetTaskResult(ctx context.Context, req *clientpb.Task) (*clientpb.TaskResult, error) {
+	result, err := db.FindTaskResult(req.SessionId, req.TaskId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFoundTaskResult
+	} else if err != nil {
+		return nil, err
+	}
+	return taskResultToProtobuf(result)
+}
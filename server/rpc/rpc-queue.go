@@ -0,0 +1,326 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/chainreactors/logs"
+	"github.com/chainreactors/malice-network/helper/consts"
+	"github.com/chainreactors/malice-network/proto/client/clientpb"
+	"github.com/chainreactors/malice-network/proto/implant/implantpb"
+	"github.com/chainreactors/malice-network/proto/listener/lispb"
+	"github.com/chainreactors/malice-network/server/internal/core"
+	"github.com/chainreactors/malice-network/server/internal/db"
+	"github.com/chainreactors/malice-network/server/internal/db/models"
+	"github.com/gookit/config/v2"
+	"google.golang.org/protobuf/proto"
+	"gorm.io/gorm"
+	"sync"
+	"time"
+)
+
+var (
+	// delivering - sessions whose queue is being delivered, one delivery per session at a time
+	delivering = &sync.Map{}
+	// pendingQueue - sessions with requests queued since their last delivery
+	pendingQueue = &sync.Map{}
+	// queuedStreams - input channel of queued stream requests, forwarded once the first request is delivered
+	queuedStreams = &sync.Map{}
+)
+
+type queueKey struct {
+	sessionID string
+	taskID    uint32
+}
+
+// StartTaskQueue - drop queued tasks that expired before their session checked in
+func StartTaskQueue() error {
+	_, err := core.GlobalTicker.Start(consts.QueueExpiryCheckJitter, expireQueue)
+	return err
+}
+
+func queueExpiry() time.Duration {
+	if expiry := config.Int(consts.QueueExpiry); expiry > 0 {
+		return time.Duration(expiry) * time.Second
+	}
+	return consts.DefaultQueueExpiry
+}
+
+// queueRequest - store request to a late or dead session, the response arrives on the returned channel after delivery
+func (rpc *Server) queueRequest(req *GenericRequest, msg *lispb.SpiteSession) (chan *implantpb.Spite, error) {
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	err = db.EnqueueTask(&models.QueuedTask{
+		SessionID: req.Session.ID,
+		TaskID:    req.Task.Id,
+		Type:      req.Task.Type,
+		Total:     req.Task.Total,
+		Operator:  req.Task.Callby,
+		ExpireAt:  time.Now().Add(queueExpiry()),
+		Spite:     data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *implantpb.Spite)
+	req.Session.StoreResp(req.Task.Id, out)
+	pendingQueue.Store(req.Session.ID, true)
+	core.EventBroker.Publish(core.Event{
+		EventType: consts.EventQueue,
+		Session:   req.Session,
+		Task:      req.Task,
+		Message:   fmt.Sprintf("task %d %s queued for %s", req.Task.Id, req.Task.Type, req.Session.ID),
+	})
+	return out, nil
+}
+
+// queueStream - queue the first request of a stream, later requests on in are sent after it is delivered
+func (rpc *Server) queueStream(req *GenericRequest, msg *lispb.SpiteSession) (chan *implantpb.Spite, chan *implantpb.Spite, error) {
+	out, err := rpc.queueRequest(req, msg)
+	if err != nil {
+		return nil, nil, err
+	}
+	in := make(chan *implantpb.Spite)
+	queuedStreams.Store(queueKey{req.Session.ID, req.Task.Id}, in)
+	return in, out, nil
+}
+
+// deliverOnCheckin - deliver queue when session comes back or has requests queued while alive
+func deliverOnCheckin(sess *core.Session, wasAlive bool) {
+	if _, pending := pendingQueue.Load(sess.ID); pending || !wasAlive {
+		go deliverQueue(sess)
+	}
+}
+
+// deliverQueue - send queued requests of session in order, stops at the first failure
+func deliverQueue(sess *core.Session) {
+	if _, loaded := delivering.LoadOrStore(sess.ID, true); loaded {
+		return
+	}
+	defer delivering.Delete(sess.ID)
+	pendingQueue.Delete(sess.ID)
+
+	queued, err := db.FindQueuedTasks(sess.ID)
+	if err != nil {
+		logs.Log.Errorf("cannot find queued tasks of %s, %s", sess.ID, err.Error())
+		return
+	}
+	if len(queued) == 0 {
+		return
+	}
+	stream, ok := pipelinesCh[sess.PipelineID]
+	if !ok {
+		return
+	}
+	for _, q := range queued {
+		if q.Expired() {
+			failQueuedTask(sess, q, "queued task expired")
+			continue
+		}
+		msg := &lispb.SpiteSession{}
+		if err := proto.Unmarshal(q.Spite, msg); err != nil {
+			failQueuedTask(sess, q, err.Error())
+			continue
+		}
+		// claim the request, it may be cancelled or delivered meanwhile
+		if err := db.DeleteQueuedTask(q.SessionID, q.TaskID); errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		} else if err != nil {
+			logs.Log.Errorf("cannot claim queued task %d of %s, %s", q.TaskID, sess.ID, err.Error())
+			pendingQueue.Store(sess.ID, true)
+			return
+		}
+		task := sess.Tasks.Get(q.TaskID)
+		if !running(task) {
+			task = recoverQueuedTask(sess, q)
+		}
+		if err := sess.Request(msg, stream, consts.MinTimeout); err != nil {
+			logs.Log.Warnf("deliver queued task %d to %s failed, %s", q.TaskID, sess.ID, err.Error())
+			if err := db.RequeueTask(q); err != nil {
+				failQueuedTask(sess, q, err.Error())
+			}
+			pendingQueue.Store(sess.ID, true)
+			return
+		}
+		if in, ok := queuedStreams.LoadAndDelete(queueKey{sess.ID, q.TaskID}); ok {
+			if out, ok := sess.GetResp(q.TaskID); ok {
+				go sess.ForwardStream(q.TaskID, in.(chan *implantpb.Spite), out, stream)
+			}
+		}
+		core.EventBroker.Publish(core.Event{
+			EventType: consts.EventQueue,
+			Session:   sess,
+			Task:      task,
+			Message:   fmt.Sprintf("task %d %s delivered to %s", q.TaskID, q.Type, sess.ID),
+		})
+	}
+}
+
+// RecoverQueuedTasks - restore tasks queued or waiting for responses before server restart,
+// so that responses are accepted and new tasks do not reuse their ids
+func RecoverQueuedTasks(sess *core.Session) {
+	queued, err := db.FindQueuedTasks(sess.ID)
+	if err != nil {
+		logs.Log.Errorf("cannot find queued tasks of %s, %s", sess.ID, err.Error())
+		return
+	}
+	delivered, err := db.FindDeliveredTasks(sess.ID)
+	if err != nil {
+		logs.Log.Errorf("cannot find delivered tasks of %s, %s", sess.ID, err.Error())
+	}
+	for _, q := range append(queued, delivered...) {
+		if !running(sess.Tasks.Get(q.TaskID)) {
+			recoverQueuedTask(sess, q)
+		}
+	}
+	// delivered tasks live in memory again, they are saved at the next shutdown if still running
+	for _, q := range delivered {
+		if err := db.DeleteQueuedTask(q.SessionID, q.TaskID); err != nil {
+			logs.Log.Errorf("cannot delete delivered task %d of %s, %s", q.TaskID, q.SessionID, err.Error())
+		}
+	}
+}
+
+// SaveInflightTasks - save tasks sent to implants but not finished, they are restored by RecoverQueuedTasks
+func SaveInflightTasks() (int, error) {
+	var count int
+	for _, sess := range core.Sessions.All() {
+		queued, err := db.FindQueuedTasks(sess.ID)
+		if err != nil {
+			return count, err
+		}
+		pending := map[uint32]bool{}
+		for _, q := range queued {
+			pending[q.TaskID] = true
+		}
+		for _, task := range sess.Tasks.All() {
+			if !running(task) || task.Ctx.Err() != nil || pending[task.Id] {
+				continue
+			}
+			err = db.EnqueueTask(&models.QueuedTask{
+				SessionID: sess.ID,
+				TaskID:    task.Id,
+				Type:      task.Type,
+				Total:     task.Total,
+				Cur:       task.Cur,
+				Operator:  task.Callby,
+				ExpireAt:  time.Now().Add(queueExpiry()),
+				Delivered: true,
+			})
+			if err != nil {
+				return count, err
+			}
+			count++
+		}
+	}
+	return count, nil
+}
+
+// running - task created in this process, tasks loaded from database history have no context
+func running(task *core.Task) bool {
+	return task != nil && task.Ctx != nil
+}
+
+// recoverQueuedTask - the original handler is gone after restart, responses are only checked for status
+func recoverQueuedTask(sess *core.Session, q *models.QueuedTask) *core.Task {
+	task := sess.RecoverTask(q.TaskID, q.Type, q.Total)
+	task.Callby = q.Operator
+	task.Cur = q.Cur
+	out := make(chan *implantpb.Spite)
+	sess.StoreResp(task.Id, out)
+	go func() {
+		for i := q.Cur; i < task.Total; i++ {
+			resp := <-out
+			if err := AssertStatus(resp); err != nil {
+				task.Panic(buildErrorEvent(task, err), resp)
+				return
+			}
+			sess.AddMessage(resp, i+1)
+			task.Done(core.Event{
+				EventType: consts.EventTaskDone,
+				Task:      task,
+			})
+		}
+	}()
+	return task
+}
+
+// failQueuedTask - remove queued request, the waiting handler receives an error status
+func failQueuedTask(sess *core.Session, q *models.QueuedTask, reason string) {
+	if err := db.DeleteQueuedTask(q.SessionID, q.TaskID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		logs.Log.Errorf("cannot delete queued task %d of %s, %s", q.TaskID, q.SessionID, err.Error())
+	}
+	event := core.Event{
+		EventType: consts.EventQueue,
+		Message:   fmt.Sprintf("task %d %s dropped from queue of %s", q.TaskID, q.Type, q.SessionID),
+		Err:       reason,
+	}
+	queuedStreams.Delete(queueKey{q.SessionID, q.TaskID})
+	if sess != nil {
+		event.Session = sess
+		event.Task = sess.Tasks.Get(q.TaskID)
+		if out, ok := sess.GetResp(q.TaskID); ok {
+			select {
+			case out <- &implantpb.Spite{
+				TaskId: q.TaskID,
+				Status: &implantpb.Status{TaskId: q.TaskID, Status: 1, Error: reason},
+			}:
+			case <-time.After(time.Second):
+			}
+		}
+	}
+	core.EventBroker.Publish(event)
+}
+
+func expireQueue() {
+	queued, err := db.FindExpiredQueuedTasks()
+	if err != nil {
+		logs.Log.Errorf("cannot find expired queued tasks, %s", err.Error())
+		return
+	}
+	for _, q := range queued {
+		sess, _ := core.Sessions.Get(q.SessionID)
+		failQueuedTask(sess, q, "queued task expired")
+	}
+}
+
+// ListQueuedTasks - queued tasks of session, all sessions if session id is empty
+func (rpc *Server) ListQueuedTasks(ctx context.Context, req *clientpb.Session) (*clientpb.QueuedTasks, error) {
+	queued, err := db.FindQueuedTasks(req.SessionId)
+	if err != nil {
+		return nil, err
+	}
+	result := &clientpb.QueuedTasks{Tasks: []*clientpb.QueuedTask{}}
+	for _, q := range queued {
+		result.Tasks = append(result.Tasks, q.ToProtobuf())
+	}
+	return result, nil
+}
+
+// MoveQueuedTask - move queued task to req.Position of the session queue
+func (rpc *Server) MoveQueuedTask(ctx context.Context, req *clientpb.QueuedTask) (*clientpb.QueuedTasks, error) {
+	err := db.MoveQueuedTask(req.SessionId, req.TaskId, int(req.Position))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFoundQueuedTask
+	} else if err != nil {
+		return nil, err
+	}
+	return rpc.ListQueuedTasks(ctx, &clientpb.Session{SessionId: req.SessionId})
+}
+
+func (rpc *Server) CancelQueuedTask(ctx context.Context, req *clientpb.QueuedTask) (*clientpb.Empty, error) {
+	queued, err := db.FindQueuedTasks(req.SessionId)
+	if err != nil {
+		return nil, err
+	}
+	for _, q := range queued {
+		if q.TaskID == req.TaskId {
+			sess, _ := core.Sessions.Get(q.SessionID)
+			failQueuedTask(sess, q, fmt.Sprintf("cancelled by %s", getClientName(ctx)))
+			return &clientpb.Empty{}, nil
+		}
+	}
+	return nil, ErrNotFoundQueuedTask
+}
@@ -0,0 +1,194 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/chainreactors/malice-network/proto/client/clientpb"
+	"github.com/chainreactors/malice-network/server/internal/core"
+	"github.com/chainreactors/malice-network/server/internal/db"
+	"github.com/chainreactors/malice-network/server/internal/db/models"
+	"github.com/chainreactors/malice-network/server/internal/report"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func (rpc *Server) GenerateReport(ctx context.Context, req *clientpb.ReportRequest) (*clientpb.Report, error) {
+	r, err := buildReport(req.SessionIds)
+	if err != nil {
+		return nil, err
+	}
+	r.Name = req.Name
+	if r.Name == "" {
+		r.Name = fmt.Sprintf("report-%s", r.GeneratedAt.Format("20060102150405"))
+	}
+	r.Operator = getClientName(ctx)
+
+	content, err := r.Render(req.Format, req.Template)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &clientpb.Report{
+		Name:    r.Name,
+		Format:  req.Format,
+		Content: content,
+	}, nil
+}
+
+func buildReport(sessionIDs []string) (*report.Report, error) {
+	filter := map[string]bool{}
+	for _, id := range sessionIDs {
+		filter[id] = true
+	}
+	selected := func(sid string) bool {
+		return len(filter) == 0 || filter[sid]
+	}
+
+	r := &report.Report{GeneratedAt: time.Now()}
+	sessions, err := db.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+	for _, sess := range sessions {
+		if !selected(sess.SessionID) {
+			continue
+		}
+		r.Sessions = append(r.Sessions, toReportSession(sess))
+	}
+
+	tasks, err := db.ListTasks()
+	if err != nil {
+		return nil, err
+	}
+	recorded := map[string]bool{}
+	for _, task := range tasks {
+		if !selected(task.SessionID) {
+			continue
+		}
+		recorded[task.ID] = true
+		t := toReportTask(task)
+		r.Tasks = append(r.Tasks, t)
+		if task.Type == "download" || task.Type == "upload" {
+			var desc models.FileDescription
+			if err := json.Unmarshal([]byte(task.Description), &desc); err == nil {
+				r.Loot = append(r.Loot, &report.Loot{
+					SessionID: t.SessionID,
+					TaskID:    t.ID,
+					Type:      task.Type,
+					Name:      desc.Name,
+					Path:      desc.Path,
+					Size:      desc.Size,
+					Operator:  task.Operator,
+					CreatedAt: task.CreatedAt,
+				})
+			}
+		}
+	}
+	// tasks not persisted in db only live in memory
+	for _, sess := range core.Sessions.All() {
+		if !selected(sess.ID) {
+			continue
+		}
+		for _, task := range sess.Tasks.All() {
+			if recorded[task.SessionId+"-"+strconv.Itoa(int(task.Id))] {
+				continue
+			}
+			r.Tasks = append(r.Tasks, &report.Task{
+				ID:        task.Id,
+				SessionID: task.SessionId,
+				Type:      task.Type,
+				Operator:  task.Callby,
+				Cur:       task.Cur,
+				Total:     task.Total,
+				CreatedAt: task.CreatedAt,
+			})
+		}
+	}
+
+	listeners, err := db.ListListeners()
+	if err != nil {
+		return nil, err
+	}
+	for _, lns := range listeners {
+		l := &report.Listener{Name: lns.Name, CreatedAt: lns.CreatedAt}
+		if active := core.Listeners.Get(lns.Name); active != nil {
+			l.Addr = active.Host
+			l.Active = active.Active
+		}
+		r.Listeners = append(r.Listeners, l)
+	}
+
+	histories, err := db.ListPipelineHistory()
+	if err != nil {
+		return nil, err
+	}
+	for _, history := range histories {
+		r.Pipelines = append(r.Pipelines, &report.Pipeline{
+			Name:       history.Name,
+			ListenerID: history.ListenerID,
+			Type:       history.Type,
+			Action:     history.Action,
+			Error:      history.Error,
+			CreatedAt:  history.CreatedAt,
+		})
+	}
+
+	operators, err := db.ListOperators()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range operators.GetClients() {
+		r.Operators = append(r.Operators, &report.Operator{Name: op.Name})
+	}
+
+	r.BuildHosts()
+	r.Sort()
+	return r, nil
+}
+
+func toReportSession(sess models.Session) *report.Session {
+	s := &report.Session{
+		ID:         sess.SessionID,
+		Note:       sess.Note,
+		Group:      sess.GroupName,
+		RemoteAddr: sess.RemoteAddr,
+		ListenerID: sess.ListenerId,
+		IsAlive:    sess.IsAlive,
+		CreatedAt:  sess.CreatedAt,
+		LastSeen:   sess.Last,
+	}
+	if _, ok := core.Sessions.Get(sess.SessionID); ok {
+		s.IsAlive = true
+	}
+	if sess.Os != nil {
+		s.Hostname = sess.Os.Hostname
+		s.Username = sess.Os.Username
+		s.Os = strings.TrimSpace(sess.Os.Name + " " + sess.Os.Version)
+		s.Arch = sess.Os.Arch
+	}
+	if sess.Process != nil {
+		s.Process = sess.Process.Name
+		s.Pid = sess.Process.Pid
+	}
+	return s
+}
+
+func toReportTask(task models.Task) *report.Task {
+	t := &report.Task{
+		SessionID:   task.SessionID,
+		Type:        task.Type,
+		Operator:    task.Operator,
+		Cur:         task.Cur,
+		Total:       task.Total,
+		Description: task.Description,
+		CreatedAt:   task.CreatedAt,
+	}
+	if i := strings.LastIndex(task.ID, "-"); i != -1 {
+		id, _ := strconv.ParseUint(task.ID[i+1:], 10, 32)
+		t.ID = uint32(id)
+	}
+	return t
+}
@@ -0,0 +1,239 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/chainreactors/malice-network/helper/consts"
+	"github.com/chainreactors/malice-network/proto/client/clientpb"
+	"github.com/chainreactors/malice-network/server/internal/core"
+	"github.com/chainreactors/malice-network/server/internal/db"
+	"github.com/chainreactors/malice-network/server/internal/db/models"
+	"github.com/robfig/cron/v3"
+	"google.golang.org/grpc/metadata"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInvalidSchedule     = errors.New("must specify one of cron spec or time")
+	ErrInvalidScheduleTime = errors.New("schedule time has passed")
+	ErrScheduleTarget      = errors.New("must specify session or filter")
+
+	scheduler = &schedules{entries: map[uint32]cron.EntryID{}}
+)
+
+// schedules - cron entries of running schedules, keyed by schedule id
+type schedules struct {
+	mu      sync.Mutex
+	entries map[uint32]cron.EntryID
+}
+
+func (s *schedules) add(id uint32, entry cron.EntryID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[id] = entry
+}
+
+func (s *schedules) remove(id uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry, ok := s.entries[id]; ok {
+		core.GlobalTicker.Remove(entry)
+		delete(s.entries, id)
+	}
+}
+
+func (s *schedules) next(id uint32) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry, ok := s.entries[id]; ok {
+		return core.GlobalTicker.Next(entry)
+	}
+	return time.Time{}
+}
+
+// StartScheduler - register saved schedules, one-shot schedules missed while the server was down run at once
+func StartScheduler() error {
+	schedules, err := db.ListSchedules()
+	if err != nil {
+		return err
+	}
+	rpc := NewServer()
+	for _, schedule := range schedules {
+		if schedule.Paused {
+			continue
+		}
+		if schedule.Once() && !schedule.At.After(time.Now()) {
+			if schedule.Runs == 0 {
+				go rpc.runSchedule(schedule.ID)
+			}
+			continue
+		}
+		if err := rpc.registerSchedule(schedule); err != nil {
+			rpcLog.Errorf("cannot register schedule %d, %s", schedule.ID, err.Error())
+		}
+	}
+	return nil
+}
+
+func (rpc *Server) registerSchedule(schedule *models.Schedule) error {
+	id := schedule.ID
+	run := func() {
+		rpc.runSchedule(id)
+	}
+	if schedule.Once() {
+		if !schedule.At.After(time.Now()) {
+			return ErrInvalidScheduleTime
+		}
+		scheduler.add(id, core.GlobalTicker.At(schedule.At, run))
+		return nil
+	}
+	entry, err := core.GlobalTicker.Cron(schedule.Spec, run)
+	if err != nil {
+		return err
+	}
+	scheduler.add(id, entry)
+	return nil
+}
+
+// runSchedule - broadcast the scheduled request to its targets, every run is tracked by a batch
+func (rpc *Server) runSchedule(id uint32) {
+	schedule, err := db.FindSchedule(id)
+	if err != nil {
+		rpcLog.Errorf("cannot find schedule %d, %s", id, err.Error())
+		scheduler.remove(id)
+		return
+	}
+	if schedule.Once() {
+		scheduler.remove(id)
+	}
+	if schedule.Paused {
+		return
+	}
+
+	var batchID uint32
+	event := core.Event{EventType: consts.EventSchedule}
+	sessionIDs, err := resolveSessions(scheduleTargets(schedule))
+	if err == nil {
+		var batch *core.Batch
+		// tasks of the run are created by the operator of the schedule
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("operator", schedule.Operator))
+		batch, err = rpc.broadcast(ctx, sessionIDs, schedule.ToProtobuf().Request, schedule.Operator)
+		if err == nil {
+			batchID = batch.Id
+			event.Message = fmt.Sprintf("schedule %s: %s on %d sessions, batch %d",
+				scheduleName(schedule), schedule.Module, len(sessionIDs), batchID)
+		}
+	}
+	if err != nil {
+		event.Message = fmt.Sprintf("schedule %s: %s", scheduleName(schedule), schedule.Module)
+		event.Err = err.Error()
+	}
+	if err := db.RecordScheduleRun(schedule, batchID); err != nil {
+		rpcLog.Errorf("cannot record run of schedule %d, %s", id, err.Error())
+	}
+	rpcLog.Info(event.Message)
+	core.EventBroker.Publish(event)
+}
+
+func scheduleTargets(schedule *models.Schedule) ([]string, *clientpb.SessionFilter) {
+	if schedule.Filter != "" {
+		return nil, &clientpb.SessionFilter{Name: schedule.Filter}
+	}
+	return []string{schedule.SessionID}, nil
+}
+
+func scheduleName(schedule *models.Schedule) string {
+	if schedule.Name != "" {
+		return schedule.Name
+	}
+	return fmt.Sprintf("%d", schedule.ID)
+}
+
+// AddSchedule - run a task by cron spec, or once at a time, on a session or sessions matched by a saved filter
+func (rpc *Server) AddSchedule(ctx context.Context, req *clientpb.Schedule) (*clientpb.Schedule, error) {
+	if _, ok := broadcastHandlers[req.GetRequest().GetName()]; !ok {
+		return nil, ErrNotBroadcastable
+	}
+	if (req.Spec == "") == (req.At == 0) {
+		return nil, ErrInvalidSchedule
+	}
+	if req.Spec != "" {
+		if _, err := cron.ParseStandard(req.Spec); err != nil {
+			return nil, err
+		}
+	} else if !time.Unix(req.At, 0).After(time.Now()) {
+		return nil, ErrInvalidScheduleTime
+	}
+	if req.SessionId == "" && req.Filter == "" {
+		return nil, ErrScheduleTarget
+	}
+	if req.Filter != "" {
+		if _, err := db.FindSessionFilter(req.Filter); err != nil {
+			return nil, err
+		}
+	}
+
+	schedule := models.ScheduleFromProtobuf(req)
+	schedule.Paused = false
+	schedule.Operator = getClientName(ctx)
+	if err := db.AddSchedule(schedule); err != nil {
+		return nil, err
+	}
+	if err := rpc.registerSchedule(schedule); err != nil {
+		db.DeleteSchedule(schedule.ID)
+		return nil, err
+	}
+	rpcLog.Infof("Added schedule %s: %s", scheduleName(schedule), schedule.Module)
+	return scheduleToProtobuf(schedule), nil
+}
+
+func (rpc *Server) ListSchedules(ctx context.Context, _ *clientpb.Empty) (*clientpb.Schedules, error) {
+	schedules, err := db.ListSchedules()
+	if err != nil {
+		return nil, err
+	}
+	result := &clientpb.Schedules{Schedules: []*clientpb.Schedule{}}
+	for _, schedule := range schedules {
+		result.Schedules = append(result.Schedules, scheduleToProtobuf(schedule))
+	}
+	return result, nil
+}
+
+// PauseSchedule - pause or resume schedule by req.Paused
+func (rpc *Server) PauseSchedule(ctx context.Context, req *clientpb.Schedule) (*clientpb.Schedule, error) {
+	schedule, err := db.FindSchedule(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Paused {
+		scheduler.remove(schedule.ID)
+	} else if schedule.Paused {
+		if err := rpc.registerSchedule(schedule); err != nil {
+			return nil, err
+		}
+	}
+	if err := db.PauseSchedule(schedule.ID, req.Paused); err != nil {
+		scheduler.remove(schedule.ID)
+		return nil, err
+	}
+	schedule.Paused = req.Paused
+	return scheduleToProtobuf(schedule), nil
+}
+
+func (rpc *Server) RemoveSchedule(ctx context.Context, req *clientpb.Schedule) (*clientpb.Empty, error) {
+	scheduler.remove(req.Id)
+	if err := db.DeleteSchedule(req.Id); err != nil {
+		return nil, err
+	}
+	return &clientpb.Empty{}, nil
+}
+
+func scheduleToProtobuf(schedule *models.Schedule) *clientpb.Schedule {
+	pb := schedule.ToProtobuf()
+	if next := scheduler.next(schedule.ID); !next.IsZero() {
+		pb.NextRun = next.Unix()
+	}
+	return pb
+}
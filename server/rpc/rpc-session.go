@@ -7,6 +7,7 @@ import (
 	"github.com/chainreactors/malice-network/proto/implant/implantpb"
 	"github.com/chainreactors/malice-network/server/internal/core"
 	"github.com/chainreactors/malice-network/server/internal/db"
+	"github.com/chainreactors/malice-network/server/internal/db/models"
 )
 
 func (rpc *Server) GetSessions(ctx context.Context, _ *clientpb.Empty) (*clientpb.Sessions, error) {
@@ -47,10 +48,117 @@ func (rpc *Server) BasicSessionOP(ctx context.Context, req *clientpb.BasicUpdate
 		if err != nil {
 			return nil, err
 		}
+		if sess, ok := core.Sessions.Get(req.SessionId); ok && req.GroupName != "" {
+			sess.SetGroup(req.GroupName)
+		}
+	}
+	return &clientpb.Empty{}, nil
+}
+
+// ListSessionsByFilter - sessions matched by filter, a filter with only name refers to a saved filter
+func (rpc *Server) ListSessionsByFilter(ctx context.Context, req *clientpb.SessionFilter) (*clientpb.Sessions, error) {
+	filter, err := resolveSessionFilter(req)
+	if err != nil {
+		return nil, err
+	}
+	sessions := &clientpb.Sessions{}
+	for _, session := range core.Sessions.Filter(filter) {
+		sessions.Sessions = append(sessions.Sessions, session.ToProtobuf())
+	}
+	return sessions, nil
+}
+
+// UpdateSessions - set group and add/remove tags of sessions by id or filter, return updated sessions
+func (rpc *Server) UpdateSessions(ctx context.Context, req *clientpb.SessionsUpdate) (*clientpb.Sessions, error) {
+	var targets []*core.Session
+	if req.Filter != nil {
+		filter, err := resolveSessionFilter(req.Filter)
+		if err != nil {
+			return nil, err
+		}
+		targets = core.Sessions.Filter(filter)
+	} else {
+		for _, id := range req.SessionIds {
+			sess, ok := core.Sessions.Get(id)
+			if !ok {
+				return nil, ErrNotFoundSession
+			}
+			targets = append(targets, sess)
+		}
+	}
+
+	sessions := &clientpb.Sessions{}
+	for _, sess := range targets {
+		if req.Group != "" {
+			if err := db.UpdateSession(sess.ID, "", req.Group); err != nil {
+				return nil, err
+			}
+			sess.SetGroup(req.Group)
+		}
+		if len(req.AddTags) > 0 {
+			if err := db.AddSessionTags(sess.ID, req.AddTags); err != nil {
+				return nil, err
+			}
+			sess.AddTags(req.AddTags...)
+		}
+		if len(req.RemoveTags) > 0 {
+			if err := db.RemoveSessionTags(sess.ID, req.RemoveTags); err != nil {
+				return nil, err
+			}
+			sess.RemoveTags(req.RemoveTags...)
+		}
+		sessions.Sessions = append(sessions.Sessions, sess.ToProtobuf())
+	}
+	return sessions, nil
+}
+
+func (rpc *Server) ListSessionFilters(ctx context.Context, _ *clientpb.Empty) (*clientpb.SessionFilters, error) {
+	filters, err := db.ListSessionFilters()
+	if err != nil {
+		return nil, err
+	}
+	result := &clientpb.SessionFilters{}
+	for _, filter := range filters {
+		result.Filters = append(result.Filters, filter.ToProtobuf())
+	}
+	return result, nil
+}
+
+// SaveSessionFilter - create or replace a named filter
+func (rpc *Server) SaveSessionFilter(ctx context.Context, req *clientpb.SessionFilter) (*clientpb.Empty, error) {
+	if req.Name == "" {
+		return nil, ErrInvalidSessionFilter
+	}
+	// validate hostname regex and liveness before saving
+	if _, err := core.NewSessionFilter(req); err != nil {
+		return nil, err
+	}
+	err := db.SaveSessionFilter(models.SessionFilterFromProtobuf(req))
+	if err != nil {
+		return nil, err
+	}
+	return &clientpb.Empty{}, nil
+}
+
+func (rpc *Server) RemoveSessionFilter(ctx context.Context, req *clientpb.SessionFilter) (*clientpb.Empty, error) {
+	err := db.DeleteSessionFilter(req.Name)
+	if err != nil {
+		return nil, err
 	}
 	return &clientpb.Empty{}, nil
 }
 
+func resolveSessionFilter(req *clientpb.SessionFilter) (*core.SessionFilter, error) {
+	if req.Name != "" {
+		saved, err := db.FindSessionFilter(req.Name)
+		if err != nil {
+			return nil, err
+		}
+		req = saved.ToProtobuf()
+	}
+	return core.NewSessionFilter(req)
+}
+
 func (rpc *Server) Info(ctx context.Context, req *implantpb.Request) (*clientpb.Task, error) {
 	greq, err := newGenericRequest(ctx, req)
 	if err != nil {
@@ -6,6 +6,7 @@ import (
 	"github.com/chainreactors/malice-network/proto/implant/implantpb"
 	"github.com/chainreactors/malice-network/server/internal/core"
 	"github.com/chainreactors/malice-network/server/internal/db"
+	"google.golang.org/grpc/status"
 )
 
 func (rpc *Server) GetTasks(ctx context.Context, session *clientpb.Session) (*clientpb.Tasks, error) {
@@ -55,15 +56,20 @@ func (rpc *Server) WaitTaskContent(ctx context.Context, req *clientpb.Task) (*im
 	if task == nil {
 		return nil, ErrNotFoundTask
 	}
-	select {
-	case <-task.Ctx.Done():
-		msg, ok := sess.GetLastMessage(int(task.Id))
-		if ok {
-			return msg, nil
-		} else if task.Status != nil {
-			return task.Status, nil
+	// tasks loaded from history have no context, they are already finished
+	if running(task) {
+		select {
+		case <-task.Ctx.Done():
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
 		}
 	}
+	msg, ok := sess.GetLastMessage(int(task.Id))
+	if ok {
+		return msg, nil
+	} else if task.Status != nil {
+		return task.Status, nil
+	}
 	return nil, ErrNotFoundTaskContent
 }
 
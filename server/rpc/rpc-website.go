@@ -2,6 +2,7 @@ package rpc
 
 import (
 	"context"
+	"fmt"
 	"github.com/chainreactors/malice-network/helper/consts"
 	"github.com/chainreactors/malice-network/proto/client/clientpb"
 	"github.com/chainreactors/malice-network/proto/implant/implantpb"
@@ -9,6 +10,7 @@ import (
 	"github.com/chainreactors/malice-network/server/internal/core"
 	"github.com/chainreactors/malice-network/server/internal/db"
 	"github.com/chainreactors/malice-network/server/internal/website"
+	"google.golang.org/protobuf/proto"
 	"mime"
 	"path/filepath"
 )
@@ -96,14 +98,20 @@ func (rpc *Server) WebsiteAddContent(ctx context.Context, req *lispb.WebsiteAddC
 	return website.MapContent(req.Name, true)
 }
 
-// WebsiteUpdateContent - Update specific content from a website, currently you can only the update Content-type field
+// WebsiteUpdateContent - Update content type, headers, status code and redirect of specific content
 func (rpc *Server) WebsiteUpdateContent(ctx context.Context, req *lispb.WebsiteAddContent) (*lispb.Website, error) {
 	dbWebsite, err := website.WebsiteByName(req.Name)
 	if err != nil {
 		return nil, err
 	}
-	for _, content := range req.Contents {
-		website.AddContent(dbWebsite.Name, content)
+	for path, content := range req.Contents {
+		if content.Path == "" {
+			content.Path = path
+		}
+		_, err = website.UpdateContent(dbWebsite.Name, content)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	core.EventBroker.Publish(core.Event{
@@ -207,3 +215,33 @@ func (rpc *Server) ListWebsites(ctx context.Context, req *lispb.ListenerName) (*
 	}
 	return &lispb.Websites{Websites: websites}, nil
 }
+
+// WebsiteAccess - access of website content reported by listener
+func (rpc *Server) WebsiteAccess(ctx context.Context, req *lispb.WebsiteAccess) (*implantpb.Empty, error) {
+	err := db.AddWebsiteAccess(req)
+	if err != nil {
+		return nil, err
+	}
+	data, _ := proto.Marshal(req)
+	core.EventBroker.Publish(core.Event{
+		EventType:  consts.EventWebsite,
+		SourceName: req.Name,
+		Message: fmt.Sprintf("%s %s %s %s from %s, %d, %d bytes",
+			consts.WebsiteHit, req.Name, req.Method, req.Path, req.RemoteAddr, req.Status, req.Bytes),
+		Data: data,
+	})
+	return &implantpb.Empty{}, nil
+}
+
+// WebsiteLogs - access logs of website, latest first
+func (rpc *Server) WebsiteLogs(ctx context.Context, req *lispb.WebsiteLogRequest) (*lispb.WebsiteAccessLogs, error) {
+	accesses, err := db.ListWebsiteAccess(req.Name, req.Path, req.RemoteAddr, int(req.Limit))
+	if err != nil {
+		return nil, err
+	}
+	logs := &lispb.WebsiteAccessLogs{}
+	for _, access := range accesses {
+		logs.Logs = append(logs.Logs, access.ToProtobuf())
+	}
+	return logs, nil
+}
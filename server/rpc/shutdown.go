@@ -0,0 +1,101 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"github.com/chainreactors/logs"
+	"github.com/chainreactors/malice-network/helper/consts"
+	"github.com/chainreactors/malice-network/proto/client/clientpb"
+	"github.com/chainreactors/malice-network/server/internal/core"
+	"github.com/chainreactors/malice-network/server/internal/db"
+	"google.golang.org/grpc"
+	"sync"
+	"time"
+)
+
+var (
+	// streamsCtx - cancelled on shutdown, long-lived streams return so that GracefulStop can finish
+	streamsCtx, cancelStreams = context.WithCancel(context.Background())
+	// listenerStreams - job streams of connected listeners
+	listenerStreams = &sync.Map{}
+)
+
+// listenerStream - ctrl sent to one listener only, done is closed once its sender stops
+type listenerStream struct {
+	ctrl chan *clientpb.JobCtrl
+	done chan struct{}
+}
+
+// Shutdown - stop the server in order: stop accepting rpcs, notify clients and listeners, stop scheduled jobs,
+// save in-flight tasks and session caches, then close the database. Each step waits at most timeout.
+func Shutdown(grpcServer *grpc.Server, timeout time.Duration) error {
+	stopped := make(chan struct{})
+	go func() {
+		// new rpcs are refused at once, running rpcs and streams are waited
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	// open event and job streams still deliver until the grpc server is stopped
+	core.EventBroker.Publish(core.Event{
+		EventType: consts.EventShutdown,
+		Message:   "server is shutting down",
+	})
+	notifyListeners(timeout)
+	cancelStreams()
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		grpcServer.Stop()
+	}
+
+	select {
+	case <-core.GlobalTicker.Stop().Done():
+	case <-time.After(timeout):
+		logs.Log.Warnf("scheduled jobs still running after %s", timeout)
+	}
+
+	var errs []error
+	count, err := SaveInflightTasks()
+	if err != nil {
+		errs = append(errs, err)
+	} else if count > 0 {
+		logs.Log.Importantf("saved %d in-flight tasks", count)
+	}
+	for _, sess := range core.Sessions.All() {
+		if sess.Cache == nil {
+			continue
+		}
+		if err := sess.Save(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := db.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// notifyListeners - tell every connected listener the server is going away, waits until the ctrl is sent
+func notifyListeners(timeout time.Duration) {
+	deadline := time.After(timeout)
+	listenerStreams.Range(func(key, _ interface{}) bool {
+		ls := key.(*listenerStream)
+		select {
+		case ls.ctrl <- &clientpb.JobCtrl{
+			Id:   core.NextCtrlID(),
+			Ctrl: consts.CtrlServerShutdown,
+		}:
+		case <-ls.done:
+			return true
+		case <-deadline:
+			return false
+		}
+		select {
+		case <-ls.done:
+			return true
+		case <-deadline:
+			return false
+		}
+	})
+}
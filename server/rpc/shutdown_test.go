@@ -0,0 +1,145 @@
+package rpc
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chainreactors/malice-network/helper/consts"
+	"github.com/chainreactors/malice-network/proto/client/clientpb"
+	"github.com/chainreactors/malice-network/proto/implant/implantpb"
+	"github.com/chainreactors/malice-network/proto/listener/lispb"
+	"github.com/chainreactors/malice-network/server/internal/configs"
+	"github.com/chainreactors/malice-network/server/internal/core"
+	"github.com/chainreactors/malice-network/server/internal/db"
+	"github.com/chainreactors/malice-network/server/internal/db/models"
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
+)
+
+func openTestDB(t *testing.T, path string) {
+	client, err := gorm.Open(db.Open(path), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.AutoMigrate(&models.QueuedTask{}); err != nil {
+		t.Fatal(err)
+	}
+	db.Client = client
+}
+
+func newTestSession(id string) *core.Session {
+	return core.NewSession(&lispb.RegisterSession{
+		SessionId:  id,
+		ListenerId: "tcp_default",
+		RegisterData: &implantpb.RegisterData{
+			Name:  id,
+			Timer: &implantpb.Timer{Interval: 10},
+		},
+	})
+}
+
+func TestShutdown(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "malice.db")
+	oldClient, oldCachePath, oldSessions, oldTicker := db.Client, configs.CachePath, core.Sessions, core.GlobalTicker
+	t.Cleanup(func() {
+		db.Client, configs.CachePath, core.Sessions, core.GlobalTicker = oldClient, oldCachePath, oldSessions, oldTicker
+	})
+	configs.CachePath = dir
+	core.Sessions = core.NewSessions()
+	core.GlobalTicker = nil
+	core.NewTicker()
+	openTestDB(t, dbPath)
+
+	sess := newTestSession("shutdown")
+	inflight := sess.NewTask("inflight", 2)
+	inflight.Cur = 1
+	done := sess.NewTask("done", 1)
+	done.Close()
+	sess.AddMessage(&implantpb.Spite{TaskId: done.Id, Name: "done"}, 1)
+	core.Sessions.Add(sess)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcServer := grpc.NewServer()
+	go grpcServer.Serve(ln)
+
+	events := core.EventBroker.Subscribe()
+	defer core.EventBroker.Unsubscribe(events)
+	// two connected listeners, each must get its own shutdown ctrl
+	ctrls := make(chan uint32, 2)
+	for i := 0; i < 2; i++ {
+		ls := &listenerStream{ctrl: make(chan *clientpb.JobCtrl, 1), done: make(chan struct{})}
+		listenerStreams.Store(ls, true)
+		defer listenerStreams.Delete(ls)
+		go func() {
+			defer close(ls.done)
+			ctrls <- (<-ls.ctrl).Ctrl
+		}()
+	}
+
+	if err := Shutdown(grpcServer, 200*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case ctrl := <-ctrls:
+			if ctrl != consts.CtrlServerShutdown {
+				t.Errorf("listener got ctrl %d", ctrl)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("listener %d not notified", i)
+		}
+	}
+	notified := false
+	for !notified {
+		select {
+		case event := <-events:
+			notified = event.EventType == consts.EventShutdown
+		case <-time.After(time.Second):
+			t.Fatal("clients not notified")
+		}
+	}
+	if conn, err := net.DialTimeout("tcp", ln.Addr().String(), time.Second); err == nil {
+		conn.Close()
+		t.Error("grpc server still accepting")
+	}
+	if err := db.Client.Exec("SELECT 1").Error; err == nil {
+		t.Error("database not closed")
+	}
+
+	// restart: cache is readable and the in-flight task is restored with its response channel
+	restarted := newTestSession("shutdown")
+	if err := restarted.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := restarted.GetLastMessage(int(done.Id)); !ok {
+		t.Error("cache not saved")
+	}
+	openTestDB(t, dbPath)
+	RecoverQueuedTasks(restarted)
+	task := restarted.Tasks.Get(inflight.Id)
+	if task == nil {
+		t.Fatal("in-flight task not restored")
+	}
+	if task.Cur != 1 || task.Total != 2 {
+		t.Errorf("in-flight task restored as %s", task)
+	}
+	if _, ok := restarted.GetResp(inflight.Id); !ok {
+		t.Error("in-flight task has no response channel")
+	}
+	if next := restarted.NewTask("next", 1); next.Id <= inflight.Id {
+		t.Errorf("new task reuses id %d", next.Id)
+	}
+	if delivered, _ := db.FindDeliveredTasks(restarted.ID); len(delivered) != 0 {
+		t.Error("restored tasks left in database")
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,68 +1,87 @@
 package web
 
 import (
+	"crypto/tls"
 	"fmt"
 	"github.com/chainreactors/logs"
+	"github.com/chainreactors/malice-network/proto/listener/lispb"
+	"github.com/chainreactors/malice-network/server/internal/configs"
+	"github.com/chainreactors/malice-network/server/internal/router"
 	"github.com/chainreactors/malice-network/server/internal/website"
+	"github.com/chainreactors/malice-network/server/listener/encryption"
 	"net/http"
 )
 
 type HTTPServer struct {
 	port        int
-	server      *http.Server
+	host        string
+	server      *router.Server
+	router      *router.Router
 	rootPath    string
 	websiteName string
+	TlsConfig   *configs.TlsConfig
+	OnAccess    func(*lispb.WebsiteAccess)
 }
 
-func NewHTTPServer(port int, rootPath, websiteName string) *HTTPServer {
+func NewHTTPServer(port int, host, rootPath, websiteName string) *HTTPServer {
 	return &HTTPServer{
 		port:        port,
+		host:        host,
+		router:      router.NewRouter(),
 		rootPath:    rootPath,
 		websiteName: websiteName,
 	}
 }
 
 func (s *HTTPServer) Start() {
-	// 定义 HTTP 请求处理函数
-	http.HandleFunc(s.rootPath, s.websiteContentHandler)
+	// 每个website使用独立的路由, 同端口的website按Host区分
+	s.router.HandleFunc(s.rootPath, website.AccessLog(s.websiteName, s.websiteContentHandler, s.OnAccess))
 
-	// 启动 HTTP 服务器并监听在指定端口
-	s.server = &http.Server{Addr: fmt.Sprintf(":%d", s.port)}
-	go func() {
-		logs.Log.Importantf("HTTP Server is running on port %d", s.port)
-		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	var tlsConfig *tls.Config
+	var err error
+	if s.TlsConfig != nil && (s.TlsConfig.Enable || s.TlsConfig.Acme) {
+		tlsConfig, err = encryption.WrapToTlsConfig(s.TlsConfig)
+		if err != nil {
 			logs.Log.Errorf("HTTP Server failed to start: %v", err)
+			return
+		}
+	}
+	server, err := router.Listen(fmt.Sprintf(":%d", s.port), tlsConfig)
+	if err == nil {
+		err = server.AddHost(s.host, s.router, tlsConfig)
+	}
+	if err != nil {
+		if tlsConfig != nil {
+			encryption.ReleaseCertificate(s.TlsConfig)
 		}
-	}()
+		logs.Log.Errorf("HTTP Server failed to start: %v", err)
+		return
+	}
+	s.server = server
 }
 
 func (s *HTTPServer) Stop() {
 	if s.server != nil {
 		logs.Log.Importantf("Stopping server")
-		err := s.server.Shutdown(nil)
+		err := s.server.RemoveHost(s.host)
 		if err != nil {
 			logs.Log.Errorf("Error shutting down server: %v", err)
 		}
+		if s.TlsConfig != nil {
+			encryption.ReleaseCertificate(s.TlsConfig)
+		}
+		s.server = nil
 	}
 }
 
 func (s *HTTPServer) AddFileRoute(routePath, localFilePath string) {
-	http.Handle(routePath, http.FileServer(http.Dir(localFilePath)))
+	s.router.Handle(routePath, http.FileServer(http.Dir(localFilePath)))
 }
 
 func (s *HTTPServer) DeleteFileRoute(routePath string) {
-	// 取消注册指定路由的处理器
-	http.DefaultServeMux.Handle(routePath, nil)
-	http.DefaultServeMux.HandleFunc(routePath, nil)
+	s.router.Remove(routePath)
 }
 
 func (s *HTTPServer) websiteContentHandler(resp http.ResponseWriter, req *http.Request) {
-	content, err := website.GetContent(s.websiteName, req.URL.Path)
-	if err != nil {
-		logs.Log.Errorf("Failed to get content %s", err)
-		return
-	}
-	resp.Header().Set("Content-Type", content.ContentType)
-	resp.Header().Add("Cache-Control", "no-store, no-cache, must-revalidate")
-	resp.Write(content.Content)
+	website.ServeWebsite(s.websiteName, resp, req)
 }